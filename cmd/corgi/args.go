@@ -19,6 +19,13 @@ import (
 var (
 	// Misc
 
+	// Command is the subcommand to run, or empty, if the input file should
+	// be generated.
+	//
+	// If it is set, none of the other variables are initialized, and the
+	// subcommand parses its own flags.
+	Command string
+
 	IsGoGenerate       bool
 	ConfigDir          string
 	TrustedFiltersFile string
@@ -35,12 +42,22 @@ var (
 
 	GoExecPath string
 
+	Watch bool
+
 	Verbose bool
 	Debug   bool
+	Pretty  bool
+
+	LineDirectives bool
+	TypeCheck      bool
 
 	ForceColorSetting bool
 	Color             bool
 
+	// Format is the format errors are printed in, either FormatPretty,
+	// FormatJSON, or FormatSARIF.
+	Format string
+
 	TrustedFilters     []string
 	TrustAllFilters    bool
 	editTrustedFilters bool
@@ -48,10 +65,32 @@ var (
 	// Args
 
 	InFile string
-	InData []byte
+	// InPattern indicates that InFile is a pattern matching multiple main
+	// files, i.e. either ends in `/...` or is a glob.
+	InPattern bool
+	InData    []byte
 )
 
-func init() {
+// Formats usable with the -format flag.
+const (
+	FormatPretty = "pretty"
+	FormatJSON   = "json"
+	FormatSARIF  = "sarif"
+)
+
+// parseArgs initializes the above variables from the command line arguments.
+//
+// It is called by main, instead of being an init func, so that the package
+// can be tested.
+func parseArgs() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "fmt", "lsp", "explain":
+			Command = os.Args[1]
+			return
+		}
+	}
+
 	IsGoGenerate = os.Getenv("GOFILE") != ""
 
 	if runtime.GOOS == "windows" {
@@ -86,8 +125,17 @@ func init() {
 			"'-o', if not set, will default to `"+corgi.PrecompFileName+"`")
 	flag.BoolVar(&NoGoImports, "nogoimports", false, "do not run goimports on the generated file")
 	flag.StringVar(&GoExecPath, "go", "", "set the `PATH` of the go executable (default: $GOROOT/bin/go)")
+	flag.BoolVar(&Watch, "watch", false,
+		"keep running and regenerate the output whenever an input file or one of its dependencies changes;\n"+
+			"not compatible with stdin or -lib")
 	flag.BoolVar(&Verbose, "v", false, "enable verbose output to stderr")
 	flag.BoolVar(&Debug, "debug", false, "print file and line information as comments in the generated function")
+	flag.BoolVar(&Pretty, "pretty", false,
+		"generate indented HTML and don't minify CSS and JS, e.g. for debugging or snapshot tests")
+	flag.BoolVar(&LineDirectives, "line-directives", false,
+		"add line directives to the generated file, so that compiler errors and panics point to the corgi file")
+	flag.BoolVar(&TypeCheck, "typecheck", false,
+		"type-check the Go code of the input file before writing the output, reporting errors in the corgi file")
 	flag.Func("color", "force or disable coloring of errors (`true/false`)", func(s string) error {
 		ForceColorSetting = true
 
@@ -102,6 +150,9 @@ func init() {
 
 		return nil
 	})
+	flag.StringVar(&Format, "format", FormatPretty,
+		"print errors in the given `FORMAT`, one of "+FormatPretty+", "+FormatJSON+", or "+FormatSARIF+";\n"+
+			"json and sarif are printed without color")
 	flag.Func("colour", "force or disable colouring of errors, even if you're British (`true/false`)",
 		func(s string) error {
 			ForceColorSetting = true
@@ -180,12 +231,27 @@ func init() {
 		}
 	}
 
+	switch Format {
+	case FormatPretty, FormatJSON, FormatSARIF:
+	default:
+		fmt.Fprintf(os.Stderr, "invalid -format %q, expected one of %s, %s, or %s\n",
+			Format, FormatPretty, FormatJSON, FormatSARIF)
+		os.Exit(2)
+	}
+
 	if OutFile != "" && UseStdout {
 		fmt.Fprintln(os.Stderr, "conflicting flags -o and -stdout")
 		os.Exit(2)
 	}
 
 	InFile = flag.Arg(0)
+	InPattern = !PrecompileLibrary && isPattern(InFile)
+
+	if Watch && (InFile == "" || PrecompileLibrary) {
+		fmt.Fprintln(os.Stderr, "-watch can only be used with an input file, and not with -lib")
+		os.Exit(2)
+	}
+
 	if InFile == "" {
 		if PrecompileLibrary {
 			fmt.Fprintln(os.Stderr, "need directory to precompile")
@@ -210,7 +276,17 @@ func init() {
 		os.Exit(2)
 	}
 
-	if OutFile == "" && !UseStdout {
+	if InPattern {
+		if OutFile != "" {
+			fmt.Fprintln(os.Stderr, "cannot use `-o` with a pattern")
+			os.Exit(2)
+		} else if UseStdout {
+			fmt.Fprintln(os.Stderr, "cannot use `-stdout` with a pattern")
+			os.Exit(2)
+		}
+	}
+
+	if OutFile == "" && !UseStdout && !InPattern {
 		if PrecompileLibrary {
 			OutFile = filepath.Join(InFile, corgi.PrecompFileName)
 		} else {
@@ -233,16 +309,33 @@ func init() {
 	}
 }
 
+func isPattern(s string) bool {
+	return s == "..." || strings.HasSuffix(s, "/...") || strings.HasSuffix(s, string(filepath.Separator)+"...") ||
+		strings.ContainsAny(s, "*?[")
+}
+
 func usage() {
 	fmt.Fprintln(flag.CommandLine.Output(), "This is the compiler for the corgi template language.")
 	fmt.Fprintln(flag.CommandLine.Output(), "https://github.com/mavolin/corgi")
 	fmt.Fprintln(flag.CommandLine.Output())
-	fmt.Fprintln(flag.CommandLine.Output(), "Usage: corgi [options] [INFILE]")
+	fmt.Fprintln(flag.CommandLine.Output(), "Usage: corgi [options] [INFILE | PATTERN]")
 	fmt.Fprintln(flag.CommandLine.Output(), "       corgi [options] -lib DIR")
+	fmt.Fprintln(flag.CommandLine.Output(), "       corgi fmt [-w] [-l] [-d] [PATH ...]")
+	fmt.Fprintln(flag.CommandLine.Output(), "       corgi lsp [-go PATH] [-v]")
+	fmt.Fprintln(flag.CommandLine.Output(), "       corgi explain CODE")
 	fmt.Fprintln(flag.CommandLine.Output())
 	fmt.Fprintln(flag.CommandLine.Output(),
 		"Input may be passed through stdin, however, this will disable loading of the file's dir library.")
 	fmt.Fprintln(flag.CommandLine.Output())
+	fmt.Fprintln(flag.CommandLine.Output(),
+		"Instead of a single INFILE, a PATTERN may be given to generate multiple main files")
+	fmt.Fprintln(flag.CommandLine.Output(),
+		"at once, which is either a glob, or a DIR/... pattern, which recursively matches all")
+	fmt.Fprintln(flag.CommandLine.Output(),
+		"corgi files in DIR and its subdirectories.")
+	fmt.Fprintln(flag.CommandLine.Output(),
+		"Only files containing a func header are generated, and -o and -stdout cannot be used.")
+	fmt.Fprintln(flag.CommandLine.Output())
 	fmt.Fprintln(flag.CommandLine.Output(), "If -lib is specified, the FILE/DIR argument is mandatory.")
 	fmt.Fprintln(flag.CommandLine.Output())
 	fmt.Fprintln(flag.CommandLine.Output(),
@@ -254,6 +347,10 @@ func usage() {
 		"If ./... is used, the -o flag has no effect and the precompiled files will be")
 	fmt.Fprintln(flag.CommandLine.Output(), "placed directly into the respective directories.")
 	fmt.Fprintln(flag.CommandLine.Output())
+	fmt.Fprintln(flag.CommandLine.Output(), "Run 'corgi fmt -h' for help on formatting corgi files.")
+	fmt.Fprintln(flag.CommandLine.Output(), "Run 'corgi lsp -h' for help on the corgi language server.")
+	fmt.Fprintln(flag.CommandLine.Output(), "Run 'corgi explain CODE' for a detailed explanation of an error, e.g. C0042.")
+	fmt.Fprintln(flag.CommandLine.Output())
 	fmt.Fprintln(flag.CommandLine.Output(), "Flags:")
 	flag.PrintDefaults()
 }
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mavolin/corgi/corgierr"
+)
+
+// runExplain runs the explain subcommand using the passed args, which
+// exclude the name of the subcommand itself.
+func runExplain(args []string) error {
+	flags := flag.NewFlagSet("explain", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: corgi explain CODE")
+		fmt.Fprintln(flags.Output())
+		fmt.Fprintln(flags.Output(), "Prints a detailed explanation of the error with the given code, e.g. C0042,")
+		fmt.Fprintln(flags.Output(), "including an example of the wrong and the right way.")
+	}
+
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	explanation, ok := corgierr.Code(flags.Arg(0)).Explanation()
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown error code %q\n", flags.Arg(0))
+		os.Exit(1)
+	}
+
+	fmt.Print(explanation)
+	return nil
+}
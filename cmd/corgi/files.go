@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mavolin/corgi"
+	"github.com/mavolin/corgi/parse"
+)
+
+// mainFiles returns the paths of the main files to generate.
+//
+// If InFile is not a pattern, mainFiles simply returns InFile.
+// Otherwise, it returns all corgi main files matching the pattern, sorted
+// by name.
+func mainFiles() ([]string, error) {
+	if !InPattern {
+		return []string{InFile}, nil
+	}
+
+	var candidates []string
+
+	if slashIn := filepath.ToSlash(InFile); strings.HasSuffix(slashIn, "...") {
+		dir := strings.TrimSuffix(strings.TrimSuffix(slashIn, "..."), "/")
+		if dir == "" {
+			dir = "."
+		}
+
+		err := filepath.WalkDir(filepath.FromSlash(dir), func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				// skip the same dirs as the go command does
+				name := d.Name()
+				if p != filepath.FromSlash(dir) &&
+					(strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata") {
+					return filepath.SkipDir
+				}
+
+				return nil
+			}
+
+			if strings.HasSuffix(p, corgi.Ext) {
+				candidates = append(candidates, p)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		matches, err := filepath.Glob(InFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+
+		for _, m := range matches {
+			if strings.HasSuffix(m, corgi.Ext) {
+				candidates = append(candidates, m)
+			}
+		}
+	}
+
+	files := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		isMain, err := isMainFile(c)
+		if err != nil {
+			return nil, err
+		}
+
+		if isMain {
+			files = append(files, c)
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// isMainFile reports whether the corgi file located at p is a main file, and
+// not a template or include, i.e. whether it has a func header.
+//
+// Files that cannot be parsed are reported as main files, so that their
+// errors are reported when they are loaded.
+func isMainFile(p string) (bool, error) {
+	in, err := os.ReadFile(p)
+	if err != nil {
+		return false, err
+	}
+
+	f, err := parse.Parse(in)
+	if err != nil {
+		return true, nil //nolint:nilerr
+	}
+
+	return f != nil && f.Func != nil, nil
+}
+
+// outFile returns the path of the file to generate inFile to.
+func outFile(inFile string) string {
+	if OutFile != "" {
+		return OutFile
+	}
+
+	return inFile + ".go"
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi"
+)
+
+// newNestedModules writes a module, containing a nested module, to a
+// temporary dir and returns it.
+//
+// Both modules contain a main file, page.corgi, that extends a template only
+// found in its own module.
+func newNestedModules(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"go.mod":                  "module example.com/outer\n\ngo 1.19\n",
+		"tmpl.corgi":              "block main\n",
+		"page.corgi":              "extend \"example.com/outer/tmpl.corgi\"\n\nfunc Outer()\n\nblock main\n  p outer\n",
+		"nested/go.mod":           "module example.com/nested\n\ngo 1.19\n",
+		"nested/tmpl.corgi":       "block main\n",
+		"nested/page.corgi":       "extend \"example.com/nested/tmpl.corgi\"\n\nfunc Nested()\n\nblock main\n  p nested\n",
+		"nested/incl.corgi":       "p included\n",
+		"_skipped/page.corgi":     "func Skipped()\n",
+		"testdata/page.corgi":     "func Skipped()\n",
+		"nested/.skip/page.corgi": "func Skipped()\n",
+	}
+
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+
+	return dir
+}
+
+// setInFile sets InFile and InPattern for the duration of the test.
+func setInFile(t *testing.T, inFile string) {
+	t.Helper()
+
+	oldInFile, oldInPattern := InFile, InPattern
+	t.Cleanup(func() { InFile, InPattern = oldInFile, oldInPattern })
+
+	InFile, InPattern = inFile, isPattern(inFile)
+}
+
+func TestIsMainFile(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := []struct {
+		name   string
+		in     string
+		expect bool
+	}{
+		{name: "main", in: "func Page()\n\np foo\n", expect: true},
+		{name: "template", in: "block main\n", expect: false},
+		{name: "include", in: "p foo\n", expect: false},
+		{name: "empty", in: "", expect: false},
+		{name: "parse error", in: "func Page(\n", expect: true},
+	}
+
+	for _, c := range testCases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			p := filepath.Join(dir, c.name+corgi.Ext)
+			require.NoError(t, os.WriteFile(p, []byte(c.in), 0o644))
+
+			actual, err := isMainFile(p)
+			require.NoError(t, err)
+			assert.Equal(t, c.expect, actual)
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := isMainFile(filepath.Join(dir, "missing"+corgi.Ext))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestMainFiles(t *testing.T) {
+	dir := newNestedModules(t)
+
+	testCases := []struct {
+		name   string
+		inFile string
+		expect []string
+	}{
+		{
+			name:   "single file",
+			inFile: filepath.Join(dir, "tmpl.corgi"),
+			expect: []string{filepath.Join(dir, "tmpl.corgi")},
+		},
+		{
+			name:   "recursive",
+			inFile: filepath.Join(dir, "..."),
+			expect: []string{filepath.Join(dir, "nested", "page.corgi"), filepath.Join(dir, "page.corgi")},
+		},
+		{
+			name:   "glob",
+			inFile: filepath.Join(dir, "nested", "*"+corgi.Ext),
+			expect: []string{filepath.Join(dir, "nested", "page.corgi")},
+		},
+	}
+
+	for _, c := range testCases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			setInFile(t, c.inFile)
+
+			actual, err := mainFiles()
+			require.NoError(t, err)
+			assert.Equal(t, c.expect, actual)
+		})
+	}
+}
+
+func TestWriteFiles(t *testing.T) {
+	goExec, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not found in $PATH")
+	}
+
+	dir := newNestedModules(t)
+	setInFile(t, filepath.Join(dir, "..."))
+
+	oldPackage, oldNoGoImports := Package, NoGoImports
+	t.Cleanup(func() { Package, NoGoImports = oldPackage, oldNoGoImports })
+	Package, NoGoImports = "test", true
+
+	err = writeFiles(corgi.LoadOptions{GoExecPath: goExec, NoPrecompile: true})
+	require.NoError(t, err)
+
+	for _, name := range []string{"page.corgi.go", "nested/page.corgi.go"} {
+		out, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if assert.NoError(t, err, name) {
+			assert.Contains(t, string(out), "package test", name)
+		}
+	}
+
+	for _, name := range []string{"tmpl.corgi.go", "nested/incl.corgi.go", "_skipped/page.corgi.go"} {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+		assert.ErrorIs(t, err, os.ErrNotExist, name)
+	}
+}
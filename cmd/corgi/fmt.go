@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mavolin/corgi"
+	"github.com/mavolin/corgi/corgierr"
+	"github.com/mavolin/corgi/format"
+)
+
+var (
+	fmtWrite bool
+	fmtList  bool
+	fmtDiff  bool
+)
+
+// runFmt runs the fmt subcommand using the passed args, which exclude the
+// name of the subcommand itself.
+//
+// Errors are printed to stderr, and cause runFmt to exit with status code 1
+// after all files were processed.
+func runFmt(args []string) error {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: corgi fmt [-w] [-l] [-d] [PATH ...]")
+		fmt.Fprintln(flags.Output())
+		fmt.Fprintln(flags.Output(), "Formats the passed corgi files, or all corgi and corgi library files in the")
+		fmt.Fprintln(flags.Output(), "passed directories and their subdirectories.")
+		fmt.Fprintln(flags.Output(), "If no path is given, stdin is formatted.")
+		fmt.Fprintln(flags.Output())
+		fmt.Fprintln(flags.Output(), "By default, the formatted files are written to stdout.")
+		fmt.Fprintln(flags.Output())
+		fmt.Fprintln(flags.Output(), "Flags:")
+		flags.PrintDefaults()
+	}
+
+	flags.BoolVar(&fmtWrite, "w", false, "write the result to the source file instead of stdout")
+	flags.BoolVar(&fmtList, "l", false, "list files whose formatting differs from corgi fmt's")
+	flags.BoolVar(&fmtDiff, "d", false, "display diffs instead of rewriting files")
+
+	_ = flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		if fmtWrite {
+			fmt.Fprintln(os.Stderr, "cannot use -w with stdin")
+			os.Exit(2)
+		}
+
+		in, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("could not read stdin: %w", err)
+		}
+
+		if err := fmtFile("<standard input>", in, 0); err != nil {
+			printFmtErr(err)
+			os.Exit(1)
+		}
+
+		return nil
+	}
+
+	var failed bool
+
+	for _, root := range flags.Args() {
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				return nil
+			}
+
+			// files passed explicitly are always formatted
+			if p != root && !isCorgiFile(d.Name()) {
+				return nil
+			}
+
+			fi, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			in, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+
+			if err := fmtFile(p, in, fi.Mode().Perm()); err != nil {
+				printFmtErr(err)
+				failed = true
+			}
+
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+
+	return nil
+}
+
+func isCorgiFile(name string) bool {
+	return !strings.HasPrefix(name, ".") &&
+		(strings.HasSuffix(name, corgi.Ext) || strings.HasSuffix(name, corgi.LibExt))
+}
+
+// fmtFile formats the file with the passed name and contents, and, depending
+// on the flags set, prints or writes the result.
+//
+// perm is the permission used to write the file, if -w is set.
+func fmtFile(name string, in []byte, perm fs.FileMode) error {
+	out, err := format.Source(in)
+	if err != nil {
+		if lerr := corgierr.As(err); lerr != nil {
+			for _, err := range lerr {
+				if err.ErrorAnnotation.File != nil {
+					err.ErrorAnnotation.File.Name = name
+				}
+			}
+		}
+
+		return err
+	}
+
+	if !fmtWrite && !fmtList && !fmtDiff {
+		_, err := os.Stdout.Write(out)
+		return err
+	}
+
+	if bytes.Equal(in, out) {
+		return nil
+	}
+
+	if fmtList {
+		fmt.Println(name)
+	}
+
+	if fmtWrite {
+		if err := os.WriteFile(name, out, perm); err != nil {
+			return err
+		}
+	}
+
+	if fmtDiff {
+		fmt.Printf("diff %s.orig %s\n", name, name)
+		_, err := os.Stdout.Write(unifiedDiff(name+".orig", name, in, out))
+		return err
+	}
+
+	return nil
+}
+
+func printFmtErr(err error) {
+	if lerr := corgierr.As(err); lerr != nil {
+		fmt.Fprintln(os.Stderr, lerr.Pretty(prettyOptions("")))
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, err.Error())
+}
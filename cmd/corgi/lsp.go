@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"golang.org/x/exp/slog"
+
+	"github.com/mavolin/corgi"
+	"github.com/mavolin/corgi/lsp"
+)
+
+var (
+	lspGoExecPath string
+	lspVerbose    bool
+)
+
+// runLSP runs the lsp subcommand using the passed args, which exclude the
+// name of the subcommand itself.
+func runLSP(args []string) error {
+	flags := flag.NewFlagSet("lsp", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: corgi lsp [-go PATH] [-v]")
+		fmt.Fprintln(flags.Output())
+		fmt.Fprintln(flags.Output(), "Starts a language server for corgi files, that communicates using the")
+		fmt.Fprintln(flags.Output(), "Language Server Protocol over stdin and stdout.")
+		fmt.Fprintln(flags.Output())
+		fmt.Fprintln(flags.Output(), "Flags:")
+		flags.PrintDefaults()
+	}
+
+	flags.StringVar(&lspGoExecPath, "go", "", "set the `PATH` of the go executable (default: $GOROOT/bin/go)")
+	flags.BoolVar(&lspVerbose, "v", false, "enable verbose output to stderr")
+
+	_ = flags.Parse(args)
+
+	loadOpts := corgi.LoadOptions{GoExecPath: lspGoExecPath}
+	if lspVerbose {
+		loadOpts.Logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+
+	s, err := lsp.New(loadOpts)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(os.Stdin, os.Stdout)
+}
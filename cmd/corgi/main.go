@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
@@ -20,18 +22,37 @@ import (
 	"github.com/mavolin/corgi"
 	"github.com/mavolin/corgi/corgierr"
 	"github.com/mavolin/corgi/file"
+	"github.com/mavolin/corgi/internal/gomod"
 	"github.com/mavolin/corgi/load"
+	"github.com/mavolin/corgi/typecheck"
 	"github.com/mavolin/corgi/write"
 )
 
+// errReported is returned by commands that already printed their errors,
+// to make the program exit with a non-zero status.
+var errReported = errors.New("errors reported")
+
 func main() {
+	parseArgs()
+
 	if err := run(); err != nil {
-		fmt.Fprint(os.Stderr, err.Error())
+		if !errors.Is(err, errReported) {
+			fmt.Fprint(os.Stderr, err.Error())
+		}
 		os.Exit(1)
 	}
 }
 
 func run() error {
+	switch Command {
+	case "fmt":
+		return runFmt(os.Args[2:])
+	case "lsp":
+		return runLSP(os.Args[2:])
+	case "explain":
+		return runExplain(os.Args[2:])
+	}
+
 	loadOpts := corgi.LoadOptions{GoExecPath: GoExecPath}
 	if Verbose {
 		loadOpts.Logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
@@ -41,18 +62,19 @@ func run() error {
 		return writeLibraries(loadOpts)
 	}
 
+	if Watch {
+		return watch(loadOpts)
+	}
+
 	return writeFile(loadOpts)
 }
 
 func writeFile(loadOpts corgi.LoadOptions) error {
-	var f *file.File
-	var err error
 	if InFile != "" {
-		f, err = corgi.LoadMain(InFile, loadOpts)
-	} else {
-		f, err = corgi.LoadMainData(InData, loadOpts)
+		return writeFiles(loadOpts)
 	}
 
+	f, err := corgi.LoadMainData(InData, loadOpts)
 	if err != nil {
 		var mainMod string
 		if f != nil {
@@ -62,12 +84,130 @@ func writeFile(loadOpts corgi.LoadOptions) error {
 		return nil
 	}
 
+	if err := generateFile(f, OutFile); err != nil {
+		if corgierr.As(err) != nil {
+			writeErrs(err, f.Module)
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// writeFiles generates all main files matching InFile using a single loader
+// per module, so that files shared by multiple main files are only loaded
+// once.
+//
+// Instead of exiting on the first failure, the errors of all files are
+// collected and printed once all files were processed.
+func writeFiles(loadOpts corgi.LoadOptions) error {
+	inFiles, err := mainFiles()
+	if err != nil {
+		return err
+	}
+
+	loaders := make(map[string]*corgi.Loader)
+
+	var mainMod string
+	var cerrs corgierr.List
+	var errs []error
+
+	for _, inFile := range inFiles {
+		l, err := moduleLoader(loaders, inFile, loadOpts)
+		if err != nil {
+			return err
+		}
+
+		f, err := l.LoadMain(inFile)
+		if f != nil && mainMod == "" {
+			mainMod = f.Module
+		}
+		if err != nil {
+			if lerr := corgierr.As(err); len(lerr) > 0 {
+				cerrs = append(cerrs, lerr...)
+			} else {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		if err := generateFile(f, outFile(inFile)); err != nil {
+			if lerr := corgierr.As(err); len(lerr) > 0 {
+				cerrs = append(cerrs, lerr...)
+			} else {
+				errs = append(errs, fmt.Errorf("%s: %w", inFile, err))
+			}
+		}
+	}
+
+	if len(cerrs) == 0 && len(errs) == 0 {
+		return nil
+	}
+
+	if Format != FormatPretty {
+		if len(cerrs) > 0 {
+			errs = append([]error{cerrs}, errs...)
+		}
+		printErrs(errors.Join(errs...), mainMod)
+		return errReported
+	}
+
+	if len(cerrs) > 0 {
+		printErrs(cerrs, mainMod)
+	}
+	for _, err := range errs {
+		if len(cerrs) > 0 {
+			fmt.Println()
+		}
+		fmt.Println(err)
+	}
+
+	return errReported
+}
+
+// moduleLoader returns the loader in loaders used for the module that inFile
+// is located in, creating it, if there is none yet.
+//
+// Files that are not located in a module share a loader.
+func moduleLoader(loaders map[string]*corgi.Loader, inFile string, loadOpts corgi.LoadOptions) (*corgi.Loader, error) {
+	dir, err := filepath.Abs(filepath.Dir(inFile))
+	if err != nil {
+		return nil, err
+	}
+
+	_, goMod, err := gomod.Find(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", inFile, err)
+	}
+
+	if l := loaders[goMod]; l != nil {
+		return l, nil
+	}
+
+	l, err := corgi.NewLoader(loadOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	loaders[goMod] = l
+	return l, nil
+}
+
+func generateFile(f *file.File, outFile string) error {
+	if TypeCheck {
+		if err := typeCheck(f, outFile); err != nil {
+			return err
+		}
+	}
+
 	var out io.Writer
 	closeOut := func() error { return nil }
 	if UseStdout {
 		out = os.Stdout
 	} else {
-		fout, err := os.Create(OutFile)
+		fout, err := os.Create(outFile)
 		if err != nil {
 			return fmt.Errorf("could not create output file: %w", err)
 		}
@@ -80,6 +220,7 @@ func writeFile(loadOpts corgi.LoadOptions) error {
 
 	var goimportsErr error
 	var goimportsWait func() error
+	goimportsStderr := bytes.NewBuffer(make([]byte, 0, 256))
 
 	if !NoGoImports {
 		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
@@ -87,8 +228,7 @@ func writeFile(loadOpts corgi.LoadOptions) error {
 
 		goimports := exec.CommandContext(ctx, "goimports")
 		goimports.Stdout = out
-		stderr := bytes.NewBuffer(make([]byte, 0, 256))
-		goimports.Stderr = stderr
+		goimports.Stderr = goimportsStderr
 		pipe, err := goimports.StdinPipe()
 		if err != nil {
 			return fmt.Errorf("failed to pipe generated file into goimports: %w", err)
@@ -98,8 +238,8 @@ func writeFile(loadOpts corgi.LoadOptions) error {
 		prettyClose = pipe.Close
 
 		goimportsErr = goimports.Start()
-		if stderr.Len() > 0 {
-			goimportsErr = errors.New(stderr.String())
+		if goimportsStderr.Len() > 0 {
+			goimportsErr = errors.New(goimportsStderr.String())
 		}
 
 		goimportsWait = goimports.Wait
@@ -111,6 +251,11 @@ func writeFile(loadOpts corgi.LoadOptions) error {
 		CLI:             true,
 		CorgierrPretty:  prettyOptions(f.Module),
 		Debug:           Debug,
+		Pretty:          Pretty,
+
+		LineDirectives:        LineDirectives,
+		LineDirectiveFileName: lineDirectiveFileName(outFile),
+		GeneratedFileName:     filepath.Base(outFile),
 	})
 
 	if err := w.GenerateFile(prettyOut, Package, f); err != nil {
@@ -123,9 +268,20 @@ func writeFile(loadOpts corgi.LoadOptions) error {
 
 	if !NoGoImports {
 		if err := goimportsWait(); err != nil {
+			// with line directives, these point to the corgi file
+			if goimportsStderr.Len() > 0 {
+				err = errors.New(strings.TrimSpace(goimportsStderr.String()))
+			}
+
 			// goimport's error probably contains line/col info, so generate the
 			// file again, but this time directly
-			w := write.New(write.Options{Debug: Debug})
+			w := write.New(write.Options{
+				Debug:                 Debug,
+				Pretty:                Pretty,
+				LineDirectives:        LineDirectives,
+				LineDirectiveFileName: lineDirectiveFileName(outFile),
+				GeneratedFileName:     filepath.Base(outFile),
+			})
 			_ = w.GenerateFile(out, Package, f)
 
 			return fmt.Errorf("failed to run goimports:\n"+
@@ -137,7 +293,13 @@ func writeFile(loadOpts corgi.LoadOptions) error {
 		if goimportsErr != nil {
 			// goimport's error probably contains line/col info, so generate the
 			// file again, but this time directly
-			w := write.New(write.Options{Debug: Debug})
+			w := write.New(write.Options{
+				Debug:                 Debug,
+				Pretty:                Pretty,
+				LineDirectives:        LineDirectives,
+				LineDirectiveFileName: lineDirectiveFileName(outFile),
+				GeneratedFileName:     filepath.Base(outFile),
+			})
 			_ = w.GenerateFile(out, Package, f)
 
 			return fmt.Errorf("failed to run goimports:\n"+
@@ -154,6 +316,59 @@ func writeFile(loadOpts corgi.LoadOptions) error {
 	return nil
 }
 
+// typeCheck type-checks the Go code of f, as if it were generated to
+// outFile.
+func typeCheck(f *file.File, outFile string) error {
+	dir := "."
+	fileName := ""
+	if outFile != "" {
+		dir, fileName = filepath.Split(outFile)
+	}
+
+	o := typecheck.Options{
+		Dir:        dir,
+		FileName:   fileName,
+		Package:    Package,
+		GoExecPath: GoExecPath,
+		Write: write.Options{
+			AllowedFilters:  TrustedFilters,
+			AllowAllFilters: TrustAllFilters,
+			CLI:             true,
+			CorgierrPretty:  prettyOptions(f.Module),
+			Debug:           Debug,
+			Pretty:          Pretty,
+		},
+	}
+	if !NoGoImports {
+		o.Process = runGoimports
+	}
+
+	return typecheck.File(f, o)
+}
+
+// runGoimports runs goimports on the passed source.
+func runGoimports(src []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+
+	goimports := exec.CommandContext(ctx, "goimports")
+	goimports.Stdin = bytes.NewReader(src)
+	goimports.Stdout = &stdout
+	goimports.Stderr = &stderr
+
+	if err := goimports.Run(); err != nil {
+		if stderr.Len() > 0 {
+			err = errors.New(strings.TrimSpace(stderr.String()))
+		}
+
+		return nil, fmt.Errorf("failed to run goimports: %w", err)
+	}
+
+	return stdout.Bytes(), nil
+}
+
 func writeLibraries(loadOpts corgi.LoadOptions) error {
 	loadOpts.NoPrecompile = true
 
@@ -209,6 +424,7 @@ func writeLibrary(path, outPath string, loadOpts corgi.LoadOptions, ignoreNotExi
 		CLI:             true,
 		CorgierrPretty:  prettyOptions(lib.Module),
 		Debug:           Debug,
+		Pretty:          Pretty,
 	})
 
 	if err := w.PrecompileLibrary(out, lib); err != nil {
@@ -223,13 +439,61 @@ func writeLibrary(path, outPath string, loadOpts corgi.LoadOptions, ignoreNotExi
 }
 
 func writeErrs(err error, mainMod string) {
+	printErrs(err, mainMod)
+	os.Exit(1)
+}
+
+func printErrs(err error, mainMod string) {
+	switch Format {
+	case FormatJSON:
+		data, jsonErr := json.Marshal(errList(err))
+		if jsonErr != nil {
+			fmt.Println(err)
+			return
+		}
+
+		fmt.Println(string(data))
+		return
+	case FormatSARIF:
+		data, sarifErr := errList(err).SARIF(corgierr.SARIFOptions{FileNamePrinter: fileNamePrinter(mainMod)})
+		if sarifErr != nil {
+			fmt.Println(err)
+			return
+		}
+
+		fmt.Println(string(data))
+		return
+	}
+
 	if lerr := corgierr.As(err); lerr != nil {
 		fmt.Println(lerr.Pretty(prettyOptions(mainMod)))
-		os.Exit(1)
+		return
 	}
 
 	fmt.Println(err)
-	os.Exit(1)
+}
+
+// errList converts err to a [corgierr.List], wrapping errors that aren't
+// corgi errors in a [corgierr.Error] without a position.
+func errList(err error) corgierr.List {
+	var errs []error
+	if uw, ok := err.(interface{ Unwrap() []error }); ok { //nolint:errorlint
+		errs = uw.Unwrap()
+	} else {
+		errs = []error{err}
+	}
+
+	var lerr corgierr.List
+	for _, err := range errs {
+		if cerrs := corgierr.As(err); cerrs != nil {
+			lerr = append(lerr, cerrs...)
+			continue
+		}
+
+		lerr = append(lerr, &corgierr.Error{Message: err.Error(), Code: corgierr.CodeExternal})
+	}
+
+	return lerr
 }
 
 func prettyOptions(mainMod string) corgierr.PrettyOptions {
@@ -243,28 +507,59 @@ func prettyOptions(mainMod string) corgierr.PrettyOptions {
 			(isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd()))
 	}
 
+	prettyOpts.FileNamePrinter = fileNamePrinter(mainMod)
+	return prettyOpts
+}
+
+// lineDirectiveFileName returns a function that returns the name of the
+// passed file relative to the directory of outFile, as used in the line
+// directives of the file generated at outFile.
+func lineDirectiveFileName(outFile string) func(*file.File) string {
+	outDir, err := filepath.Abs(filepath.Dir(outFile))
+	if err != nil {
+		return nil
+	}
+
+	return func(f *file.File) string {
+		if f.AbsolutePath == "" {
+			return f.Name
+		}
+
+		rel, err := filepath.Rel(outDir, f.AbsolutePath)
+		if err != nil {
+			return f.AbsolutePath
+		}
+
+		return rel
+	}
+}
+
+// fileNamePrinter returns a function that returns the name of a file as it
+// should be displayed to the user, or nil, if the file's name should be used
+// as is.
+func fileNamePrinter(mainMod string) func(*file.File) string {
 	if IsGoGenerate {
-		prettyOpts.FileNamePrinter = func(f *file.File) string {
+		return func(f *file.File) string {
 			if f.Module == mainMod {
 				return filepath.FromSlash(f.PathInModule)
 			}
 
 			return path.Join(f.Module, f.PathInModule)
 		}
-	} else {
-		wd, err := os.Getwd()
-		if err == nil { // IS nil
-			// print the name of the file relative to the current wd
-			prettyOpts.FileNamePrinter = func(f *file.File) string {
-				rel, relErr := filepath.Rel(wd, f.AbsolutePath)
-				if relErr != nil {
-					return rel
-				}
-
-				return f.Name
-			}
-		}
 	}
 
-	return prettyOpts
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+
+	// print the name of the file relative to the current wd
+	return func(f *file.File) string {
+		rel, relErr := filepath.Rel(wd, f.AbsolutePath)
+		if relErr == nil {
+			return rel
+		}
+
+		return f.Name
+	}
 }
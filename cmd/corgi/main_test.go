@@ -0,0 +1,23 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi/corgierr"
+)
+
+func TestErrList(t *testing.T) {
+	t.Parallel()
+
+	cerr := &corgierr.Error{Message: "syntax error", Code: corgierr.CodeSyntax}
+
+	lerr := errList(errors.Join(corgierr.List{cerr}, errors.New("failed to run goimports")))
+	require.Len(t, lerr, 2)
+	assert.Same(t, cerr, lerr[0])
+	assert.Equal(t, "failed to run goimports", lerr[1].Message)
+	assert.Equal(t, corgierr.CodeExternal, lerr[1].Code)
+}
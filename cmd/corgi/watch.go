@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mavolin/corgi"
+	"github.com/mavolin/corgi/corgierr"
+	"github.com/mavolin/corgi/load"
+)
+
+// watchInterval is the interval in which watch polls the watched files for
+// changes.
+const watchInterval = 300 * time.Millisecond
+
+// watch generates the input files, and then keeps regenerating them,
+// whenever they or any of their dependencies change.
+//
+// Only the changed files and the files depending on them are reloaded,
+// everything else is taken from the loader's cache.
+// If InFile is a pattern, it is only matched once, i.e. main files added
+// after watch was started are not picked up.
+//
+// Errors are printed, but don't cause watch to return.
+func watch(loadOpts corgi.LoadOptions) error {
+	inFiles, err := mainFiles()
+	if err != nil {
+		return err
+	}
+
+	l, err := corgi.NewLoader(loadOpts)
+	if err != nil {
+		return err
+	}
+
+	var w watcher
+	deps := make([][]string, len(inFiles))
+
+	for i, inFile := range inFiles {
+		absInFile, err := filepath.Abs(inFile)
+		if err != nil {
+			return err
+		}
+
+		deps[i] = append(watchGenerate(l, inFile), absInFile)
+		w.watch(deps[i]...)
+	}
+
+	fmt.Fprintln(os.Stderr, "watching for changes...")
+
+	for {
+		time.Sleep(watchInterval)
+
+		changed := w.changed()
+		if len(changed) == 0 {
+			continue
+		}
+
+		for _, p := range changed {
+			fmt.Fprintln(os.Stderr, "changed:", p)
+		}
+
+		l.Invalidate(changed...)
+
+		for i, inFile := range inFiles {
+			if !containsAny(deps[i], changed) {
+				continue
+			}
+
+			// keep the old deps, in case the file failed to load and we
+			// therefore couldn't find all of its dependencies
+			deps[i] = appendNew(deps[i], watchGenerate(l, inFile)...)
+			w.watch(deps[i]...)
+		}
+
+		fmt.Fprintln(os.Stderr, "watching for changes...")
+	}
+}
+
+// watchGenerate loads and generates the passed input file, printing any
+// errors encountered.
+//
+// It returns the dependencies of the loaded file.
+func watchGenerate(l *corgi.Loader, inFile string) []string {
+	f, err := l.LoadMain(inFile)
+	if f == nil {
+		if err != nil {
+			printErrs(err, "")
+		}
+		return nil
+	}
+
+	deps := load.Dependencies(f)
+
+	if err != nil {
+		printErrs(err, f.Module)
+		return deps
+	}
+
+	out := outFile(inFile)
+	if err := generateFile(f, out); err != nil {
+		if corgierr.As(err) != nil {
+			printErrs(err, f.Module)
+		} else {
+			fmt.Fprintln(os.Stderr, err.Error())
+		}
+		return deps
+	}
+
+	fmt.Fprintln(os.Stderr, "generated", out)
+	return deps
+}
+
+func containsAny(s []string, vals []string) bool {
+	for _, a := range s {
+		for _, b := range vals {
+			if a == b {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func appendNew(s []string, vals ...string) []string {
+vals:
+	for _, v := range vals {
+		for _, cmp := range s {
+			if v == cmp {
+				continue vals
+			}
+		}
+
+		s = append(s, v)
+	}
+
+	return s
+}
+
+// watcher keeps track of the state of watched files and directories.
+//
+// Files are considered changed if their modification time or size changes,
+// directories are considered changed, if the set of library files they
+// contain changes.
+type watcher struct {
+	stamps map[string]string
+}
+
+// watch adds the passed absolute paths to the watched files.
+// Already watched paths are ignored.
+//
+// Watched files are never removed, so that files that were removed from
+// the dependencies of a file due to an error are still watched.
+func (w *watcher) watch(paths ...string) {
+	if w.stamps == nil {
+		w.stamps = make(map[string]string, len(paths))
+	}
+
+	for _, p := range paths {
+		if _, ok := w.stamps[p]; !ok {
+			w.stamps[p] = stamp(p)
+		}
+	}
+}
+
+// changed returns the paths of all files that changed since the last call
+// to changed.
+func (w *watcher) changed() []string {
+	var changed []string
+
+	for p, oldStamp := range w.stamps {
+		newStamp := stamp(p)
+		if newStamp != oldStamp {
+			w.stamps[p] = newStamp
+			changed = append(changed, p)
+		}
+	}
+
+	return changed
+}
+
+func stamp(p string) string {
+	fi, err := os.Stat(p)
+	if err != nil {
+		return ""
+	}
+
+	if !fi.IsDir() {
+		return fmt.Sprint(fi.ModTime().UnixNano(), fi.Size())
+	}
+
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		return ""
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		if name := e.Name(); strings.HasSuffix(name, corgi.LibExt) || name == corgi.PrecompFileName {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return "dir:" + strings.Join(names, "/")
+}
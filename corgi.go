@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"golang.org/x/exp/slog"
 	"golang.org/x/mod/modfile"
@@ -38,17 +40,28 @@ var ErrNotExists = errors.New("file does not exist")
 // linking corgi files, like the CLI does.
 //
 // It keeps state about the module data of loaded files, more concretely
-// results of calling `go mod edit -json` used to resolve import paths.
+// the go.mod files used to resolve import paths.
 //
-// It is concurrently safe, but only meant to be used once.
+// It is concurrently safe.
 type loader struct {
-	// mainMod is the go.mod of the main or library file being loaded.
-	mainMod       *modfile.File
-	mainModSysAbs string
+	mainModsMut sync.Mutex
+	// mainMods maps the absolute system paths of the files and library
+	// directories read by the loader to the go.mod of the main module they
+	// were read for, i.e. the module of the main file or standalone library
+	// that was being loaded.
+	//
+	// A nil value indicates that the main file or standalone library is not
+	// located in a module.
+	mainMods map[string]*goMod
 
 	noPrecompile bool
+	fileReader   func(sysPath string) ([]byte, error)
+	// modules is the module map of a loader created through NewFSLoader.
+	//
+	// If set, all paths are module paths, and fileReader and cmd are unset.
+	modules map[string]fs.FS
 
-	loader load.Loader
+	loader *load.CachingLoader
 	linker *link.Linker
 	cmd    *gocmd.Cmd
 	log    *slog.Logger
@@ -68,94 +81,131 @@ type LoadOptions struct {
 	//
 	// If left as nil, nothing will be logged
 	Logger *slog.Logger
+
+	// ReadFile, if set, is used instead of [os.ReadFile] to read corgi
+	// files.
+	//
+	// This is useful for language servers, which need to load the unsaved
+	// contents of the files opened in an editor.
+	//
+	// If the file does not exist, the returned error must wrap
+	// [fs.ErrNotExist].
+	ReadFile func(sysPath string) ([]byte, error)
 }
 
 var nopLog = slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))
 
 func newLoader(o LoadOptions) (*loader, error) {
 	var l loader
+	l.mainMods = make(map[string]*goMod)
 	l.noPrecompile = o.NoPrecompile
-	bl := &load.BasicLoader{
+	l.fileReader = o.ReadFile
+	if l.fileReader == nil {
+		l.fileReader = os.ReadFile
+	}
+	l.initLoader(&load.BasicLoader{
 		MainReader:     l.readMain,
 		TemplateReader: l.readTemplate,
 		IncludeReader:  l.readInclude,
 		LibraryReader:  l.readLibrary,
-		Parser: func(in []byte) (*file.File, error) {
-			log := l.log.WithGroup("parser")
+	})
 
-			log.Info("parsing")
-			f, err := parse.Parse(in)
-			if err != nil {
-				log.Error("parse failed", slog.Any("err", err))
-				return f, err
-			}
-			log.Info("parsed")
+	if o.GoExecPath == "" {
+		if goroot := os.Getenv("GOROOT"); goroot != "" {
+			o.GoExecPath = filepath.Join(goroot, "bin", "go")
+		} else {
+			return nil, errors.New("corgi.LoadOptions: GoExecPath not set and $GOROOT is empty")
+		}
+	}
+	l.cmd = gocmd.NewCmd(o.GoExecPath)
+
+	l.log = o.Logger
+	if l.log == nil {
+		l.log = nopLog
+	}
+
+	return &l, nil
+}
+
+// initLoader completes bl, which only has its readers set, and sets it up as
+// the loader of l.
+func (l *loader) initLoader(bl *load.BasicLoader) {
+	bl.Parser = func(in []byte) (*file.File, error) {
+		log := l.log.WithGroup("parser")
+
+		log.Info("parsing")
+		f, err := parse.Parse(in)
+		if err != nil {
+			log.Error("parse failed", slog.Any("err", err))
 			return f, err
-		},
-		PreLinkValidator: func(f *file.File) error {
-			log := l.log.
-				WithGroup("pre_link_validator").
-				With(slog.String("mod", f.Module), slog.String("path_in_mod", f.PathInModule),
-					slog.String("abs", f.AbsolutePath))
-
-			log.Info("validating use namespaces")
-			err := validate.PreLink(f)
-			log.Info("validated use namespaces", slog.Any("err", err))
-			return err
-		},
-		Linker: func(f *file.File) error {
-			log := l.log.
-				WithGroup("linker").
-				With(slog.String("mod", f.Module), slog.String("path_in_mod", f.PathInModule),
-					slog.String("abs", f.AbsolutePath))
-
-			log.Info("inferring types of mixin params")
+		}
+		log.Info("parsed")
+		return f, err
+	}
+	bl.PreLinkValidator = func(f *file.File) error {
+		log := l.log.
+			WithGroup("pre_link_validator").
+			With(slog.String("mod", f.Module), slog.String("path_in_mod", f.PathInModule),
+				slog.String("abs", f.AbsolutePath))
+
+		log.Info("validating use namespaces")
+		err := validate.PreLink(f)
+		log.Info("validated use namespaces", slog.Any("err", err))
+		return err
+	}
+	bl.Linker = func(f *file.File) error {
+		log := l.log.
+			WithGroup("linker").
+			With(slog.String("mod", f.Module), slog.String("path_in_mod", f.PathInModule),
+				slog.String("abs", f.AbsolutePath))
+
+		log.Info("inferring types of mixin params")
+		typeinfer.Scope(f.Scope)
+		log.Info("inferred types")
+		log.Info("linking")
+		err := l.linker.LinkFile(f)
+		log.Info("linked file", slog.Any("err", err))
+		return err
+	}
+	bl.LibraryLinker = func(lib *file.Library) error {
+		log := l.log.
+			WithGroup("lib_linker").
+			With(slog.String("mod", lib.Module), slog.String("path_in_mod", lib.PathInModule),
+				slog.String("abs", lib.AbsolutePath))
+
+		log.Info("inferring types of mixin params")
+		for _, f := range lib.Files {
 			typeinfer.Scope(f.Scope)
-			log.Info("inferred types")
-			log.Info("linking")
-			err := l.linker.LinkFile(f)
-			log.Info("linked file", slog.Any("err", err))
-			return err
-		},
-		LibraryLinker: func(lib *file.Library) error {
-			log := l.log.
-				WithGroup("lib_linker").
-				With(slog.String("mod", lib.Module), slog.String("path_in_mod", lib.PathInModule),
-					slog.String("abs", lib.AbsolutePath))
-
-			log.Info("inferring types of mixin params")
-			for _, f := range lib.Files {
-				typeinfer.Scope(f.Scope)
-			}
-			log.Info("inferred types")
-			log.Info("linking")
-			err := l.linker.LinkLibrary(lib)
-			log.Info("linked file", slog.Any("err", err))
-			return err
-		},
-		MainValidator: func(f *file.File) error {
-			log := l.log.
-				WithGroup("main_validator").
-				With(slog.String("mod", f.Module), slog.String("path_in_mod", f.PathInModule),
-					slog.String("abs", f.AbsolutePath))
-
-			log.Info("validating file")
-			err := validate.File(f)
-			log.Info("validated file", slog.Any("err", err))
-			return err
-		},
-		LibraryValidator: func(lib *file.Library) error {
-			log := l.log.
-				WithGroup("library_validator").
-				With(slog.String("mod", lib.Module), slog.String("path_in_mod", lib.PathInModule),
-					slog.String("abs", lib.AbsolutePath))
-
-			log.Info("validating library")
-			err := validate.Library(lib)
-			log.Info("validated library", slog.Any("err", err))
-			return err
-		},
+		}
+		log.Info("inferred types")
+		log.Info("linking")
+		err := l.linker.LinkLibrary(lib)
+		log.Info("linked file", slog.Any("err", err))
+		return err
 	}
+	bl.MainValidator = func(f *file.File) error {
+		log := l.log.
+			WithGroup("main_validator").
+			With(slog.String("mod", f.Module), slog.String("path_in_mod", f.PathInModule),
+				slog.String("abs", f.AbsolutePath))
+
+		log.Info("validating file")
+		err := validate.File(f)
+		log.Info("validated file", slog.Any("err", err))
+		return err
+	}
+	bl.LibraryValidator = func(lib *file.Library) error {
+		log := l.log.
+			WithGroup("library_validator").
+			With(slog.String("mod", lib.Module), slog.String("path_in_mod", lib.PathInModule),
+				slog.String("abs", lib.AbsolutePath))
+
+		log.Info("validating library")
+		err := validate.Library(lib)
+		log.Info("validated library", slog.Any("err", err))
+		return err
+	}
+
 	cl := load.Cache(bl)
 	bl.DirLibraryLoader = func(f *file.File) (*file.Library, error) {
 		if f.Module == "" {
@@ -178,40 +228,15 @@ func newLoader(o LoadOptions) (*loader, error) {
 
 	l.loader = cl
 	l.linker = link.New(l.loader)
-
-	if o.GoExecPath == "" {
-		if goroot := os.Getenv("GOROOT"); goroot != "" {
-			o.GoExecPath = filepath.Join(goroot, "bin", "go")
-		} else {
-			return nil, errors.New("corgi.LoadOptions: GoExecPath not set and $GOROOT is empty")
-		}
-	}
-	l.cmd = gocmd.NewCmd(o.GoExecPath)
-
-	l.log = o.Logger
-	if l.log == nil {
-		l.log = nopLog
-	}
-
-	return &l, nil
 }
 
 func LoadMain(sysPath string, o LoadOptions) (*file.File, error) {
-	l, err := newLoader(o)
+	l, err := NewLoader(o)
 	if err != nil {
 		return nil, err
 	}
 
-	f, err := l.loader.LoadMain(sysPath)
-	if err != nil {
-		return f, err
-	}
-
-	if f == nil && err == nil {
-		return nil, ErrNotExists
-	}
-
-	return f, nil
+	return l.LoadMain(sysPath)
 }
 
 // LoadMainData parses and links the passed main file's raw data.
@@ -247,12 +272,62 @@ func LoadMainData(in []byte, o LoadOptions) (*file.File, error) {
 // LoadLibrary parses and links the library located at the passed file system
 // path.
 func LoadLibrary(sysPath string, o LoadOptions) (*file.Library, error) {
+	l, err := NewLoader(o)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.LoadLibrary(sysPath)
+}
+
+// Loader is a reusable loader, that caches the files and libraries it
+// loads.
+//
+// Contrary to [LoadMain] and [LoadLibrary], which start with a fresh cache
+// every time, a Loader only loads each file once, making it suitable for
+// loading multiple files that share dependencies, or for repeatedly loading
+// the same files, as long as changed files are invalidated.
+//
+// It is concurrently safe.
+type Loader struct {
+	l *loader
+}
+
+// NewLoader creates a new *Loader using the passed options.
+func NewLoader(o LoadOptions) (*Loader, error) {
 	l, err := newLoader(o)
 	if err != nil {
 		return nil, err
 	}
 
-	lib, err := l.loader.LoadLibrary(nil, sysPath)
+	return &Loader{l: l}, nil
+}
+
+// LoadMain parses, links, and validates the main file located at the passed
+// file system path.
+//
+// If l was created using [NewFSLoader], sysPath is the module path of the
+// main file instead.
+func (l *Loader) LoadMain(sysPath string) (*file.File, error) {
+	f, err := l.l.loader.LoadMain(sysPath)
+	if err != nil {
+		return f, err
+	}
+
+	if f == nil && err == nil {
+		return nil, ErrNotExists
+	}
+
+	return f, nil
+}
+
+// LoadLibrary parses, links, and validates the library located at the passed
+// file system path.
+//
+// If l was created using [NewFSLoader], sysPath is the module path of the
+// library instead.
+func (l *Loader) LoadLibrary(sysPath string) (*file.Library, error) {
+	lib, err := l.l.loader.LoadLibrary(nil, sysPath)
 	if err != nil {
 		return lib, err
 	}
@@ -264,6 +339,66 @@ func LoadLibrary(sysPath string, o LoadOptions) (*file.Library, error) {
 	return lib, nil
 }
 
+// LoadTemplate parses and links the template file located at the passed
+// file system path.
+//
+// Contrary to main files, templates are not validated, as they may contain
+// blocks that are only filled by the files extending them.
+//
+// The template must be located in a Go module.
+//
+// If l was created using [NewFSLoader], sysPath is the module path of the
+// template instead.
+func (l *Loader) LoadTemplate(sysPath string) (*file.File, error) {
+	if l.l.modules != nil {
+		return l.loadTemplate(nil, sysPath)
+	}
+
+	log := l.l.log.WithGroup("template_loader").With(slog.String("path", sysPath))
+
+	p, err := l.l.resolvePaths(log, sysPath)
+	if err != nil {
+		return nil, err
+	}
+
+	mod, err := l.l.findMod(log, filepath.Dir(p.sysAbs))
+	if err != nil {
+		return nil, err
+	}
+	if mod == nil {
+		return nil, fmt.Errorf("%s: template is not located in a Go module", sysPath)
+	}
+
+	extendPath := path.Join(mod.file.Module.Mod.Path, filepath.ToSlash(pathInMod(mod.sysAbs, p.sysAbs)))
+
+	// the template is its own main module, so we let it resolve its own
+	// path, as if it extended itself
+	l.l.setMainMod(mod, p.sysAbs)
+	return l.loadTemplate(&file.File{AbsolutePath: p.sysAbs}, extendPath)
+}
+
+func (l *Loader) loadTemplate(extendingFile *file.File, extendPath string) (*file.File, error) {
+	f, err := l.l.loader.LoadTemplate(extendingFile, extendPath)
+	if err != nil {
+		return f, err
+	}
+
+	if f == nil && err == nil {
+		return nil, ErrNotExists
+	}
+
+	return f, nil
+}
+
+// Invalidate removes the files and library directories located at the
+// passed absolute system paths from the cache, as well as all files and
+// libraries that depend on them.
+//
+// See [load.CachingLoader.Invalidate] for more information.
+func (l *Loader) Invalidate(sysAbsPaths ...string) {
+	l.l.loader.Invalidate(sysAbsPaths...)
+}
+
 func (l *loader) readMain(sysPath string) (*load.File, error) {
 	log := l.log.WithGroup("main_reader").With(slog.String("path", sysPath))
 
@@ -282,9 +417,11 @@ func (l *loader) readMain(sysPath string) (*load.File, error) {
 		return nil, nil
 	}
 
-	if err := l.readMainMod(log, filepath.Dir(sysPath)); err != nil {
+	mod, err := l.findMod(log, filepath.Dir(p.sysAbs))
+	if err != nil {
 		return nil, err
 	}
+	l.setMainMod(mod, p.sysAbs)
 
 	f := &load.File{
 		Name:         p.base,
@@ -292,20 +429,26 @@ func (l *loader) readMain(sysPath string) (*load.File, error) {
 		IsCorgi:      true,
 		Raw:          data,
 	}
-	if l.mainMod != nil {
-		f.Module = l.mainMod.Module.Mod.Path
-		f.PathInModule = pathInMod(l.mainModSysAbs, p.sysAbs)
+	if mod != nil {
+		f.Module = mod.file.Module.Mod.Path
+		f.PathInModule = pathInMod(mod.sysAbs, p.sysAbs)
 	}
 	return f, nil
 }
 
-func (l *loader) readTemplate(_ *file.File, extendPath string) (*load.File, error) {
+func (l *loader) readTemplate(extendingFile *file.File, extendPath string) (*load.File, error) {
 	log := l.log.WithGroup("template_reader").With(slog.String("extend_path", extendPath))
 
 	log.Info("reading file")
+
+	mainMod, err := l.mainModOf(log, extendingFile)
+	if err != nil {
+		return nil, err
+	}
+
 	log.Info("locating parent module")
 
-	mod, err := l.locateModule(extendPath)
+	mod, err := l.locateModule(mainMod, extendPath)
 	if err != nil {
 		return nil, err
 	}
@@ -318,7 +461,7 @@ func (l *loader) readTemplate(_ *file.File, extendPath string) (*load.File, erro
 		slog.String("abs", mod.sysAbsPath))
 	log.Info("located parent module", slog.String("module", mod.path))
 
-	f, err := os.ReadFile(sysAbs)
+	f, err := l.fileReader(sysAbs)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			log.Info("file doesn't exist in module")
@@ -331,6 +474,7 @@ func (l *loader) readTemplate(_ *file.File, extendPath string) (*load.File, erro
 
 	log.Info("loaded file")
 
+	l.setMainMod(mainMod, sysAbs)
 	return &load.File{
 		Name:         filepath.Base(sysAbs),
 		Module:       mod.path,
@@ -357,6 +501,12 @@ func (l *loader) readInclude(includingFile *file.File, slashPath string) (*load.
 		return nil, nil
 	}
 
+	mainMod, err := l.mainModOf(log, includingFile)
+	if err != nil {
+		return nil, err
+	}
+	l.setMainMod(mainMod, sysAbs)
+
 	return &load.File{
 		Name:         path.Base(sysAbs),
 		Module:       includingFile.Module,
@@ -387,27 +537,33 @@ func (l *loader) readStandaloneLibrary(sysDir string) (*load.Library, error) {
 
 	log = log.With(slog.String("abs", dir.sysAbs))
 
-	if err := l.readMainMod(log, sysDir); err != nil {
+	mod, err := l.findMod(log, dir.sysAbs)
+	if err != nil {
 		return nil, err
 	}
 
 	log.Info("reading standalone library")
 
 	var modulePath, pathInModule string
-	if l.mainMod != nil {
-		modulePath = l.mainMod.Module.Mod.Path
-		pathInModule, _ = filepath.Rel(l.mainModSysAbs, dir.sysAbs)
+	if mod != nil {
+		modulePath = mod.file.Module.Mod.Path
+		pathInModule, _ = filepath.Rel(mod.sysAbs, dir.sysAbs)
 	}
 
-	return l.readLibraryDir(log, sysDir, modulePath, pathInModule)
+	return l.readLibraryDir(log, mod, sysDir, modulePath, pathInModule)
 }
 
-func (l *loader) readDepLibrary(_ *file.File, usePath string) (*load.Library, error) {
+func (l *loader) readDepLibrary(usingFile *file.File, usePath string) (*load.Library, error) {
 	log := l.log.
 		WithGroup("dep_library_reader").
 		With(slog.String("path", usePath))
 
-	mod, err := l.locateModule(usePath)
+	mainMod, err := l.mainModOf(log, usingFile)
+	if err != nil {
+		return nil, err
+	}
+
+	mod, err := l.locateModule(mainMod, usePath)
 	if err != nil {
 		return nil, err
 	}
@@ -422,11 +578,13 @@ func (l *loader) readDepLibrary(_ *file.File, usePath string) (*load.Library, er
 	sysAbs := filepath.Join(mod.sysAbsPath, filepath.FromSlash(mod.pathInMod))
 	log = log.With(slog.String("abs", sysAbs))
 
-	return l.readLibraryDir(log, sysAbs, mod.path, mod.pathInMod)
+	return l.readLibraryDir(log, mainMod, sysAbs, mod.path, mod.pathInMod)
 }
 
+// readLibraryDir reads the library in sysDir, which is read for the passed
+// main module.
 func (l *loader) readLibraryDir(
-	log *slog.Logger, sysDir string, modulePath, pathInModule string,
+	log *slog.Logger, mainMod *goMod, sysDir string, modulePath, pathInModule string,
 ) (*load.Library, error) {
 	log.Info("loading dir information")
 
@@ -437,7 +595,7 @@ func (l *loader) readLibraryDir(
 
 	files, err := os.ReadDir(sysDir)
 	if err != nil {
-		if errors.Is(err, os.ErrExist) {
+		if errors.Is(err, os.ErrNotExist) {
 			log.Info("library does not exist")
 			return nil, nil
 		}
@@ -495,6 +653,7 @@ func (l *loader) readLibraryDir(
 			lib.AbsolutePath = dir.sysAbs
 			lib.Module = modulePath
 			lib.PathInModule = pathInModule
+			l.setMainMod(mainMod, dir.sysAbs)
 			return &load.Library{Precompiled: lib}, nil
 		}
 
@@ -527,7 +686,7 @@ func (l *loader) readLibraryDir(
 		log.Info("found corgi lib file, reading")
 
 		p := filepath.Join(sysDir, name)
-		readFile, err := os.ReadFile(p)
+		readFile, err := l.fileReader(p)
 		if err != nil {
 			log.Error("failed to open corgi lib file", slog.Any("err", err))
 			return nil, fmt.Errorf("%s: failed to read library file: %w", p, err)
@@ -547,8 +706,11 @@ func (l *loader) readLibraryDir(
 		}
 
 		lib.Files = append(lib.Files, f)
+		l.setMainMod(mainMod, f.AbsolutePath)
 	}
 
+	l.setMainMod(mainMod, dir.sysAbs)
+
 	log.Info("read directory, returning with library",
 		slog.Int("size", len(lib.Files)), slog.Int("skipped", len(files)-len(lib.Files)))
 
@@ -562,9 +724,9 @@ func (l *loader) readLibraryDir(
 func (l *loader) readFile(log *slog.Logger, sysPath string) ([]byte, error) {
 	log.Info("reading file")
 
-	f, err := os.ReadFile(sysPath)
+	f, err := l.fileReader(sysPath)
 	if err != nil {
-		if errors.Is(err, os.ErrExist) {
+		if errors.Is(err, os.ErrNotExist) {
 			log.Error("file not found")
 			return nil, nil
 		}
@@ -607,22 +769,24 @@ type mod struct {
 	sysAbsPath string
 }
 
-func (l *loader) locateModule(of string) (*mod, error) {
+// locateModule locates the module providing the file or library with the
+// passed module path, using the go.mod of the passed main module.
+func (l *loader) locateModule(mainMod *goMod, of string) (*mod, error) {
 	log := l.log.WithGroup("locate_module").With(slog.String("of", of))
 	log.Info("locating module")
 
-	if l.mainMod == nil {
+	if mainMod == nil {
 		log.Info("main file has no go.mod, downloading latest instead of using tagged version")
 		return l.downloadModule(log, of, "latest")
 	}
 
-	if strings.HasPrefix(of, l.mainMod.Module.Mod.Path) {
+	if strings.HasPrefix(of, mainMod.file.Module.Mod.Path) {
 		log.Info("file is in main module, using workdir instead of module cache",
-			slog.String("module", l.mainMod.Module.Mod.Path))
+			slog.String("module", mainMod.file.Module.Mod.Path))
 		return &mod{
-			path:       l.mainMod.Module.Mod.Path,
-			pathInMod:  pathInMod(l.mainMod.Module.Mod.Path, of),
-			sysAbsPath: l.mainModSysAbs,
+			path:       mainMod.file.Module.Mod.Path,
+			pathInMod:  pathInMod(mainMod.file.Module.Mod.Path, of),
+			sysAbsPath: mainMod.sysAbs,
 		}, nil
 	}
 
@@ -632,7 +796,7 @@ func (l *loader) locateModule(of string) (*mod, error) {
 
 	log.Info("looking for replace directives")
 
-	for _, replace := range l.mainMod.Replace {
+	for _, replace := range mainMod.file.Replace {
 		log := log.With(slog.String("old", replace.Old.String()), slog.String("new", replace.New.String()))
 		log.Debug("scanning replace directive")
 		if strings.HasPrefix(of, replace.Old.Path) {
@@ -650,7 +814,7 @@ func (l *loader) locateModule(of string) (*mod, error) {
 		}
 	}
 
-	for _, require := range l.mainMod.Require {
+	for _, require := range mainMod.file.Require {
 		log := log.With(slog.String("require", require.Mod.String()))
 		log.Debug("scanning require directive")
 
@@ -682,7 +846,7 @@ foundModule:
 	sysModuleAbs := filepath.Join(sysModCache, filepath.FromSlash(dep.Path)) + "@" + dep.Version
 	f, err := os.Open(sysModuleAbs)
 	if err != nil {
-		if errors.Is(err, os.ErrExist) {
+		if errors.Is(err, os.ErrNotExist) {
 			log.Info("module version not cached, downloading")
 			return l.downloadModule(log, dep.Path, dep.Version)
 		}
@@ -756,21 +920,69 @@ func pathInMod(modulePath string, fullPath string) string {
 	return fullPath
 }
 
-func (l *loader) readMainMod(log *slog.Logger, sysDir string) error {
+// goMod is a parsed go.mod file.
+type goMod struct {
+	file *modfile.File
+	// sysAbs is the absolute system path of the module's root directory.
+	sysAbs string
+}
+
+// findMod finds and reads the go.mod governing sysDir.
+//
+// If sysDir is not located in a module, findMod returns nil.
+func (l *loader) findMod(log *slog.Logger, sysDir string) (*goMod, error) {
 	log.Info("reading main module")
 
 	mod, absPath, err := gomod.Find(sysDir)
 	if err != nil {
 		log.Error("failed to read main module", slog.Any("err", err))
-		return err
+		return nil, err
 	}
 	if mod == nil {
 		log.Info("file not in module")
-	} else {
-		log.Info("read main module", slog.String("path", filepath.FromSlash(mod.Module.Mod.Path)))
-		l.mainMod = mod
-		l.mainModSysAbs = filepath.Dir(absPath)
+		return nil, nil
+	}
+
+	log.Info("read main module", slog.String("path", filepath.FromSlash(mod.Module.Mod.Path)))
+	return &goMod{file: mod, sysAbs: filepath.Dir(absPath)}, nil
+}
+
+// setMainMod records that the files or library directories located at the
+// passed absolute system paths were read for the passed main module.
+func (l *loader) setMainMod(mainMod *goMod, sysAbsPaths ...string) {
+	l.mainModsMut.Lock()
+	defer l.mainModsMut.Unlock()
+
+	for _, p := range sysAbsPaths {
+		l.mainMods[p] = mainMod
+	}
+}
+
+// mainModOf returns the main module that the dependencies of f are resolved
+// with, i.e. the main module f itself was read for.
+//
+// If f was not read by l, e.g. because it is a main file loaded from memory,
+// mainModOf falls back to the module f is located in, if any.
+func (l *loader) mainModOf(log *slog.Logger, f *file.File) (*goMod, error) {
+	if f == nil {
+		return nil, nil
+	}
+
+	sysAbs, sysDir := f.AbsolutePath, filepath.Dir(f.AbsolutePath)
+	// the files of precompiled libraries have no path of their own
+	if sysAbs == "" && f.Library != nil {
+		sysAbs, sysDir = f.Library.AbsolutePath, f.Library.AbsolutePath
+	}
+	if sysAbs == "" {
+		return nil, nil
+	}
+
+	l.mainModsMut.Lock()
+	mainMod, ok := l.mainMods[sysAbs]
+	l.mainModsMut.Unlock()
+	if ok {
+		return mainMod, nil
 	}
 
-	return nil
+	return l.findMod(log, sysDir)
 }
@@ -0,0 +1,157 @@
+package corgi_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi"
+	"github.com/mavolin/corgi/corgierr"
+)
+
+// newModule writes the passed files to a temporary module, and returns its
+// directory.
+//
+// Unless files contains a go.mod, the module is called example.com/test.
+func newModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	if _, ok := files["go.mod"]; !ok {
+		files["go.mod"] = "module example.com/test\n\ngo 1.19\n"
+	}
+
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+
+	return dir
+}
+
+func newLoader(t *testing.T) *corgi.Loader {
+	t.Helper()
+
+	goExec, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not found in $PATH")
+	}
+
+	l, err := corgi.NewLoader(corgi.LoadOptions{GoExecPath: goExec, NoPrecompile: true})
+	require.NoError(t, err)
+	return l
+}
+
+func TestLoader_NotExists(t *testing.T) {
+	t.Parallel()
+
+	t.Run("main", func(t *testing.T) {
+		t.Parallel()
+
+		dir := newModule(t, map[string]string{})
+
+		_, err := newLoader(t).LoadMain(filepath.Join(dir, "missing.corgi"))
+		assert.ErrorIs(t, err, corgi.ErrNotExists)
+	})
+
+	t.Run("include", func(t *testing.T) {
+		t.Parallel()
+
+		dir := newModule(t, map[string]string{
+			"page.corgi": "func Page()\n\ninclude \"missing.corgi\"\n",
+		})
+
+		_, err := newLoader(t).LoadMain(filepath.Join(dir, "page.corgi"))
+		lerr := corgierr.As(err)
+		require.Len(t, lerr, 1, "err: %v", err)
+		assert.Equal(t, corgierr.CodeIncludeNotFound, lerr[0].Code)
+	})
+
+	t.Run("library", func(t *testing.T) {
+		t.Parallel()
+
+		dir := newModule(t, map[string]string{
+			"page.corgi": "use \"example.com/test/missing\"\n\nfunc Page()\n",
+		})
+
+		_, err := newLoader(t).LoadMain(filepath.Join(dir, "page.corgi"))
+		lerr := corgierr.As(err)
+		require.Len(t, lerr, 1, "err: %v", err)
+		assert.Equal(t, corgierr.CodeLibraryNotFound, lerr[0].Code)
+	})
+}
+
+func TestLoader_Modules(t *testing.T) {
+	t.Parallel()
+
+	// newPageModule creates a module with the passed path, whose page.corgi
+	// extends a template of the module that only it provides.
+	newPageModule := func(t *testing.T, modPath string) string {
+		t.Helper()
+
+		return newModule(t, map[string]string{
+			"go.mod":     "module " + modPath + "\n\ngo 1.19\n",
+			"tmpl.corgi": "block main\n",
+			"page.corgi": "extend \"" + modPath + "/tmpl.corgi\"\n\nfunc Page()\n\nblock main\n  p foo\n",
+		})
+	}
+
+	t.Run("concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		dirs := []string{newPageModule(t, "example.com/a"), newPageModule(t, "example.com/b")}
+
+		l := newLoader(t)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			for _, dir := range dirs {
+				dir := dir
+
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					p := filepath.Join(dir, "page.corgi")
+					l.Invalidate(p)
+					_, err := l.LoadMain(p)
+					assert.NoError(t, err)
+				}()
+			}
+		}
+		wg.Wait()
+	})
+
+	t.Run("not in module", func(t *testing.T) {
+		t.Parallel()
+
+		modDir := newPageModule(t, "example.com/a")
+		require.NoError(t, os.WriteFile(filepath.Join(modDir, "other.corgi"), []byte("block main\n"), 0o644))
+
+		dir := t.TempDir()
+		if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "go.mod")); err == nil {
+			t.Skip("temp dir is located in a module")
+		}
+		p := filepath.Join(dir, "page.corgi")
+		require.NoError(t, os.WriteFile(p, []byte("extend \"example.com/a/other.corgi\"\n\nfunc Page()\n"), 0o644))
+
+		l := newLoader(t)
+
+		_, err := l.LoadMain(filepath.Join(modDir, "page.corgi"))
+		require.NoError(t, err)
+
+		// the template is only resolvable from inside example.com/a, which
+		// page.corgi is not part of
+		f, err := l.LoadMain(p)
+		if err == nil {
+			require.NotNil(t, f.Extend)
+			assert.Nil(t, f.Extend.File, "template resolved using the go.mod of another main file")
+		}
+	})
+}
@@ -0,0 +1,249 @@
+package corgierr
+
+import (
+	"embed"
+	"regexp"
+	"strings"
+)
+
+// Code is a stable code identifying the kind of an [Error].
+//
+// Unlike an error's message, codes are guaranteed to never change, and can
+// therefore be used by tooling to track or suppress specific errors.
+//
+// Codes are never reused: If a kind of error is removed, its code will not
+// be assigned to any other kind of error.
+type Code string
+
+// ============================================================================
+// Syntax
+// ======================================================================================
+
+const (
+	CodeSyntax Code = "C0001"
+)
+
+// ============================================================================
+// Preamble
+// ======================================================================================
+
+const (
+	CodeDuplicateImport          Code = "C0002"
+	CodeDuplicateImportNamespace Code = "C0003"
+	CodeUseNonIdentifierBase     Code = "C0004"
+	CodeUnusedUse                Code = "C0005"
+	CodeDuplicateUse             Code = "C0006"
+	CodeDuplicateUseAlias        Code = "C0007"
+	CodeUseNamespaceCollision    Code = "C0008"
+)
+
+// ============================================================================
+// File Types
+// ======================================================================================
+
+const (
+	CodeMissingFuncHeader              Code = "C0009"
+	CodeTemplateBlockPlaceholderInMain Code = "C0010"
+	CodeIfBlockInMain                  Code = "C0011"
+	CodeTemplateFuncHeader             Code = "C0012"
+	CodeUseFileFuncHeader              Code = "C0013"
+	CodeUnexpectedTopLevelItem         Code = "C0014"
+)
+
+// ============================================================================
+// Template Blocks
+// ======================================================================================
+
+const (
+	CodeTemplateBlockWithoutExtend   Code = "C0015"
+	CodeTemplateBlockOutsideTemplate Code = "C0016"
+	CodeTemplateBlockFilledTwice     Code = "C0017"
+	CodeUnknownTemplateBlock         Code = "C0018"
+)
+
+// ============================================================================
+// Elements
+// ======================================================================================
+
+const (
+	CodeTopLevelAttribute         Code = "C0019"
+	CodeTopLevelAnd               Code = "C0020"
+	CodeTopLevelAndInBlock        Code = "C0021"
+	CodeTopLevelAndInBlockDefault Code = "C0022"
+	CodeAttributeAfterBody        Code = "C0023"
+	CodeAndInForLoopWritingBody   Code = "C0024"
+)
+
+// ============================================================================
+// Mixins
+// ======================================================================================
+
+const (
+	CodeNestedMixin           Code = "C0025"
+	CodeDuplicateMixin        Code = "C0026"
+	CodeUninferrableParamType Code = "C0027"
+	CodeDuplicateMixinParam   Code = "C0028"
+	CodeDuplicateLibraryMixin Code = "C0029"
+)
+
+// ============================================================================
+// Mixin Calls
+// ======================================================================================
+
+const (
+	CodeUnknownMixinCallArg               Code = "C0030"
+	CodeDuplicateMixinCallArg             Code = "C0031"
+	CodeRequiredArgChainWithoutDefault    Code = "C0032"
+	CodeMissingMixinCallArg               Code = "C0033"
+	CodeAndWithoutPlaceholder             Code = "C0034"
+	CodeConditionalMixinCallBlock         Code = "C0035"
+	CodeUnexpectedMixinCallItem           Code = "C0036"
+	CodeUnknownMixinCallBlock             Code = "C0037"
+	CodeMixinCallBlockFilledTwice         Code = "C0038"
+	CodeTopLevelAttributeInMixinCallBlock Code = "C0039"
+	CodeAndPlaceholderOutsideMixin        Code = "C0040"
+)
+
+// ============================================================================
+// Mixin Call Attributes
+// ======================================================================================
+
+const (
+	CodeMixinCallAttributeType       Code = "C0041"
+	CodeMixinCallAttributeAttributes Code = "C0042"
+	CodeMixinCallAttributeElements   Code = "C0043"
+	CodeInterpolatedMixinAttributes  Code = "C0044"
+	CodeMixinCallValueWithoutBlock   Code = "C0045"
+)
+
+// ============================================================================
+// Linking
+// ======================================================================================
+
+const (
+	CodeLoadTemplate               Code = "C0046"
+	CodeTemplateNotFound           Code = "C0047"
+	CodeLoadInclude                Code = "C0048"
+	CodeIncludeNotFound            Code = "C0049"
+	CodeLoadLibrary                Code = "C0050"
+	CodeLibraryNotFound            Code = "C0051"
+	CodeLoadPrecompiledDependency  Code = "C0052"
+	CodeEmptyPrecompiledDependency Code = "C0053"
+	CodeLinkPrecompiledDependency  Code = "C0054"
+	CodeUnknownMixin               Code = "C0055"
+	CodeMissingUse                 Code = "C0056"
+	CodeRecursion                  Code = "C0057"
+	CodeAnalyzeMixin               Code = "C0058"
+)
+
+// ============================================================================
+// Filters
+// ======================================================================================
+
+const (
+	CodeDisallowedFilter Code = "C0059"
+	CodeFilterFailed     Code = "C0060"
+)
+
+// ============================================================================
+// Type Checking
+// ======================================================================================
+
+const (
+	CodeTypeError     Code = "C0061"
+	CodeGoSyntaxError Code = "C0062"
+)
+
+// ============================================================================
+// Scripts
+// ======================================================================================
+
+const (
+	CodeDynamicScriptType Code = "C0063"
+)
+
+// ============================================================================
+// Control Structures
+// ======================================================================================
+
+const (
+	CodeElseOnInfiniteFor      Code = "C0064"
+	CodeLoopControlOutsideLoop Code = "C0065"
+	CodeLoopControlAcrossMixin Code = "C0066"
+)
+
+// ============================================================================
+// Generic Mixins
+// ======================================================================================
+
+const (
+	CodeMixinTypeArgCount         Code = "C0067"
+	CodeUninferrableMixinTypeArgs Code = "C0068"
+)
+
+// ============================================================================
+// Variadic Mixin Params
+// ======================================================================================
+
+const (
+	CodeSpreadNonVariadicMixinArg Code = "C0069"
+)
+
+// ============================================================================
+// Scoped Mixin Blocks
+// ======================================================================================
+
+const (
+	CodeTemplateBlockParams        Code = "C0070"
+	CodeUntypedMixinBlockParam     Code = "C0071"
+	CodeMismatchedMixinBlockParams Code = "C0072"
+	CodeMixinCallBlockParamCount   Code = "C0073"
+	CodeTypedMixinCallBlockParam   Code = "C0074"
+	CodeMixinCallBlockParamValue   Code = "C0075"
+)
+
+// ============================================================================
+// Machine Comments
+// ======================================================================================
+
+const (
+	CodeContextParamCollision Code = "C0076"
+	CodeUnknownFragmentBlock  Code = "C0077"
+)
+
+// ============================================================================
+// External Errors
+// ======================================================================================
+
+const (
+	CodeExternal Code = "C0078"
+)
+
+// ============================================================================
+// Explanations
+// ======================================================================================
+
+//go:embed explanations/*.md
+var explanations embed.FS
+
+var codeRegexp = regexp.MustCompile(`^C\d{4}$`)
+
+// Explanation returns a long-form explanation of the kind of error the code
+// identifies, including an example of the wrong and the right way.
+//
+// The explanation is formatted as Markdown.
+//
+// If there is no explanation for the code, Explanation returns false.
+func (c Code) Explanation() (string, bool) {
+	c = Code(strings.ToUpper(string(c)))
+	if !codeRegexp.MatchString(string(c)) {
+		return "", false
+	}
+
+	data, err := explanations.ReadFile("explanations/" + string(c) + ".md")
+	if err != nil {
+		return "", false
+	}
+
+	return string(data), true
+}
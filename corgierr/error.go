@@ -42,6 +42,10 @@ func As(err error) List {
 
 type Error struct {
 	Message string
+	// Code is the stable code identifying the kind of error.
+	//
+	// It may be empty, if the error is not of a known kind.
+	Code Code
 
 	ErrorAnnotation Annotation
 	HintAnnotations []Annotation
@@ -216,7 +220,11 @@ func equalFile(a, b *file.File) bool {
 }
 
 func (err *Error) prettyMessage(sb *strings.Builder, o PrettyOptions) {
-	colored(sb, o, "error: ", color.Bold, color.FgRed)
+	if err.Code != "" {
+		colored(sb, o, "error["+string(err.Code)+"]: ", color.Bold, color.FgRed)
+	} else {
+		colored(sb, o, "error: ", color.Bold, color.FgRed)
+	}
 
 	err.prettyText(o, sb, err.Message, color.Bold)
 	sb.WriteByte('\n')
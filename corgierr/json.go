@@ -0,0 +1,149 @@
+package corgierr
+
+import (
+	"encoding/json"
+
+	"github.com/mavolin/corgi/file"
+)
+
+type (
+	jsonError struct {
+		Message string `json:"message"`
+		Code    Code   `json:"code,omitempty"`
+
+		ErrorAnnotation *jsonAnnotation  `json:"errorAnnotation,omitempty"`
+		HintAnnotations []jsonAnnotation `json:"hintAnnotations,omitempty"`
+
+		Example     string           `json:"example,omitempty"`
+		ShouldBe    string           `json:"shouldBe,omitempty"`
+		Suggestions []jsonSuggestion `json:"suggestions,omitempty"`
+
+		Cause string `json:"cause,omitempty"`
+	}
+
+	jsonAnnotation struct {
+		File *jsonFile `json:"file,omitempty"`
+
+		ContextStart int `json:"contextStart"`
+		ContextEnd   int `json:"contextEnd"`
+		Line         int `json:"line"`
+		Start        int `json:"start"`
+		End          int `json:"end"`
+
+		Annotation string   `json:"annotation,omitempty"`
+		Lines      []string `json:"lines,omitempty"`
+	}
+
+	jsonFile struct {
+		Name         string `json:"name"`
+		Module       string `json:"module,omitempty"`
+		PathInModule string `json:"pathInModule,omitempty"`
+		AbsolutePath string `json:"absolutePath,omitempty"`
+	}
+
+	jsonSuggestion struct {
+		Suggestion string `json:"suggestion"`
+		Example    string `json:"example,omitempty"`
+		ShouldBe   string `json:"shouldBe,omitempty"`
+		Code       string `json:"code,omitempty"`
+	}
+)
+
+// MarshalJSON encodes the list as a JSON array of the errors it contains.
+//
+// A nil List is encoded as an empty array.
+//
+// See [Error.MarshalJSON] for the encoding of the individual errors.
+func (l List) MarshalJSON() ([]byte, error) {
+	if l == nil {
+		return []byte("[]"), nil
+	}
+
+	errs := make([]jsonError, len(l))
+	for i, err := range l {
+		errs[i] = err.toJSON()
+	}
+
+	return json.Marshal(errs)
+}
+
+// MarshalJSON encodes the error as a JSON object.
+//
+// The object contains the message, the code, the error and hint annotations, including
+// the module and path of the file they are located in, as well as the
+// example, should be, and suggestions of the error.
+//
+// Lines and columns are 1-based, with columns being counted in runes.
+// Like the fields of [Annotation], start is inclusive, and end exclusive.
+//
+// The error annotation is omitted, if it is empty, i.e. if the error is not
+// associated with a position.
+// If the error has a cause, the cause's error message is included.
+func (err *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(err.toJSON())
+}
+
+func (err *Error) toJSON() jsonError {
+	jerr := jsonError{
+		Message:  err.Message,
+		Code:     err.Code,
+		Example:  err.Example,
+		ShouldBe: err.ShouldBe,
+	}
+
+	if err.ErrorAnnotation.File != nil || err.ErrorAnnotation.Line > 0 {
+		a := annotationToJSON(err.ErrorAnnotation)
+		jerr.ErrorAnnotation = &a
+	}
+
+	if len(err.HintAnnotations) > 0 {
+		jerr.HintAnnotations = make([]jsonAnnotation, len(err.HintAnnotations))
+		for i, a := range err.HintAnnotations {
+			jerr.HintAnnotations[i] = annotationToJSON(a)
+		}
+	}
+
+	if len(err.Suggestions) > 0 {
+		jerr.Suggestions = make([]jsonSuggestion, len(err.Suggestions))
+		for i, s := range err.Suggestions {
+			jerr.Suggestions[i] = jsonSuggestion{
+				Suggestion: s.Suggestion,
+				Example:    s.Example,
+				ShouldBe:   s.ShouldBe,
+				Code:       s.Code,
+			}
+		}
+	}
+
+	if err.Cause != nil {
+		jerr.Cause = err.Cause.Error()
+	}
+
+	return jerr
+}
+
+func annotationToJSON(a Annotation) jsonAnnotation {
+	return jsonAnnotation{
+		File:         fileToJSON(a.File),
+		ContextStart: a.ContextStart,
+		ContextEnd:   a.ContextEnd,
+		Line:         a.Line,
+		Start:        a.Start,
+		End:          a.End,
+		Annotation:   a.Annotation,
+		Lines:        a.Lines,
+	}
+}
+
+func fileToJSON(f *file.File) *jsonFile {
+	if f == nil {
+		return nil
+	}
+
+	return &jsonFile{
+		Name:         f.Name,
+		Module:       f.Module,
+		PathInModule: f.PathInModule,
+		AbsolutePath: f.AbsolutePath,
+	}
+}
@@ -0,0 +1,133 @@
+package corgierr_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi/corgierr"
+	"github.com/mavolin/corgi/file"
+)
+
+// newTestError returns an error with all fields set, whose error annotation
+// is located in the passed file and whose hint annotation is located in
+// hintFile.
+func newTestError(f, hintFile *file.File) *corgierr.Error {
+	return &corgierr.Error{
+		Message: "duplicate use",
+		Code:    corgierr.CodeDuplicateUse,
+		ErrorAnnotation: corgierr.Annotation{
+			File:         f,
+			ContextStart: 2,
+			ContextEnd:   4,
+			Line:         3,
+			Start:        5,
+			End:          9,
+			Annotation:   "duplicate",
+			Lines:        []string{"use (", "    \"föö\"", ")"},
+		},
+		HintAnnotations: []corgierr.Annotation{{
+			File:         hintFile,
+			ContextStart: 1,
+			ContextEnd:   2,
+			Line:         1,
+			Start:        5,
+			End:          10,
+			Annotation:   "first use",
+			Lines:        []string{"use \"föö\""},
+		}},
+		Example:  "use \"foo\"",
+		ShouldBe: "use \"bar\"",
+		Suggestions: []corgierr.Suggestion{
+			{Suggestion: "remove one of the uses", Code: "use \"foo\""},
+		},
+		Cause: errors.New("cause"),
+	}
+}
+
+func TestList_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := json.Marshal(corgierr.List(nil))
+		require.NoError(t, err)
+		assert.JSONEq(t, "[]", string(data))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		f := &file.File{
+			Name:         "page.corgi",
+			Module:       "example.com/test",
+			PathInModule: "page.corgi",
+			AbsolutePath: "/src/test/page.corgi",
+		}
+
+		l := corgierr.List{
+			newTestError(f, f),
+			{Message: "failed to run goimports", Code: corgierr.CodeExternal},
+		}
+
+		data, err := json.Marshal(l)
+		require.NoError(t, err)
+
+		var actual []map[string]any
+		require.NoError(t, json.Unmarshal(data, &actual))
+		require.Len(t, actual, 2)
+
+		expect := map[string]any{
+			"message": "duplicate use",
+			"code":    "C0006",
+			"errorAnnotation": map[string]any{
+				"file": map[string]any{
+					"name":         "page.corgi",
+					"module":       "example.com/test",
+					"pathInModule": "page.corgi",
+					"absolutePath": "/src/test/page.corgi",
+				},
+				"contextStart": 2.0,
+				"contextEnd":   4.0,
+				"line":         3.0,
+				"start":        5.0,
+				"end":          9.0,
+				"annotation":   "duplicate",
+				"lines":        []any{"use (", "    \"föö\"", ")"},
+			},
+			"hintAnnotations": []any{map[string]any{
+				"file": map[string]any{
+					"name":         "page.corgi",
+					"module":       "example.com/test",
+					"pathInModule": "page.corgi",
+					"absolutePath": "/src/test/page.corgi",
+				},
+				"contextStart": 1.0,
+				"contextEnd":   2.0,
+				"line":         1.0,
+				"start":        5.0,
+				"end":          10.0,
+				"annotation":   "first use",
+				"lines":        []any{"use \"föö\""},
+			}},
+			"example":  "use \"foo\"",
+			"shouldBe": "use \"bar\"",
+			"suggestions": []any{map[string]any{
+				"suggestion": "remove one of the uses",
+				"code":       "use \"foo\"",
+			}},
+			"cause": "cause",
+		}
+		assert.Equal(t, expect, actual[0])
+
+		// errors without a position have no error annotation
+		assert.Equal(t, map[string]any{
+			"message": "failed to run goimports",
+			"code":    "C0078",
+		}, actual[1])
+	})
+}
@@ -0,0 +1,208 @@
+package corgierr
+
+import (
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/mavolin/corgi/file"
+	"github.com/mavolin/corgi/internal/meta"
+)
+
+// SARIFVersion is the version of the SARIF format produced by [List.SARIF].
+const SARIFVersion = "2.1.0"
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// SARIFOptions are the options used by [List.SARIF].
+type SARIFOptions struct {
+	// FileNamePrinter returns the system path of the passed file, which is
+	// used as the uri of the file in the SARIF log.
+	//
+	// If the returned path is relative, the %SRCROOT% uri base id is used.
+	//
+	// If FileNamePrinter is nil, the file's absolute path is used, or if that
+	// is not set, its name.
+	FileNamePrinter func(*file.File) string
+}
+
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool       sarifTool     `json:"tool"`
+		ColumnKind string        `json:"columnKind"`
+		Results    []sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string `json:"name"`
+		Version        string `json:"version,omitempty"`
+		InformationURI string `json:"informationUri"`
+	}
+
+	sarifResult struct {
+		RuleID           string          `json:"ruleId,omitempty"`
+		Level            string          `json:"level"`
+		Message          sarifMessage    `json:"message"`
+		Locations        []sarifLocation `json:"locations,omitempty"`
+		RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+		Properties       *sarifProps     `json:"properties,omitempty"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifLocation struct {
+		ID               *int                  `json:"id,omitempty"`
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+		Message          *sarifMessage         `json:"message,omitempty"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+
+	sarifArtifactLocation struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId,omitempty"`
+	}
+
+	sarifRegion struct {
+		StartLine   int           `json:"startLine"`
+		StartColumn int           `json:"startColumn"`
+		EndLine     int           `json:"endLine"`
+		EndColumn   int           `json:"endColumn"`
+		Snippet     *sarifMessage `json:"snippet,omitempty"`
+	}
+
+	sarifProps struct {
+		Example     string           `json:"example,omitempty"`
+		ShouldBe    string           `json:"shouldBe,omitempty"`
+		Suggestions []jsonSuggestion `json:"suggestions,omitempty"`
+	}
+)
+
+// SARIF encodes the list as a SARIF 2.1.0 log, consisting of a single run
+// with a result for each error.
+//
+// The code of each error is used as the result's rule id.
+// Its error annotation is used as the result's location, and its hint
+// annotations as related locations.
+// The example, should be, and suggestions of an error are stored in the
+// result's property bag.
+//
+// Columns are counted in Unicode code points.
+func (l List) SARIF(o SARIFOptions) ([]byte, error) {
+	if o.FileNamePrinter == nil {
+		o.FileNamePrinter = func(f *file.File) string {
+			if f.AbsolutePath != "" {
+				return f.AbsolutePath
+			}
+
+			return f.Name
+		}
+	}
+
+	results := make([]sarifResult, len(l))
+	for i, err := range l {
+		results[i] = err.sarifResult(o)
+	}
+
+	return json.Marshal(sarifLog{
+		Schema:  sarifSchema,
+		Version: SARIFVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "corgi",
+				Version:        meta.Version,
+				InformationURI: "https://github.com/mavolin/corgi",
+			}},
+			ColumnKind: "unicodeCodePoints",
+			Results:    results,
+		}},
+	})
+}
+
+func (err *Error) sarifResult(o SARIFOptions) sarifResult {
+	r := sarifResult{
+		RuleID:  string(err.Code),
+		Level:   "error",
+		Message: sarifMessage{Text: err.Message},
+	}
+	if err.ErrorAnnotation.Annotation != "" {
+		r.Message.Text += ": " + err.ErrorAnnotation.Annotation
+	}
+
+	if err.ErrorAnnotation.File != nil {
+		r.Locations = []sarifLocation{sarifAnnotationLocation(err.ErrorAnnotation, o)}
+	}
+
+	for i, a := range err.HintAnnotations {
+		if a.File == nil {
+			continue
+		}
+
+		id := i + 1
+		loc := sarifAnnotationLocation(a, o)
+		loc.ID = &id
+		if a.Annotation != "" {
+			loc.Message = &sarifMessage{Text: a.Annotation}
+		}
+
+		r.RelatedLocations = append(r.RelatedLocations, loc)
+	}
+
+	if err.Example != "" || err.ShouldBe != "" || len(err.Suggestions) > 0 {
+		r.Properties = &sarifProps{
+			Example:     err.Example,
+			ShouldBe:    err.ShouldBe,
+			Suggestions: err.toJSON().Suggestions,
+		}
+	}
+
+	return r
+}
+
+func sarifAnnotationLocation(a Annotation, o SARIFOptions) sarifLocation {
+	var loc sarifLocation
+
+	p := o.FileNamePrinter(a.File)
+	if !filepath.IsAbs(p) {
+		loc.PhysicalLocation.ArtifactLocation.URIBaseID = "%SRCROOT%"
+	}
+
+	slashPath := filepath.ToSlash(p)
+	if filepath.IsAbs(p) && !strings.HasPrefix(slashPath, "/") {
+		slashPath = "/" + slashPath // windows drive letter
+	}
+
+	u := url.URL{Path: slashPath}
+	if filepath.IsAbs(p) {
+		u.Scheme = "file"
+	}
+	loc.PhysicalLocation.ArtifactLocation.URI = u.String()
+
+	loc.PhysicalLocation.Region = sarifRegion{
+		StartLine:   a.Line,
+		StartColumn: a.Start,
+		EndLine:     a.Line,
+		EndColumn:   a.End,
+	}
+	if a.Line >= a.ContextStart && a.Line-a.ContextStart < len(a.Lines) {
+		loc.PhysicalLocation.Region.Snippet = &sarifMessage{Text: a.Lines[a.Line-a.ContextStart]}
+	}
+
+	return loc
+}
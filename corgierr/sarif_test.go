@@ -0,0 +1,297 @@
+package corgierr_test
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi/corgierr"
+	"github.com/mavolin/corgi/file"
+)
+
+// sarifProperties are the properties that the objects of the SARIF 2.1.0
+// schema produced by List.SARIF may have, keyed by the name of the
+// definition in the schema.
+//
+// The schema disallows additional properties for all of them.
+var sarifProperties = map[string][]string{
+	"sarifLog": {"$schema", "version", "runs", "inlineExternalProperties", "properties"},
+	"run": {
+		"tool", "invocations", "conversion", "language", "versionControlProvenance", "originalUriBaseIds",
+		"artifacts", "logicalLocations", "graphs", "results", "automationDetails", "runAggregates",
+		"baselineGuid", "redactionTokens", "defaultEncoding", "defaultSourceLanguage", "newlineSequences",
+		"columnKind", "externalPropertyFileReferences", "threadFlowLocations", "taxonomies", "addresses",
+		"translations", "policies", "webRequests", "webResponses", "specialLocations", "properties",
+	},
+	"tool": {"driver", "extensions", "properties"},
+	"toolComponent": {
+		"guid", "name", "organization", "product", "productSuite", "shortDescription", "fullDescription",
+		"fullName", "version", "semanticVersion", "dottedQuadFileVersion", "releaseDateUtc", "downloadUri",
+		"informationUri", "globalMessageStrings", "notifications", "rules", "taxa", "locations", "language",
+		"contents", "isComprehensive", "localizedDataSemanticVersion", "minimumRequiredLocalizedDataSemanticVersion",
+		"associatedComponent", "translationMetadata", "supportedTaxonomies", "properties",
+	},
+	"result": {
+		"ruleId", "ruleIndex", "rule", "kind", "level", "message", "analysisTarget", "locations", "guid",
+		"correlationGuid", "occurrenceCount", "partialFingerprints", "fingerprints", "stacks", "codeFlows",
+		"graphs", "graphTraversals", "relatedLocations", "suppressions", "baselineState", "rank",
+		"attachments", "hostedViewerUri", "workItemUris", "provenance", "fixes", "taxa", "webRequest",
+		"webResponse", "properties",
+	},
+	"message":          {"text", "markdown", "id", "arguments", "properties"},
+	"location":         {"id", "physicalLocation", "logicalLocations", "message", "annotations", "relationships", "properties"},
+	"physicalLocation": {"address", "artifactLocation", "region", "contextRegion", "properties"},
+	"artifactLocation": {"uri", "uriBaseId", "index", "description", "properties"},
+	"region": {
+		"startLine", "startColumn", "endLine", "endColumn", "charOffset", "charLength", "byteOffset",
+		"byteLength", "snippet", "sourceLanguage", "message", "properties",
+	},
+	"artifactContent": {"text", "binary", "rendered", "properties"},
+}
+
+// validateSARIF checks that log has the shape required by the SARIF 2.1.0
+// schema, as far as it is relevant for the logs produced by List.SARIF.
+func validateSARIF(t *testing.T, log map[string]any) {
+	t.Helper()
+
+	object := func(v any, def, path string) map[string]any {
+		t.Helper()
+
+		obj, ok := v.(map[string]any)
+		if !assert.True(t, ok, "%s: expected %s object, got %T", path, def, v) {
+			return nil
+		}
+
+		for k := range obj {
+			assert.Contains(t, sarifProperties[def], k, "%s: unknown property of %s", path, def)
+		}
+
+		return obj
+	}
+	array := func(v any, path string) []any {
+		t.Helper()
+
+		arr, ok := v.([]any)
+		assert.True(t, ok, "%s: expected array, got %T", path, v)
+		return arr
+	}
+	positiveInt := func(v any, path string) {
+		t.Helper()
+
+		n, ok := v.(float64)
+		if assert.True(t, ok, "%s: expected integer, got %T", path, v) {
+			assert.Equal(t, float64(int(n)), n, "%s: expected integer", path)
+			assert.GreaterOrEqual(t, n, 1.0, "%s: minimum is 1", path)
+		}
+	}
+	message := func(v any, def, path string) {
+		t.Helper()
+
+		msg := object(v, def, path)
+		assert.IsType(t, "", msg["text"], "%s.text", path)
+	}
+	location := func(v any, path string) {
+		t.Helper()
+
+		loc := object(v, "location", path)
+		if id, ok := loc["id"]; ok {
+			positiveInt(id, path+".id")
+		}
+		if msg, ok := loc["message"]; ok {
+			message(msg, "message", path+".message")
+		}
+
+		phys := object(loc["physicalLocation"], "physicalLocation", path+".physicalLocation")
+
+		artifact := object(phys["artifactLocation"], "artifactLocation", path+".physicalLocation.artifactLocation")
+		assert.IsType(t, "", artifact["uri"], "%s.physicalLocation.artifactLocation.uri", path)
+
+		regionPath := path + ".physicalLocation.region"
+		region := object(phys["region"], "region", regionPath)
+		for _, k := range []string{"startLine", "startColumn", "endLine", "endColumn"} {
+			positiveInt(region[k], regionPath+"."+k)
+		}
+		if snippet, ok := region["snippet"]; ok {
+			message(snippet, "artifactContent", regionPath+".snippet")
+		}
+	}
+
+	object(log, "sarifLog", "sarifLog")
+	assert.Equal(t, "2.1.0", log["version"])
+	assert.IsType(t, "", log["$schema"])
+
+	runs := array(log["runs"], "runs")
+	for _, v := range runs {
+		run := object(v, "run", "run")
+		assert.Contains(t, []any{"utf16CodeUnits", "unicodeCodePoints"}, run["columnKind"])
+
+		tool := object(run["tool"], "tool", "run.tool")
+		driver := object(tool["driver"], "toolComponent", "run.tool.driver")
+		assert.NotEmpty(t, driver["name"], "run.tool.driver.name")
+
+		for _, v := range array(run["results"], "run.results") {
+			result := object(v, "result", "result")
+			assert.Contains(t, []any{"none", "note", "warning", "error"}, result["level"])
+			message(result["message"], "message", "result.message")
+
+			if locs, ok := result["locations"]; ok {
+				for _, loc := range array(locs, "result.locations") {
+					location(loc, "result.locations[]")
+				}
+			}
+			if locs, ok := result["relatedLocations"]; ok {
+				for _, loc := range array(locs, "result.relatedLocations") {
+					location(loc, "result.relatedLocations[]")
+				}
+			}
+			if props, ok := result["properties"]; ok {
+				assert.IsType(t, map[string]any{}, props, "result.properties")
+			}
+		}
+	}
+}
+
+func TestList_SARIF(t *testing.T) {
+	t.Parallel()
+
+	abs, err := filepath.Abs(filepath.Join("src", "page.corgi"))
+	require.NoError(t, err)
+
+	f := &file.File{Name: "page.corgi", AbsolutePath: abs}
+	tmpl := &file.File{Name: "tmpl.corgi", AbsolutePath: filepath.Join(filepath.Dir(abs), "tmpl.corgi")}
+
+	l := corgierr.List{
+		newTestError(f, tmpl),
+		{Message: "failed to run goimports", Code: corgierr.CodeExternal},
+	}
+
+	t.Run("default file names", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := l.SARIF(corgierr.SARIFOptions{})
+		require.NoError(t, err)
+
+		var log map[string]any
+		require.NoError(t, json.Unmarshal(data, &log))
+		validateSARIF(t, log)
+
+		var actual struct {
+			Runs []struct {
+				Results []struct {
+					RuleID    string `json:"ruleId"`
+					Level     string `json:"level"`
+					Message   struct{ Text string }
+					Locations []struct {
+						PhysicalLocation struct {
+							ArtifactLocation struct {
+								URI       string `json:"uri"`
+								URIBaseID string `json:"uriBaseId"`
+							} `json:"artifactLocation"`
+							Region struct {
+								StartLine   int `json:"startLine"`
+								StartColumn int `json:"startColumn"`
+								EndLine     int `json:"endLine"`
+								EndColumn   int `json:"endColumn"`
+								Snippet     *struct{ Text string }
+							} `json:"region"`
+						} `json:"physicalLocation"`
+					} `json:"locations"`
+					RelatedLocations []struct {
+						ID               int `json:"id"`
+						Message          struct{ Text string }
+						PhysicalLocation struct {
+							ArtifactLocation struct {
+								URI string `json:"uri"`
+							} `json:"artifactLocation"`
+							Region struct {
+								StartLine   int `json:"startLine"`
+								StartColumn int `json:"startColumn"`
+								EndColumn   int `json:"endColumn"`
+							} `json:"region"`
+						} `json:"physicalLocation"`
+					} `json:"relatedLocations"`
+					Properties struct {
+						Example     string
+						ShouldBe    string
+						Suggestions []struct{ Suggestion string }
+					} `json:"properties"`
+				} `json:"results"`
+			} `json:"runs"`
+		}
+		require.NoError(t, json.Unmarshal(data, &actual))
+		require.Len(t, actual.Runs, 1)
+
+		results := actual.Runs[0].Results
+		require.Len(t, results, 2)
+
+		r := results[0]
+		assert.Equal(t, "C0006", r.RuleID)
+		assert.Equal(t, "error", r.Level)
+		assert.Equal(t, "duplicate use: duplicate", r.Message.Text)
+
+		require.Len(t, r.Locations, 1)
+		loc := r.Locations[0].PhysicalLocation
+		assert.Equal(t, "file://"+filepath.ToSlash(abs), loc.ArtifactLocation.URI)
+		assert.Empty(t, loc.ArtifactLocation.URIBaseID)
+		assert.Equal(t, 3, loc.Region.StartLine)
+		assert.Equal(t, 5, loc.Region.StartColumn)
+		assert.Equal(t, 3, loc.Region.EndLine)
+		assert.Equal(t, 9, loc.Region.EndColumn)
+		require.NotNil(t, loc.Region.Snippet)
+		assert.Equal(t, "    \"föö\"", loc.Region.Snippet.Text)
+
+		require.Len(t, r.RelatedLocations, 1)
+		related := r.RelatedLocations[0]
+		assert.Equal(t, 1, related.ID)
+		assert.Equal(t, "first use", related.Message.Text)
+		assert.Equal(t, "file://"+filepath.ToSlash(tmpl.AbsolutePath), related.PhysicalLocation.ArtifactLocation.URI)
+		assert.Equal(t, 1, related.PhysicalLocation.Region.StartLine)
+		assert.Equal(t, 5, related.PhysicalLocation.Region.StartColumn)
+		assert.Equal(t, 10, related.PhysicalLocation.Region.EndColumn)
+
+		assert.Equal(t, "use \"foo\"", r.Properties.Example)
+		assert.Equal(t, "use \"bar\"", r.Properties.ShouldBe)
+		require.Len(t, r.Properties.Suggestions, 1)
+		assert.Equal(t, "remove one of the uses", r.Properties.Suggestions[0].Suggestion)
+
+		assert.Equal(t, "C0078", results[1].RuleID)
+		assert.Equal(t, "failed to run goimports", results[1].Message.Text)
+		assert.Empty(t, results[1].Locations)
+	})
+
+	t.Run("relative file names", func(t *testing.T) {
+		t.Parallel()
+
+		data, err := l.SARIF(corgierr.SARIFOptions{FileNamePrinter: func(f *file.File) string {
+			return filepath.Join("src", f.Name)
+		}})
+		require.NoError(t, err)
+
+		var log map[string]any
+		require.NoError(t, json.Unmarshal(data, &log))
+		validateSARIF(t, log)
+
+		var actual struct {
+			Runs []struct {
+				Results []struct {
+					Locations []struct {
+						PhysicalLocation struct {
+							ArtifactLocation struct {
+								URI       string `json:"uri"`
+								URIBaseID string `json:"uriBaseId"`
+							} `json:"artifactLocation"`
+						} `json:"physicalLocation"`
+					} `json:"locations"`
+				} `json:"results"`
+			} `json:"runs"`
+		}
+		require.NoError(t, json.Unmarshal(data, &actual))
+
+		artifact := actual.Runs[0].Results[0].Locations[0].PhysicalLocation.ArtifactLocation
+		assert.Equal(t, "src/page.corgi", artifact.URI)
+		assert.Equal(t, "%SRCROOT%", artifact.URIBaseID)
+	})
+}
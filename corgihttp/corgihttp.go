@@ -0,0 +1,362 @@
+// Package corgihttp provides an adapter that turns generated functions into
+// http.Handlers.
+//
+// Main files with a `//corgi:handler` machine comment additionally get a
+// generated companion function, named after the main function and suffixed
+// with Handler, that calls [Handler] for the main function.
+package corgihttp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mavolin/corgi/woof"
+)
+
+// RenderFunc renders a template for the passed request to w.
+//
+// Usually, it calls a generated function:
+//
+//	func(w io.Writer, r *http.Request) error {
+//		return Page(w, r.URL.Query().Get("name"))
+//	}
+type RenderFunc func(w io.Writer, r *http.Request) error
+
+// Options are the options used by [Handler].
+type Options struct {
+	// ContentType is the value of the Content-Type header.
+	//
+	// It defaults to "text/html; charset=utf-8".
+	ContentType string
+
+	// Unbuffered, if set to true, writes the rendered template to the
+	// response at every `//corgi:flush` machine comment, instead of
+	// buffering it until rendering is complete.
+	// The response is flushed to the client at every flush point, so that,
+	// for example, the head of a page can be sent before slow data is loaded.
+	//
+	// This reduces the memory usage and the time to first byte of large
+	// pages, but if rendering fails after something was written, the
+	// response can't be replaced by an error response anymore.
+	// Instead, the connection is aborted by panicking with
+	// [http.ErrAbortHandler].
+	//
+	// ETag is ignored if Unbuffered is true.
+	Unbuffered bool
+
+	// Nonce, if set to true, generates a random nonce for every request.
+	//
+	// The nonce is passed to the template using [woof.WithNonce], and can be
+	// retrieved using [Nonce], e.g. to pass it to the template manually.
+	//
+	// Only templates with a `//corgi:nonce` or `//corgi:stylenonce` machine
+	// comment without args inject the nonce into their script or style
+	// elements respectively.
+	Nonce bool
+	// ContentSecurityPolicy, if set, is the value of the
+	// Content-Security-Policy header.
+	//
+	// All occurrences of `{nonce}` are replaced with the nonce of the request.
+	ContentSecurityPolicy string
+
+	// Gzip, if set to true, compresses the response using gzip, if the
+	// client accepts it.
+	Gzip bool
+
+	// ETag, if set to true, adds an ETag header computed from the response,
+	// and responds with 304 Not Modified, if it matches the If-None-Match
+	// header of the request.
+	//
+	// Since nonces change with every request, ETag should not be used in
+	// combination with Nonce.
+	ETag bool
+
+	// RecoverPanics, if set to true, converts panics during rendering into
+	// errors using [woof.WithPanicRecovery], so that they are handled by the
+	// ErrorHandler, instead of aborting the connection.
+	RecoverPanics bool
+
+	// ErrorHandler is called if the RenderFunc returns an error, before
+	// anything was written.
+	//
+	// It defaults to [DefaultErrorHandler].
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// Handler returns an http.Handler that responds with the template rendered
+// by render.
+func Handler(render RenderFunc, o Options) http.Handler {
+	if o.ContentType == "" {
+		o.ContentType = "text/html; charset=utf-8"
+	}
+	if o.ErrorHandler == nil {
+		o.ErrorHandler = DefaultErrorHandler
+	}
+
+	return &handler{render: render, o: o}
+}
+
+// Error is an error that causes [DefaultErrorHandler] to respond with the
+// status code Status.
+//
+// It can be returned by the RenderFunc or a template to signal a client
+// error, e.g. a 404 Not Found.
+type Error struct {
+	Status int
+	Err    error
+}
+
+var _ error = (*Error)(nil)
+
+func (err *Error) Error() string {
+	if err.Err == nil {
+		return http.StatusText(err.Status)
+	}
+
+	return err.Err.Error()
+}
+
+func (err *Error) Unwrap() error {
+	return err.Err
+}
+
+// DefaultErrorHandler is the default error handler of [Handler].
+//
+// If err is or wraps an [*Error], it responds with the Error's status code,
+// otherwise it responds with 500 Internal Server Error.
+//
+// To not leak any internals, the response body is only the status text, and
+// never the error message.
+func DefaultErrorHandler(w http.ResponseWriter, _ *http.Request, err error) {
+	status := http.StatusInternalServerError
+
+	var herr *Error
+	if errors.As(err, &herr) && herr.Status != 0 {
+		status = herr.Status
+	}
+
+	http.Error(w, http.StatusText(status), status)
+}
+
+type nonceKey struct{}
+
+// Nonce returns the nonce generated for the passed request, or "", if the
+// request wasn't handled by a [Handler] with [Options.Nonce] set.
+func Nonce(r *http.Request) string {
+	nonce, _ := r.Context().Value(nonceKey{}).(string)
+	return nonce
+}
+
+type handler struct {
+	render RenderFunc
+	o      Options
+}
+
+var _ http.Handler = (*handler)(nil)
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var nonce string
+	if h.o.Nonce {
+		var err error
+		nonce, err = newNonce()
+		if err != nil {
+			h.o.ErrorHandler(w, r, err)
+			return
+		}
+
+		r = r.WithContext(context.WithValue(r.Context(), nonceKey{}, nonce))
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", h.o.ContentType)
+	if h.o.ContentSecurityPolicy != "" {
+		header.Set("Content-Security-Policy", strings.ReplaceAll(h.o.ContentSecurityPolicy, "{nonce}", nonce))
+	}
+
+	var gz bool
+	if h.o.Gzip {
+		header.Add("Vary", "Accept-Encoding")
+		gz = acceptsGzip(r)
+	}
+
+	if h.o.Unbuffered {
+		h.serveUnbuffered(w, r, nonce, gz)
+		return
+	}
+
+	h.serveBuffered(w, r, nonce, gz)
+}
+
+func (h *handler) serveBuffered(w http.ResponseWriter, r *http.Request, nonce string, gz bool) {
+	var buf bytes.Buffer
+	if err := h.render(h.templateWriter(&buf, nonce), r); err != nil {
+		h.o.ErrorHandler(w, r, err)
+		return
+	}
+
+	body := buf.Bytes()
+	header := w.Header()
+
+	if h.o.ETag {
+		etag := etagOf(body, gz)
+		header.Set("ETag", etag)
+
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	if gz {
+		var gzBuf bytes.Buffer
+		gzw := gzip.NewWriter(&gzBuf)
+		// writes to a bytes.Buffer never fail
+		_, _ = gzw.Write(body)
+		_ = gzw.Close()
+
+		body = gzBuf.Bytes()
+		header.Set("Content-Encoding", "gzip")
+	}
+
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	_, _ = w.Write(body)
+}
+
+func (h *handler) serveUnbuffered(w http.ResponseWriter, r *http.Request, nonce string, gz bool) {
+	lw := &lazyWriter{w: w, gz: gz}
+	if err := h.render(woof.WithBuffering(h.templateWriter(lw, nonce)), r); err != nil {
+		if !lw.started {
+			h.o.ErrorHandler(w, r, err)
+			return
+		}
+
+		panic(http.ErrAbortHandler)
+	}
+
+	if lw.gzw != nil {
+		if err := lw.gzw.Close(); err != nil {
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
+// lazyWriter is a writer that delays starting the response until the first
+// write, so that the error handler can still respond, if rendering fails
+// before anything was written.
+type lazyWriter struct {
+	w  http.ResponseWriter
+	gz bool
+
+	started bool
+	gzw     *gzip.Writer
+}
+
+func (lw *lazyWriter) Write(p []byte) (int, error) {
+	if !lw.started {
+		lw.started = true
+
+		if lw.gz {
+			lw.w.Header().Set("Content-Encoding", "gzip")
+			lw.gzw = gzip.NewWriter(lw.w)
+		}
+	}
+
+	if lw.gzw != nil {
+		return lw.gzw.Write(p)
+	}
+
+	return lw.w.Write(p)
+}
+
+// Flush flushes the response, starting it, if it hasn't been started yet.
+func (lw *lazyWriter) Flush() error {
+	if !lw.started {
+		// start the response
+		if _, err := lw.Write(nil); err != nil {
+			return err
+		}
+	}
+
+	if lw.gzw != nil {
+		if err := lw.gzw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if f, ok := lw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+
+	return nil
+}
+
+func (h *handler) templateWriter(w io.Writer, nonce string) io.Writer {
+	if nonce != "" {
+		w = woof.WithNonce(w, nonce)
+	}
+	if h.o.RecoverPanics {
+		w = woof.WithPanicRecovery(w)
+	}
+
+	return w
+}
+
+func newNonce() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(b[:]), nil
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(enc, ";")
+		if strings.TrimSpace(name) != "gzip" {
+			continue
+		}
+
+		params = strings.ReplaceAll(params, " ", "")
+		return params != "q=0" && params != "q=0.0" && params != "q=0.00" && params != "q=0.000"
+	}
+
+	return false
+}
+
+func etagOf(body []byte, gz bool) string {
+	sum := sha256.Sum256(body)
+	etag := base64.RawURLEncoding.EncodeToString(sum[:16])
+	if gz {
+		// representations with different encodings must have different tags
+		etag += "-gzip"
+	}
+
+	return `"` + etag + `"`
+}
+
+// etagMatches reports whether the passed If-None-Match header matches etag,
+// using weak comparison.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,362 @@
+package corgihttp_test
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi/corgihttp"
+	"github.com/mavolin/corgi/woof"
+)
+
+// staticPage returns a RenderFunc that renders body, like a generated
+// function would.
+func staticPage(body string) corgihttp.RenderFunc {
+	return func(w io.Writer, _ *http.Request) error {
+		ctx := woof.NewContext(w)
+		ctx.Write(body)
+		ctx.Done()
+		return nil
+	}
+}
+
+func serve(h http.Handler, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for k, v := range header {
+		r.Header[k] = v
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	rec := serve(corgihttp.Handler(staticPage("<p>foo</p>"), corgihttp.Options{}), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "10", rec.Header().Get("Content-Length"))
+	assert.Equal(t, "<p>foo</p>", rec.Body.String())
+}
+
+func TestHandler_ETag(t *testing.T) {
+	t.Parallel()
+
+	h := corgihttp.Handler(staticPage("<p>foo</p>"), corgihttp.Options{ETag: true})
+
+	rec := serve(h, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.True(t, strings.HasPrefix(etag, `"`) && strings.HasSuffix(etag, `"`), "unquoted etag %s", etag)
+
+	t.Run("same body", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, etag, serve(h, nil).Header().Get("ETag"))
+	})
+
+	t.Run("different body", func(t *testing.T) {
+		t.Parallel()
+
+		other := serve(corgihttp.Handler(staticPage("<p>bar</p>"), corgihttp.Options{ETag: true}), nil)
+		assert.NotEqual(t, etag, other.Header().Get("ETag"))
+	})
+
+	notModified := []struct {
+		name        string
+		ifNoneMatch string
+	}{
+		{name: "strong", ifNoneMatch: etag},
+		{name: "weak", ifNoneMatch: "W/" + etag},
+		{name: "list", ifNoneMatch: `"foo", ` + etag},
+		{name: "any", ifNoneMatch: "*"},
+	}
+
+	for _, c := range notModified {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := serve(h, http.Header{"If-None-Match": {c.ifNoneMatch}})
+			assert.Equal(t, http.StatusNotModified, rec.Code)
+			assert.Equal(t, etag, rec.Header().Get("ETag"))
+			assert.Empty(t, rec.Body.String())
+		})
+	}
+
+	t.Run("modified", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serve(h, http.Header{"If-None-Match": {`"foo"`}})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "<p>foo</p>", rec.Body.String())
+	})
+
+	t.Run("gzip", func(t *testing.T) {
+		t.Parallel()
+
+		h := corgihttp.Handler(staticPage("<p>foo</p>"), corgihttp.Options{ETag: true, Gzip: true})
+
+		gzRec := serve(h, http.Header{"Accept-Encoding": {"gzip"}})
+		assert.NotEqual(t, etag, gzRec.Header().Get("ETag"), "gzipped and identity responses share an etag")
+
+		rec := serve(h, http.Header{"Accept-Encoding": {"gzip"}, "If-None-Match": {etag}})
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("unbuffered", func(t *testing.T) {
+		t.Parallel()
+
+		h := corgihttp.Handler(staticPage("<p>foo</p>"), corgihttp.Options{ETag: true, Unbuffered: true})
+
+		rec := serve(h, http.Header{"If-None-Match": {"*"}})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("ETag"))
+		assert.Equal(t, "<p>foo</p>", rec.Body.String())
+	})
+}
+
+func TestHandler_Gzip(t *testing.T) {
+	t.Parallel()
+
+	body := strings.Repeat("<p>foo</p>", 100)
+
+	testCases := []struct {
+		name           string
+		acceptEncoding string
+		expectGzip     bool
+	}{
+		{name: "gzip", acceptEncoding: "gzip", expectGzip: true},
+		{name: "list", acceptEncoding: "deflate, gzip;q=0.8, br", expectGzip: true},
+		{name: "q=0", acceptEncoding: "gzip; q=0", expectGzip: false},
+		{name: "other", acceptEncoding: "br", expectGzip: false},
+		{name: "none", acceptEncoding: "", expectGzip: false},
+	}
+
+	for _, unbuffered := range []bool{false, true} {
+		unbuffered := unbuffered
+		h := corgihttp.Handler(staticPage(body), corgihttp.Options{Gzip: true, Unbuffered: unbuffered})
+
+		name := "buffered"
+		if unbuffered {
+			name = "unbuffered"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, c := range testCases {
+				c := c
+				t.Run(c.name, func(t *testing.T) {
+					t.Parallel()
+
+					rec := serve(h, http.Header{"Accept-Encoding": {c.acceptEncoding}})
+					assert.Equal(t, http.StatusOK, rec.Code)
+					assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+
+					if !c.expectGzip {
+						assert.Empty(t, rec.Header().Get("Content-Encoding"))
+						assert.Equal(t, body, rec.Body.String())
+						return
+					}
+
+					assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+					if !unbuffered {
+						assert.Equal(t, strconv.Itoa(rec.Body.Len()), rec.Header().Get("Content-Length"))
+					}
+
+					gzr, err := gzip.NewReader(rec.Body)
+					require.NoError(t, err)
+					actual, err := io.ReadAll(gzr)
+					require.NoError(t, err)
+					assert.Equal(t, body, string(actual))
+				})
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serve(corgihttp.Handler(staticPage(body), corgihttp.Options{}), http.Header{"Accept-Encoding": {"gzip"}})
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Empty(t, rec.Header().Get("Vary"))
+		assert.Equal(t, body, rec.Body.String())
+	})
+}
+
+func TestHandler_Unbuffered(t *testing.T) {
+	t.Parallel()
+
+	rec := httptest.NewRecorder()
+
+	render := func(w io.Writer, _ *http.Request) error {
+		ctx := woof.NewContext(w)
+		ctx.Write("<head></head>")
+		// nothing is written before the first flush point
+		assert.Empty(t, rec.Body.String())
+
+		ctx.Flush()
+		assert.Equal(t, "<head></head>", rec.Body.String(), "output not written at flush point")
+		assert.True(t, rec.Flushed, "response not flushed at flush point")
+
+		ctx.Write("<body></body>")
+		ctx.Done()
+		return nil
+	}
+
+	h := corgihttp.Handler(render, corgihttp.Options{Unbuffered: true})
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Content-Length"))
+	assert.Equal(t, "<head></head><body></body>", rec.Body.String())
+}
+
+func TestHandler_Error(t *testing.T) {
+	t.Parallel()
+
+	errRender := errors.New("render failed")
+
+	failing := func(w io.Writer, _ *http.Request) error {
+		ctx := woof.NewContext(w)
+		ctx.Write("<p>foo</p>")
+		ctx.Done()
+		return errRender
+	}
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+
+		rec := serve(corgihttp.Handler(failing, corgihttp.Options{}), nil)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "<p>foo</p>")
+		assert.NotContains(t, rec.Body.String(), errRender.Error())
+	})
+
+	t.Run("status", func(t *testing.T) {
+		t.Parallel()
+
+		render := func(io.Writer, *http.Request) error {
+			return &corgihttp.Error{Status: http.StatusNotFound}
+		}
+
+		rec := serve(corgihttp.Handler(render, corgihttp.Options{}), nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, http.StatusText(http.StatusNotFound)+"\n", rec.Body.String())
+	})
+
+	t.Run("custom handler", func(t *testing.T) {
+		t.Parallel()
+
+		var handled error
+		h := corgihttp.Handler(failing, corgihttp.Options{
+			ErrorHandler: func(w http.ResponseWriter, _ *http.Request, err error) {
+				handled = err
+				w.WriteHeader(http.StatusTeapot)
+			},
+		})
+
+		rec := serve(h, nil)
+		assert.Equal(t, http.StatusTeapot, rec.Code)
+		assert.ErrorIs(t, handled, errRender)
+	})
+
+	t.Run("unbuffered before first write", func(t *testing.T) {
+		t.Parallel()
+
+		render := func(w io.Writer, _ *http.Request) error {
+			ctx := woof.NewContext(w)
+			ctx.Write("<p>foo</p>")
+			// the output is still buffered, so the error handler can respond
+			return errRender
+		}
+
+		rec := serve(corgihttp.Handler(render, corgihttp.Options{Unbuffered: true}), nil)
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "<p>foo</p>")
+	})
+
+	t.Run("unbuffered after headers were sent", func(t *testing.T) {
+		t.Parallel()
+
+		render := func(w io.Writer, _ *http.Request) error {
+			ctx := woof.NewContext(w)
+			ctx.Write("<head></head>")
+			ctx.Flush()
+			return errRender
+		}
+
+		var handlerCalled bool
+		h := corgihttp.Handler(render, corgihttp.Options{
+			Unbuffered: true,
+			ErrorHandler: func(http.ResponseWriter, *http.Request, error) {
+				handlerCalled = true
+			},
+		})
+
+		rec := httptest.NewRecorder()
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+		assert.False(t, handlerCalled, "error handler called after the response was started")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "<head></head>", rec.Body.String())
+	})
+
+	t.Run("unbuffered server aborts connection", func(t *testing.T) {
+		t.Parallel()
+
+		render := func(w io.Writer, _ *http.Request) error {
+			ctx := woof.NewContext(w)
+			ctx.Write("<head></head>")
+			ctx.Flush()
+			return errRender
+		}
+
+		srv := httptest.NewServer(corgihttp.Handler(render, corgihttp.Options{Unbuffered: true}))
+		defer srv.Close()
+
+		resp, err := srv.Client().Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		_, err = io.ReadAll(resp.Body)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF, "truncated response not detectable by the client")
+	})
+}
+
+func TestHandler_Nonce(t *testing.T) {
+	t.Parallel()
+
+	var nonces []string
+	render := func(_ io.Writer, r *http.Request) error {
+		nonces = append(nonces, corgihttp.Nonce(r))
+		return nil
+	}
+
+	h := corgihttp.Handler(render, corgihttp.Options{
+		Nonce:                 true,
+		ContentSecurityPolicy: "script-src 'nonce-{nonce}'",
+	})
+
+	rec := serve(h, nil)
+	serve(h, nil)
+
+	require.Len(t, nonces, 2)
+	assert.NotEmpty(t, nonces[0])
+	assert.NotEqual(t, nonces[0], nonces[1])
+	assert.Equal(t, "script-src 'nonce-"+nonces[0]+"'", rec.Header().Get("Content-Security-Policy"))
+}
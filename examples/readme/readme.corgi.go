@@ -8,12 +8,15 @@ import (
 	__corgi_woof "github.com/mavolin/corgi/woof"
 )
 
-// Code generated by github.com/mavolin/corgi (devel-50993374313459a8e4fcb611f6c51bea11d8eb4e). DO NOT EDIT.
+// Code generated by github.com/mavolin/corgi (0.0.0-20261017230454-b9c4dae3debb+dirty). DO NOT EDIT.
 
-func LearnCorgi(__corgi_w __corgi_io.Writer, name string, knowsPug bool, friends []string) error {
+func LearnCorgi(__corgi_w __corgi_io.Writer, name string, knowsPug bool, friends []string) (__corgi_err error) {
 	__corgi_ctx := __corgi_woof.NewContext(__corgi_w)
-	var __corgi_err error
-	defer func() { __corgi_err = __corgi_ctx.Recover() }()
+	defer func() {
+		if __corgi_ctx.Recovers() {
+			__corgi_err = __corgi_ctx.Recover(recover())
+		}
+	}()
 	var __corgi_mixin0 func(any, *string, *string)
 	{
 		listSep := ", "
@@ -22,27 +25,37 @@ func LearnCorgi(__corgi_w __corgi_io.Writer, name string, knowsPug bool, friends
 		__corgi_preMixin5 = func(val any, __corgi_mixinParam_sep *string, __corgi_mixinParam_lastSep *string) {
 			sep := __corgi_woof.ResolveDefault(__corgi_mixinParam_sep, listSep)
 			lastSep := __corgi_woof.ResolveDefault(__corgi_mixinParam_lastSep, listLastSep)
+			__corgi_ctx.At("github.com/mavolin/corgi/std/fmt/list.corgil", 31, 6)
 			if val == nil {
 				return
 			}
+			__corgi_ctx.At("github.com/mavolin/corgi/std/fmt/list.corgil", 34, 5)
 			rval := __corgi_std_reflect.ValueOf(val)
+			__corgi_ctx.At("github.com/mavolin/corgi/std/fmt/list.corgil", 35, 10)
 			switch rval.Len() {
 			case 0:
 				return
 			case 1:
 				__corgi_ctx.CloseStartTag("", false)
+				__corgi_ctx.At("github.com/mavolin/corgi/std/fmt/list.corgil", 39, 11)
 				__corgi_woof.WriteAny(__corgi_ctx, __corgi_woof.EscapeHTML, rval.Index(0).Interface())
 				__corgi_ctx.Closed()
 				return
-				__corgi_ctx.Closed()
 			}
 			__corgi_ctx.CloseStartTag("", false)
+			__corgi_ctx.At("github.com/mavolin/corgi/std/fmt/list.corgil", 42, 7)
 			__corgi_woof.WriteAny(__corgi_ctx, __corgi_woof.EscapeHTML, rval.Index(0).Interface())
+			__corgi_ctx.At("github.com/mavolin/corgi/std/fmt/list.corgil", 43, 7)
 			for i := 1; i < rval.Len()-1; i++ {
+				__corgi_ctx.CheckContext()
+				__corgi_ctx.At("github.com/mavolin/corgi/std/fmt/list.corgil", 44, 9)
 				__corgi_woof.WriteAny(__corgi_ctx, __corgi_woof.EscapeHTML, sep)
+				__corgi_ctx.At("github.com/mavolin/corgi/std/fmt/list.corgil", 44, 15)
 				__corgi_woof.WriteAny(__corgi_ctx, __corgi_woof.EscapeHTML, rval.Index(i).Interface())
 			}
+			__corgi_ctx.At("github.com/mavolin/corgi/std/fmt/list.corgil", 45, 7)
 			__corgi_woof.WriteAny(__corgi_ctx, __corgi_woof.EscapeHTML, lastSep)
+			__corgi_ctx.At("github.com/mavolin/corgi/std/fmt/list.corgil", 45, 17)
 			__corgi_woof.WriteAny(__corgi_ctx, __corgi_woof.EscapeHTML, rval.Index(rval.Len()-1).Interface())
 			__corgi_ctx.Closed()
 		}
@@ -53,6 +66,7 @@ func LearnCorgi(__corgi_w __corgi_io.Writer, name string, knowsPug bool, friends
 	__corgi_mixin1 := func(name string) {
 		__corgi_ctx.CloseStartTag("", false)
 		__corgi_ctx.Write("Hello, ")
+		__corgi_ctx.At("github.com/mavolin/corgi/examples/readme/readme.corgi", 7, 35)
 		__corgi_woof.WriteAny(__corgi_ctx, __corgi_woof.EscapeHTML, name)
 		__corgi_ctx.Write("!")
 		__corgi_ctx.Closed()
@@ -60,24 +74,32 @@ func LearnCorgi(__corgi_w __corgi_io.Writer, name string, knowsPug bool, friends
 	__corgi_ctx.Write("<!doctype html><html lang=en><head><title>Learn Corgi</title></head><body><h1>Learn Corgi</h1><p id=greeting")
 	__corgi_ctx.BufferClassAttr("greeting")
 	__corgi_ctx.Unclosed()
+	__corgi_ctx.At("github.com/mavolin/corgi/examples/readme/readme.corgi", 17, 10)
 	if strings.HasPrefix(name, "M") {
 		__corgi_ctx.BufferClassAttr("font-size--big")
 	}
+	__corgi_ctx.PushMixin("greet", "github.com/mavolin/corgi/examples/readme/readme.corgi", 18, 7)
 	__corgi_mixin1(name)
+	__corgi_ctx.PopMixin()
 	__corgi_ctx.CloseStartTag("", false)
 	__corgi_ctx.Write("</p><p")
 	__corgi_ctx.Unclosed()
+	__corgi_ctx.At("github.com/mavolin/corgi/examples/readme/readme.corgi", 21, 10)
 	if knowsPug {
 		__corgi_ctx.Write(">")
+		__corgi_ctx.At("github.com/mavolin/corgi/examples/readme/readme.corgi", 22, 13)
 		__corgi_woof.WriteAny(__corgi_ctx, __corgi_woof.EscapeHTMLBody, name)
 		__corgi_ctx.Write(", since you already know pug,\nlearning corgi will be even more of <strong>a breeze</strong> for you! ")
 		__corgi_ctx.Closed()
 	}
 	__corgi_ctx.CloseStartTag("", false)
 	__corgi_ctx.Write("Head over to <a href=https://mavolin.gitbook.io/corgi>GitBook</a>\nto learn it.</p><p>And make sure to tell ")
+	__corgi_ctx.PushMixin("fmt.List", "github.com/mavolin/corgi/examples/readme/readme.corgi", 28, 30)
 	__corgi_mixin0(friends, nil, nil)
+	__corgi_ctx.PopMixin()
 	__corgi_ctx.Write(" about corgi too!")
 	__corgi_ctx.CloseStartTag("", false)
 	__corgi_ctx.Write("</p></body></html>")
+	__corgi_ctx.Done()
 	return __corgi_err
 }
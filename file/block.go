@@ -22,6 +22,16 @@ type Block struct {
 	// Name is the name of the block.
 	Name Ident
 
+	// Params are the parameters of the block, if it has a parameter list.
+	//
+	// Only the blocks of mixins and mixin calls may have parameters.
+	// In a mixin, each parameter has a type, and its value is either the
+	// value bound to it using `=`, or the variable of the same name in the
+	// scope of the block.
+	// In a mixin call, the parameters have no types, and are bound to the
+	// values passed by the mixin, in the order the mixin declares them.
+	Params []BlockParam
+
 	Body Scope
 
 	Position
@@ -31,6 +41,27 @@ var _ ScopeItem = Block{}
 
 func (Block) _typeScopeItem() {}
 
+// BlockParam represents a parameter of a mixin block, or of the block of a
+// mixin call.
+type BlockParam struct {
+	// Name is the name of the parameter.
+	Name GoIdent
+	// Type is the type of the parameter.
+	//
+	// It is nil for the parameters of mixin call blocks.
+	Type *GoType
+
+	// AssignPos is the position of the '=', if the parameter has a value.
+	AssignPos *Position
+	// Value is the value bound to the parameter of a mixin block, if any.
+	//
+	// If it is nil, the value of the parameter is the variable of the same
+	// name.
+	Value *Expression // never a chain expression
+
+	Position
+}
+
 // ============================================================================
 // Block Expansion
 // ======================================================================================
@@ -121,12 +121,59 @@ type Case struct {
 
 // For represents a for loop.
 type For struct {
+	// Label is the label of the loop, if it has one.
+	//
+	// It can be used by [Break] and [Continue] to refer to this loop from
+	// inside a nested loop.
+	Label *Ident
 	// Expression is the expression written in the head of the for, or nil if
 	// this is an infinite loop.
 	Expression *Expression
 	Body       Scope
 
+	// Else is the scope of the else of the for, if it has one.
+	//
+	// It is executed, if Body is never executed, i.e. if the ranged over
+	// collection is empty or if the condition is false from the start.
+	Else *Else
+
 	Position
 }
 
 func (For) _typeScopeItem() {}
+
+// ============================================================================
+// Break
+// ======================================================================================
+
+// Break represents a break statement.
+type Break struct {
+	// Label is the label of the loop to break out of.
+	//
+	// If it is nil, the innermost loop is broken out of.
+	Label *Ident
+
+	Position
+}
+
+var _ ScopeItem = Break{}
+
+func (Break) _typeScopeItem() {}
+
+// ============================================================================
+// Continue
+// ======================================================================================
+
+// Continue represents a continue statement.
+type Continue struct {
+	// Label is the label of the loop to continue.
+	//
+	// If it is nil, the innermost loop is continued.
+	Label *Ident
+
+	Position
+}
+
+var _ ScopeItem = Continue{}
+
+func (Continue) _typeScopeItem() {}
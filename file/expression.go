@@ -45,6 +45,9 @@ type RangeExpression struct {
 	Ordered  bool     // true if the range expression is ordered ('= ordered range')
 
 	// RangeExpression is the expression that is being iterated over.
+	//
+	// It is written to the generated file as is, so ranging over an int
+	// requires the generated file to be compiled with Go 1.22 or later.
 	RangeExpression Expression
 
 	Position
@@ -3,6 +3,9 @@
 package fileutil
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/mavolin/corgi/file"
 )
 
@@ -26,6 +29,10 @@ func FirstNonControl(s file.Scope) file.ScopeItem {
 			return true, nil
 		case file.Code:
 			return false, nil
+		case file.Break:
+			return false, nil
+		case file.Continue:
+			return false, nil
 		case file.CorgiComment:
 			return false, nil
 		default:
@@ -52,6 +59,10 @@ func IsFirstNonControlAttr(s file.Scope) (file.ScopeItem, bool) {
 			return true, nil
 		case file.Code:
 			return false, nil
+		case file.Break:
+			return false, nil
+		case file.Continue:
+			return false, nil
 		case file.CorgiComment:
 			return false, nil
 		case file.And:
@@ -132,6 +143,10 @@ func firstActualAttr(s file.Scope) file.ScopeItem {
 			return true, nil
 		case file.Code:
 			return false, nil
+		case file.Break:
+			return false, nil
+		case file.Continue:
+			return false, nil
 		case file.CorgiComment:
 			return false, nil
 		case file.Block:
@@ -206,3 +221,65 @@ func IsAttrMixin(lm file.LinkedMixin) bool {
 func IsElementMixin(lm file.LinkedMixin) bool {
 	return IsStdLibFile(lm.File) && lm.File.PathInModule == "std/html" && lm.Mixin.Name.Ident == "Attr"
 }
+
+// ScriptType returns the value of the type attribute of the passed script
+// element, as set in its attribute lists, or "", if it has none.
+//
+// Like browsers, ScriptType uses the first type attribute, if there are
+// multiple.
+//
+// If the type attribute is set to anything but a string literal without
+// interpolations, static is false.
+func ScriptType(el file.Element) (typ string, static bool) {
+	for _, acoll := range el.Attributes {
+		alist, ok := acoll.(file.AttributeList)
+		if !ok {
+			continue
+		}
+
+		for _, attr := range alist.Attributes {
+			switch attr := attr.(type) {
+			case file.SimpleAttribute:
+				if attr.Name != "type" {
+					continue
+				}
+
+				if attr.Value == nil {
+					return "", true
+				}
+
+				return staticString(*attr.Value)
+			case file.MixinCallAttribute:
+				if attr.Name == "type" {
+					return "", false
+				}
+			}
+		}
+	}
+
+	return "", true
+}
+
+func staticString(expr file.Expression) (string, bool) {
+	if len(expr.Expressions) != 1 {
+		return "", false
+	}
+
+	sexpr, ok := expr.Expressions[0].(file.StringExpression)
+	if !ok {
+		return "", false
+	}
+
+	var sb strings.Builder
+	for _, itm := range sexpr.Contents {
+		txt, ok := itm.(file.StringExpressionText)
+		if !ok {
+			return "", false
+		}
+
+		sb.WriteString(txt.Text)
+	}
+
+	s, err := strconv.Unquote(string(sexpr.Quote) + sb.String() + string(sexpr.Quote))
+	return s, err == nil
+}
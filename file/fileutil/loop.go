@@ -0,0 +1,39 @@
+package fileutil
+
+import "github.com/mavolin/corgi/file"
+
+// LoopOf returns the loop that a [file.Break] or [file.Continue] with the
+// passed label refers to, given the parents of the break or continue, as
+// passed to a [WalkFunc].
+//
+// If label is nil, that is the innermost loop, otherwise the innermost loop
+// with that label.
+// The else of a loop is not considered part of that loop.
+//
+// If there is no such loop among the parents, LoopOf returns nil.
+//
+// acrossMixin indicates whether a [file.Mixin] or [file.MixinCall] lies
+// between the break or continue and the loop, or, if there is no such loop,
+// the outermost parent.
+// Since mixins and the bodies of mixin calls are generated as separate
+// functions, loops outside of them can't be broken out of or continued.
+func LoopOf(parents []WalkContext, label *file.Ident) (loop *file.For, acrossMixin bool) {
+	for i := len(parents) - 1; i >= 0; i-- {
+		switch itm := (*parents[i].Item).(type) {
+		case file.Mixin:
+			acrossMixin = true
+		case file.MixinCall:
+			acrossMixin = true
+		case file.For:
+			if parents[i].Else != nil {
+				continue
+			}
+
+			if label == nil || (itm.Label != nil && itm.Label.Ident == label.Ident) {
+				return &itm, acrossMixin
+			}
+		}
+	}
+
+	return nil, acrossMixin
+}
@@ -36,7 +36,8 @@ type WalkContext struct {
 	// Note that if this is set, the parent context's item will be if's
 	// parent, not the if itself.
 	ElseIfBlock *file.ElseIfBlock
-	// Else is the else of Item.(file.If) that we are walking.
+	// Else is the else of Item.(file.If), Item.(file.IfBlock), or
+	// Item.(file.For) that we are walking.
 	//
 	// Note that if this is set, the parent context's item will be if's
 	// parent, not the if itself.
@@ -132,6 +133,21 @@ func walk(parents []WalkContext, s file.Scope, f WalkFunc) error {
 				}
 				ctx.ElseIf = nil
 
+				if itm.Else != nil {
+					ctx.Else = itm.Else
+					parents = append(parents, ctx)
+					if err := walk(parents, itm.Else.Then, f); err != nil {
+						return err
+					}
+					parents = parents[:len(parents)-1]
+				}
+			case file.For:
+				parents = append(parents, ctx)
+				if err := walk(parents, itm.Body, f); err != nil {
+					return err
+				}
+				parents = parents[:len(parents)-1]
+
 				if itm.Else != nil {
 					ctx.Else = itm.Else
 					parents = append(parents, ctx)
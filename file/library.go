@@ -93,4 +93,29 @@ type PrecompiledMixin struct {
 	Var string
 	// RequiredBy are the names of the depending mixins.
 	RequiredBy []string
+
+	// Instantiations are the instantiations of generic library mixins called
+	// by this mixin.
+	Instantiations []MixinInstantiation
+
+	// ScriptHashes and StyleHashes are the CSP hashes of the static inline
+	// scripts and styles written by this mixin.
+	ScriptHashes []string
+	StyleHashes  []string
+}
+
+// MixinInstantiation is an instantiation of a generic library mixin, called
+// by a precompiled mixin.
+type MixinInstantiation struct {
+	// Module and PathInModule identify the library of the called mixin.
+	Module       string
+	PathInModule string
+	// Name is the name of the called mixin.
+	Name string
+
+	// TypeArgs are the type args of the instantiation.
+	//
+	// If the calling mixin is generic itself, they may refer to its type
+	// params.
+	TypeArgs []string
 }
@@ -8,6 +8,9 @@ type Mixin struct {
 	// Name is the name of the mixin.
 	Name Ident
 
+	// TypeParams are the type parameters of the mixin, if it is generic.
+	TypeParams []MixinTypeParam
+
 	LParenPos *Position // nil if params were omitted
 
 	// Params is a list of the parameters of the mixin.
@@ -19,9 +22,10 @@ type Mixin struct {
 	Body Scope
 
 	// Precompiled is the precompiled function literal.
-	// Its args start with the mixins args, followed by func()s for each of
-	// the Blocks, and lastly, if HasAndPlaceholders is true, a final func()
-	// called each time that the mixin's &s are supposed to be placed.
+	// Its args start with the mixins args, followed by funcs for each of
+	// the Blocks taking the block's params, and lastly, if
+	// HasAndPlaceholders is true, a final func() called each time that the
+	// mixin's &s are supposed to be placed.
 	//
 	// It is only present, if this mixin was precompiled.
 	Precompiled []byte
@@ -53,7 +57,10 @@ type (
 	}
 
 	MixinBlockInfo struct {
-		Name     string
+		Name string
+		// Params are the params of the block, as declared by its
+		// placeholders.
+		Params   []BlockParam
 		TopLevel bool // writes directly to the element it is called in
 		// CanAttributes specifies whether &-directives can be used in this block.
 		CanAttributes                   bool
@@ -68,6 +75,21 @@ var _ ScopeItem = Mixin{}
 
 func (Mixin) _typeScopeItem() {}
 
+// ================================= Mixin Type Param ==================================
+
+// MixinTypeParam represents a type parameter of a generic mixin.
+type MixinTypeParam struct {
+	// Name is the name of the type parameter.
+	Name Ident
+	// Constraint is the type constraint of the type parameter.
+	//
+	// Type parameters that are declared together, e.g. `K, V any`, share
+	// the same Constraint, including its position.
+	Constraint GoType
+
+	Position
+}
+
 // ==================================== Mixin Param =====================================
 
 // MixinParam represents a parameter of a mixin.
@@ -75,6 +97,12 @@ type MixinParam struct {
 	// Name is the name of the parameter.
 	Name Ident
 
+	// Variadic indicates whether the parameter is variadic.
+	//
+	// Variadic parameters can be set any number of times in a mixin call,
+	// and are a slice of Type inside the mixin.
+	// They always have a Type and never a Default.
+	Variadic bool
 	// Type is the name of the type of the parameter, or nil if the type is
 	// inferred from the default.
 	Type *GoType
@@ -116,6 +144,17 @@ type MixinCall struct {
 	// Name is the name of the mixin.
 	Name Ident
 
+	// TypeArgs are the explicitly specified type arguments of the call to a
+	// generic mixin.
+	TypeArgs []GoType
+	// InferredTypeArgs are the type arguments inferred from Args, if
+	// TypeArgs is empty and the called mixin is generic.
+	//
+	// It will be set by package typeinfer during linking.
+	//
+	// A nil slice indicates the type arguments could not be inferred.
+	InferredTypeArgs []string
+
 	// Mixin is a pointer to the called mixin.
 	//
 	// It is set by the linker.
@@ -159,6 +198,9 @@ type MixinArg struct {
 	Name Ident
 	// Value is the expression that yields the value of the argument.
 	Value Expression
+	// Spread indicates whether Value is a slice whose elements are passed to
+	// a variadic parameter, as in `tags=xs...`.
+	Spread bool
 
 	Position
 }
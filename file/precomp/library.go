@@ -272,15 +272,20 @@ type mixin struct {
 
 	Name corgiIdent
 
+	TypeParams []mixinTypeParam `msg:",omitempty"`
+
 	LParenPos *position
 	Params    []mixinParam
 	RParenPos *position
 
 	Position position
 
-	Var         string
-	Precompiled []byte
-	RequiredBy  []string
+	Var            string
+	Precompiled    []byte
+	RequiredBy     []string
+	Instantiations []mixinInstantiation `msg:",omitempty"`
+	ScriptHashes   []string             `msg:",omitempty"`
+	StyleHashes    []string             `msg:",omitempty"`
 
 	WritesBody               bool
 	WritesElements           bool
@@ -317,17 +322,37 @@ func newMixin(fs []*cfile.File, m *cfile.PrecompiledMixin) (*mixin, error) {
 		block := block
 		blocks[i] = *newMixinBlock(&block)
 	}
+	var typeParams []mixinTypeParam
+	if len(m.Mixin.TypeParams) > 0 {
+		typeParams = make([]mixinTypeParam, len(m.Mixin.TypeParams))
+		for i, tp := range m.Mixin.TypeParams {
+			tp := tp
+			typeParams[i] = *newMixinTypeParam(&tp)
+		}
+	}
+	var insts []mixinInstantiation
+	if len(m.Instantiations) > 0 {
+		insts = make([]mixinInstantiation, len(m.Instantiations))
+		for i, inst := range m.Instantiations {
+			inst := inst
+			insts[i] = *newMixinInstantiation(&inst)
+		}
+	}
 
 	return &mixin{
 		FileIndex:                fileIndex,
 		MachineComments:          m.MachineComments,
 		Name:                     *newCorgiIdent(&m.Mixin.Name),
+		TypeParams:               typeParams,
 		LParenPos:                newPosition(m.Mixin.LParenPos),
 		Params:                   params,
 		RParenPos:                newPosition(m.Mixin.RParenPos),
 		Position:                 *newPosition(&m.Mixin.Position),
 		Var:                      m.Var,
 		RequiredBy:               m.RequiredBy,
+		Instantiations:           insts,
+		ScriptHashes:             m.ScriptHashes,
+		StyleHashes:              m.StyleHashes,
 		Precompiled:              m.Mixin.Precompiled,
 		WritesBody:               m.Mixin.WritesBody,
 		WritesElements:           m.Mixin.WritesElements,
@@ -351,14 +376,29 @@ func (m *mixin) toFile(fs []*cfile.File) *cfile.PrecompiledMixin {
 	for i, eItm := range m.Blocks {
 		blocks[i] = *eItm.toFile()
 	}
+	var typeParams []cfile.MixinTypeParam
+	if len(m.TypeParams) > 0 {
+		typeParams = make([]cfile.MixinTypeParam, len(m.TypeParams))
+		for i, eItm := range m.TypeParams {
+			typeParams[i] = *eItm.toFile()
+		}
+	}
+	var insts []cfile.MixinInstantiation
+	if len(m.Instantiations) > 0 {
+		insts = make([]cfile.MixinInstantiation, len(m.Instantiations))
+		for i, eItm := range m.Instantiations {
+			insts[i] = *eItm.toFile()
+		}
+	}
 	return &cfile.PrecompiledMixin{
 		File:            fs[m.FileIndex],
 		MachineComments: m.MachineComments,
 		Mixin: cfile.Mixin{
-			Name:      *m.Name.toFile(),
-			LParenPos: m.LParenPos.toFile(),
-			Params:    params,
-			RParenPos: m.RParenPos.toFile(),
+			Name:       *m.Name.toFile(),
+			TypeParams: typeParams,
+			LParenPos:  m.LParenPos.toFile(),
+			Params:     params,
+			RParenPos:  m.RParenPos.toFile(),
 			MixinInfo: &cfile.MixinInfo{
 				WritesBody:               m.WritesBody,
 				WritesElements:           m.WritesElements,
@@ -370,13 +410,76 @@ func (m *mixin) toFile(fs []*cfile.File) *cfile.PrecompiledMixin {
 			Precompiled: m.Precompiled,
 			Position:    *m.Position.toFile(),
 		},
-		Var:        m.Var,
-		RequiredBy: m.RequiredBy,
+		Var:            m.Var,
+		RequiredBy:     m.RequiredBy,
+		Instantiations: insts,
+		ScriptHashes:   m.ScriptHashes,
+		StyleHashes:    m.StyleHashes,
+	}
+}
+
+type mixinTypeParam struct {
+	Name       corgiIdent
+	Constraint goType
+	Position   position
+}
+
+func newMixinTypeParam(tp *cfile.MixinTypeParam) *mixinTypeParam {
+	if tp == nil {
+		return nil
+	}
+	return &mixinTypeParam{
+		Name:       *newCorgiIdent(&tp.Name),
+		Constraint: *newGoType(&tp.Constraint),
+		Position:   *newPosition(&tp.Position),
+	}
+}
+
+func (tp *mixinTypeParam) toFile() *cfile.MixinTypeParam {
+	if tp == nil {
+		return nil
+	}
+	return &cfile.MixinTypeParam{
+		Name:       *tp.Name.toFile(),
+		Constraint: *tp.Constraint.toFile(),
+		Position:   *tp.Position.toFile(),
+	}
+}
+
+type mixinInstantiation struct {
+	Module       string
+	PathInModule string
+	Name         string
+	TypeArgs     []string
+}
+
+func newMixinInstantiation(inst *cfile.MixinInstantiation) *mixinInstantiation {
+	if inst == nil {
+		return nil
+	}
+	return &mixinInstantiation{
+		Module:       inst.Module,
+		PathInModule: inst.PathInModule,
+		Name:         inst.Name,
+		TypeArgs:     inst.TypeArgs,
+	}
+}
+
+func (inst *mixinInstantiation) toFile() *cfile.MixinInstantiation {
+	if inst == nil {
+		return nil
+	}
+	return &cfile.MixinInstantiation{
+		Module:       inst.Module,
+		PathInModule: inst.PathInModule,
+		Name:         inst.Name,
+		TypeArgs:     inst.TypeArgs,
 	}
 }
 
 type mixinParam struct {
 	Name         corgiIdent
+	Variadic     bool `msg:",omitempty"`
 	Type         *goType
 	InferredType string
 
@@ -397,6 +500,7 @@ func newMixinParam(param *cfile.MixinParam) (*mixinParam, error) {
 	}
 	return &mixinParam{
 		Name:         *newCorgiIdent(&param.Name),
+		Variadic:     param.Variadic,
 		Type:         newGoType(param.Type),
 		InferredType: param.InferredType,
 		AssignPos:    newPosition(param.AssignPos),
@@ -411,6 +515,7 @@ func (param *mixinParam) toFile() *cfile.MixinParam {
 	}
 	return &cfile.MixinParam{
 		Name:         *param.Name.toFile(),
+		Variadic:     param.Variadic,
 		Type:         param.Type.toFile(),
 		InferredType: param.InferredType,
 		AssignPos:    param.AssignPos.toFile(),
@@ -421,6 +526,7 @@ func (param *mixinParam) toFile() *cfile.MixinParam {
 
 type mixinBlock struct {
 	Name                            string
+	Params                          []blockParam `msg:",omitempty"`
 	TopLevel                        bool
 	CanAttributes                   bool
 	DefaultWritesBody               bool
@@ -433,8 +539,18 @@ func newMixinBlock(mb *cfile.MixinBlockInfo) *mixinBlock {
 	if mb == nil {
 		return nil
 	}
+	var params []blockParam
+	if len(mb.Params) > 0 {
+		params = make([]blockParam, len(mb.Params))
+		for i, param := range mb.Params {
+			param := param
+			params[i] = *newBlockParam(&param)
+		}
+	}
+
 	return &mixinBlock{
 		Name:                            mb.Name,
+		Params:                          params,
 		TopLevel:                        mb.TopLevel,
 		CanAttributes:                   mb.CanAttributes,
 		DefaultWritesBody:               mb.DefaultWritesBody,
@@ -448,8 +564,18 @@ func (mb *mixinBlock) toFile() *cfile.MixinBlockInfo {
 	if mb == nil {
 		return nil
 	}
+	var params []cfile.BlockParam
+	if len(mb.Params) > 0 {
+		params = make([]cfile.BlockParam, len(mb.Params))
+		for i, param := range mb.Params {
+			param := param
+			params[i] = *param.toFile()
+		}
+	}
+
 	return &cfile.MixinBlockInfo{
 		Name:                            mb.Name,
+		Params:                          params,
 		TopLevel:                        mb.TopLevel,
 		CanAttributes:                   mb.CanAttributes,
 		DefaultWritesBody:               mb.DefaultWritesBody,
@@ -459,6 +585,34 @@ func (mb *mixinBlock) toFile() *cfile.MixinBlockInfo {
 	}
 }
 
+type blockParam struct {
+	Name     goIdent
+	Type     *goType
+	Position position
+}
+
+func newBlockParam(param *cfile.BlockParam) *blockParam {
+	if param == nil {
+		return nil
+	}
+	return &blockParam{
+		Name:     *newGoIdent(&param.Name),
+		Type:     newGoType(param.Type),
+		Position: *newPosition(&param.Position),
+	}
+}
+
+func (param *blockParam) toFile() *cfile.BlockParam {
+	if param == nil {
+		return nil
+	}
+	return &cfile.BlockParam{
+		Name:     *param.Name.toFile(),
+		Type:     param.Type.toFile(),
+		Position: *param.Position.toFile(),
+	}
+}
+
 type expression struct {
 	Expressions []expressionItem
 }
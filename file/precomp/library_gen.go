@@ -221,6 +221,250 @@ func (z *_import) Msgsize() (s int) {
 	return
 }
 
+// DecodeMsg implements msgp.Decodable
+func (z *blockParam) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Name":
+			err = z.Name.DecodeMsg(dc)
+			if err != nil {
+				err = msgp.WrapError(err, "Name")
+				return
+			}
+		case "Type":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					err = msgp.WrapError(err, "Type")
+					return
+				}
+				z.Type = nil
+			} else {
+				if z.Type == nil {
+					z.Type = new(goType)
+				}
+				err = z.Type.DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Type")
+					return
+				}
+			}
+		case "Position":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Position")
+				return
+			}
+			if zb0002 != 2 {
+				err = msgp.ArrayError{Wanted: 2, Got: zb0002}
+				return
+			}
+			z.Position.Line, err = dc.ReadInt()
+			if err != nil {
+				err = msgp.WrapError(err, "Position", "Line")
+				return
+			}
+			z.Position.Col, err = dc.ReadInt()
+			if err != nil {
+				err = msgp.WrapError(err, "Position", "Col")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *blockParam) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 3
+	// write "Name"
+	err = en.Append(0x83, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
+	if err != nil {
+		return
+	}
+	err = z.Name.EncodeMsg(en)
+	if err != nil {
+		err = msgp.WrapError(err, "Name")
+		return
+	}
+	// write "Type"
+	err = en.Append(0xa4, 0x54, 0x79, 0x70, 0x65)
+	if err != nil {
+		return
+	}
+	if z.Type == nil {
+		err = en.WriteNil()
+		if err != nil {
+			return
+		}
+	} else {
+		err = z.Type.EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Type")
+			return
+		}
+	}
+	// write "Position"
+	err = en.Append(0xa8, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e)
+	if err != nil {
+		return
+	}
+	// array header, size 2
+	err = en.Append(0x92)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt(z.Position.Line)
+	if err != nil {
+		err = msgp.WrapError(err, "Position", "Line")
+		return
+	}
+	err = en.WriteInt(z.Position.Col)
+	if err != nil {
+		err = msgp.WrapError(err, "Position", "Col")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *blockParam) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 3
+	// string "Name"
+	o = append(o, 0x83, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
+	o, err = z.Name.MarshalMsg(o)
+	if err != nil {
+		err = msgp.WrapError(err, "Name")
+		return
+	}
+	// string "Type"
+	o = append(o, 0xa4, 0x54, 0x79, 0x70, 0x65)
+	if z.Type == nil {
+		o = msgp.AppendNil(o)
+	} else {
+		o, err = z.Type.MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Type")
+			return
+		}
+	}
+	// string "Position"
+	o = append(o, 0xa8, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e)
+	// array header, size 2
+	o = append(o, 0x92)
+	o = msgp.AppendInt(o, z.Position.Line)
+	o = msgp.AppendInt(o, z.Position.Col)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *blockParam) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Name":
+			bts, err = z.Name.UnmarshalMsg(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Name")
+				return
+			}
+		case "Type":
+			if msgp.IsNil(bts) {
+				bts, err = msgp.ReadNilBytes(bts)
+				if err != nil {
+					return
+				}
+				z.Type = nil
+			} else {
+				if z.Type == nil {
+					z.Type = new(goType)
+				}
+				bts, err = z.Type.UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Type")
+					return
+				}
+			}
+		case "Position":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Position")
+				return
+			}
+			if zb0002 != 2 {
+				err = msgp.ArrayError{Wanted: 2, Got: zb0002}
+				return
+			}
+			z.Position.Line, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Position", "Line")
+				return
+			}
+			z.Position.Col, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Position", "Col")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *blockParam) Msgsize() (s int) {
+	s = 1 + 5 + z.Name.Msgsize() + 5
+	if z.Type == nil {
+		s += msgp.NilSize
+	} else {
+		s += z.Type.Msgsize()
+	}
+	s += 9 + 1 + msgp.IntSize + msgp.IntSize
+	return
+}
+
 // DecodeMsg implements msgp.Decodable
 func (z *code) DecodeMsg(dc *msgp.Reader) (err error) {
 	var field []byte
@@ -3101,6 +3345,25 @@ func (z *mixin) DecodeMsg(dc *msgp.Reader) (err error) {
 				err = msgp.WrapError(err, "Name")
 				return
 			}
+		case "TypeParams":
+			var zb0003 uint32
+			zb0003, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "TypeParams")
+				return
+			}
+			if cap(z.TypeParams) >= int(zb0003) {
+				z.TypeParams = (z.TypeParams)[:zb0003]
+			} else {
+				z.TypeParams = make([]mixinTypeParam, zb0003)
+			}
+			for za0002 := range z.TypeParams {
+				err = z.TypeParams[za0002].DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "TypeParams", za0002)
+					return
+				}
+			}
 		case "LParenPos":
 			if dc.IsNil() {
 				err = dc.ReadNil()
@@ -3113,14 +3376,14 @@ func (z *mixin) DecodeMsg(dc *msgp.Reader) (err error) {
 				if z.LParenPos == nil {
 					z.LParenPos = new(position)
 				}
-				var zb0003 uint32
-				zb0003, err = dc.ReadArrayHeader()
+				var zb0004 uint32
+				zb0004, err = dc.ReadArrayHeader()
 				if err != nil {
 					err = msgp.WrapError(err, "LParenPos")
 					return
 				}
-				if zb0003 != 2 {
-					err = msgp.ArrayError{Wanted: 2, Got: zb0003}
+				if zb0004 != 2 {
+					err = msgp.ArrayError{Wanted: 2, Got: zb0004}
 					return
 				}
 				z.LParenPos.Line, err = dc.ReadInt()
@@ -3135,21 +3398,21 @@ func (z *mixin) DecodeMsg(dc *msgp.Reader) (err error) {
 				}
 			}
 		case "Params":
-			var zb0004 uint32
-			zb0004, err = dc.ReadArrayHeader()
+			var zb0005 uint32
+			zb0005, err = dc.ReadArrayHeader()
 			if err != nil {
 				err = msgp.WrapError(err, "Params")
 				return
 			}
-			if cap(z.Params) >= int(zb0004) {
-				z.Params = (z.Params)[:zb0004]
+			if cap(z.Params) >= int(zb0005) {
+				z.Params = (z.Params)[:zb0005]
 			} else {
-				z.Params = make([]mixinParam, zb0004)
+				z.Params = make([]mixinParam, zb0005)
 			}
-			for za0002 := range z.Params {
-				err = z.Params[za0002].DecodeMsg(dc)
+			for za0003 := range z.Params {
+				err = z.Params[za0003].DecodeMsg(dc)
 				if err != nil {
-					err = msgp.WrapError(err, "Params", za0002)
+					err = msgp.WrapError(err, "Params", za0003)
 					return
 				}
 			}
@@ -3165,14 +3428,14 @@ func (z *mixin) DecodeMsg(dc *msgp.Reader) (err error) {
 				if z.RParenPos == nil {
 					z.RParenPos = new(position)
 				}
-				var zb0005 uint32
-				zb0005, err = dc.ReadArrayHeader()
+				var zb0006 uint32
+				zb0006, err = dc.ReadArrayHeader()
 				if err != nil {
 					err = msgp.WrapError(err, "RParenPos")
 					return
 				}
-				if zb0005 != 2 {
-					err = msgp.ArrayError{Wanted: 2, Got: zb0005}
+				if zb0006 != 2 {
+					err = msgp.ArrayError{Wanted: 2, Got: zb0006}
 					return
 				}
 				z.RParenPos.Line, err = dc.ReadInt()
@@ -3187,14 +3450,14 @@ func (z *mixin) DecodeMsg(dc *msgp.Reader) (err error) {
 				}
 			}
 		case "Position":
-			var zb0006 uint32
-			zb0006, err = dc.ReadArrayHeader()
-			if err != nil {
+			var zb0007 uint32
+			zb0007, err = dc.ReadArrayHeader()
+			if err != nil {
 				err = msgp.WrapError(err, "Position")
 				return
 			}
-			if zb0006 != 2 {
-				err = msgp.ArrayError{Wanted: 2, Got: zb0006}
+			if zb0007 != 2 {
+				err = msgp.ArrayError{Wanted: 2, Got: zb0007}
 				return
 			}
 			z.Position.Line, err = dc.ReadInt()
@@ -3220,21 +3483,78 @@ func (z *mixin) DecodeMsg(dc *msgp.Reader) (err error) {
 				return
 			}
 		case "RequiredBy":
-			var zb0007 uint32
-			zb0007, err = dc.ReadArrayHeader()
+			var zb0008 uint32
+			zb0008, err = dc.ReadArrayHeader()
 			if err != nil {
 				err = msgp.WrapError(err, "RequiredBy")
 				return
 			}
-			if cap(z.RequiredBy) >= int(zb0007) {
-				z.RequiredBy = (z.RequiredBy)[:zb0007]
+			if cap(z.RequiredBy) >= int(zb0008) {
+				z.RequiredBy = (z.RequiredBy)[:zb0008]
+			} else {
+				z.RequiredBy = make([]string, zb0008)
+			}
+			for za0004 := range z.RequiredBy {
+				z.RequiredBy[za0004], err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "RequiredBy", za0004)
+					return
+				}
+			}
+		case "Instantiations":
+			var zb0009 uint32
+			zb0009, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Instantiations")
+				return
+			}
+			if cap(z.Instantiations) >= int(zb0009) {
+				z.Instantiations = (z.Instantiations)[:zb0009]
+			} else {
+				z.Instantiations = make([]mixinInstantiation, zb0009)
+			}
+			for za0005 := range z.Instantiations {
+				err = z.Instantiations[za0005].DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Instantiations", za0005)
+					return
+				}
+			}
+		case "ScriptHashes":
+			var zb0010 uint32
+			zb0010, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "ScriptHashes")
+				return
+			}
+			if cap(z.ScriptHashes) >= int(zb0010) {
+				z.ScriptHashes = (z.ScriptHashes)[:zb0010]
+			} else {
+				z.ScriptHashes = make([]string, zb0010)
+			}
+			for za0006 := range z.ScriptHashes {
+				z.ScriptHashes[za0006], err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "ScriptHashes", za0006)
+					return
+				}
+			}
+		case "StyleHashes":
+			var zb0011 uint32
+			zb0011, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "StyleHashes")
+				return
+			}
+			if cap(z.StyleHashes) >= int(zb0011) {
+				z.StyleHashes = (z.StyleHashes)[:zb0011]
 			} else {
-				z.RequiredBy = make([]string, zb0007)
+				z.StyleHashes = make([]string, zb0011)
 			}
-			for za0003 := range z.RequiredBy {
-				z.RequiredBy[za0003], err = dc.ReadString()
+			for za0007 := range z.StyleHashes {
+				z.StyleHashes[za0007], err = dc.ReadString()
 				if err != nil {
-					err = msgp.WrapError(err, "RequiredBy", za0003)
+					err = msgp.WrapError(err, "StyleHashes", za0007)
 					return
 				}
 			}
@@ -3263,21 +3583,21 @@ func (z *mixin) DecodeMsg(dc *msgp.Reader) (err error) {
 				return
 			}
 		case "Blocks":
-			var zb0008 uint32
-			zb0008, err = dc.ReadArrayHeader()
+			var zb0012 uint32
+			zb0012, err = dc.ReadArrayHeader()
 			if err != nil {
 				err = msgp.WrapError(err, "Blocks")
 				return
 			}
-			if cap(z.Blocks) >= int(zb0008) {
-				z.Blocks = (z.Blocks)[:zb0008]
+			if cap(z.Blocks) >= int(zb0012) {
+				z.Blocks = (z.Blocks)[:zb0012]
 			} else {
-				z.Blocks = make([]mixinBlock, zb0008)
+				z.Blocks = make([]mixinBlock, zb0012)
 			}
-			for za0004 := range z.Blocks {
-				err = z.Blocks[za0004].DecodeMsg(dc)
+			for za0008 := range z.Blocks {
+				err = z.Blocks[za0008].DecodeMsg(dc)
 				if err != nil {
-					err = msgp.WrapError(err, "Blocks", za0004)
+					err = msgp.WrapError(err, "Blocks", za0008)
 					return
 				}
 			}
@@ -3300,9 +3620,9 @@ func (z *mixin) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *mixin) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 16
+	// map header, size 20
 	// write "FileIndex"
-	err = en.Append(0xde, 0x0, 0x10, 0xa9, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78)
+	err = en.Append(0xde, 0x0, 0x14, 0xa9, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78)
 	if err != nil {
 		return
 	}
@@ -3338,6 +3658,23 @@ func (z *mixin) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "Name")
 		return
 	}
+	// write "TypeParams"
+	err = en.Append(0xaa, 0x54, 0x79, 0x70, 0x65, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.TypeParams)))
+	if err != nil {
+		err = msgp.WrapError(err, "TypeParams")
+		return
+	}
+	for za0002 := range z.TypeParams {
+		err = z.TypeParams[za0002].EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "TypeParams", za0002)
+			return
+		}
+	}
 	// write "LParenPos"
 	err = en.Append(0xa9, 0x4c, 0x50, 0x61, 0x72, 0x65, 0x6e, 0x50, 0x6f, 0x73)
 	if err != nil {
@@ -3375,10 +3712,10 @@ func (z *mixin) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "Params")
 		return
 	}
-	for za0002 := range z.Params {
-		err = z.Params[za0002].EncodeMsg(en)
+	for za0003 := range z.Params {
+		err = z.Params[za0003].EncodeMsg(en)
 		if err != nil {
-			err = msgp.WrapError(err, "Params", za0002)
+			err = msgp.WrapError(err, "Params", za0003)
 			return
 		}
 	}
@@ -3459,10 +3796,61 @@ func (z *mixin) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "RequiredBy")
 		return
 	}
-	for za0003 := range z.RequiredBy {
-		err = en.WriteString(z.RequiredBy[za0003])
+	for za0004 := range z.RequiredBy {
+		err = en.WriteString(z.RequiredBy[za0004])
+		if err != nil {
+			err = msgp.WrapError(err, "RequiredBy", za0004)
+			return
+		}
+	}
+	// write "Instantiations"
+	err = en.Append(0xae, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x74, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Instantiations)))
+	if err != nil {
+		err = msgp.WrapError(err, "Instantiations")
+		return
+	}
+	for za0005 := range z.Instantiations {
+		err = z.Instantiations[za0005].EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Instantiations", za0005)
+			return
+		}
+	}
+	// write "ScriptHashes"
+	err = en.Append(0xac, 0x53, 0x63, 0x72, 0x69, 0x70, 0x74, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.ScriptHashes)))
+	if err != nil {
+		err = msgp.WrapError(err, "ScriptHashes")
+		return
+	}
+	for za0006 := range z.ScriptHashes {
+		err = en.WriteString(z.ScriptHashes[za0006])
+		if err != nil {
+			err = msgp.WrapError(err, "ScriptHashes", za0006)
+			return
+		}
+	}
+	// write "StyleHashes"
+	err = en.Append(0xab, 0x53, 0x74, 0x79, 0x6c, 0x65, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.StyleHashes)))
+	if err != nil {
+		err = msgp.WrapError(err, "StyleHashes")
+		return
+	}
+	for za0007 := range z.StyleHashes {
+		err = en.WriteString(z.StyleHashes[za0007])
 		if err != nil {
-			err = msgp.WrapError(err, "RequiredBy", za0003)
+			err = msgp.WrapError(err, "StyleHashes", za0007)
 			return
 		}
 	}
@@ -3516,10 +3904,10 @@ func (z *mixin) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "Blocks")
 		return
 	}
-	for za0004 := range z.Blocks {
-		err = z.Blocks[za0004].EncodeMsg(en)
+	for za0008 := range z.Blocks {
+		err = z.Blocks[za0008].EncodeMsg(en)
 		if err != nil {
-			err = msgp.WrapError(err, "Blocks", za0004)
+			err = msgp.WrapError(err, "Blocks", za0008)
 			return
 		}
 	}
@@ -3539,9 +3927,9 @@ func (z *mixin) EncodeMsg(en *msgp.Writer) (err error) {
 // MarshalMsg implements msgp.Marshaler
 func (z *mixin) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 16
+	// map header, size 20
 	// string "FileIndex"
-	o = append(o, 0xde, 0x0, 0x10, 0xa9, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78)
+	o = append(o, 0xde, 0x0, 0x14, 0xa9, 0x46, 0x69, 0x6c, 0x65, 0x49, 0x6e, 0x64, 0x65, 0x78)
 	o = msgp.AppendInt(o, z.FileIndex)
 	// string "MachineComments"
 	o = append(o, 0xaf, 0x4d, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73)
@@ -3556,6 +3944,16 @@ func (z *mixin) MarshalMsg(b []byte) (o []byte, err error) {
 		err = msgp.WrapError(err, "Name")
 		return
 	}
+	// string "TypeParams"
+	o = append(o, 0xaa, 0x54, 0x79, 0x70, 0x65, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.TypeParams)))
+	for za0002 := range z.TypeParams {
+		o, err = z.TypeParams[za0002].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "TypeParams", za0002)
+			return
+		}
+	}
 	// string "LParenPos"
 	o = append(o, 0xa9, 0x4c, 0x50, 0x61, 0x72, 0x65, 0x6e, 0x50, 0x6f, 0x73)
 	if z.LParenPos == nil {
@@ -3569,10 +3967,10 @@ func (z *mixin) MarshalMsg(b []byte) (o []byte, err error) {
 	// string "Params"
 	o = append(o, 0xa6, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73)
 	o = msgp.AppendArrayHeader(o, uint32(len(z.Params)))
-	for za0002 := range z.Params {
-		o, err = z.Params[za0002].MarshalMsg(o)
+	for za0003 := range z.Params {
+		o, err = z.Params[za0003].MarshalMsg(o)
 		if err != nil {
-			err = msgp.WrapError(err, "Params", za0002)
+			err = msgp.WrapError(err, "Params", za0003)
 			return
 		}
 	}
@@ -3601,8 +3999,30 @@ func (z *mixin) MarshalMsg(b []byte) (o []byte, err error) {
 	// string "RequiredBy"
 	o = append(o, 0xaa, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x42, 0x79)
 	o = msgp.AppendArrayHeader(o, uint32(len(z.RequiredBy)))
-	for za0003 := range z.RequiredBy {
-		o = msgp.AppendString(o, z.RequiredBy[za0003])
+	for za0004 := range z.RequiredBy {
+		o = msgp.AppendString(o, z.RequiredBy[za0004])
+	}
+	// string "Instantiations"
+	o = append(o, 0xae, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x74, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Instantiations)))
+	for za0005 := range z.Instantiations {
+		o, err = z.Instantiations[za0005].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Instantiations", za0005)
+			return
+		}
+	}
+	// string "ScriptHashes"
+	o = append(o, 0xac, 0x53, 0x63, 0x72, 0x69, 0x70, 0x74, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.ScriptHashes)))
+	for za0006 := range z.ScriptHashes {
+		o = msgp.AppendString(o, z.ScriptHashes[za0006])
+	}
+	// string "StyleHashes"
+	o = append(o, 0xab, 0x53, 0x74, 0x79, 0x6c, 0x65, 0x48, 0x61, 0x73, 0x68, 0x65, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.StyleHashes)))
+	for za0007 := range z.StyleHashes {
+		o = msgp.AppendString(o, z.StyleHashes[za0007])
 	}
 	// string "WritesBody"
 	o = append(o, 0xaa, 0x57, 0x72, 0x69, 0x74, 0x65, 0x73, 0x42, 0x6f, 0x64, 0x79)
@@ -3619,10 +4039,10 @@ func (z *mixin) MarshalMsg(b []byte) (o []byte, err error) {
 	// string "Blocks"
 	o = append(o, 0xa6, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73)
 	o = msgp.AppendArrayHeader(o, uint32(len(z.Blocks)))
-	for za0004 := range z.Blocks {
-		o, err = z.Blocks[za0004].MarshalMsg(o)
+	for za0008 := range z.Blocks {
+		o, err = z.Blocks[za0008].MarshalMsg(o)
 		if err != nil {
-			err = msgp.WrapError(err, "Blocks", za0004)
+			err = msgp.WrapError(err, "Blocks", za0008)
 			return
 		}
 	}
@@ -3681,6 +4101,25 @@ func (z *mixin) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				err = msgp.WrapError(err, "Name")
 				return
 			}
+		case "TypeParams":
+			var zb0003 uint32
+			zb0003, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "TypeParams")
+				return
+			}
+			if cap(z.TypeParams) >= int(zb0003) {
+				z.TypeParams = (z.TypeParams)[:zb0003]
+			} else {
+				z.TypeParams = make([]mixinTypeParam, zb0003)
+			}
+			for za0002 := range z.TypeParams {
+				bts, err = z.TypeParams[za0002].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "TypeParams", za0002)
+					return
+				}
+			}
 		case "LParenPos":
 			if msgp.IsNil(bts) {
 				bts, err = msgp.ReadNilBytes(bts)
@@ -3692,14 +4131,14 @@ func (z *mixin) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				if z.LParenPos == nil {
 					z.LParenPos = new(position)
 				}
-				var zb0003 uint32
-				zb0003, bts, err = msgp.ReadArrayHeaderBytes(bts)
+				var zb0004 uint32
+				zb0004, bts, err = msgp.ReadArrayHeaderBytes(bts)
 				if err != nil {
 					err = msgp.WrapError(err, "LParenPos")
 					return
 				}
-				if zb0003 != 2 {
-					err = msgp.ArrayError{Wanted: 2, Got: zb0003}
+				if zb0004 != 2 {
+					err = msgp.ArrayError{Wanted: 2, Got: zb0004}
 					return
 				}
 				z.LParenPos.Line, bts, err = msgp.ReadIntBytes(bts)
@@ -3714,21 +4153,21 @@ func (z *mixin) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				}
 			}
 		case "Params":
-			var zb0004 uint32
-			zb0004, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			var zb0005 uint32
+			zb0005, bts, err = msgp.ReadArrayHeaderBytes(bts)
 			if err != nil {
 				err = msgp.WrapError(err, "Params")
 				return
 			}
-			if cap(z.Params) >= int(zb0004) {
-				z.Params = (z.Params)[:zb0004]
+			if cap(z.Params) >= int(zb0005) {
+				z.Params = (z.Params)[:zb0005]
 			} else {
-				z.Params = make([]mixinParam, zb0004)
+				z.Params = make([]mixinParam, zb0005)
 			}
-			for za0002 := range z.Params {
-				bts, err = z.Params[za0002].UnmarshalMsg(bts)
+			for za0003 := range z.Params {
+				bts, err = z.Params[za0003].UnmarshalMsg(bts)
 				if err != nil {
-					err = msgp.WrapError(err, "Params", za0002)
+					err = msgp.WrapError(err, "Params", za0003)
 					return
 				}
 			}
@@ -3743,14 +4182,14 @@ func (z *mixin) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				if z.RParenPos == nil {
 					z.RParenPos = new(position)
 				}
-				var zb0005 uint32
-				zb0005, bts, err = msgp.ReadArrayHeaderBytes(bts)
+				var zb0006 uint32
+				zb0006, bts, err = msgp.ReadArrayHeaderBytes(bts)
 				if err != nil {
 					err = msgp.WrapError(err, "RParenPos")
 					return
 				}
-				if zb0005 != 2 {
-					err = msgp.ArrayError{Wanted: 2, Got: zb0005}
+				if zb0006 != 2 {
+					err = msgp.ArrayError{Wanted: 2, Got: zb0006}
 					return
 				}
 				z.RParenPos.Line, bts, err = msgp.ReadIntBytes(bts)
@@ -3765,14 +4204,14 @@ func (z *mixin) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				}
 			}
 		case "Position":
-			var zb0006 uint32
-			zb0006, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			var zb0007 uint32
+			zb0007, bts, err = msgp.ReadArrayHeaderBytes(bts)
 			if err != nil {
 				err = msgp.WrapError(err, "Position")
 				return
 			}
-			if zb0006 != 2 {
-				err = msgp.ArrayError{Wanted: 2, Got: zb0006}
+			if zb0007 != 2 {
+				err = msgp.ArrayError{Wanted: 2, Got: zb0007}
 				return
 			}
 			z.Position.Line, bts, err = msgp.ReadIntBytes(bts)
@@ -3798,21 +4237,78 @@ func (z *mixin) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				return
 			}
 		case "RequiredBy":
-			var zb0007 uint32
-			zb0007, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			var zb0008 uint32
+			zb0008, bts, err = msgp.ReadArrayHeaderBytes(bts)
 			if err != nil {
 				err = msgp.WrapError(err, "RequiredBy")
 				return
 			}
-			if cap(z.RequiredBy) >= int(zb0007) {
-				z.RequiredBy = (z.RequiredBy)[:zb0007]
+			if cap(z.RequiredBy) >= int(zb0008) {
+				z.RequiredBy = (z.RequiredBy)[:zb0008]
+			} else {
+				z.RequiredBy = make([]string, zb0008)
+			}
+			for za0004 := range z.RequiredBy {
+				z.RequiredBy[za0004], bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "RequiredBy", za0004)
+					return
+				}
+			}
+		case "Instantiations":
+			var zb0009 uint32
+			zb0009, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Instantiations")
+				return
+			}
+			if cap(z.Instantiations) >= int(zb0009) {
+				z.Instantiations = (z.Instantiations)[:zb0009]
+			} else {
+				z.Instantiations = make([]mixinInstantiation, zb0009)
+			}
+			for za0005 := range z.Instantiations {
+				bts, err = z.Instantiations[za0005].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Instantiations", za0005)
+					return
+				}
+			}
+		case "ScriptHashes":
+			var zb0010 uint32
+			zb0010, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ScriptHashes")
+				return
+			}
+			if cap(z.ScriptHashes) >= int(zb0010) {
+				z.ScriptHashes = (z.ScriptHashes)[:zb0010]
+			} else {
+				z.ScriptHashes = make([]string, zb0010)
+			}
+			for za0006 := range z.ScriptHashes {
+				z.ScriptHashes[za0006], bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "ScriptHashes", za0006)
+					return
+				}
+			}
+		case "StyleHashes":
+			var zb0011 uint32
+			zb0011, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "StyleHashes")
+				return
+			}
+			if cap(z.StyleHashes) >= int(zb0011) {
+				z.StyleHashes = (z.StyleHashes)[:zb0011]
 			} else {
-				z.RequiredBy = make([]string, zb0007)
+				z.StyleHashes = make([]string, zb0011)
 			}
-			for za0003 := range z.RequiredBy {
-				z.RequiredBy[za0003], bts, err = msgp.ReadStringBytes(bts)
+			for za0007 := range z.StyleHashes {
+				z.StyleHashes[za0007], bts, err = msgp.ReadStringBytes(bts)
 				if err != nil {
-					err = msgp.WrapError(err, "RequiredBy", za0003)
+					err = msgp.WrapError(err, "StyleHashes", za0007)
 					return
 				}
 			}
@@ -3841,21 +4337,21 @@ func (z *mixin) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				return
 			}
 		case "Blocks":
-			var zb0008 uint32
-			zb0008, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			var zb0012 uint32
+			zb0012, bts, err = msgp.ReadArrayHeaderBytes(bts)
 			if err != nil {
 				err = msgp.WrapError(err, "Blocks")
 				return
 			}
-			if cap(z.Blocks) >= int(zb0008) {
-				z.Blocks = (z.Blocks)[:zb0008]
+			if cap(z.Blocks) >= int(zb0012) {
+				z.Blocks = (z.Blocks)[:zb0012]
 			} else {
-				z.Blocks = make([]mixinBlock, zb0008)
+				z.Blocks = make([]mixinBlock, zb0012)
 			}
-			for za0004 := range z.Blocks {
-				bts, err = z.Blocks[za0004].UnmarshalMsg(bts)
+			for za0008 := range z.Blocks {
+				bts, err = z.Blocks[za0008].UnmarshalMsg(bts)
 				if err != nil {
-					err = msgp.WrapError(err, "Blocks", za0004)
+					err = msgp.WrapError(err, "Blocks", za0008)
 					return
 				}
 			}
@@ -3883,15 +4379,19 @@ func (z *mixin) Msgsize() (s int) {
 	for za0001 := range z.MachineComments {
 		s += msgp.StringPrefixSize + len(z.MachineComments[za0001])
 	}
-	s += 5 + z.Name.Msgsize() + 10
+	s += 5 + z.Name.Msgsize() + 11 + msgp.ArrayHeaderSize
+	for za0002 := range z.TypeParams {
+		s += z.TypeParams[za0002].Msgsize()
+	}
+	s += 10
 	if z.LParenPos == nil {
 		s += msgp.NilSize
 	} else {
 		s += 1 + msgp.IntSize + msgp.IntSize
 	}
 	s += 7 + msgp.ArrayHeaderSize
-	for za0002 := range z.Params {
-		s += z.Params[za0002].Msgsize()
+	for za0003 := range z.Params {
+		s += z.Params[za0003].Msgsize()
 	}
 	s += 10
 	if z.RParenPos == nil {
@@ -3900,12 +4400,24 @@ func (z *mixin) Msgsize() (s int) {
 		s += 1 + msgp.IntSize + msgp.IntSize
 	}
 	s += 9 + 1 + msgp.IntSize + msgp.IntSize + 4 + msgp.StringPrefixSize + len(z.Var) + 12 + msgp.BytesPrefixSize + len(z.Precompiled) + 11 + msgp.ArrayHeaderSize
-	for za0003 := range z.RequiredBy {
-		s += msgp.StringPrefixSize + len(z.RequiredBy[za0003])
+	for za0004 := range z.RequiredBy {
+		s += msgp.StringPrefixSize + len(z.RequiredBy[za0004])
+	}
+	s += 15 + msgp.ArrayHeaderSize
+	for za0005 := range z.Instantiations {
+		s += z.Instantiations[za0005].Msgsize()
+	}
+	s += 13 + msgp.ArrayHeaderSize
+	for za0006 := range z.ScriptHashes {
+		s += msgp.StringPrefixSize + len(z.ScriptHashes[za0006])
+	}
+	s += 12 + msgp.ArrayHeaderSize
+	for za0007 := range z.StyleHashes {
+		s += msgp.StringPrefixSize + len(z.StyleHashes[za0007])
 	}
 	s += 11 + msgp.BoolSize + 15 + msgp.BoolSize + 25 + msgp.BoolSize + 23 + msgp.BoolSize + 7 + msgp.ArrayHeaderSize
-	for za0004 := range z.Blocks {
-		s += z.Blocks[za0004].Msgsize()
+	for za0008 := range z.Blocks {
+		s += z.Blocks[za0008].Msgsize()
 	}
 	s += 19 + msgp.BoolSize
 	return
@@ -3935,6 +4447,25 @@ func (z *mixinBlock) DecodeMsg(dc *msgp.Reader) (err error) {
 				err = msgp.WrapError(err, "Name")
 				return
 			}
+		case "Params":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Params")
+				return
+			}
+			if cap(z.Params) >= int(zb0002) {
+				z.Params = (z.Params)[:zb0002]
+			} else {
+				z.Params = make([]blockParam, zb0002)
+			}
+			for za0001 := range z.Params {
+				err = z.Params[za0001].DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Params", za0001)
+					return
+				}
+			}
 		case "TopLevel":
 			z.TopLevel, err = dc.ReadBool()
 			if err != nil {
@@ -3984,9 +4515,9 @@ func (z *mixinBlock) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *mixinBlock) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 7
+	// map header, size 8
 	// write "Name"
-	err = en.Append(0x87, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
+	err = en.Append(0x88, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
 	if err != nil {
 		return
 	}
@@ -3995,6 +4526,23 @@ func (z *mixinBlock) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "Name")
 		return
 	}
+	// write "Params"
+	err = en.Append(0xa6, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Params)))
+	if err != nil {
+		err = msgp.WrapError(err, "Params")
+		return
+	}
+	for za0001 := range z.Params {
+		err = z.Params[za0001].EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Params", za0001)
+			return
+		}
+	}
 	// write "TopLevel"
 	err = en.Append(0xa8, 0x54, 0x6f, 0x70, 0x4c, 0x65, 0x76, 0x65, 0x6c)
 	if err != nil {
@@ -4061,10 +4609,20 @@ func (z *mixinBlock) EncodeMsg(en *msgp.Writer) (err error) {
 // MarshalMsg implements msgp.Marshaler
 func (z *mixinBlock) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 7
+	// map header, size 8
 	// string "Name"
-	o = append(o, 0x87, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
+	o = append(o, 0x88, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
 	o = msgp.AppendString(o, z.Name)
+	// string "Params"
+	o = append(o, 0xa6, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Params)))
+	for za0001 := range z.Params {
+		o, err = z.Params[za0001].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Params", za0001)
+			return
+		}
+	}
 	// string "TopLevel"
 	o = append(o, 0xa8, 0x54, 0x6f, 0x70, 0x4c, 0x65, 0x76, 0x65, 0x6c)
 	o = msgp.AppendBool(o, z.TopLevel)
@@ -4110,6 +4668,25 @@ func (z *mixinBlock) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				err = msgp.WrapError(err, "Name")
 				return
 			}
+		case "Params":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Params")
+				return
+			}
+			if cap(z.Params) >= int(zb0002) {
+				z.Params = (z.Params)[:zb0002]
+			} else {
+				z.Params = make([]blockParam, zb0002)
+			}
+			for za0001 := range z.Params {
+				bts, err = z.Params[za0001].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Params", za0001)
+					return
+				}
+			}
 		case "TopLevel":
 			z.TopLevel, bts, err = msgp.ReadBoolBytes(bts)
 			if err != nil {
@@ -4160,7 +4737,11 @@ func (z *mixinBlock) UnmarshalMsg(bts []byte) (o []byte, err error) {
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
 func (z *mixinBlock) Msgsize() (s int) {
-	s = 1 + 5 + msgp.StringPrefixSize + len(z.Name) + 9 + msgp.BoolSize + 14 + msgp.BoolSize + 18 + msgp.BoolSize + 22 + msgp.BoolSize + 32 + msgp.BoolSize + 30 + msgp.BoolSize
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Name) + 7 + msgp.ArrayHeaderSize
+	for za0001 := range z.Params {
+		s += z.Params[za0001].Msgsize()
+	}
+	s += 9 + msgp.BoolSize + 14 + msgp.BoolSize + 18 + msgp.BoolSize + 22 + msgp.BoolSize + 32 + msgp.BoolSize + 30 + msgp.BoolSize
 	return
 }
 
@@ -4357,7 +4938,7 @@ func (z *mixinDependency) Msgsize() (s int) {
 }
 
 // DecodeMsg implements msgp.Decodable
-func (z *mixinParam) DecodeMsg(dc *msgp.Reader) (err error) {
+func (z *mixinInstantiation) DecodeMsg(dc *msgp.Reader) (err error) {
 	var field []byte
 	_ = field
 	var zb0001 uint32
@@ -4374,48 +4955,271 @@ func (z *mixinParam) DecodeMsg(dc *msgp.Reader) (err error) {
 			return
 		}
 		switch msgp.UnsafeString(field) {
+		case "Module":
+			z.Module, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Module")
+				return
+			}
+		case "PathInModule":
+			z.PathInModule, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "PathInModule")
+				return
+			}
 		case "Name":
-			err = z.Name.DecodeMsg(dc)
+			z.Name, err = dc.ReadString()
 			if err != nil {
 				err = msgp.WrapError(err, "Name")
 				return
 			}
-		case "Type":
-			if dc.IsNil() {
-				err = dc.ReadNil()
-				if err != nil {
-					err = msgp.WrapError(err, "Type")
-					return
-				}
-				z.Type = nil
+		case "TypeArgs":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "TypeArgs")
+				return
+			}
+			if cap(z.TypeArgs) >= int(zb0002) {
+				z.TypeArgs = (z.TypeArgs)[:zb0002]
 			} else {
-				if z.Type == nil {
-					z.Type = new(goType)
-				}
-				err = z.Type.DecodeMsg(dc)
+				z.TypeArgs = make([]string, zb0002)
+			}
+			for za0001 := range z.TypeArgs {
+				z.TypeArgs[za0001], err = dc.ReadString()
 				if err != nil {
-					err = msgp.WrapError(err, "Type")
+					err = msgp.WrapError(err, "TypeArgs", za0001)
 					return
 				}
 			}
-		case "InferredType":
-			z.InferredType, err = dc.ReadString()
+		default:
+			err = dc.Skip()
 			if err != nil {
-				err = msgp.WrapError(err, "InferredType")
+				err = msgp.WrapError(err)
 				return
 			}
-		case "AssignPos":
-			if dc.IsNil() {
-				err = dc.ReadNil()
-				if err != nil {
-					err = msgp.WrapError(err, "AssignPos")
-					return
-				}
-				z.AssignPos = nil
-			} else {
-				if z.AssignPos == nil {
-					z.AssignPos = new(position)
-				}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *mixinInstantiation) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 4
+	// write "Module"
+	err = en.Append(0x84, 0xa6, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Module)
+	if err != nil {
+		err = msgp.WrapError(err, "Module")
+		return
+	}
+	// write "PathInModule"
+	err = en.Append(0xac, 0x50, 0x61, 0x74, 0x68, 0x49, 0x6e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.PathInModule)
+	if err != nil {
+		err = msgp.WrapError(err, "PathInModule")
+		return
+	}
+	// write "Name"
+	err = en.Append(0xa4, 0x4e, 0x61, 0x6d, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Name)
+	if err != nil {
+		err = msgp.WrapError(err, "Name")
+		return
+	}
+	// write "TypeArgs"
+	err = en.Append(0xa8, 0x54, 0x79, 0x70, 0x65, 0x41, 0x72, 0x67, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.TypeArgs)))
+	if err != nil {
+		err = msgp.WrapError(err, "TypeArgs")
+		return
+	}
+	for za0001 := range z.TypeArgs {
+		err = en.WriteString(z.TypeArgs[za0001])
+		if err != nil {
+			err = msgp.WrapError(err, "TypeArgs", za0001)
+			return
+		}
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *mixinInstantiation) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 4
+	// string "Module"
+	o = append(o, 0x84, 0xa6, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65)
+	o = msgp.AppendString(o, z.Module)
+	// string "PathInModule"
+	o = append(o, 0xac, 0x50, 0x61, 0x74, 0x68, 0x49, 0x6e, 0x4d, 0x6f, 0x64, 0x75, 0x6c, 0x65)
+	o = msgp.AppendString(o, z.PathInModule)
+	// string "Name"
+	o = append(o, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
+	o = msgp.AppendString(o, z.Name)
+	// string "TypeArgs"
+	o = append(o, 0xa8, 0x54, 0x79, 0x70, 0x65, 0x41, 0x72, 0x67, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.TypeArgs)))
+	for za0001 := range z.TypeArgs {
+		o = msgp.AppendString(o, z.TypeArgs[za0001])
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *mixinInstantiation) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Module":
+			z.Module, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Module")
+				return
+			}
+		case "PathInModule":
+			z.PathInModule, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "PathInModule")
+				return
+			}
+		case "Name":
+			z.Name, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Name")
+				return
+			}
+		case "TypeArgs":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "TypeArgs")
+				return
+			}
+			if cap(z.TypeArgs) >= int(zb0002) {
+				z.TypeArgs = (z.TypeArgs)[:zb0002]
+			} else {
+				z.TypeArgs = make([]string, zb0002)
+			}
+			for za0001 := range z.TypeArgs {
+				z.TypeArgs[za0001], bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "TypeArgs", za0001)
+					return
+				}
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *mixinInstantiation) Msgsize() (s int) {
+	s = 1 + 7 + msgp.StringPrefixSize + len(z.Module) + 13 + msgp.StringPrefixSize + len(z.PathInModule) + 5 + msgp.StringPrefixSize + len(z.Name) + 9 + msgp.ArrayHeaderSize
+	for za0001 := range z.TypeArgs {
+		s += msgp.StringPrefixSize + len(z.TypeArgs[za0001])
+	}
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *mixinParam) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Name":
+			err = z.Name.DecodeMsg(dc)
+			if err != nil {
+				err = msgp.WrapError(err, "Name")
+				return
+			}
+		case "Variadic":
+			z.Variadic, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "Variadic")
+				return
+			}
+		case "Type":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					err = msgp.WrapError(err, "Type")
+					return
+				}
+				z.Type = nil
+			} else {
+				if z.Type == nil {
+					z.Type = new(goType)
+				}
+				err = z.Type.DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Type")
+					return
+				}
+			}
+		case "InferredType":
+			z.InferredType, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "InferredType")
+				return
+			}
+		case "AssignPos":
+			if dc.IsNil() {
+				err = dc.ReadNil()
+				if err != nil {
+					err = msgp.WrapError(err, "AssignPos")
+					return
+				}
+				z.AssignPos = nil
+			} else {
+				if z.AssignPos == nil {
+					z.AssignPos = new(position)
+				}
 				var zb0002 uint32
 				zb0002, err = dc.ReadArrayHeader()
 				if err != nil {
@@ -4525,9 +5329,9 @@ func (z *mixinParam) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *mixinParam) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 6
+	// map header, size 7
 	// write "Name"
-	err = en.Append(0x86, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
+	err = en.Append(0x87, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
 	if err != nil {
 		return
 	}
@@ -4536,6 +5340,16 @@ func (z *mixinParam) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "Name")
 		return
 	}
+	// write "Variadic"
+	err = en.Append(0xa8, 0x56, 0x61, 0x72, 0x69, 0x61, 0x64, 0x69, 0x63)
+	if err != nil {
+		return
+	}
+	err = en.WriteBool(z.Variadic)
+	if err != nil {
+		err = msgp.WrapError(err, "Variadic")
+		return
+	}
 	// write "Type"
 	err = en.Append(0xa4, 0x54, 0x79, 0x70, 0x65)
 	if err != nil {
@@ -4646,14 +5460,17 @@ func (z *mixinParam) EncodeMsg(en *msgp.Writer) (err error) {
 // MarshalMsg implements msgp.Marshaler
 func (z *mixinParam) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 6
+	// map header, size 7
 	// string "Name"
-	o = append(o, 0x86, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
+	o = append(o, 0x87, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
 	o, err = z.Name.MarshalMsg(o)
 	if err != nil {
 		err = msgp.WrapError(err, "Name")
 		return
 	}
+	// string "Variadic"
+	o = append(o, 0xa8, 0x56, 0x61, 0x72, 0x69, 0x61, 0x64, 0x69, 0x63)
+	o = msgp.AppendBool(o, z.Variadic)
 	// string "Type"
 	o = append(o, 0xa4, 0x54, 0x79, 0x70, 0x65)
 	if z.Type == nil {
@@ -4728,6 +5545,12 @@ func (z *mixinParam) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				err = msgp.WrapError(err, "Name")
 				return
 			}
+		case "Variadic":
+			z.Variadic, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Variadic")
+				return
+			}
 		case "Type":
 			if msgp.IsNil(bts) {
 				bts, err = msgp.ReadNilBytes(bts)
@@ -4871,7 +5694,7 @@ func (z *mixinParam) UnmarshalMsg(bts []byte) (o []byte, err error) {
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
 func (z *mixinParam) Msgsize() (s int) {
-	s = 1 + 5 + z.Name.Msgsize() + 5
+	s = 1 + 5 + z.Name.Msgsize() + 9 + msgp.BoolSize + 5
 	if z.Type == nil {
 		s += msgp.NilSize
 	} else {
@@ -4896,6 +5719,210 @@ func (z *mixinParam) Msgsize() (s int) {
 	return
 }
 
+// DecodeMsg implements msgp.Decodable
+func (z *mixinTypeParam) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Name":
+			err = z.Name.DecodeMsg(dc)
+			if err != nil {
+				err = msgp.WrapError(err, "Name")
+				return
+			}
+		case "Constraint":
+			err = z.Constraint.DecodeMsg(dc)
+			if err != nil {
+				err = msgp.WrapError(err, "Constraint")
+				return
+			}
+		case "Position":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Position")
+				return
+			}
+			if zb0002 != 2 {
+				err = msgp.ArrayError{Wanted: 2, Got: zb0002}
+				return
+			}
+			z.Position.Line, err = dc.ReadInt()
+			if err != nil {
+				err = msgp.WrapError(err, "Position", "Line")
+				return
+			}
+			z.Position.Col, err = dc.ReadInt()
+			if err != nil {
+				err = msgp.WrapError(err, "Position", "Col")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *mixinTypeParam) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 3
+	// write "Name"
+	err = en.Append(0x83, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
+	if err != nil {
+		return
+	}
+	err = z.Name.EncodeMsg(en)
+	if err != nil {
+		err = msgp.WrapError(err, "Name")
+		return
+	}
+	// write "Constraint"
+	err = en.Append(0xaa, 0x43, 0x6f, 0x6e, 0x73, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x74)
+	if err != nil {
+		return
+	}
+	err = z.Constraint.EncodeMsg(en)
+	if err != nil {
+		err = msgp.WrapError(err, "Constraint")
+		return
+	}
+	// write "Position"
+	err = en.Append(0xa8, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e)
+	if err != nil {
+		return
+	}
+	// array header, size 2
+	err = en.Append(0x92)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt(z.Position.Line)
+	if err != nil {
+		err = msgp.WrapError(err, "Position", "Line")
+		return
+	}
+	err = en.WriteInt(z.Position.Col)
+	if err != nil {
+		err = msgp.WrapError(err, "Position", "Col")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *mixinTypeParam) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 3
+	// string "Name"
+	o = append(o, 0x83, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
+	o, err = z.Name.MarshalMsg(o)
+	if err != nil {
+		err = msgp.WrapError(err, "Name")
+		return
+	}
+	// string "Constraint"
+	o = append(o, 0xaa, 0x43, 0x6f, 0x6e, 0x73, 0x74, 0x72, 0x61, 0x69, 0x6e, 0x74)
+	o, err = z.Constraint.MarshalMsg(o)
+	if err != nil {
+		err = msgp.WrapError(err, "Constraint")
+		return
+	}
+	// string "Position"
+	o = append(o, 0xa8, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e)
+	// array header, size 2
+	o = append(o, 0x92)
+	o = msgp.AppendInt(o, z.Position.Line)
+	o = msgp.AppendInt(o, z.Position.Col)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *mixinTypeParam) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Name":
+			bts, err = z.Name.UnmarshalMsg(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Name")
+				return
+			}
+		case "Constraint":
+			bts, err = z.Constraint.UnmarshalMsg(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Constraint")
+				return
+			}
+		case "Position":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Position")
+				return
+			}
+			if zb0002 != 2 {
+				err = msgp.ArrayError{Wanted: 2, Got: zb0002}
+				return
+			}
+			z.Position.Line, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Position", "Line")
+				return
+			}
+			z.Position.Col, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Position", "Col")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *mixinTypeParam) Msgsize() (s int) {
+	s = 1 + 5 + z.Name.Msgsize() + 11 + z.Constraint.Msgsize() + 9 + 1 + msgp.IntSize + msgp.IntSize
+	return
+}
+
 // DecodeMsg implements msgp.Decodable
 func (z *position) DecodeMsg(dc *msgp.Reader) (err error) {
 	var zb0001 uint32
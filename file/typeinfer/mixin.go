@@ -31,3 +31,66 @@ func Scope(s file.Scope) {
 		return true, nil
 	})
 }
+
+// MixinTypeArgs attempts to infer the type args of mc, a linked call to a
+// generic mixin, that doesn't specify its type args explicitly.
+//
+// To do so, it matches the types of mc's args against the types of the
+// mixin's params.
+// The type of an arg is either inferred using [Infer], or, if the arg is a
+// single identifier, looked up in the params of the mixins surrounding mc
+// and lastly in the params of f's func.
+//
+// When it succeeds, it stores the inferred type args as
+// [file.MixinCall.InferredTypeArgs].
+func MixinTypeArgs(f *file.File, parents []fileutil.WalkContext, mc *file.MixinCall) {
+	m := mc.Mixin.Mixin
+	if len(m.TypeParams) == 0 || len(mc.TypeArgs) > 0 {
+		return
+	}
+
+	typeParams := make(map[string]string, len(m.TypeParams))
+	for _, tp := range m.TypeParams {
+		typeParams[tp.Name.Ident] = ""
+	}
+
+	for _, param := range m.Params {
+		if param.Type == nil {
+			continue
+		}
+
+		for _, arg := range mc.Args {
+			if arg.Name.Ident != param.Name.Ident {
+				continue
+			}
+
+			paramType := param.Type.Type
+			if arg.Spread {
+				paramType = "[]" + paramType
+			}
+
+			argType := Infer(arg.Value)
+			if argType == "" {
+				argType = identType(f, parents, arg.Value)
+			}
+			if argType != "" {
+				unifyTypes(paramType, argType, typeParams)
+			}
+
+			// variadic params may be set multiple times
+			if !param.Variadic {
+				break
+			}
+		}
+	}
+
+	typeArgs := make([]string, len(m.TypeParams))
+	for i, tp := range m.TypeParams {
+		typeArgs[i] = typeParams[tp.Name.Ident]
+		if typeArgs[i] == "" {
+			return
+		}
+	}
+
+	mc.InferredTypeArgs = typeArgs
+}
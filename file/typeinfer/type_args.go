@@ -0,0 +1,171 @@
+package typeinfer
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+
+	"github.com/mavolin/corgi/file"
+	"github.com/mavolin/corgi/file/fileutil"
+)
+
+// identType returns the type of expr, if it is a single identifier naming a
+// param of one of the mixins in parents, or of f's func.
+func identType(f *file.File, parents []fileutil.WalkContext, expr file.Expression) string {
+	if len(expr.Expressions) != 1 {
+		return ""
+	}
+
+	gexpr, ok := expr.Expressions[0].(file.GoExpression)
+	if !ok || !token.IsIdentifier(gexpr.Expression) {
+		return ""
+	}
+
+	ident := gexpr.Expression
+
+	for i := len(parents) - 1; i >= 0; i-- {
+		m, ok := (*parents[i].Item).(file.Mixin)
+		if !ok {
+			continue
+		}
+
+		for _, param := range m.Params {
+			if param.Name.Ident != ident {
+				continue
+			}
+
+			if param.Variadic {
+				return "[]" + param.Type.Type
+			} else if param.Type != nil {
+				return param.Type.Type
+			}
+			return param.InferredType
+		}
+	}
+
+	if f.Func == nil {
+		return ""
+	}
+
+	for _, param := range f.Func.Params {
+		for _, name := range param.Names {
+			if name.Ident != ident {
+				continue
+			}
+
+			if param.Variadic {
+				return "[]" + param.Type.Type
+			}
+			return param.Type.Type
+		}
+	}
+
+	return ""
+}
+
+// unifyTypes matches the type paramType against argType, and stores the
+// types that the type params in paramType correspond to in typeParams.
+//
+// typeParams maps the names of the type params to their type, or to "", if
+// their type is not known yet.
+// Type params that already have a type are not overwritten.
+func unifyTypes(paramType, argType string, typeParams map[string]string) {
+	paramExpr, err := parser.ParseExpr(paramType)
+	if err != nil {
+		return
+	}
+
+	argExpr, err := parser.ParseExpr(argType)
+	if err != nil {
+		return
+	}
+
+	unify(paramExpr, argExpr, typeParams)
+}
+
+func unify(param, arg ast.Expr, typeParams map[string]string) {
+	switch param := param.(type) {
+	case *ast.Ident:
+		if t, ok := typeParams[param.Name]; ok && t == "" {
+			typeParams[param.Name] = types.ExprString(arg)
+		}
+	case *ast.ParenExpr:
+		unify(param.X, arg, typeParams)
+	case *ast.StarExpr:
+		if arg, ok := arg.(*ast.StarExpr); ok {
+			unify(param.X, arg.X, typeParams)
+		}
+	case *ast.ArrayType:
+		arg, ok := arg.(*ast.ArrayType)
+		if !ok || (param.Len == nil) != (arg.Len == nil) {
+			return
+		}
+
+		unify(param.Elt, arg.Elt, typeParams)
+	case *ast.MapType:
+		if arg, ok := arg.(*ast.MapType); ok {
+			unify(param.Key, arg.Key, typeParams)
+			unify(param.Value, arg.Value, typeParams)
+		}
+	case *ast.Ellipsis:
+		if arg, ok := arg.(*ast.Ellipsis); ok {
+			unify(param.Elt, arg.Elt, typeParams)
+		}
+	case *ast.ChanType:
+		if arg, ok := arg.(*ast.ChanType); ok {
+			unify(param.Value, arg.Value, typeParams)
+		}
+	case *ast.FuncType:
+		if arg, ok := arg.(*ast.FuncType); ok {
+			unifyFields(param.Params, arg.Params, typeParams)
+			unifyFields(param.Results, arg.Results, typeParams)
+		}
+	case *ast.IndexExpr:
+		if arg, ok := arg.(*ast.IndexExpr); ok {
+			unify(param.Index, arg.Index, typeParams)
+		}
+	case *ast.IndexListExpr:
+		arg, ok := arg.(*ast.IndexListExpr)
+		if !ok || len(param.Indices) != len(arg.Indices) {
+			return
+		}
+
+		for i := range param.Indices {
+			unify(param.Indices[i], arg.Indices[i], typeParams)
+		}
+	}
+}
+
+func unifyFields(param, arg *ast.FieldList, typeParams map[string]string) {
+	paramTypes, argTypes := fieldTypes(param), fieldTypes(arg)
+	if len(paramTypes) != len(argTypes) {
+		return
+	}
+
+	for i := range paramTypes {
+		unify(paramTypes[i], argTypes[i], typeParams)
+	}
+}
+
+// fieldTypes returns the types of the fields in fl, repeating the type of
+// fields declared together.
+func fieldTypes(fl *ast.FieldList) []ast.Expr {
+	if fl == nil {
+		return nil
+	}
+
+	var ts []ast.Expr
+	for _, field := range fl.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+
+		for i := 0; i < n; i++ {
+			ts = append(ts, field.Type)
+		}
+	}
+
+	return ts
+}
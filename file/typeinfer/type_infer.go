@@ -1,6 +1,9 @@
 package typeinfer
 
 import (
+	"go/ast"
+	"go/parser"
+	"go/types"
 	"regexp"
 	"strings"
 
@@ -9,8 +12,8 @@ import (
 
 // Infer attempts to infer the type expr would yield.
 //
-// Infer can detect int, float, bool, rune, string, and composite literals, as
-// well as the above wrapped in ternary expressions
+// Infer can detect int, float, bool, rune, string, composite, and function
+// literals, as well as the above wrapped in ternary expressions
 //
 // If Infer returns an empty string, it could not identify the type.
 func Infer(expr file.Expression) string {
@@ -34,6 +37,8 @@ func inferLit(expr file.Expression) string {
 		return t
 	} else if t := inferCompositeLit(expr); t != "" {
 		return t
+	} else if t := inferFuncLit(expr); t != "" {
+		return t
 	}
 
 	return ""
@@ -70,10 +75,11 @@ func inferPrimitiveLit(expr file.Expression) string {
 	// need to run all regexps
 	switch e[0] {
 	case '+', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', '.':
-		if numLitRegexp.MatchString(e) {
-			return "int"
-		} else if floatLitRegexp.MatchString(e) {
+		// float lits start with an int lit, so check them first
+		if floatLitRegexp.MatchString(e) {
 			return "float64"
+		} else if numLitRegexp.MatchString(e) {
+			return "int"
 		}
 		return ""
 	case 't', 'f':
@@ -111,6 +117,25 @@ func inferCompositeLit(expr file.Expression) string {
 	return t
 }
 
+func inferFuncLit(expr file.Expression) string {
+	gexpr, ok := expr.Expressions[0].(file.GoExpression)
+	if !ok || len(expr.Expressions) > 1 || !strings.HasPrefix(gexpr.Expression, "func") {
+		return ""
+	}
+
+	goExpr, err := parser.ParseExpr(gexpr.Expression)
+	if err != nil {
+		return ""
+	}
+
+	lit, ok := goExpr.(*ast.FuncLit)
+	if !ok {
+		return ""
+	}
+
+	return types.ExprString(lit.Type)
+}
+
 var typeAssertionRegexp = regexp.MustCompile(`(?i)\. *\(([^)]+)\)$`)
 
 func inferTypeAssertion(expr file.Expression) string {
@@ -0,0 +1,73 @@
+package format
+
+import (
+	"reflect"
+
+	"github.com/mavolin/corgi/file"
+)
+
+// equal reports whether the two parsed files are equivalent, i.e. whether
+// they are equal, ignoring positions, and whitespace surrounding Go
+// expressions.
+//
+// Both files are modified in the process.
+func equal(a, b *file.File) bool {
+	a.Raw, a.Lines = "", nil
+	b.Raw, b.Lines = "", nil
+
+	normalize(reflect.ValueOf(a).Elem())
+	normalize(reflect.ValueOf(b).Elem())
+
+	return reflect.DeepEqual(a, b)
+}
+
+var (
+	positionType   = reflect.TypeOf(file.Position{})
+	expressionType = reflect.TypeOf(file.Expression{})
+)
+
+// normalize zeros all positions contained in v, and trims all expressions
+// contained in it, using trimExpression.
+//
+// v must be settable.
+func normalize(v reflect.Value) {
+	switch v.Type() {
+	case positionType:
+		v.Set(reflect.Zero(positionType))
+		return
+	case expressionType:
+		v.Set(reflect.ValueOf(trimExpression(v.Interface().(file.Expression))))
+	}
+
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.Pointer:
+		if !v.IsNil() {
+			normalize(v.Elem())
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+
+		// values stored in interfaces are not settable, so we need to copy
+		// them first
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		normalize(elem)
+		v.Set(elem)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			normalize(v.Field(i))
+		}
+	case reflect.Slice:
+		if v.Len() == 0 {
+			// treat empty and nil slices the same
+			v.Set(reflect.Zero(v.Type()))
+			return
+		}
+
+		for i := 0; i < v.Len(); i++ {
+			normalize(v.Index(i))
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package format
+
+import (
+	"strings"
+
+	"github.com/mavolin/corgi/file"
+)
+
+// ============================================================================
+// String
+// ======================================================================================
+
+func (p *printer) string(s file.String) {
+	p.write(string(s.Quote), s.Contents, string(s.Quote))
+}
+
+// ============================================================================
+// Expression
+// ======================================================================================
+
+// trimExpression returns a copy of e with leading and trailing whitespace
+// of its Go expressions removed.
+//
+// Such whitespace is included by the parser, e.g. in the expression
+// `foo ` in `(bar=foo , baz)`, but has no meaning.
+func trimExpression(e file.Expression) file.Expression {
+	if len(e.Expressions) == 0 {
+		return e
+	}
+
+	itms := make([]file.ExpressionItem, len(e.Expressions))
+	copy(itms, e.Expressions)
+
+	if goExpr, ok := itms[0].(file.GoExpression); ok {
+		goExpr.Expression = strings.TrimLeft(goExpr.Expression, " \t\r\n")
+		itms[0] = goExpr
+	}
+
+	if goExpr, ok := itms[len(itms)-1].(file.GoExpression); ok {
+		goExpr.Expression = strings.TrimRight(goExpr.Expression, " \t\r\n")
+		itms[len(itms)-1] = goExpr
+	}
+
+	if goExpr, ok := itms[len(itms)-1].(file.GoExpression); ok && goExpr.Expression == "" {
+		itms = itms[:len(itms)-1]
+	}
+
+	if len(itms) > 0 {
+		if goExpr, ok := itms[0].(file.GoExpression); ok && goExpr.Expression == "" {
+			itms = itms[1:]
+		}
+	}
+
+	return file.Expression{Expressions: itms}
+}
+
+func (p *printer) expression(e file.Expression) {
+	for _, itm := range trimExpression(e).Expressions {
+		switch itm := itm.(type) {
+		case file.GoExpression:
+			p.write(itm.Expression)
+		case file.RangeExpression:
+			p.rangeExpression(itm)
+		case file.StringExpression:
+			p.stringExpression(itm)
+		case file.TernaryExpression:
+			p.ternaryExpression(itm)
+		case file.ChainExpression:
+			p.chainExpression(itm)
+		}
+	}
+}
+
+func (p *printer) rangeExpression(re file.RangeExpression) {
+	if re.Var1 != nil {
+		p.write(re.Var1.Ident)
+		if re.Var2 != nil {
+			p.write(", ", re.Var2.Ident)
+		}
+
+		if re.Declares {
+			p.write(" := ")
+		} else {
+			p.write(" = ")
+		}
+	}
+
+	if re.Ordered {
+		p.write("ordered ")
+	}
+
+	p.write("range ")
+	p.expression(re.RangeExpression)
+}
+
+func (p *printer) stringExpression(se file.StringExpression) {
+	p.write(string(se.Quote))
+
+	for _, itm := range se.Contents {
+		switch itm := itm.(type) {
+		case file.StringExpressionText:
+			p.write(itm.Text)
+		case file.StringExpressionInterpolation:
+			p.write("#")
+			if itm.FormatDirective != "" {
+				p.write("%", itm.FormatDirective)
+			}
+
+			p.write("{")
+			p.expression(itm.Expression)
+			p.write("}")
+		}
+	}
+
+	p.write(string(se.Quote))
+}
+
+func (p *printer) ternaryExpression(te file.TernaryExpression) {
+	p.write("?(")
+	p.expression(te.Condition)
+	p.write(", ")
+	p.expression(te.IfTrue)
+	p.write(", ")
+	p.expression(te.IfFalse)
+	p.write(")")
+}
+
+func (p *printer) chainExpression(ce file.ChainExpression) {
+	p.write(strings.Repeat("*", ce.DerefCount), ce.Root.Expression)
+	if ce.CheckRoot {
+		p.write("?")
+	}
+
+	for _, itm := range ce.Chain {
+		switch itm := itm.(type) {
+		case file.IndexExpression:
+			p.write("[")
+			p.expression(itm.Index)
+			if itm.CheckIndex {
+				p.write("?")
+			}
+			p.write("]")
+			if itm.CheckValue {
+				p.write("?")
+			}
+		case file.DotIdentExpression:
+			p.write(".", itm.Ident.Ident)
+			if itm.Check {
+				p.write("?")
+			}
+		case file.ParenExpression:
+			p.write("(")
+			for i, arg := range itm.Args {
+				if i > 0 {
+					p.write(", ")
+				}
+				p.expression(arg)
+			}
+			p.write(")")
+			if itm.Check {
+				p.write("?")
+			}
+		case file.TypeAssertionExpression:
+			p.write(".(", strings.Repeat("*", itm.PointerCount))
+			if itm.Package != nil {
+				p.write(itm.Package.Ident, ".")
+			}
+			p.write(itm.Type.Ident, ")")
+			if itm.Check {
+				p.write("?")
+			}
+		}
+	}
+
+	if ce.Default != nil {
+		p.write(" ~ ")
+		p.expression(*ce.Default)
+	}
+}
+
+// ============================================================================
+// Text
+// ======================================================================================
+
+func (p *printer) textLine(ln file.TextLine) {
+	for _, itm := range ln {
+		switch itm := itm.(type) {
+		case file.Text:
+			p.write(itm.Text)
+		case file.SimpleInterpolation:
+			p.write("#")
+			if itm.NoEscape {
+				p.write("!")
+			}
+			p.interpolationValue(itm.Value)
+		case file.ElementInterpolation:
+			p.write("#", itm.Element.Name)
+			p.attributeCollections(itm.Element.Attributes, false)
+			if itm.Element.Void {
+				p.write("/")
+			} else if itm.Value != nil {
+				p.interpolationValue(itm.Value)
+			}
+		case file.MixinCallInterpolation:
+			p.write("#")
+			p.mixinCallHeader(itm.MixinCall)
+			if itm.Value != nil {
+				p.interpolationValue(itm.Value)
+			}
+		}
+	}
+}
+
+func (p *printer) interpolationValue(val file.InterpolationValue) {
+	switch val := val.(type) {
+	case file.TextInterpolationValue:
+		p.write("[", val.Text, "]")
+	case file.ExpressionInterpolationValue:
+		if val.FormatDirective != "" {
+			p.write("%", val.FormatDirective)
+		}
+
+		p.write("{")
+		p.expression(val.Expression)
+		p.write("}")
+	}
+}
@@ -0,0 +1,67 @@
+// Package format implements canonical formatting of corgi source files.
+package format
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/mavolin/corgi/file"
+	"github.com/mavolin/corgi/parse"
+)
+
+// ErrNotEquivalent is the error returned by [Source], if the formatted source
+// does not parse to the same file as the input.
+//
+// It indicates a bug in the formatter.
+var ErrNotEquivalent = errors.New("formatted file is not equivalent to the input " +
+	"(you shouldn't see this error, please open an issue)")
+
+// Source formats the passed corgi source and returns the result.
+//
+// If src contains syntax errors, Source returns the [corgierr.List] returned
+// by the parser, and does not format the file.
+//
+// Source guarantees that the formatted source parses to the same file as src,
+// ignoring positions, and whitespace surrounding Go expressions.
+// If it does not, Source returns [ErrNotEquivalent].
+func Source(src []byte) ([]byte, error) {
+	f, err := parse.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := File(&buf, f); err != nil {
+		return nil, err
+	}
+
+	formatted, err := parse.Parse(buf.Bytes())
+	if err != nil {
+		return nil, ErrNotEquivalent
+	}
+
+	if !equal(f, formatted) {
+		return nil, ErrNotEquivalent
+	}
+
+	return buf.Bytes(), nil
+}
+
+// File writes the canonical source of f to w.
+//
+// f must be a file returned by [parse.Parse] that was parsed without errors.
+// If f.Lines is set, it is used to preserve blank lines between items.
+// Multiple consecutive blank lines are collapsed into a single one.
+//
+// Attribute lists of elements and &s that would make their line wider than
+// 100 runes are split into one line per attribute.
+//
+// Linking information is ignored, so f may also be a linked file.
+func File(w io.Writer, f *file.File) error {
+	p := printer{lines: f.Lines}
+	p.file(f)
+
+	_, err := w.Write(p.buf.Bytes())
+	return err
+}
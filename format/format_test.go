@@ -0,0 +1,58 @@
+package format_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi"
+	"github.com/mavolin/corgi/format"
+	"github.com/mavolin/corgi/parse"
+)
+
+// TestSource_Fixtures formats the corgi files used by the integration tests,
+// and checks that the formatted files parse to the same files, and that
+// formatting them again doesn't change them.
+func TestSource_Fixtures(t *testing.T) {
+	t.Parallel()
+
+	var n int
+	err := filepath.WalkDir(filepath.Join("..", "test"), func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || (!strings.HasSuffix(p, corgi.Ext) && !strings.HasSuffix(p, corgi.LibExt)) {
+			return err
+		}
+
+		src, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		// fixtures of tests that expect parse errors can't be formatted
+		if _, err := parse.Parse(src); err != nil {
+			return nil //nolint:nilerr
+		}
+
+		n++
+		t.Run(filepath.ToSlash(p), func(t *testing.T) {
+			t.Parallel()
+
+			// Source itself makes sure, that the formatted file is
+			// equivalent to src
+			formatted, err := format.Source(src)
+			require.NoError(t, err)
+
+			reformatted, err := format.Source(formatted)
+			require.NoError(t, err)
+			assert.Equal(t, string(formatted), string(reformatted), "formatting is not idempotent")
+		})
+
+		return nil
+	})
+	require.NoError(t, err)
+	assert.NotZero(t, n, "no fixtures found")
+}
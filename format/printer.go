@@ -0,0 +1,877 @@
+package format
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mavolin/corgi/file"
+)
+
+// indentation is the string used to indent a single level.
+const indentation = "  "
+
+// maxLineWidth is the width in runes, that a line with an attribute list may
+// have, before the attribute list is split into one line per attribute.
+const maxLineWidth = 100
+
+type printer struct {
+	buf bytes.Buffer
+
+	// lines are the lines of the file that is being printed, used to detect
+	// blank lines.
+	lines  []string
+	indent int
+	// inline is the number of block expansions the printer is in.
+	//
+	// Items in block expansions must fit on a single line.
+	inline int
+}
+
+func (p *printer) write(ss ...string) {
+	for _, s := range ss {
+		p.buf.WriteString(s)
+	}
+}
+
+func (p *printer) newline() {
+	p.buf.WriteByte('\n')
+}
+
+func (p *printer) writeIndent() {
+	for i := 0; i < p.indent; i++ {
+		p.buf.WriteString(indentation)
+	}
+}
+
+// startLine starts a new line for an item located at pos.
+//
+// If the item is not the first in its scope, and it is preceded by a blank
+// line in the source, a single blank line is written first.
+func (p *printer) startLine(pos file.Position, first bool) {
+	if !first && p.blankBefore(pos) {
+		p.newline()
+	}
+
+	p.writeIndent()
+}
+
+// blankBefore reports whether the line at pos or the line before it is
+// blank.
+//
+// The line at pos itself is checked, because the position of some items,
+// such as else, point to the start of the newlines preceding them.
+func (p *printer) blankBefore(pos file.Position) bool {
+	return p.isBlank(pos.Line-1) || p.isBlank(pos.Line-2)
+}
+
+func (p *printer) isBlank(lineIndex int) bool {
+	if lineIndex < 0 || lineIndex >= len(p.lines) {
+		return false
+	}
+
+	return strings.TrimSpace(p.lines[lineIndex]) == ""
+}
+
+// ============================================================================
+// File
+// ======================================================================================
+
+type preambleKind uint8
+
+const (
+	preambleComment preambleKind = iota
+	preambleExtend
+	preambleImport
+	preambleUse
+	preambleCode
+	preambleFunc
+)
+
+type preambleItem struct {
+	kind  preambleKind
+	pos   file.Position
+	print func()
+}
+
+func (p *printer) file(f *file.File) {
+	preamble := p.preamble(f)
+
+	for i, itm := range preamble {
+		if i > 0 {
+			// separate different kinds of items, but keep comments
+			// attached to the item following them
+			prev := preamble[i-1]
+			if p.blankBefore(itm.pos) || (prev.kind != itm.kind && prev.kind != preambleComment) {
+				p.newline()
+			}
+		}
+
+		itm.print()
+	}
+
+	if len(preamble) > 0 && len(f.Scope) > 0 {
+		p.newline()
+	}
+
+	p.scope(f.Scope)
+}
+
+func (p *printer) preamble(f *file.File) []preambleItem {
+	var itms []preambleItem
+
+	for _, c := range f.TopLevelComments {
+		c := c
+		itms = append(itms, preambleItem{kind: preambleComment, pos: c.Position, print: func() {
+			p.corgiComment(c)
+		}})
+	}
+
+	if f.Extend != nil {
+		itms = append(itms, preambleItem{kind: preambleExtend, pos: f.Extend.Position, print: func() {
+			p.extend(*f.Extend)
+		}})
+	}
+
+	for _, imp := range f.Imports {
+		imp := imp
+		itms = append(itms, preambleItem{kind: preambleImport, pos: imp.Position, print: func() {
+			p._import(imp)
+		}})
+	}
+
+	for _, use := range f.Uses {
+		use := use
+		itms = append(itms, preambleItem{kind: preambleUse, pos: use.Position, print: func() {
+			p.use(use)
+		}})
+	}
+
+	for _, code := range f.GlobalCode {
+		code := code
+		itms = append(itms, preambleItem{kind: preambleCode, pos: code.Position, print: func() {
+			p.code(code)
+		}})
+	}
+
+	if f.Func != nil {
+		itms = append(itms, preambleItem{kind: preambleFunc, pos: f.Func.Position, print: func() {
+			p._func(*f.Func)
+		}})
+	}
+
+	sort.SliceStable(itms, func(i, j int) bool {
+		return itms[i].pos.Line < itms[j].pos.Line
+	})
+
+	return itms
+}
+
+// ================================= Extend =================================
+
+func (p *printer) extend(ext file.Extend) {
+	p.write("extend ")
+	p.string(ext.Path)
+	p.newline()
+}
+
+// ================================= Import =================================
+
+func (p *printer) _import(imp file.Import) {
+	// keep single imports in blocks, if they were written that way
+	if len(imp.Imports) == 1 && imp.Imports[0].Line == imp.Line {
+		p.write("import ")
+		p.importSpec(imp.Imports[0])
+		return
+	}
+
+	p.write("import")
+	p.newline()
+
+	p.indent++
+	for i, spec := range imp.Imports {
+		p.startLine(spec.Position, i == 0)
+		p.importSpec(spec)
+	}
+	p.indent--
+}
+
+func (p *printer) importSpec(spec file.ImportSpec) {
+	if spec.Alias != nil {
+		p.write(spec.Alias.Ident, " ")
+	}
+
+	p.string(spec.Path)
+	p.newline()
+}
+
+// ================================== Use ===================================
+
+func (p *printer) use(use file.Use) {
+	if len(use.Uses) == 1 && use.Uses[0].Line == use.Line {
+		p.write("use ")
+		p.useSpec(use.Uses[0])
+		return
+	}
+
+	p.write("use")
+	p.newline()
+
+	p.indent++
+	for i, spec := range use.Uses {
+		p.startLine(spec.Position, i == 0)
+		p.useSpec(spec)
+	}
+	p.indent--
+}
+
+func (p *printer) useSpec(spec file.UseSpec) {
+	if spec.Alias != nil {
+		p.write(spec.Alias.Ident, " ")
+	}
+
+	p.string(spec.Path)
+	p.newline()
+}
+
+// ================================== Func ==================================
+
+func (p *printer) _func(fun file.Func) {
+	p.write("func ", fun.Name.Ident, "(")
+
+	for i, param := range fun.Params {
+		if i > 0 {
+			p.write(", ")
+		}
+
+		for j, name := range param.Names {
+			if j > 0 {
+				p.write(", ")
+			}
+			p.write(name.Ident)
+		}
+
+		if len(param.Names) > 0 {
+			p.write(" ")
+		}
+
+		if param.Variadic {
+			p.write("...")
+		}
+		p.write(param.Type.Type)
+	}
+
+	p.write(")")
+	p.newline()
+}
+
+// ============================================================================
+// Scope
+// ======================================================================================
+
+func (p *printer) scope(s file.Scope) {
+	for i, itm := range s {
+		p.startLine(itm.Pos(), i == 0)
+		p.scopeItem(itm)
+	}
+}
+
+// body prints the body of an item that may have a block expansion, inline
+// text, or an indented body.
+func (p *printer) body(s file.Scope) {
+	if len(s) == 1 {
+		switch itm := s[0].(type) {
+		case file.BlockExpansion:
+			p.blockExpansion(itm)
+			return
+		case file.InlineText:
+			p.write(" ")
+			p.textLine(itm.Text)
+			p.newline()
+			return
+		}
+	}
+
+	p.newline()
+
+	inline := p.inline
+	p.inline = 0
+	p.indent++
+	p.scope(s)
+	p.indent--
+	p.inline = inline
+}
+
+func (p *printer) scopeItem(itm file.ScopeItem) {
+	switch itm := itm.(type) {
+	case file.Doctype:
+		p.write("doctype html")
+		p.newline()
+	case file.CorgiComment:
+		p.corgiComment(itm)
+	case file.HTMLComment:
+		p.htmlComment(itm)
+	case file.Element:
+		p.element(itm)
+	case file.DivShorthand:
+		p.divShorthand(itm)
+	case file.And:
+		p.and(itm)
+	case file.Code:
+		p.code(itm)
+	case file.Block:
+		p.block(itm)
+	case file.If:
+		p._if(itm)
+	case file.IfBlock:
+		p.ifBlock(itm)
+	case file.Switch:
+		p._switch(itm)
+	case file.For:
+		p._for(itm)
+	case file.Break:
+		p.loopControl("break", itm.Label)
+	case file.Continue:
+		p.loopControl("continue", itm.Label)
+	case file.RawFilter:
+		p.rawFilter(itm)
+	case file.CommandFilter:
+		p.commandFilter(itm)
+	case file.Include:
+		p.include(itm)
+	case file.Mixin:
+		p.mixin(itm)
+	case file.Return:
+		p._return(itm)
+	case file.MixinCall:
+		p.mixinCall(itm)
+	case file.ArrowBlock:
+		p.arrowBlock(itm)
+	case file.BadItem:
+		p.badItem(itm)
+	}
+}
+
+// ================================= Comments =================================
+
+func (p *printer) corgiComment(c file.CorgiComment) {
+	// a single line starting with a '-' would be parsed as an HTML comment
+	if len(c.Lines) == 1 && !strings.HasPrefix(c.Lines[0].Comment, "-") {
+		p.write("//", c.Lines[0].Comment)
+		p.newline()
+		return
+	}
+
+	p.write("//")
+	p.newline()
+
+	p.indent++
+	for i, ln := range c.Lines {
+		p.startLine(ln.Position, i == 0)
+		p.write(ln.Comment)
+		p.newline()
+	}
+	p.indent--
+}
+
+func (p *printer) htmlComment(c file.HTMLComment) {
+	if len(c.Lines) == 1 {
+		// leading whitespace of single line comments is stripped by the
+		// parser
+		if cmt := c.Lines[0].Comment; cmt != "" && cmt[0] != ' ' && cmt[0] != '\t' {
+			p.write("//- ", cmt)
+			p.newline()
+			return
+		}
+	}
+
+	p.write("//-")
+	p.newline()
+
+	p.indent++
+	for i, ln := range c.Lines {
+		p.startLine(ln.Position, i == 0)
+		p.write(ln.Comment)
+		p.newline()
+	}
+	p.indent--
+}
+
+// ================================== Code ==================================
+
+func (p *printer) code(c file.Code) {
+	for i, ln := range c.Lines {
+		if i == 0 {
+			p.write("- ", ln.Code)
+			p.newline()
+			continue
+		}
+
+		p.startLine(ln.Position, false)
+		p.write(indentation, ln.Code)
+		p.newline()
+	}
+}
+
+// ================================= Element ==================================
+
+func (p *printer) element(el file.Element) {
+	p.write(el.Name)
+	p.attributeCollections(el.Attributes, p.inline == 0)
+	if el.Void {
+		p.write("/")
+	}
+
+	p.body(el.Body)
+}
+
+func (p *printer) divShorthand(div file.DivShorthand) {
+	p.attributeCollections(div.Attributes, p.inline == 0)
+	p.body(div.Body)
+}
+
+func (p *printer) and(and file.And) {
+	p.write("&")
+	p.attributeCollections(and.Attributes, p.inline == 0)
+	p.newline()
+}
+
+// attributeCollections prints acolls.
+//
+// If wrap is true, attribute lists that would make the line exceed
+// maxLineWidth are split into one line per attribute.
+func (p *printer) attributeCollections(acolls []file.AttributeCollection, wrap bool) {
+	for _, acoll := range acolls {
+		switch acoll := acoll.(type) {
+		case file.IDShorthand:
+			p.write("#", acoll.ID)
+		case file.ClassShorthand:
+			p.write(".", acoll.Name)
+		case file.AttributeList:
+			start := p.buf.Len()
+			p.attributeList(acoll, false)
+			if wrap && len(acoll.Attributes) > 1 && p.lineWidth() > maxLineWidth {
+				p.buf.Truncate(start)
+				p.attributeList(acoll, true)
+			}
+		}
+	}
+}
+
+// lineWidth returns the width in runes of the line that is currently being
+// printed.
+func (p *printer) lineWidth() int {
+	ln := p.buf.Bytes()
+	if i := bytes.LastIndexByte(ln, '\n'); i >= 0 {
+		ln = ln[i+1:]
+	}
+
+	return utf8.RuneCount(ln)
+}
+
+// attributeList prints l.
+//
+// If multiline is true, each attribute is printed on its own line, followed
+// by a comma.
+func (p *printer) attributeList(l file.AttributeList, multiline bool) {
+	p.write("(")
+	if multiline {
+		p.indent++
+	}
+
+	for i, attr := range l.Attributes {
+		if multiline {
+			p.newline()
+			p.writeIndent()
+		} else if i > 0 {
+			p.write(", ")
+		}
+
+		switch attr := attr.(type) {
+		case file.SimpleAttribute:
+			p.write(attr.Name)
+			if attr.Value != nil {
+				p.write("=")
+				p.expression(*attr.Value)
+			}
+		case file.AndPlaceholder:
+			p.write("&&")
+		case file.MixinCallAttribute:
+			p.write(attr.Name, "=")
+			p.mixinCallHeader(attr.MixinCall)
+			if attr.Value != nil {
+				p.interpolationValue(attr.Value)
+			}
+		}
+
+		if multiline {
+			p.write(",")
+		}
+	}
+
+	if multiline {
+		p.indent--
+		p.newline()
+		p.writeIndent()
+	}
+	p.write(")")
+}
+
+// ================================== Block ===================================
+
+func (p *printer) block(b file.Block) {
+	switch b.Type {
+	case file.BlockTypeBlock:
+		p.write("block ")
+	case file.BlockTypePrepend:
+		p.write("prepend ")
+	case file.BlockTypeAppend:
+		p.write("append ")
+	}
+
+	p.write(b.Name.Ident)
+
+	if b.Params != nil {
+		p.write("(")
+		for i, param := range b.Params {
+			if i > 0 {
+				p.write(", ")
+			}
+
+			p.write(param.Name.Ident)
+			if param.Type != nil {
+				p.write(" ", param.Type.Type)
+			}
+
+			if param.Value != nil {
+				p.write(" = ")
+				p.expression(*param.Value)
+			}
+		}
+		p.write(")")
+	}
+
+	p.body(b.Body)
+}
+
+func (p *printer) blockExpansion(be file.BlockExpansion) {
+	p.write(":")
+
+	if ab, ok := be.Item.(file.ArrowBlock); ok && len(ab.Lines) == 1 {
+		p.write("> ")
+		p.textLine(ab.Lines[0])
+		p.newline()
+		return
+	}
+
+	p.write(" ")
+	p.inline++
+	p.scopeItem(be.Item)
+	p.inline--
+}
+
+// =========================== Control Structures =============================
+
+func (p *printer) _if(_if file.If) {
+	p.write("if ")
+	p.expression(_if.Condition)
+	p.body(_if.Then)
+
+	for _, elseIf := range _if.ElseIfs {
+		p.startLine(elseIf.Position, false)
+		p.write("else if ")
+		p.expression(elseIf.Condition)
+		p.body(elseIf.Then)
+	}
+
+	p._else(_if.Else)
+}
+
+func (p *printer) ifBlock(ifBlock file.IfBlock) {
+	p.write("if block ", ifBlock.Name.Ident)
+	p.body(ifBlock.Then)
+
+	for _, elseIf := range ifBlock.ElseIfs {
+		p.startLine(elseIf.Position, false)
+		p.write("else if block ", elseIf.Name.Ident)
+		p.body(elseIf.Then)
+	}
+
+	p._else(ifBlock.Else)
+}
+
+func (p *printer) _else(_else *file.Else) {
+	if _else == nil {
+		return
+	}
+
+	p.startLine(_else.Position, false)
+	p.write("else")
+	p.body(_else.Then)
+}
+
+func (p *printer) _switch(sw file.Switch) {
+	p.write("switch")
+	if sw.Comparator != nil {
+		p.write(" ")
+		p.expression(*sw.Comparator)
+	}
+	p.newline()
+
+	cases := sw.Cases
+	if sw.Default != nil {
+		// keep the default where it was written
+		cases = make([]file.Case, 0, len(sw.Cases)+1)
+		var defaultAdded bool
+		for _, c := range sw.Cases {
+			if !defaultAdded && sw.Default.Line < c.Line {
+				cases = append(cases, *sw.Default)
+				defaultAdded = true
+			}
+			cases = append(cases, c)
+		}
+		if !defaultAdded {
+			cases = append(cases, *sw.Default)
+		}
+	}
+
+	p.indent++
+	for i, c := range cases {
+		p.startLine(c.Position, i == 0)
+		if c.Expression != nil {
+			p.write("case ")
+			p.expression(*c.Expression)
+		} else {
+			p.write("default")
+		}
+
+		if len(c.Then) == 0 {
+			p.newline()
+			continue
+		}
+
+		p.body(c.Then)
+	}
+	p.indent--
+}
+
+func (p *printer) _for(f file.For) {
+	p.write("for")
+	if f.Label != nil {
+		p.write(" #", f.Label.Ident)
+	}
+	if f.Expression != nil {
+		p.write(" ")
+		p.expression(*f.Expression)
+	}
+
+	p.body(f.Body)
+	p._else(f.Else)
+}
+
+func (p *printer) loopControl(keyword string, label *file.Ident) {
+	p.write(keyword)
+	if label != nil {
+		p.write(" ", label.Ident)
+	}
+	p.newline()
+}
+
+// ================================= Filters ==================================
+
+func (p *printer) rawFilter(rf file.RawFilter) {
+	p.write(":raw")
+	if rf.Type != "" {
+		p.write(" ", string(rf.Type))
+	}
+	p.newline()
+
+	p.filterBody(rf.Body)
+}
+
+func (p *printer) commandFilter(cf file.CommandFilter) {
+	p.write(":", cf.Name)
+
+	for _, arg := range cf.Args {
+		p.write(" ")
+
+		switch arg := arg.(type) {
+		case file.RawCommandFilterArg:
+			p.write(arg.Value)
+		case file.StringCommandFilterArg:
+			p.string(file.String(arg))
+		}
+	}
+	p.newline()
+
+	p.filterBody(cf.Body)
+}
+
+func (p *printer) filterBody(lines []file.FilterLine) {
+	p.indent++
+	for i, ln := range lines {
+		p.startLine(ln.Position, i == 0)
+		p.write(ln.Line)
+		p.newline()
+	}
+	p.indent--
+}
+
+// ================================= Include ==================================
+
+func (p *printer) include(incl file.Include) {
+	p.write("include ")
+	p.string(incl.Path)
+	p.newline()
+}
+
+// ================================== Mixin ===================================
+
+func (p *printer) mixin(m file.Mixin) {
+	p.write("mixin ", m.Name.Ident)
+
+	if len(m.TypeParams) > 0 {
+		p.write("[")
+		for i, tp := range m.TypeParams {
+			if i > 0 {
+				p.write(", ")
+			}
+
+			p.write(tp.Name.Ident)
+			// type params declared together share their constraint
+			if i+1 < len(m.TypeParams) && m.TypeParams[i+1].Constraint.Position == tp.Constraint.Position {
+				continue
+			}
+
+			p.write(" ", tp.Constraint.Type)
+		}
+		p.write("]")
+	}
+
+	if m.LParenPos != nil {
+		p.write("(")
+		for i, param := range m.Params {
+			if i > 0 {
+				p.write(", ")
+			}
+
+			p.write(param.Name.Ident)
+			if param.Variadic {
+				p.write(" ...", param.Type.Type)
+			} else if param.Type != nil {
+				p.write(" ", param.Type.Type)
+			}
+
+			if param.Default != nil {
+				p.write(" = ")
+				p.expression(*param.Default)
+			}
+		}
+		p.write(")")
+	}
+
+	p.body(m.Body)
+}
+
+func (p *printer) _return(ret file.Return) {
+	p.write("return")
+	if ret.Err != nil {
+		p.write(" ")
+		p.expression(*ret.Err)
+	}
+	p.newline()
+}
+
+func (p *printer) mixinCall(c file.MixinCall) {
+	p.mixinCallHeader(c)
+
+	if len(c.Body) == 1 {
+		if sh, ok := c.Body[0].(file.MixinMainBlockShorthand); ok {
+			p.write("\\")
+			p.newline()
+
+			p.indent++
+			p.scope(sh.Body)
+			p.indent--
+			return
+		}
+	}
+
+	p.body(c.Body)
+}
+
+// mixinCallHeader prints the plus, the name, and the args of c.
+func (p *printer) mixinCallHeader(c file.MixinCall) {
+	p.write("+")
+	if c.Namespace != nil {
+		p.write(c.Namespace.Ident, ".")
+	}
+	p.write(c.Name.Ident)
+
+	if len(c.TypeArgs) > 0 {
+		p.write("[")
+		for i, typeArg := range c.TypeArgs {
+			if i > 0 {
+				p.write(", ")
+			}
+
+			p.write(typeArg.Type)
+		}
+		p.write("]")
+	}
+
+	if c.LParenPos == nil {
+		return
+	}
+
+	p.write("(")
+	for i, arg := range c.Args {
+		if i > 0 {
+			p.write(", ")
+		}
+
+		p.write(arg.Name.Ident, "=")
+		p.expression(arg.Value)
+		if arg.Spread {
+			p.write("...")
+		}
+	}
+	p.write(")")
+}
+
+// =================================== Text ===================================
+
+func (p *printer) arrowBlock(ab file.ArrowBlock) {
+	if len(ab.Lines) == 0 {
+		p.write(">")
+		p.newline()
+		return
+	}
+
+	for i, ln := range ab.Lines {
+		if i == 0 {
+			p.write("> ")
+		} else {
+			p.startLine(ln.Pos(), false)
+			p.write(indentation)
+		}
+
+		p.textLine(ln)
+		p.newline()
+	}
+}
+
+// ================================= Bad Item =================================
+
+// badItem prints the bad item as it was written.
+//
+// It is only used if File is called with an erroneous file.
+func (p *printer) badItem(bi file.BadItem) {
+	p.write(bi.Line)
+	p.newline()
+
+	p.indent++
+	p.scope(bi.Body)
+	p.indent--
+}
@@ -0,0 +1,248 @@
+package corgi
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"golang.org/x/exp/slog"
+
+	"github.com/mavolin/corgi/file"
+	"github.com/mavolin/corgi/file/precomp"
+	"github.com/mavolin/corgi/load"
+	"github.com/mavolin/corgi/std"
+)
+
+type FSLoadOptions struct {
+	// Modules maps module paths to the file systems holding the files of
+	// the module, e.g. an [embed.FS], an [fstest.MapFS], or an [os.DirFS].
+	//
+	// The root of each file system must be the root of its module, i.e. the
+	// directory where its go.mod is or would be located.
+	//
+	// Modules may be nested, in which case the longest matching module path
+	// is used.
+	Modules map[string]fs.FS
+
+	// NoPrecompile forces the loader to always read corgi files instead of
+	// loading a precompiled file.
+	NoPrecompile bool
+
+	// Logger is used to log the individual steps of the logging process.
+	//
+	// If left as nil, nothing will be logged
+	Logger *slog.Logger
+}
+
+// NewFSLoader creates a new *Loader that loads files from the file systems
+// in the passed module map, instead of from the OS file system.
+//
+// Contrary to a loader created by [NewLoader], it requires no Go toolchain:
+// imports are resolved solely using the module map and the corgi standard
+// library.
+//
+// All paths passed to the returned Loader's methods are slash-separated
+// module paths, e.g. `example.com/site/page.corgi` for a main file, or
+// `example.com/site/ui` for a library, rather than system paths.
+//
+// Files loaded by the returned Loader have no AbsolutePath, and Invalidate
+// is a no-op for them.
+func NewFSLoader(o FSLoadOptions) (*Loader, error) {
+	if len(o.Modules) == 0 {
+		return nil, errors.New("corgi.FSLoadOptions: no modules")
+	}
+
+	var l loader
+	l.noPrecompile = o.NoPrecompile
+	l.modules = o.Modules
+	l.initLoader(&load.BasicLoader{
+		MainReader:     l.readFSMain,
+		TemplateReader: l.readFSTemplate,
+		IncludeReader:  l.readFSInclude,
+		LibraryReader:  l.readFSLibrary,
+	})
+
+	l.log = o.Logger
+	if l.log == nil {
+		l.log = nopLog
+	}
+
+	return &Loader{l: &l}, nil
+}
+
+// fsModule returns the module that contains the file or directory with the
+// passed module path, the path of the file relative to the module's root,
+// and the file system of the module.
+//
+// If no module contains the path, fsModule returns a nil fs.FS.
+func (l *loader) fsModule(p string) (modulePath, pathInModule string, fsys fs.FS) {
+	for mod, modFS := range l.modules {
+		if len(mod) <= len(modulePath) {
+			continue
+		}
+
+		if p == mod {
+			modulePath, pathInModule, fsys = mod, "", modFS
+		} else if strings.HasPrefix(p, mod+"/") {
+			modulePath, pathInModule, fsys = mod, p[len(mod)+1:], modFS
+		}
+	}
+
+	return modulePath, pathInModule, fsys
+}
+
+func (l *loader) readFSMain(p string) (*load.File, error) {
+	log := l.log.WithGroup("fs_main_reader").With(slog.String("path", p))
+	return l.readFSFile(log, p)
+}
+
+func (l *loader) readFSTemplate(_ *file.File, extendPath string) (*load.File, error) {
+	log := l.log.WithGroup("fs_template_reader").With(slog.String("extend_path", extendPath))
+	return l.readFSFile(log, extendPath)
+}
+
+func (l *loader) readFSInclude(includingFile *file.File, slashPath string) (*load.File, error) {
+	p := path.Join(includingFile.Module, path.Dir(includingFile.PathInModule), slashPath)
+
+	log := l.log.
+		WithGroup("fs_include_reader").
+		With(slog.String("include_path", slashPath), slog.String("path", p),
+			slog.String("including_file", path.Join(includingFile.Module, includingFile.PathInModule)))
+
+	f, err := l.readFSFile(log, p)
+	if err != nil || f == nil {
+		return f, err
+	}
+
+	f.IsCorgi = path.Ext(slashPath) == ".corgi"
+	return f, nil
+}
+
+func (l *loader) readFSFile(log *slog.Logger, p string) (*load.File, error) {
+	log.Info("locating module")
+
+	modulePath, pathInModule, fsys := l.fsModule(p)
+	if fsys == nil || pathInModule == "" {
+		log.Info("file is in no module")
+		return nil, nil
+	}
+
+	log = log.With(slog.String("module", modulePath), slog.String("path_in_mod", pathInModule))
+	log.Info("reading file")
+
+	data, err := fs.ReadFile(fsys, pathInModule)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			log.Info("file doesn't exist in module")
+			return nil, nil
+		}
+
+		log.Error("failed to read file", slog.Any("err", err))
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+
+	log.Info("file read")
+
+	return &load.File{
+		Name:         path.Base(pathInModule),
+		Module:       modulePath,
+		PathInModule: pathInModule,
+		IsCorgi:      true,
+		Raw:          data,
+	}, nil
+}
+
+func (l *loader) readFSLibrary(_ *file.File, usePath string) (*load.Library, error) {
+	log := l.log.WithGroup("fs_library_reader").With(slog.String("path", usePath))
+
+	modulePath, pathInModule, fsys := l.fsModule(usePath)
+	if fsys == nil {
+		if stdlib := std.Lib[usePath]; stdlib != nil {
+			log.Info("using standard library")
+			return &load.Library{Precompiled: stdlib}, nil
+		}
+
+		log.Info("library is in no module")
+		return nil, nil
+	}
+
+	log = log.With(slog.String("module", modulePath), slog.String("path_in_mod", pathInModule))
+
+	dir := pathInModule
+	if dir == "" {
+		dir = "."
+	}
+
+	log.Info("loading dir information")
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			log.Info("library does not exist")
+			return nil, nil
+		}
+
+		log.Error("failed to load dir information", slog.Any("err", err))
+		return nil, fmt.Errorf("%s: %w", usePath, err)
+	}
+
+	log.Info("loaded dir information", slog.Int("num_files", len(entries)))
+
+	if !l.noPrecompile {
+		precompFile, err := fsys.Open(path.Join(dir, PrecompFileName))
+		if err == nil {
+			defer precompFile.Close()
+
+			log.Info("decoding precompiled library file")
+
+			lib, err := precomp.Decode(precompFile)
+			if err != nil {
+				log.Error("failed to decode precompiled library file", slog.Any("err", err))
+				return nil, fmt.Errorf("%s: failed to decode precompiled library: %w", usePath, err)
+			}
+
+			lib.Module = modulePath
+			lib.PathInModule = pathInModule
+			return &load.Library{Precompiled: lib}, nil
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			log.Error("failed to open precompiled library file", slog.Any("err", err))
+			return nil, fmt.Errorf("%s: failed to open precompiled library: %w", usePath, err)
+		}
+
+		log.Info("found no precompiled library file, compiling by hand")
+	}
+
+	lib := load.Library{
+		Module:       modulePath,
+		PathInModule: pathInModule,
+		Files:        make([]load.File, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, LibExt) {
+			continue
+		}
+
+		p := path.Join(dir, name)
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			log.Error("failed to read corgi lib file", slog.String("file", name), slog.Any("err", err))
+			return nil, fmt.Errorf("%s: failed to read library file: %w", path.Join(usePath, name), err)
+		}
+
+		lib.Files = append(lib.Files, load.File{
+			Name:         name,
+			Module:       modulePath,
+			PathInModule: path.Join(pathInModule, name),
+			IsCorgi:      true,
+			Raw:          data,
+		})
+	}
+
+	log.Info("read directory, returning with library", slog.Int("size", len(lib.Files)))
+
+	return &lib, nil
+}
@@ -0,0 +1,117 @@
+package corgi_test
+
+import (
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi"
+	"github.com/mavolin/corgi/file"
+	"github.com/mavolin/corgi/write"
+)
+
+func TestNewFSLoader(t *testing.T) {
+	t.Parallel()
+
+	l, err := corgi.NewFSLoader(corgi.FSLoadOptions{
+		Modules: map[string]fs.FS{
+			"example.com/site": fstest.MapFS{
+				"base/base.corgi": &fstest.MapFile{Data: []byte("html\n  body\n    block content\n")},
+				"pages/page.corgi": &fstest.MapFile{Data: []byte(
+					"extend \"example.com/site/base/base.corgi\"\n\n" +
+						"use \"example.com/ui/button\"\n" +
+						"use \"strings\"\n\n" +
+						"func Page()\n\n" +
+						"block content\n" +
+						"  include \"header.corgi\"\n" +
+						"  +button.Button(label=\"a\")\n" +
+						"  +title()\n" +
+						"  p: +strings.Nbsp(val=\"a b\")\n"),
+				},
+				"pages/header.corgi": &fstest.MapFile{Data: []byte("header Header\n")},
+				"pages/title.corgil": &fstest.MapFile{Data: []byte("mixin title()\n  h1 Title\n")},
+			},
+			"example.com/ui": fstest.MapFS{
+				"button/button.corgil": &fstest.MapFile{Data: []byte("mixin Button(label string)\n  button #{label}\n")},
+			},
+		},
+		NoPrecompile: true,
+	})
+	require.NoError(t, err)
+
+	f, err := l.LoadMain("example.com/site/pages/page.corgi")
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com/site", f.Module)
+	assert.Equal(t, "pages/page.corgi", f.PathInModule)
+	assert.Equal(t, "page.corgi", f.Name)
+
+	t.Run("extend", func(t *testing.T) {
+		require.NotNil(t, f.Extend)
+		require.NotNil(t, f.Extend.File)
+		assert.Equal(t, "example.com/site", f.Extend.File.Module)
+		assert.Equal(t, "base/base.corgi", f.Extend.File.PathInModule)
+	})
+
+	t.Run("use", func(t *testing.T) {
+		require.Len(t, f.Uses, 2)
+		require.Len(t, f.Uses[0].Uses, 1)
+
+		lib := f.Uses[0].Uses[0].Library
+		require.NotNil(t, lib)
+		assert.Equal(t, "example.com/ui", lib.Module)
+		assert.Equal(t, "button", lib.PathInModule)
+		assert.False(t, lib.Precompiled)
+		require.Len(t, lib.Files, 1)
+		assert.Equal(t, "button/button.corgil", lib.Files[0].PathInModule)
+	})
+
+	t.Run("std", func(t *testing.T) {
+		require.Len(t, f.Uses, 2)
+		require.Len(t, f.Uses[1].Uses, 1)
+
+		lib := f.Uses[1].Uses[0].Library
+		require.NotNil(t, lib)
+		assert.Equal(t, "strings", lib.PathInModule)
+		assert.True(t, lib.Precompiled)
+	})
+
+	t.Run("dir library", func(t *testing.T) {
+		require.NotNil(t, f.DirLibrary)
+		assert.Equal(t, "example.com/site", f.DirLibrary.Module)
+		assert.Equal(t, "pages", f.DirLibrary.PathInModule)
+		require.Len(t, f.DirLibrary.Files, 1)
+		assert.Equal(t, "pages/title.corgil", f.DirLibrary.Files[0].PathInModule)
+	})
+
+	t.Run("include", func(t *testing.T) {
+		var incl *file.File
+		for _, itm := range f.Scope {
+			b, ok := itm.(file.Block)
+			if !ok {
+				continue
+			}
+
+			for _, itm := range b.Body {
+				if i, ok := itm.(file.Include); ok {
+					if ci, ok := i.Include.(file.CorgiInclude); ok {
+						incl = ci.File
+					}
+				}
+			}
+		}
+
+		require.NotNil(t, incl)
+		assert.Equal(t, "example.com/site", incl.Module)
+		assert.Equal(t, "pages/header.corgi", incl.PathInModule)
+	})
+
+	t.Run("generate", func(t *testing.T) {
+		err := write.New(write.Options{}).GenerateFile(io.Discard, "pages", f)
+		assert.NoError(t, err)
+	})
+}
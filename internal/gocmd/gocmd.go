@@ -19,5 +19,11 @@ func NewCmd(goExecPath string) *Cmd {
 }
 
 func (c *Cmd) command(subcmd string, args ...string) ([]byte, error) {
-	return (&exec.Cmd{Path: c.path, Args: append([]string{c.path, subcmd}, args...)}).Output()
+	return c.commandIn("", subcmd, args...)
+}
+
+// commandIn is the same as command, but runs the command in the passed
+// directory.
+func (c *Cmd) commandIn(dir, subcmd string, args ...string) ([]byte, error) {
+	return (&exec.Cmd{Path: c.path, Args: append([]string{c.path, subcmd}, args...), Dir: dir}).Output()
 }
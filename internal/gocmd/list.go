@@ -0,0 +1,32 @@
+package gocmd
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// ListExports runs `go list -export -deps` in dir for the passed packages,
+// and returns the paths to the export data files of the packages and their
+// dependencies, mapped to the packages' import paths.
+//
+// Packages that fail to build are not included.
+func (c *Cmd) ListExports(dir string, pkgs ...string) (map[string]string, error) {
+	args := append([]string{"-e", "-export", "-deps", "-f", "{{.ImportPath}}\t{{.Export}}"}, pkgs...)
+	data, err := c.commandIn(dir, "list", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	exports := make(map[string]string)
+
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		path, export, _ := strings.Cut(s.Text(), "\t")
+		if export != "" {
+			exports[path] = export
+		}
+	}
+
+	return exports, s.Err()
+}
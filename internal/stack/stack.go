@@ -49,3 +49,13 @@ func (s *Stack[T]) Peek() T {
 func (s *Stack[T]) Len() int {
 	return s.l.Len()
 }
+
+// Each calls f for each element of the stack, starting with the top-most
+// element, until f returns false.
+func (s *Stack[T]) Each(f func(T) bool) {
+	for e := s.l.Back(); e != nil; e = e.Prev() {
+		if !f(e.V()) {
+			return
+		}
+	}
+}
@@ -33,6 +33,7 @@ func (l *Linker) linkExtend(ctx *context, f *file.File) {
 
 			ctx.errs <- list.List1(&corgierr.Error{
 				Message: "failed to load template",
+				Code:    corgierr.CodeLoadTemplate,
 				ErrorAnnotation: anno.Anno(f, anno.Annotation{
 					Start:      f.Extend.Position,
 					ToEOL:      true,
@@ -46,6 +47,7 @@ func (l *Linker) linkExtend(ctx *context, f *file.File) {
 		if template == nil {
 			ctx.errs <- list.List1(&corgierr.Error{
 				Message: "template not found",
+				Code:    corgierr.CodeTemplateNotFound,
 				ErrorAnnotation: anno.Anno(f, anno.Annotation{
 					Start:      f.Extend.Position,
 					ToEOL:      true,
@@ -42,6 +42,7 @@ func (l *Linker) linkInclude(f *file.File, incl *file.Include) *errList {
 
 		return list.List1(&corgierr.Error{
 			Message: "failed to load included file",
+			Code:    corgierr.CodeLoadInclude,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      incl.Position,
 				ToEOL:      true,
@@ -54,6 +55,7 @@ func (l *Linker) linkInclude(f *file.File, incl *file.Include) *errList {
 	if inclFile == nil {
 		return list.List1(&corgierr.Error{
 			Message: "included file not found",
+			Code:    corgierr.CodeIncludeNotFound,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      incl.Position,
 				ToEOL:      true,
@@ -56,6 +56,7 @@ func (l *Linker) analyzeMixins(fs ...*file.File) *errList {
 			m := mE.V()
 			errs.PushBack(&corgierr.Error{
 				Message: "linker: failed to analyze mixin",
+				Code:    corgierr.CodeAnalyzeMixin,
 				ErrorAnnotation: anno.Anno(m.f, anno.Annotation{
 					Start:      m.m.Name.Position,
 					Len:        len(m.m.Name.Ident),
@@ -257,6 +258,7 @@ func analyzeBlock(
 	bi, ok := blockInfos[b.Name.Ident]
 	bi.Name = b.Name.Ident
 	if !ok {
+		bi.Params = b.Params
 		bi.CanAttributes = canAttrs
 	} else if !canAttrs {
 		bi.CanAttributes = false
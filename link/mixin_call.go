@@ -7,6 +7,7 @@ import (
 	"github.com/mavolin/corgi/corgierr"
 	"github.com/mavolin/corgi/file"
 	"github.com/mavolin/corgi/file/fileutil"
+	"github.com/mavolin/corgi/file/typeinfer"
 	"github.com/mavolin/corgi/internal/anno"
 	"github.com/mavolin/corgi/internal/list"
 )
@@ -37,6 +38,10 @@ func (l *Linker) linkMixinCalls(f *file.File) *errList {
 		for _, mc := range mcs {
 			mcErrs := l.linkMixinCall(f, parents, ctx, mc)
 			errs.PushBackList(mcErrs)
+
+			if mc.Mixin != nil {
+				typeinfer.MixinTypeArgs(f, parents, mc)
+			}
 		}
 		return true, err
 	})
@@ -123,6 +128,7 @@ func (l *Linker) linkMixinCall(
 
 		return list.List1(&corgierr.Error{
 			Message: "call to unknown mixin",
+			Code:    corgierr.CodeUnknownMixin,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start: mc.Name.Position,
 				Len:   len(mc.Name.Ident),
@@ -156,6 +162,7 @@ func (l *Linker) linkMixinCall(
 
 			return list.List1(&corgierr.Error{
 				Message: "call to unknown mixin",
+				Code:    corgierr.CodeUnknownMixin,
 				ErrorAnnotation: anno.Anno(f, anno.Annotation{
 					Start:      mc.Namespace.Position,
 					End:        mc.Name.Position,
@@ -175,6 +182,7 @@ func (l *Linker) linkMixinCall(
 
 	return list.List1(&corgierr.Error{
 		Message: "missing use for library `" + mc.Namespace.Ident + "`",
+		Code:    corgierr.CodeMissingUse,
 		ErrorAnnotation: anno.Anno(f, anno.Annotation{
 			Start:      mc.Namespace.Position,
 			Len:        len(mc.Namespace.Ident),
@@ -301,6 +309,7 @@ func (l *Linker) _checkRecursion(f *file.File, m *file.Mixin, mcs ...file.MixinC
 			if i == 0 {
 				return list.List1(&corgierr.Error{
 					Message: "recursion",
+					Code:    corgierr.CodeRecursion,
 					ErrorAnnotation: anno.Anno(f, anno.Annotation{
 						Start:      m.Position,
 						Len:        (m.Name.Col - m.Col) + len(m.Name.Ident),
@@ -344,6 +353,7 @@ func (l *Linker) _checkRecursion(f *file.File, m *file.Mixin, mcs ...file.MixinC
 
 			return list.List1(&corgierr.Error{
 				Message: "recursion",
+				Code:    corgierr.CodeRecursion,
 				ErrorAnnotation: anno.Anno(f, anno.Annotation{
 					Start:      m.Position,
 					Len:        (m.Name.Col - m.Col) + len(m.Name.Ident),
@@ -31,6 +31,7 @@ func (l *Linker) linkDependencies(ctx *context, lib *file.Library) {
 			if err != nil {
 				ctx.errs <- list.List1(&corgierr.Error{
 					Message: "failed to load dependency of precompiled library",
+					Code:    corgierr.CodeLoadPrecompiledDependency,
 					ErrorAnnotation: corgierr.Annotation{
 						File:         usingFile,
 						ContextStart: 1,
@@ -56,6 +57,7 @@ func (l *Linker) linkDependencies(ctx *context, lib *file.Library) {
 			if len(dep.Library.Files) == 0 {
 				ctx.errs <- list.List1(&corgierr.Error{
 					Message: "dependency of precompiled library contains no library files",
+					Code:    corgierr.CodeEmptyPrecompiledDependency,
 					ErrorAnnotation: corgierr.Annotation{
 						File:         usingFile,
 						ContextStart: 1,
@@ -99,6 +101,7 @@ mixins:
 
 			errs.PushBack(&corgierr.Error{
 				Message: "failed to link mixin dependency of precompiled library",
+				Code:    corgierr.CodeLinkPrecompiledDependency,
 				ErrorAnnotation: corgierr.Annotation{
 					File:         lib.Files[0],
 					ContextStart: 1,
@@ -129,6 +132,7 @@ mixins:
 
 		errs.PushBack(&corgierr.Error{
 			Message: "failed to link mixin dependency of precompiled library",
+			Code:    corgierr.CodeLinkPrecompiledDependency,
 			ErrorAnnotation: corgierr.Annotation{
 				File:         lib.Files[0],
 				ContextStart: 1,
@@ -176,6 +180,7 @@ func (l *Linker) linkUseSpec(f *file.File, spec *file.UseSpec) *errList {
 
 		return list.List1(&corgierr.Error{
 			Message: "failed to load library",
+			Code:    corgierr.CodeLoadLibrary,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      spec.Position,
 				ToEOL:      true,
@@ -188,6 +193,7 @@ func (l *Linker) linkUseSpec(f *file.File, spec *file.UseSpec) *errList {
 	if lib == nil {
 		return list.List1(&corgierr.Error{
 			Message: "library not found",
+			Code:    corgierr.CodeLibraryNotFound,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      spec.Position,
 				ToEOL:      true,
@@ -26,6 +26,9 @@ type cachedFile struct {
 	lib  *file.Library
 	err  error
 	done <-chan struct{}
+
+	// inclPath is the absolute path of a non-corgi include.
+	inclPath string
 }
 
 // Cache wraps the passed [Loader] and returns a load that caches results to
@@ -82,10 +85,26 @@ func (l *CachingLoader) LoadDirLibrary(f *file.File, get func() (*file.Library,
 func (l *CachingLoader) LoadInclude(includingFile *file.File, p string) (file.IncludeFile, error) {
 	cached := l.load(l.includes, func(cached *cachedFile) {
 		cached.incl, cached.err = l.l.LoadInclude(includingFile, p)
-	}, path.Clean(includingFile.AbsolutePath+p))
+		if includingFile.AbsolutePath != "" {
+			cached.inclPath = includePath(includingFile, p)
+		}
+	}, includeKey(includingFile, p))
 	return cached.incl, cached.err
 }
 
+// includeKey returns the key used to cache the include with the passed path
+// included by includingFile.
+//
+// Files not loaded from the file system have no absolute path, and are
+// identified by their module path instead.
+func includeKey(includingFile *file.File, p string) string {
+	if includingFile.AbsolutePath != "" {
+		return path.Clean(includingFile.AbsolutePath + p)
+	}
+
+	return path.Join(includingFile.Module, path.Dir(includingFile.PathInModule), p)
+}
+
 func (l *CachingLoader) LoadTemplate(extendingFile *file.File, extendPath string) (*file.File, error) {
 	cached := l.load(l.templates, func(cached *cachedFile) {
 		cached.f, cached.err = l.l.LoadTemplate(extendingFile, extendPath)
@@ -117,3 +136,73 @@ func (l *CachingLoader) load(m map[string]*cachedFile, loader func(*cachedFile),
 	close(done)
 	return &cached
 }
+
+// Invalidate removes all cached files and libraries from the cache that are
+// located at or depend on any of the passed absolute system paths, as
+// determined by [Dependencies] and [LibraryDependencies].
+//
+// A path may also be the directory of a library, in which case all files
+// that use that library are invalidated as well.
+// This is useful if a library file was added or removed.
+//
+// Files that failed to load or are still loading are always invalidated.
+func (l *CachingLoader) Invalidate(sysAbsPaths ...string) {
+	if len(sysAbsPaths) == 0 {
+		return
+	}
+
+	changed := make(map[string]struct{}, len(sysAbsPaths))
+	for _, p := range sysAbsPaths {
+		changed[p] = struct{}{}
+	}
+
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	for _, m := range []map[string]*cachedFile{
+		l.mainFiles, l.templates, l.includes, l.linkedLibraries, l.unlinkedLibraries,
+	} {
+		for k, cached := range m {
+			if cached.dependsOn(changed) {
+				delete(m, k)
+			}
+		}
+	}
+}
+
+func (cached *cachedFile) dependsOn(changed map[string]struct{}) bool {
+	select {
+	case <-cached.done:
+	default:
+		return true
+	}
+
+	if cached.err != nil {
+		return true
+	}
+
+	var deps []string
+	switch {
+	case cached.f != nil:
+		deps = Dependencies(cached.f)
+	case cached.lib != nil:
+		deps = LibraryDependencies(cached.lib)
+	case cached.incl != nil:
+		switch incl := cached.incl.(type) {
+		case file.CorgiInclude:
+			deps = Dependencies(incl.File)
+		case file.OtherInclude:
+			deps = []string{cached.inclPath}
+		}
+	default: // the file didn't exist, maybe it does now
+		return true
+	}
+
+	for _, dep := range deps {
+		if _, ok := changed[dep]; ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,169 @@
+package load_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi/file"
+	"github.com/mavolin/corgi/load"
+)
+
+// countingLoader is a [load.Loader] that returns the main files and includes
+// stored in it, and counts how often each was loaded.
+type countingLoader struct {
+	mut   sync.Mutex
+	files map[string]*file.File
+	loads map[string]int
+}
+
+var _ load.Loader = (*countingLoader)(nil)
+
+var errBroken = errors.New("broken")
+
+func newCountingLoader(files map[string]*file.File) *countingLoader {
+	return &countingLoader{files: files, loads: make(map[string]int)}
+}
+
+func (l *countingLoader) count(p string) int {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	return l.loads[p]
+}
+
+func (l *countingLoader) load(p string) (*file.File, error) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	l.loads[p]++
+	if p == "broken" {
+		return nil, errBroken
+	}
+	return l.files[p], nil
+}
+
+func (l *countingLoader) LoadLibrary(*file.File, string) (*file.Library, error) {
+	return nil, nil
+}
+
+func (l *countingLoader) LoadInclude(_ *file.File, p string) (file.IncludeFile, error) {
+	if _, err := l.load(p); err != nil {
+		return nil, err
+	}
+
+	return file.OtherInclude{Contents: "foo"}, nil
+}
+
+func (l *countingLoader) LoadTemplate(_ *file.File, p string) (*file.File, error) {
+	return l.load(p)
+}
+
+func (l *countingLoader) LoadMain(p string) (*file.File, error) {
+	return l.load(p)
+}
+
+func TestCachingLoader_Invalidate(t *testing.T) {
+	t.Parallel()
+
+	mainFile, _ := newDepFiles()
+	mainPath := mainFile.AbsolutePath
+
+	t.Run("cached", func(t *testing.T) {
+		t.Parallel()
+
+		cl := newCountingLoader(map[string]*file.File{mainPath: mainFile})
+		l := load.Cache(cl)
+
+		for i := 0; i < 2; i++ {
+			f, err := l.LoadMain(mainPath)
+			require.NoError(t, err)
+			assert.Same(t, mainFile, f)
+		}
+		assert.Equal(t, 1, cl.count(mainPath))
+
+		l.Invalidate(abs("mod/other.corgi"), abs("other"))
+		_, err := l.LoadMain(mainPath)
+		require.NoError(t, err)
+		assert.Equal(t, 1, cl.count(mainPath), "invalidated file that doesn't depend on the changed paths")
+	})
+
+	transitive := []struct {
+		name    string
+		changed string
+	}{
+		{name: "self", changed: mainPath},
+		{name: "template", changed: abs("mod/tmpl.corgi")},
+		{name: "include of template", changed: abs("mod/inc/incl.corgi")},
+		{name: "non-corgi include", changed: abs("mod/inc/raw.txt")},
+		{name: "library file", changed: abs("mod/lib/a.corgil")},
+		{name: "library dir", changed: abs("mod/lib")},
+		{name: "library dependency", changed: abs("dep/lib")},
+		{name: "dir of template", changed: abs("mod")},
+	}
+
+	for _, c := range transitive {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			cl := newCountingLoader(map[string]*file.File{mainPath: mainFile})
+			l := load.Cache(cl)
+
+			_, err := l.LoadMain(mainPath)
+			require.NoError(t, err)
+
+			l.Invalidate(c.changed)
+
+			_, err = l.LoadMain(mainPath)
+			require.NoError(t, err)
+			assert.Equal(t, 2, cl.count(mainPath))
+		})
+	}
+
+	t.Run("include", func(t *testing.T) {
+		t.Parallel()
+
+		cl := newCountingLoader(nil)
+		l := load.Cache(cl)
+
+		includingFile := &file.File{AbsolutePath: abs("mod/page.corgi")}
+
+		_, err := l.LoadInclude(includingFile, "raw.txt")
+		require.NoError(t, err)
+
+		l.Invalidate(abs("mod/other.txt"))
+		_, err = l.LoadInclude(includingFile, "raw.txt")
+		require.NoError(t, err)
+		assert.Equal(t, 1, cl.count("raw.txt"))
+
+		l.Invalidate(abs("mod/raw.txt"))
+		_, err = l.LoadInclude(includingFile, "raw.txt")
+		require.NoError(t, err)
+		assert.Equal(t, 2, cl.count("raw.txt"))
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		t.Parallel()
+
+		cl := newCountingLoader(nil)
+		l := load.Cache(cl)
+
+		for _, p := range []string{"broken", "missing"} {
+			_, err := l.LoadMain(p)
+			if p == "broken" {
+				require.ErrorIs(t, err, errBroken)
+			}
+		}
+
+		l.Invalidate(abs("other"))
+
+		for _, p := range []string{"broken", "missing"} {
+			_, _ = l.LoadMain(p)
+			assert.Equal(t, 2, cl.count(p), p)
+		}
+	})
+}
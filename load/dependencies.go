@@ -0,0 +1,135 @@
+package load
+
+import (
+	"path/filepath"
+
+	"github.com/mavolin/corgi/file"
+	"github.com/mavolin/corgi/file/fileutil"
+)
+
+// Dependencies returns the absolute system paths of f and all the files and
+// library directories f depends on, i.e. its template, its includes, its dir
+// library, and the libraries it uses.
+//
+// The directory of a main, template, or include file is always included,
+// even if it contains no dir library (yet).
+//
+// Dependencies are resolved recursively.
+// Paths of files not loaded from the file system, such as those of the
+// standard library, are not included.
+//
+// The paths of non-corgi includes are resolved relative to the directory of
+// the including file.
+func Dependencies(f *file.File) []string {
+	var d deps
+	d.file(f)
+	return d.paths
+}
+
+// LibraryDependencies is the same as [Dependencies], but for libraries.
+//
+// The first path is the directory of the library itself, followed by the
+// paths of its files.
+func LibraryDependencies(lib *file.Library) []string {
+	var d deps
+	d.library(lib)
+	return d.paths
+}
+
+type deps struct {
+	paths []string
+
+	files map[*file.File]struct{}
+	libs  map[*file.Library]struct{}
+}
+
+func (d *deps) add(sysAbsPath string) {
+	if sysAbsPath == "" {
+		return
+	}
+
+	for _, p := range d.paths {
+		if p == sysAbsPath {
+			return
+		}
+	}
+
+	d.paths = append(d.paths, sysAbsPath)
+}
+
+func (d *deps) file(f *file.File) {
+	if f == nil {
+		return
+	}
+
+	if d.files == nil {
+		d.files = make(map[*file.File]struct{})
+	}
+	if _, ok := d.files[f]; ok {
+		return
+	}
+	d.files[f] = struct{}{}
+
+	d.add(f.AbsolutePath)
+	if f.Type != file.TypeLibraryFile && f.AbsolutePath != "" {
+		d.add(filepath.Dir(f.AbsolutePath))
+	}
+
+	if f.Extend != nil {
+		d.file(f.Extend.File)
+	}
+
+	for _, use := range f.Uses {
+		for _, spec := range use.Uses {
+			d.library(spec.Library)
+		}
+	}
+
+	d.library(f.DirLibrary)
+
+	fileutil.Walk(f.Scope, func(_ []fileutil.WalkContext, ctx fileutil.WalkContext) (dive bool, err error) {
+		incl, ok := (*ctx.Item).(file.Include)
+		if !ok {
+			return true, nil
+		}
+
+		switch inclf := incl.Include.(type) {
+		case file.CorgiInclude:
+			d.file(inclf.File)
+		case file.OtherInclude:
+			if f.AbsolutePath != "" {
+				d.add(includePath(f, incl.Path.Contents))
+			}
+		}
+
+		return false, nil
+	})
+}
+
+func (d *deps) library(lib *file.Library) {
+	if lib == nil {
+		return
+	}
+
+	if d.libs == nil {
+		d.libs = make(map[*file.Library]struct{})
+	}
+	if _, ok := d.libs[lib]; ok {
+		return
+	}
+	d.libs[lib] = struct{}{}
+
+	d.add(lib.AbsolutePath)
+
+	for _, f := range lib.Files {
+		d.file(f)
+	}
+
+	for _, dep := range lib.Dependencies {
+		d.library(dep.Library)
+	}
+}
+
+func includePath(includingFile *file.File, slashPath string) string {
+	return filepath.Join(filepath.Dir(includingFile.AbsolutePath), filepath.FromSlash(slashPath))
+}
@@ -0,0 +1,77 @@
+package load_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mavolin/corgi/file"
+	"github.com/mavolin/corgi/load"
+)
+
+// abs returns the absolute system path of the passed slash-separated path.
+func abs(p string) string {
+	return filepath.FromSlash("/" + p)
+}
+
+// newDepFiles returns a main file, that extends a template, which includes a
+// file, that uses a library with a dependency and includes a text file.
+func newDepFiles() (mainFile *file.File, lib *file.Library) {
+	depLib := &file.Library{AbsolutePath: abs("dep/lib")}
+
+	lib = &file.Library{
+		AbsolutePath: abs("mod/lib"),
+		Dependencies: []file.LibDependency{{Library: depLib}},
+	}
+	lib.Files = []*file.File{{Type: file.TypeLibraryFile, AbsolutePath: abs("mod/lib/a.corgil"), Library: lib}}
+
+	incl := &file.File{
+		Type:         file.TypeInclude,
+		AbsolutePath: abs("mod/inc/incl.corgi"),
+		Uses:         []file.Use{{Uses: []file.UseSpec{{Library: lib}}}},
+		Scope: file.Scope{
+			file.Include{Path: file.String{Contents: "raw.txt"}, Include: file.OtherInclude{}},
+		},
+	}
+
+	tmpl := &file.File{
+		Type:         file.TypeTemplate,
+		AbsolutePath: abs("mod/tmpl.corgi"),
+		Scope: file.Scope{
+			file.Include{Path: file.String{Contents: "inc/incl.corgi"}, Include: file.CorgiInclude{File: incl}},
+		},
+	}
+
+	mainFile = &file.File{
+		Type:         file.TypeMain,
+		AbsolutePath: abs("mod/page/page.corgi"),
+		Extend:       &file.Extend{File: tmpl},
+	}
+
+	return mainFile, lib
+}
+
+func TestDependencies(t *testing.T) {
+	t.Parallel()
+
+	mainFile, _ := newDepFiles()
+
+	expect := []string{
+		abs("mod/page/page.corgi"), abs("mod/page"),
+		abs("mod/tmpl.corgi"), abs("mod"),
+		abs("mod/inc/incl.corgi"), abs("mod/inc"), abs("mod/inc/raw.txt"),
+		abs("mod/lib"), abs("mod/lib/a.corgil"),
+		abs("dep/lib"),
+	}
+	assert.ElementsMatch(t, expect, load.Dependencies(mainFile))
+}
+
+func TestLibraryDependencies(t *testing.T) {
+	t.Parallel()
+
+	_, lib := newDepFiles()
+
+	expect := []string{abs("mod/lib"), abs("mod/lib/a.corgil"), abs("dep/lib")}
+	assert.Equal(t, expect, load.LibraryDependencies(lib))
+}
@@ -0,0 +1,258 @@
+package lsp
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/mavolin/corgi/file"
+	"github.com/mavolin/corgi/file/fileutil"
+)
+
+var (
+	// blockCompletionRegexp matches the beginning of a block, whose name is
+	// being typed.
+	blockCompletionRegexp = regexp.MustCompile(`^\s*(?:block|prepend|append)\s+[\w-]*$`)
+	// argCompletionRegexp matches the beginning of a mixin call, whose args
+	// are being typed.
+	argCompletionRegexp = regexp.MustCompile(`\+(?:(\w+)\.)?(\w+)\(([^()]*)$`)
+	// mixinCompletionRegexp matches the beginning of a mixin call, whose
+	// name is being typed.
+	mixinCompletionRegexp = regexp.MustCompile(`\+(?:(\w+)\.)?(\w*)$`)
+
+	argNameRegexp = regexp.MustCompile(`(\w+)\s*=`)
+)
+
+// completion returns completion items for mixin names, mixin args, and
+// block names.
+//
+// As the document is likely erroneous while typing, the context of the
+// completion is determined by looking at the text before the cursor, rather
+// than by using the parsed file.
+func (s *Server) completion(params TextDocumentPositionParams) (*CompletionList, error) {
+	doc := s.docs[params.TextDocument.URI]
+	if doc == nil {
+		return nil, nil
+	}
+
+	before := lineBefore(doc.lines, params.Position)
+
+	var items []CompletionItem
+
+	if blockCompletionRegexp.MatchString(before) {
+		items = blockCompletions(doc, params.Position.Line+1)
+	} else if m := argCompletionRegexp.FindStringSubmatch(before); m != nil {
+		items = argCompletions(doc, m[1], m[2], m[3])
+	} else if m := mixinCompletionRegexp.FindStringSubmatch(before); m != nil {
+		items = mixinCompletions(doc, m[1])
+	}
+
+	return &CompletionList{Items: items}, nil
+}
+
+// ============================================================================
+// Mixins
+// ======================================================================================
+
+func mixinCompletions(doc *document, namespace string) []CompletionItem {
+	items := make([]CompletionItem, 0)
+
+	for _, decl := range availableMixins(doc) {
+		if decl.namespace != namespace {
+			continue
+		}
+
+		items = append(items, CompletionItem{
+			Label:         decl.mixin.Name.Ident,
+			Kind:          CompletionItemKindFunction,
+			Detail:        decl.signature(),
+			Documentation: &MarkupContent{Kind: markupKindMarkdown, Value: decl.markdown()},
+		})
+	}
+
+	if namespace != "" {
+		return items
+	}
+
+	for _, use := range doc.f.Uses {
+		for _, spec := range use.Uses {
+			if namespace := useNamespace(spec); namespace != "" {
+				items = append(items, CompletionItem{
+					Label:  namespace,
+					Kind:   CompletionItemKindModule,
+					Detail: "use " + fileutil.Quote(spec.Path),
+				})
+			}
+		}
+	}
+
+	return items
+}
+
+// ============================================================================
+// Mixin Args
+// ======================================================================================
+
+// argCompletions returns completions for the args of the mixin with the
+// passed namespace and name.
+//
+// args is the text typed between the opening parenthesis and the cursor.
+func argCompletions(doc *document, namespace, name, args string) []CompletionItem {
+	// only complete names, not values
+	if i := strings.LastIndexByte(args, ','); i >= 0 {
+		if strings.ContainsAny(args[i+1:], "=\"'`") {
+			return nil
+		}
+	} else if strings.ContainsAny(args, "=\"'`") {
+		return nil
+	}
+
+	var decl *mixinDecl
+	for _, d := range availableMixins(doc) {
+		if d.namespace == namespace && d.mixin.Name.Ident == name {
+			d := d
+			decl = &d
+			break
+		}
+	}
+	if decl == nil {
+		return nil
+	}
+
+	given := make(map[string]struct{})
+	for _, m := range argNameRegexp.FindAllStringSubmatch(args, -1) {
+		given[m[1]] = struct{}{}
+	}
+
+	items := make([]CompletionItem, 0, len(decl.mixin.Params))
+
+	for _, param := range decl.mixin.Params {
+		if _, ok := given[param.Name.Ident]; ok && !param.Variadic {
+			continue
+		}
+
+		items = append(items, CompletionItem{
+			Label:      param.Name.Ident,
+			Kind:       CompletionItemKindVariable,
+			Detail:     paramSignature(param),
+			InsertText: param.Name.Ident + "=",
+		})
+	}
+
+	return items
+}
+
+// ============================================================================
+// Blocks
+// ======================================================================================
+
+// blockCompletions returns completions for the names of the blocks that
+// may be filled at the passed 1-based line.
+//
+// These are the blocks of the mixin call enclosing the line, or if there is
+// none, the blocks of the template extended by doc.
+func blockCompletions(doc *document, line int) []CompletionItem {
+	var names []string
+
+	if mc := enclosingMixinCall(doc, line); mc != nil {
+		if decl := resolveMixinCall(doc, mc); decl != nil {
+			names = mixinBlocks(decl.mixin)
+		}
+	} else {
+		names = templateBlocks(doc)
+	}
+
+	items := make([]CompletionItem, len(names))
+	for i, name := range names {
+		items[i] = CompletionItem{Label: name, Kind: CompletionItemKindReference}
+	}
+
+	return items
+}
+
+// enclosingMixinCall returns the mixin call, whose body contains the passed
+// 1-based line.
+//
+// It determines the body of a mixin call using the indentation of the
+// lines following it.
+func enclosingMixinCall(doc *document, line int) *file.MixinCall {
+	if line > len(doc.lines) {
+		return nil
+	}
+
+	var found *file.MixinCall
+
+	_ = fileutil.Walk(doc.f.Scope, func(_ []fileutil.WalkContext, ctx fileutil.WalkContext) (bool, error) {
+		mc, ok := (*ctx.Item).(file.MixinCall)
+		if !ok {
+			return true, nil
+		}
+
+		if mc.Line >= line || mc.Line < 1 {
+			return true, nil
+		}
+
+		indent := indentation(doc.lines[mc.Line-1])
+		for _, ln := range doc.lines[mc.Line : line-1] {
+			if strings.TrimSpace(ln) != "" && indentation(ln) <= indent {
+				return true, nil
+			}
+		}
+
+		if indentation(doc.lines[line-1]) <= indent {
+			return true, nil
+		}
+
+		// we walk depth-first, so inner mixin calls overwrite outer ones
+		found = &mc
+		return true, nil
+	})
+
+	return found
+}
+
+func indentation(ln string) int {
+	return len(ln) - len(strings.TrimLeft(ln, " \t"))
+}
+
+// templateBlocks returns the names of the blocks of the template extended
+// by doc, and of the templates extended by that template.
+func templateBlocks(doc *document) []string {
+	tmpl := doc.extend
+	if doc.f.Extend != nil && doc.f.Extend.File != nil {
+		tmpl = doc.f.Extend.File
+	}
+
+	var names []string
+
+	for tmpl != nil {
+		_ = fileutil.Walk(tmpl.Scope, func(_ []fileutil.WalkContext, ctx fileutil.WalkContext) (bool, error) {
+			switch itm := (*ctx.Item).(type) {
+			case file.Block:
+				if itm.Type != file.BlockTypeBlock {
+					return true, nil
+				}
+
+				for _, name := range names {
+					if name == itm.Name.Ident {
+						return true, nil
+					}
+				}
+
+				names = append(names, itm.Name.Ident)
+				return true, nil
+			case file.Mixin, file.MixinCall:
+				return false, nil
+			default:
+				return true, nil
+			}
+		})
+
+		if tmpl.Extend == nil {
+			break
+		}
+
+		tmpl = tmpl.Extend.File
+	}
+
+	return names
+}
@@ -0,0 +1,52 @@
+package lsp
+
+import "unicode/utf8"
+
+// definition returns the location of the declaration of the mixin called at
+// the passed position, or of the mixin param, whose argument is located
+// there.
+func (s *Server) definition(params TextDocumentPositionParams) (*Location, error) {
+	doc := s.docs[params.TextDocument.URI]
+	if doc == nil {
+		return nil, nil
+	}
+
+	line, col := fromPosition(doc.lines, params.Position)
+
+	if mc := mixinCallAt(doc.f, line, col); mc != nil {
+		decl := resolveMixinCall(doc, mc)
+		if decl == nil {
+			return nil, nil
+		}
+
+		if loc, ok := decl.location(s); ok {
+			return &loc, nil
+		}
+
+		return nil, nil
+	}
+
+	mc, arg := mixinArgAt(doc.f, line, col)
+	if mc == nil {
+		return nil, nil
+	}
+
+	decl := resolveMixinCall(doc, mc)
+	if decl == nil {
+		return nil, nil
+	}
+
+	for _, param := range decl.mixin.Params {
+		if param.Name.Ident != arg.Name.Ident {
+			continue
+		}
+
+		if loc, ok := decl.positionLocation(s, param.Name.Position, utf8.RuneCountInString(param.Name.Ident)); ok {
+			return &loc, nil
+		}
+
+		return nil, nil
+	}
+
+	return nil, nil
+}
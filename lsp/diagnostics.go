@@ -0,0 +1,113 @@
+package lsp
+
+import (
+	"github.com/mavolin/corgi/corgierr"
+)
+
+// publishDiagnostics publishes the passed error, that was returned when
+// loading doc, as diagnostics.
+//
+// Errors located in other files than doc are published for those files,
+// unless they are opened themselves.
+// Diagnostics previously published for doc, that are now fixed, are cleared.
+func (s *Server) publishDiagnostics(doc *document, err error) error {
+	diags := make(map[string][]Diagnostic)
+	diags[doc.uri] = []Diagnostic{}
+
+	if err != nil {
+		lerr := corgierr.As(err)
+		if len(lerr) == 0 {
+			diags[doc.uri] = append(diags[doc.uri], Diagnostic{
+				Severity: DiagnosticSeverityError,
+				Source:   "corgi",
+				Message:  err.Error(),
+			})
+		}
+
+		for _, err := range lerr {
+			uri := s.annotationURI(doc, err.ErrorAnnotation)
+			// opened documents publish their own diagnostics
+			if uri != doc.uri && s.docs[uri] != nil {
+				continue
+			}
+
+			diags[uri] = append(diags[uri], s.diagnostic(doc, err))
+		}
+	}
+
+	for _, uri := range doc.diagnosed {
+		if _, ok := diags[uri]; !ok && s.docs[uri] == nil {
+			diags[uri] = []Diagnostic{}
+		}
+	}
+
+	doc.diagnosed = doc.diagnosed[:0]
+	for uri, diags := range diags {
+		if len(diags) > 0 {
+			doc.diagnosed = append(doc.diagnosed, uri)
+		}
+
+		params := PublishDiagnosticsParams{URI: uri, Diagnostics: diags}
+		if uri == doc.uri {
+			params.Version = &doc.version
+		}
+
+		if err := s.conn.notify("textDocument/publishDiagnostics", params); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) diagnostic(doc *document, err *corgierr.Error) Diagnostic {
+	d := Diagnostic{
+		Range:    s.annotationRange(doc, err.ErrorAnnotation),
+		Severity: DiagnosticSeverityError,
+		Code:     string(err.Code),
+		Source:   "corgi",
+		Message:  err.Message,
+	}
+	if err.ErrorAnnotation.Annotation != "" {
+		d.Message += ": " + err.ErrorAnnotation.Annotation
+	}
+
+	for _, sugg := range err.Suggestions {
+		d.Message += "\n" + sugg.Suggestion
+	}
+
+	for _, hint := range err.HintAnnotations {
+		d.RelatedInformation = append(d.RelatedInformation, DiagnosticRelatedInformation{
+			Location: Location{
+				URI:   s.annotationURI(doc, hint),
+				Range: s.annotationRange(doc, hint),
+			},
+			Message: hint.Annotation,
+		})
+	}
+
+	return d
+}
+
+// annotationURI returns the uri of the file a is located in.
+//
+// If a has no file, it is assumed to be located in doc.
+func (s *Server) annotationURI(doc *document, a corgierr.Annotation) string {
+	if a.File == nil || a.File.AbsolutePath == "" || a.File.AbsolutePath == doc.path {
+		return doc.uri
+	}
+
+	return s.uri(a.File.AbsolutePath)
+}
+
+func (s *Server) annotationRange(doc *document, a corgierr.Annotation) Range {
+	lines := doc.lines
+	if a.File != nil && a.File.AbsolutePath != "" && a.File.AbsolutePath != doc.path {
+		lines = a.File.Lines
+	}
+
+	return Range{
+		Start: toPosition(lines, a.Line, a.Start),
+		End:   toPosition(lines, a.Line, a.End),
+	}
+}
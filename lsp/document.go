@@ -0,0 +1,324 @@
+package lsp
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/mavolin/corgi"
+	"github.com/mavolin/corgi/file"
+	"github.com/mavolin/corgi/parse"
+	"github.com/mavolin/corgi/validate"
+)
+
+// document is a document opened in the client.
+type document struct {
+	uri     string
+	path    string
+	version int
+	lines   []string
+
+	// f is the most recent version of the document.
+	//
+	// If the document could be loaded, it is the loaded, i.e. linked file.
+	// Otherwise, it is the file as returned by the parser.
+	f *file.File
+
+	// The below fields hold the results of the last time the document's
+	// dependencies could be linked.
+	// They are used as fallback, if the document is currently erroneous.
+
+	dirLib *file.Library
+	lib    *file.Library
+	uses   map[string]*file.Library // by use path
+	extend *file.File
+
+	// diagnosed are the uris of the files that the last diagnostics of this
+	// document were published for.
+	diagnosed []string
+}
+
+func splitLines(text string) []string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+
+	return lines
+}
+
+// ============================================================================
+// Text Synchronization
+// ======================================================================================
+
+func (s *Server) didOpen(params DidOpenTextDocumentParams) error {
+	p, err := uriToPath(params.TextDocument.URI)
+	if err != nil {
+		return err
+	}
+
+	doc := &document{
+		uri:     params.TextDocument.URI,
+		path:    p,
+		version: params.TextDocument.Version,
+		uses:    make(map[string]*file.Library),
+	}
+	s.docs[params.TextDocument.URI] = doc
+
+	return s.update(doc, params.TextDocument.Text)
+}
+
+func (s *Server) didChange(params DidChangeTextDocumentParams) error {
+	doc := s.docs[params.TextDocument.URI]
+	if doc == nil {
+		return fmt.Errorf("change of document that wasn't opened: %s", params.TextDocument.URI)
+	}
+
+	if len(params.ContentChanges) == 0 {
+		return nil
+	}
+
+	doc.version = params.TextDocument.Version
+	return s.update(doc, params.ContentChanges[len(params.ContentChanges)-1].Text)
+}
+
+func (s *Server) didClose(params DidCloseTextDocumentParams) error {
+	doc := s.docs[params.TextDocument.URI]
+	if doc == nil {
+		return nil
+	}
+
+	delete(s.docs, params.TextDocument.URI)
+
+	s.overlayMut.Lock()
+	delete(s.overlay, doc.path)
+	s.overlayMut.Unlock()
+
+	// the file on disk may differ from what the client last sent us
+	s.loader.Invalidate(doc.path)
+
+	return s.publishDiagnostics(doc, nil)
+}
+
+// update updates the contents of doc to text, and publishes new
+// diagnostics.
+func (s *Server) update(doc *document, text string) error {
+	doc.lines = splitLines(text)
+
+	s.overlayMut.Lock()
+	s.overlay[doc.path] = []byte(text)
+	s.overlayMut.Unlock()
+
+	s.loader.Invalidate(doc.path)
+
+	if err := s.publishDiagnostics(doc, s.analyze(doc)); err != nil {
+		return err
+	}
+
+	// other open documents may depend on this one
+	for _, other := range s.docs {
+		if other != doc {
+			if err := s.publishDiagnostics(other, s.analyze(other)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ============================================================================
+// Analysis
+// ======================================================================================
+
+// analyze loads doc, updating doc.f, and returns the error produced while
+// loading it.
+func (s *Server) analyze(doc *document) error {
+	f, err := s.load(doc)
+	if f == nil {
+		f, _ = parse.Parse([]byte(strings.Join(doc.lines, "\n")))
+		f.Name = filepath.Base(doc.path)
+		f.AbsolutePath = doc.path
+	}
+
+	doc.f = f
+
+	if f.DirLibrary != nil {
+		doc.dirLib = f.DirLibrary
+	}
+	if f.Library != nil {
+		doc.lib = f.Library
+	}
+	if f.Extend != nil && f.Extend.File != nil {
+		doc.extend = f.Extend.File
+	}
+	for _, use := range f.Uses {
+		for _, spec := range use.Uses {
+			if spec.Library != nil {
+				doc.uses[spec.Path.Contents] = spec.Library
+			}
+		}
+	}
+
+	return err
+}
+
+// load loads the file represented by doc.
+//
+// It may return a nil file, if the file could not be loaded.
+func (s *Server) load(doc *document) (*file.File, error) {
+	switch filepath.Ext(doc.path) {
+	case corgi.LibExt:
+		lib, err := s.loader.LoadLibrary(filepath.Dir(doc.path))
+		if lib == nil {
+			return nil, err
+		}
+
+		for _, f := range lib.Files {
+			if f.AbsolutePath == doc.path {
+				return f, err
+			}
+		}
+
+		return nil, err
+	default:
+		// we don't know if this is a main file, a template, or an include,
+		// so we need to take a look at its func header first
+		f, err := parse.Parse([]byte(strings.Join(doc.lines, "\n")))
+		if err != nil || f.Func != nil {
+			return s.loader.LoadMain(doc.path)
+		}
+
+		f, err = s.loader.LoadTemplate(doc.path)
+		if err != nil && f == nil {
+			// not in a module, just validate what we can without linking
+			f, err = parse.Parse([]byte(strings.Join(doc.lines, "\n")))
+			if err != nil {
+				return nil, err
+			}
+			f.Name = filepath.Base(doc.path)
+			f.AbsolutePath = doc.path
+			return f, validate.PreLink(f)
+		}
+
+		return f, err
+	}
+}
+
+// ============================================================================
+// Positions
+// ======================================================================================
+
+// toPosition converts the passed 1-based line and col, with col being
+// counted in runes, to a Position.
+//
+// If col exceeds the line's length, the position is placed at the end of
+// the line.
+func toPosition(lines []string, line, col int) Position {
+	if line < 1 {
+		return Position{}
+	} else if line > len(lines) {
+		return Position{Line: line - 1}
+	}
+
+	ln := lines[line-1]
+
+	var character int
+	for i := 1; i < col && ln != ""; i++ {
+		r, size := utf8.DecodeRuneInString(ln)
+		ln = ln[size:]
+		character += utf16.RuneLen(r)
+	}
+
+	return Position{Line: line - 1, Character: character}
+}
+
+// fromPosition converts the passed Position to a 1-based line and col,
+// with col being counted in runes.
+func fromPosition(lines []string, pos Position) (line, col int) {
+	line = pos.Line + 1
+	col = 1
+
+	if pos.Line < 0 || pos.Line >= len(lines) {
+		return line, col
+	}
+
+	ln := lines[pos.Line]
+
+	for character := 0; character < pos.Character && ln != ""; col++ {
+		r, size := utf8.DecodeRuneInString(ln)
+		ln = ln[size:]
+		character += utf16.RuneLen(r)
+	}
+
+	return line, col
+}
+
+// lineBefore returns the text of the line pos is located at, up to pos.
+func lineBefore(lines []string, pos Position) string {
+	line, col := fromPosition(lines, pos)
+	if line > len(lines) {
+		return ""
+	}
+
+	ln := lines[line-1]
+
+	var n int
+	for i := 1; i < col && n < len(ln); i++ {
+		_, size := utf8.DecodeRuneInString(ln[n:])
+		n += size
+	}
+
+	return ln[:n]
+}
+
+// ============================================================================
+// URIs
+// ======================================================================================
+
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+
+	if u.Scheme != "file" {
+		return "", errors.New("unsupported uri scheme: " + u.Scheme)
+	}
+
+	p := u.Path
+	// file:///C:/foo
+	if runtime.GOOS == "windows" && len(p) >= 3 && p[0] == '/' && p[2] == ':' {
+		p = p[1:]
+	}
+
+	return filepath.Clean(filepath.FromSlash(p)), nil
+}
+
+// uri returns the uri of the file located at the passed absolute path.
+//
+// If the file is opened, the uri used by the client is returned.
+func (s *Server) uri(p string) string {
+	for _, doc := range s.docs {
+		if doc.path == p {
+			return doc.uri
+		}
+	}
+
+	return pathToURI(p)
+}
+
+func pathToURI(p string) string {
+	p = filepath.ToSlash(p)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+
+	u := url.URL{Scheme: "file", Path: p}
+	return u.String()
+}
@@ -0,0 +1,53 @@
+package lsp
+
+import (
+	"unicode/utf8"
+
+	"github.com/mavolin/corgi/file"
+)
+
+// hover returns the signature and documentation of the mixin called or
+// declared at the passed position.
+func (s *Server) hover(params TextDocumentPositionParams) (*Hover, error) {
+	doc := s.docs[params.TextDocument.URI]
+	if doc == nil {
+		return nil, nil
+	}
+
+	line, col := fromPosition(doc.lines, params.Position)
+
+	if mc := mixinCallAt(doc.f, line, col); mc != nil {
+		decl := resolveMixinCall(doc, mc)
+		if decl == nil {
+			return nil, nil
+		}
+
+		start := mc.Name.Position
+		if mc.Namespace != nil {
+			start = mc.Namespace.Position
+		}
+
+		return &Hover{
+			Contents: MarkupContent{Kind: markupKindMarkdown, Value: decl.markdown()},
+			Range:    identRange(doc.lines, start, mc.Name),
+		}, nil
+	}
+
+	if m := mixinAt(doc.f, line, col); m != nil {
+		decl := mixinDecl{file: doc.f, mixin: m}
+		return &Hover{
+			Contents: MarkupContent{Kind: markupKindMarkdown, Value: decl.markdown()},
+			Range:    identRange(doc.lines, m.Name.Position, m.Name),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// identRange returns the range from start to the end of ident.
+func identRange(lines []string, start file.Position, ident file.Ident) *Range {
+	return &Range{
+		Start: toPosition(lines, start.Line, start.Col),
+		End:   toPosition(lines, ident.Line, ident.Col+utf8.RuneCountInString(ident.Ident)),
+	}
+}
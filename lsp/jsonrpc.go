@@ -0,0 +1,196 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// conn is a JSON-RPC 2.0 connection using the base protocol of the LSP,
+// i.e. messages prefixed by a Content-Length header.
+type conn struct {
+	r *textproto.Reader
+	// resync is set, if the last message was malformed and the rest of it
+	// must be skipped before reading the next message.
+	resync bool
+
+	wMut sync.Mutex
+	w    io.Writer
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	return &conn{r: textproto.NewReader(bufio.NewReader(r)), w: w}
+}
+
+// request is a request or notification sent by the client.
+//
+// Notifications have no ID.
+type request struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+func (r *request) isNotification() bool {
+	return len(r.ID) == 0
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (err *responseError) Error() string {
+	return err.Message
+}
+
+// Error codes as defined by JSON-RPC and the LSP.
+const (
+	codeParseError           = -32700
+	codeMethodNotFound       = -32601
+	codeInvalidParams        = -32602
+	codeInternalError        = -32603
+	codeServerNotInitialized = -32002
+)
+
+// contentLengthHeader is the name of the header that starts each message,
+// followed by a colon.
+const contentLengthHeader = "Content-Length:"
+
+// read reads the next message from the connection.
+//
+// If the message is malformed, read returns a *responseError, and the next
+// call to read skips the rest of the message before reading the message
+// after it.
+func (c *conn) read() (*request, error) {
+	if c.resync {
+		if err := c.skipMessage(); err != nil {
+			return nil, err
+		}
+		c.resync = false
+	}
+
+	header, err := c.r.ReadMIMEHeader()
+	if err != nil {
+		var perr textproto.ProtocolError
+		if !errors.As(err, &perr) {
+			return nil, err
+		}
+
+		c.resync = true
+		return nil, &responseError{Code: codeParseError, Message: "invalid header: " + err.Error()}
+	}
+
+	n, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || n < 0 {
+		c.resync = true
+		return nil, &responseError{
+			Code:    codeParseError,
+			Message: "invalid Content-Length: " + strconv.Quote(header.Get("Content-Length")),
+		}
+	}
+
+	body := make([]byte, n)
+	if _, err := io.ReadFull(c.r.R, body); err != nil {
+		return nil, err
+	}
+
+	var req request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, &responseError{Code: codeParseError, Message: err.Error()}
+	}
+
+	return &req, nil
+}
+
+// skipMessage discards the input up to the start of the next message.
+//
+// Since the length of the skipped message is unknown, the next message is
+// found by looking for its Content-Length header.
+func (c *conn) skipMessage() error {
+	for matched := 0; matched < len(contentLengthHeader); {
+		b, err := c.r.R.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case toLowerASCII(b) == toLowerASCII(contentLengthHeader[matched]):
+			matched++
+		case toLowerASCII(b) == toLowerASCII(contentLengthHeader[0]):
+			matched = 1
+		default:
+			matched = 0
+		}
+	}
+
+	// put back the header we just consumed
+	r := io.MultiReader(strings.NewReader(contentLengthHeader), c.r.R)
+	c.r = textproto.NewReader(bufio.NewReader(r))
+	return nil
+}
+
+func toLowerASCII(b byte) byte {
+	if 'A' <= b && b <= 'Z' {
+		return b + ('a' - 'A')
+	}
+
+	return b
+}
+
+// reply sends the response to the request with the passed id.
+//
+// If err is not nil, result is ignored.
+// If err is not a *responseError, it is reported as internal error.
+func (c *conn) reply(id json.RawMessage, result any, err error) error {
+	if err != nil {
+		var rerr *responseError
+		if !errors.As(err, &rerr) {
+			rerr = &responseError{Code: codeInternalError, Message: err.Error()}
+		}
+
+		return c.write(struct {
+			JSONRPC string          `json:"jsonrpc"`
+			ID      json.RawMessage `json:"id"`
+			Error   *responseError  `json:"error"`
+		}{JSONRPC: "2.0", ID: id, Error: rerr})
+	}
+
+	return c.write(struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Result  any             `json:"result"`
+	}{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+// notify sends a notification to the client.
+func (c *conn) notify(method string, params any) error {
+	return c.write(struct {
+		JSONRPC string `json:"jsonrpc"`
+		Method  string `json:"method"`
+		Params  any    `json:"params"`
+	}{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (c *conn) write(msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	c.wMut.Lock()
+	defer c.wMut.Unlock()
+
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n", len(data)); err != nil {
+		return err
+	}
+
+	_, err = c.w.Write(data)
+	return err
+}
@@ -0,0 +1,237 @@
+// Package lsp implements a language server for corgi files, speaking the
+// Language Server Protocol.
+//
+// The server supports publishing diagnostics, going to the definition of
+// called mixins, completion of mixin names, mixin args, and template block
+// names, as well as showing the signature and documentation of mixins on
+// hover.
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/mavolin/corgi"
+	"github.com/mavolin/corgi/internal/meta"
+)
+
+// Server is a corgi language server.
+//
+// A Server handles a single connection, and cannot be reused once Serve
+// returned.
+type Server struct {
+	conn   *conn
+	loader *corgi.Loader
+
+	// overlayMut guards overlay.
+	//
+	// It is the only mutex needed, as all messages are handled sequentially,
+	// however, the loader may read files concurrently.
+	overlayMut sync.RWMutex
+	// overlay contains the contents of all opened documents, by their
+	// absolute system path.
+	overlay  map[string][]byte
+	readFile func(sysPath string) ([]byte, error)
+
+	docs map[string]*document
+
+	initialized bool
+	shutdown    bool
+}
+
+// ErrExitWithoutShutdown is returned by [Server.Serve], if the client sent
+// an exit notification before requesting a shutdown.
+var ErrExitWithoutShutdown = errors.New("lsp: received exit notification without prior shutdown request")
+
+// New creates a new *Server that loads files using the passed options.
+//
+// If o.ReadFile is set, it is used to read all files not opened in the
+// client.
+func New(o corgi.LoadOptions) (*Server, error) {
+	s := &Server{
+		overlay:  make(map[string][]byte),
+		readFile: o.ReadFile,
+		docs:     make(map[string]*document),
+	}
+	if s.readFile == nil {
+		s.readFile = os.ReadFile
+	}
+
+	o.ReadFile = s.readOverlay
+
+	var err error
+	s.loader, err = corgi.NewLoader(o)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Serve serves the client connected through r and w, until the client
+// sends an exit notification, or r returns an error.
+//
+// If r returns io.EOF, Serve returns nil.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+
+	for {
+		req, err := s.conn.read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			var rerr *responseError
+			if errors.As(err, &rerr) {
+				if err := s.conn.reply(json.RawMessage("null"), nil, rerr); err != nil {
+					return err
+				}
+				continue
+			}
+
+			return err
+		}
+
+		if req.Method == "exit" {
+			if !s.shutdown {
+				return ErrExitWithoutShutdown
+			}
+
+			return nil
+		}
+
+		result, err := s.handle(req)
+		if req.isNotification() {
+			if err != nil {
+				s.logError(req.Method + ": " + err.Error())
+			}
+			continue
+		}
+
+		if err := s.conn.reply(req.ID, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+// handle handles the passed request or notification, and returns its
+// result.
+func (s *Server) handle(req *request) (any, error) {
+	if !s.initialized && req.Method != "initialize" {
+		if req.isNotification() {
+			return nil, nil
+		}
+
+		return nil, &responseError{Code: codeServerNotInitialized, Message: "server not initialized"}
+	}
+
+	switch req.Method {
+	case "initialize":
+		var params InitializeParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return s.initialize(params)
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didOpen(params)
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didChange(params)
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.didClose(params)
+
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return s.definition(params)
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return s.completion(params)
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(req, &params); err != nil {
+			return nil, err
+		}
+		return s.hover(params)
+	}
+
+	if req.isNotification() {
+		// notifications we don't know, such as $/cancelRequest, can safely
+		// be ignored
+		return nil, nil
+	}
+
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not supported: " + req.Method}
+}
+
+func unmarshalParams(req *request, v any) error {
+	if err := json.Unmarshal(req.Params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	return nil
+}
+
+func (s *Server) initialize(InitializeParams) (*InitializeResult, error) {
+	s.initialized = true
+
+	return &InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync: TextDocumentSyncOptions{
+				OpenClose: true,
+				Change:    TextDocumentSyncKindFull,
+			},
+			DefinitionProvider: true,
+			HoverProvider:      true,
+			CompletionProvider: &CompletionOptions{TriggerCharacters: []string{"+", ".", "(", ",", " "}},
+		},
+		ServerInfo: &ServerInfo{Name: "corgi", Version: meta.Version},
+	}, nil
+}
+
+// logError sends the passed error message to the client to be logged.
+func (s *Server) logError(msg string) {
+	_ = s.conn.notify("window/logMessage", struct {
+		Type    int    `json:"type"`
+		Message string `json:"message"`
+	}{Type: 1, Message: msg})
+}
+
+// readOverlay reads the file located at the passed path, preferring the
+// contents of opened documents over those on disk.
+func (s *Server) readOverlay(sysPath string) ([]byte, error) {
+	s.overlayMut.RLock()
+	data, ok := s.overlay[sysPath]
+	s.overlayMut.RUnlock()
+	if ok {
+		return data, nil
+	}
+
+	return s.readFile(sysPath)
+}
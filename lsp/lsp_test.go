@@ -0,0 +1,476 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi"
+)
+
+// testClient is an in-process LSP client connected to a Server.
+type testClient struct {
+	t *testing.T
+
+	w      io.WriteCloser
+	msgs   chan message
+	done   chan error
+	nextID int
+}
+
+// message is a message sent by the server.
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+// newTestClient starts a Server for a module located in a temporary
+// directory, and returns a client connected to it, along with the
+// directory.
+func newTestClient(t *testing.T) (*testClient, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/lsptest\n\ngo 1.19\n"), 0o644)
+	require.NoError(t, err)
+
+	goExec, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not found in $PATH")
+	}
+
+	s, err := New(corgi.LoadOptions{GoExecPath: goExec})
+	require.NoError(t, err)
+
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+
+	c := &testClient{
+		t:    t,
+		w:    clientW,
+		msgs: make(chan message, 64),
+		done: make(chan error, 1),
+	}
+
+	go func() {
+		err := s.Serve(serverR, serverW)
+		_ = serverW.Close()
+		c.done <- err
+	}()
+
+	go func() {
+		defer close(c.msgs)
+
+		r := textproto.NewReader(bufio.NewReader(clientR))
+		for {
+			header, err := r.ReadMIMEHeader()
+			if err != nil {
+				return
+			}
+
+			n, err := strconv.Atoi(header.Get("Content-Length"))
+			if err != nil {
+				return
+			}
+
+			body := make([]byte, n)
+			if _, err := io.ReadFull(r.R, body); err != nil {
+				return
+			}
+
+			var msg message
+			if err := json.Unmarshal(body, &msg); err != nil {
+				return
+			}
+
+			c.msgs <- msg
+		}
+	}()
+
+	t.Cleanup(func() {
+		_ = clientW.Close()
+		_ = clientR.Close()
+	})
+
+	return c, dir
+}
+
+func (c *testClient) send(msg any) {
+	c.t.Helper()
+
+	data, err := json.Marshal(msg)
+	require.NoError(c.t, err)
+
+	c.sendRaw(fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(data), data))
+}
+
+func (c *testClient) sendRaw(s string) {
+	c.t.Helper()
+
+	_, err := io.WriteString(c.w, s)
+	require.NoError(c.t, err)
+}
+
+// notify sends a notification with the passed params.
+func (c *testClient) notify(method string, params any) {
+	c.t.Helper()
+
+	c.send(struct {
+		JSONRPC string `json:"jsonrpc"`
+		Method  string `json:"method"`
+		Params  any    `json:"params"`
+	}{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// call sends a request with the passed params, and returns its response.
+//
+// Notifications received while waiting for the response are dropped.
+func (c *testClient) call(method string, params any) message {
+	c.t.Helper()
+
+	c.nextID++
+	id := json.RawMessage(strconv.Itoa(c.nextID))
+
+	c.send(struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Method  string          `json:"method"`
+		Params  any             `json:"params"`
+	}{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+
+	for {
+		msg := c.receive()
+		if string(msg.ID) == string(id) {
+			return msg
+		}
+	}
+}
+
+// receive returns the next message sent by the server.
+func (c *testClient) receive() message {
+	c.t.Helper()
+
+	select {
+	case msg, ok := <-c.msgs:
+		require.True(c.t, ok, "server closed the connection")
+		return msg
+	case <-time.After(10 * time.Second):
+		require.FailNow(c.t, "timed out waiting for message")
+		return message{}
+	}
+}
+
+// initialize performs the initialization handshake.
+func (c *testClient) initialize() InitializeResult {
+	c.t.Helper()
+
+	resp := c.call("initialize", InitializeParams{})
+	require.Nil(c.t, resp.Error)
+	c.notify("initialized", struct{}{})
+
+	var result InitializeResult
+	require.NoError(c.t, json.Unmarshal(resp.Result, &result))
+	return result
+}
+
+// open opens the document with the passed name and text, and returns its
+// uri along with the diagnostics published for it.
+func (c *testClient) open(dir, name, text string) (string, PublishDiagnosticsParams) {
+	c.t.Helper()
+
+	uri := pathToURI(filepath.Join(dir, name))
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, LanguageID: "corgi", Version: 1, Text: text},
+	})
+
+	for {
+		msg := c.receive()
+		if msg.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+
+		var params PublishDiagnosticsParams
+		require.NoError(c.t, json.Unmarshal(msg.Params, &params))
+		if params.URI == uri {
+			return uri, params
+		}
+	}
+}
+
+func position(uri string, line, character int) TextDocumentPositionParams {
+	return TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     Position{Line: line, Character: character},
+	}
+}
+
+// ============================================================================
+// Tests
+// ======================================================================================
+
+const testDoc = `func Page()
+
+// greet greets someone.
+mixin greet(greeting string = "Hello", name string)
+  p #{greeting}, #{name}!
+
++greet(greeting="😀👋", name="world")
++`
+
+func TestServer_Initialize(t *testing.T) {
+	t.Parallel()
+
+	c, _ := newTestClient(t)
+
+	result := c.initialize()
+	assert.True(t, result.Capabilities.HoverProvider)
+	assert.True(t, result.Capabilities.DefinitionProvider)
+	assert.True(t, result.Capabilities.TextDocumentSync.OpenClose)
+	assert.Equal(t, TextDocumentSyncKindFull, result.Capabilities.TextDocumentSync.Change)
+	require.NotNil(t, result.Capabilities.CompletionProvider)
+	require.NotNil(t, result.ServerInfo)
+	assert.Equal(t, "corgi", result.ServerInfo.Name)
+}
+
+func TestServer_NotInitialized(t *testing.T) {
+	t.Parallel()
+
+	c, _ := newTestClient(t)
+
+	resp := c.call("textDocument/hover", position("file:///page.corgi", 0, 0))
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, codeServerNotInitialized, resp.Error.Code)
+}
+
+func TestServer_Exit(t *testing.T) {
+	t.Parallel()
+
+	c, _ := newTestClient(t)
+	c.initialize()
+
+	resp := c.call("shutdown", nil)
+	assert.Nil(t, resp.Error)
+	c.notify("exit", nil)
+
+	select {
+	case err := <-c.done:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "timed out waiting for Serve to return")
+	}
+}
+
+func TestServer_DidOpen(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no errors", func(t *testing.T) {
+		t.Parallel()
+
+		c, dir := newTestClient(t)
+		c.initialize()
+
+		_, diags := c.open(dir, "page.corgi", strings.TrimSuffix(testDoc, "+"))
+		assert.Empty(t, diags.Diagnostics)
+		require.NotNil(t, diags.Version)
+		assert.Equal(t, 1, *diags.Version)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		c, dir := newTestClient(t)
+		c.initialize()
+
+		_, diags := c.open(dir, "page.corgi", "func Page()\n\np 😀 #+nope()\n")
+		require.Len(t, diags.Diagnostics, 1)
+
+		diag := diags.Diagnostics[0]
+		assert.Equal(t, "C0055", diag.Code)
+		assert.Equal(t, DiagnosticSeverityError, diag.Severity)
+		assert.Equal(t, "corgi", diag.Source)
+		// the emoji counts as two UTF-16 code units
+		assert.Equal(t, Range{
+			Start: Position{Line: 2, Character: 7},
+			End:   Position{Line: 2, Character: 11},
+		}, diag.Range)
+	})
+
+	t.Run("fixed by change", func(t *testing.T) {
+		t.Parallel()
+
+		c, dir := newTestClient(t)
+		c.initialize()
+
+		uri, diags := c.open(dir, "page.corgi", "func Page()\n\n+nope()\n")
+		require.Len(t, diags.Diagnostics, 1)
+
+		c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+			TextDocument:   VersionedTextDocumentIdentifier{URI: uri, Version: 2},
+			ContentChanges: []TextDocumentContentChangeEvent{{Text: "func Page()\n\np ok\n"}},
+		})
+
+		for {
+			msg := c.receive()
+			if msg.Method != "textDocument/publishDiagnostics" {
+				continue
+			}
+
+			require.NoError(t, json.Unmarshal(msg.Params, &diags))
+			if diags.URI == uri {
+				break
+			}
+		}
+
+		assert.Empty(t, diags.Diagnostics)
+		require.NotNil(t, diags.Version)
+		assert.Equal(t, 2, *diags.Version)
+	})
+}
+
+func TestServer_Hover(t *testing.T) {
+	t.Parallel()
+
+	c, dir := newTestClient(t)
+	c.initialize()
+
+	uri, _ := c.open(dir, "page.corgi", testDoc)
+
+	t.Run("call", func(t *testing.T) {
+		resp := c.call("textDocument/hover", position(uri, 6, 3))
+		require.Nil(t, resp.Error)
+
+		var hover Hover
+		require.NoError(t, json.Unmarshal(resp.Result, &hover))
+		assert.Equal(t, markupKindMarkdown, hover.Contents.Kind)
+		assert.Contains(t, hover.Contents.Value, `mixin greet(greeting string = "…", name string)`)
+		assert.Contains(t, hover.Contents.Value, "greet greets someone.")
+		require.NotNil(t, hover.Range)
+		assert.Equal(t, Range{
+			Start: Position{Line: 6, Character: 1},
+			End:   Position{Line: 6, Character: 6},
+		}, *hover.Range)
+	})
+
+	t.Run("declaration", func(t *testing.T) {
+		resp := c.call("textDocument/hover", position(uri, 3, 8))
+		require.Nil(t, resp.Error)
+
+		var hover Hover
+		require.NoError(t, json.Unmarshal(resp.Result, &hover))
+		assert.Contains(t, hover.Contents.Value, "mixin greet(")
+	})
+
+	t.Run("nothing", func(t *testing.T) {
+		resp := c.call("textDocument/hover", position(uri, 0, 0))
+		require.Nil(t, resp.Error)
+		assert.Equal(t, "null", string(resp.Result))
+	})
+}
+
+func TestServer_Definition(t *testing.T) {
+	t.Parallel()
+
+	c, dir := newTestClient(t)
+	c.initialize()
+
+	uri, _ := c.open(dir, "page.corgi", testDoc)
+
+	t.Run("mixin", func(t *testing.T) {
+		resp := c.call("textDocument/definition", position(uri, 6, 2))
+		require.Nil(t, resp.Error)
+
+		var loc Location
+		require.NoError(t, json.Unmarshal(resp.Result, &loc))
+		assert.Equal(t, uri, loc.URI)
+		assert.Equal(t, 3, loc.Range.Start.Line)
+		assert.Equal(t, 6, loc.Range.Start.Character)
+	})
+
+	t.Run("arg after emojis", func(t *testing.T) {
+		// `+greet(greeting="😀👋", ` is 21 runes, but 23 UTF-16 code units
+		// long
+		resp := c.call("textDocument/definition", position(uri, 6, 24))
+		require.Nil(t, resp.Error)
+
+		var loc Location
+		require.NoError(t, json.Unmarshal(resp.Result, &loc))
+		assert.Equal(t, uri, loc.URI)
+		assert.Equal(t, Range{
+			Start: Position{Line: 3, Character: 39},
+			End:   Position{Line: 3, Character: 43},
+		}, loc.Range)
+	})
+}
+
+func TestServer_Completion(t *testing.T) {
+	t.Parallel()
+
+	c, dir := newTestClient(t)
+	c.initialize()
+
+	uri, _ := c.open(dir, "page.corgi", testDoc)
+
+	labels := func(t *testing.T, resp message) []string {
+		t.Helper()
+		require.Nil(t, resp.Error)
+
+		var list CompletionList
+		require.NoError(t, json.Unmarshal(resp.Result, &list))
+
+		labels := make([]string, len(list.Items))
+		for i, itm := range list.Items {
+			labels[i] = itm.Label
+		}
+		return labels
+	}
+
+	t.Run("mixins", func(t *testing.T) {
+		resp := c.call("textDocument/completion", position(uri, 7, 1))
+		assert.Contains(t, labels(t, resp), "greet")
+	})
+
+	t.Run("args", func(t *testing.T) {
+		// completes the args not given yet, after `+greet(greeting="😀👋", `
+		c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+			TextDocument: VersionedTextDocumentIdentifier{URI: uri, Version: 2},
+			ContentChanges: []TextDocumentContentChangeEvent{
+				{Text: strings.TrimSuffix(testDoc, "+") + `+greet(greeting="😀👋", `},
+			},
+		})
+
+		resp := c.call("textDocument/completion", position(uri, 7, 23))
+		assert.Equal(t, []string{"name"}, labels(t, resp))
+	})
+}
+
+func TestServer_MalformedMessage(t *testing.T) {
+	t.Parallel()
+
+	c, _ := newTestClient(t)
+
+	c.sendRaw("Content-Length: abc\r\n\r\n{\"jsonrpc\":\"2.0\",\"id\":99,\"method\":\"initialize\"}")
+
+	msg := c.receive()
+	require.NotNil(t, msg.Error)
+	assert.Equal(t, codeParseError, msg.Error.Code)
+	assert.Equal(t, "null", string(msg.ID))
+
+	// the server keeps serving
+	result := c.initialize()
+	assert.True(t, result.Capabilities.HoverProvider)
+}
@@ -0,0 +1,532 @@
+package lsp
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mavolin/corgi/file"
+	"github.com/mavolin/corgi/file/fileutil"
+)
+
+// mixinDecl is the declaration of a mixin available to a document.
+type mixinDecl struct {
+	// namespace is the namespace under which the mixin is available, or
+	// empty, if the mixin can be called without one.
+	namespace string
+
+	// file is the file in which the mixin is declared.
+	//
+	// Note that its scope may be empty, if the mixin was precompiled.
+	file  *file.File
+	mixin *file.Mixin
+}
+
+// ============================================================================
+// Available Mixins
+// ======================================================================================
+
+// availableMixins returns all mixins that may be called from the top-level
+// scope of doc.
+func availableMixins(doc *document) []mixinDecl {
+	var decls []mixinDecl
+
+	decls = append(decls, scopeMixins("", doc.f, doc.f.Scope)...)
+
+	dirLib := doc.f.DirLibrary
+	if dirLib == nil {
+		dirLib = doc.dirLib
+	}
+	if dirLib != nil {
+		decls = append(decls, libraryMixins("", dirLib, doc.path)...)
+	}
+
+	lib := doc.f.Library
+	if lib == nil {
+		lib = doc.lib
+	}
+	if lib != nil {
+		decls = append(decls, libraryMixins("", lib, doc.path)...)
+	}
+
+	for _, use := range doc.f.Uses {
+		for _, spec := range use.Uses {
+			lib := spec.Library
+			if lib == nil {
+				lib = doc.uses[spec.Path.Contents]
+			}
+			if lib == nil {
+				continue
+			}
+
+			decls = append(decls, libraryMixins(useNamespace(spec), lib, "")...)
+		}
+	}
+
+	return decls
+}
+
+// useNamespace returns the namespace under which the mixins of the library
+// used through spec are available.
+//
+// If they are available without a namespace, useNamespace returns an empty
+// string.
+func useNamespace(spec file.UseSpec) string {
+	if spec.Alias == nil {
+		return path.Base(spec.Path.Contents)
+	} else if spec.Alias.Ident == "." {
+		return ""
+	}
+
+	return spec.Alias.Ident
+}
+
+// libraryMixins returns the mixins of lib, excluding those from the file
+// with the absolute path exclude.
+func libraryMixins(namespace string, lib *file.Library, exclude string) []mixinDecl {
+	if lib.Precompiled {
+		decls := make([]mixinDecl, len(lib.Mixins))
+		for i := range lib.Mixins {
+			decls[i] = mixinDecl{namespace: namespace, file: lib.Mixins[i].File, mixin: &lib.Mixins[i].Mixin}
+		}
+
+		return decls
+	}
+
+	var decls []mixinDecl
+	for _, f := range lib.Files {
+		if exclude != "" && f.AbsolutePath == exclude {
+			continue
+		}
+
+		decls = append(decls, scopeMixins(namespace, f, f.Scope)...)
+	}
+
+	return decls
+}
+
+func scopeMixins(namespace string, f *file.File, s file.Scope) []mixinDecl {
+	var decls []mixinDecl
+	for _, itm := range s {
+		if m, ok := itm.(file.Mixin); ok {
+			m := m
+			decls = append(decls, mixinDecl{namespace: namespace, file: f, mixin: &m})
+		}
+	}
+
+	return decls
+}
+
+// resolveMixinCall returns the declaration of the mixin called by mc.
+//
+// If mc is not linked, resolveMixinCall attempts to find the mixin in the
+// mixins available to doc.
+func resolveMixinCall(doc *document, mc *file.MixinCall) *mixinDecl {
+	var namespace string
+	if mc.Namespace != nil {
+		namespace = mc.Namespace.Ident
+	}
+
+	if mc.Mixin != nil {
+		return &mixinDecl{namespace: namespace, file: mc.Mixin.File, mixin: mc.Mixin.Mixin}
+	}
+
+	for _, decl := range availableMixins(doc) {
+		if decl.namespace == namespace && decl.mixin.Name.Ident == mc.Name.Ident {
+			decl := decl
+			return &decl
+		}
+	}
+
+	return nil
+}
+
+// location returns the location of the declaration of decl's mixin.
+//
+// If the location is unknown, location returns false.
+func (decl mixinDecl) location(s *Server) (Location, bool) {
+	return decl.positionLocation(s, decl.mixin.Name.Position, utf8.RuneCountInString(decl.mixin.Name.Ident))
+}
+
+func (decl mixinDecl) positionLocation(s *Server, pos file.Position, length int) (Location, bool) {
+	p := decl.file.AbsolutePath
+	if p == "" {
+		// precompiled
+		if decl.file.Library == nil || decl.file.Library.AbsolutePath == "" {
+			return Location{}, false
+		}
+
+		p = filepath.Join(decl.file.Library.AbsolutePath, decl.file.Name)
+	}
+
+	return Location{
+		URI: s.uri(p),
+		Range: Range{
+			Start: toPosition(decl.file.Lines, pos.Line, pos.Col),
+			End:   toPosition(decl.file.Lines, pos.Line, pos.Col+length),
+		},
+	}, true
+}
+
+// ============================================================================
+// Signature and Documentation
+// ======================================================================================
+
+// signature returns the signature of decl's mixin, as it would be written in
+// corgi.
+func (decl mixinDecl) signature() string {
+	var sb strings.Builder
+
+	sb.WriteString("mixin ")
+	if decl.namespace != "" {
+		sb.WriteString(decl.namespace)
+		sb.WriteByte('.')
+	}
+	sb.WriteString(decl.mixin.Name.Ident)
+
+	if len(decl.mixin.TypeParams) > 0 {
+		sb.WriteByte('[')
+		for i, tp := range decl.mixin.TypeParams {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+
+			sb.WriteString(tp.Name.Ident + " " + tp.Constraint.Type)
+		}
+		sb.WriteByte(']')
+	}
+
+	sb.WriteByte('(')
+	for i, param := range decl.mixin.Params {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+
+		sb.WriteString(paramSignature(param))
+	}
+	sb.WriteByte(')')
+
+	return sb.String()
+}
+
+func paramSignature(param file.MixinParam) string {
+	s := param.Name.Ident
+	if param.Variadic {
+		s += " ..." + param.Type.Type
+	} else if param.Type != nil {
+		s += " " + param.Type.Type
+	} else if param.InferredType != "" {
+		s += " " + param.InferredType
+	}
+
+	if param.Default != nil {
+		s += " = " + expressionString(*param.Default)
+	}
+
+	return s
+}
+
+// expressionString returns a short string representation of the passed
+// expression.
+//
+// Only Go expressions are printed as is, all other expression items are
+// abbreviated.
+func expressionString(e file.Expression) string {
+	var sb strings.Builder
+	for _, itm := range e.Expressions {
+		switch itm := itm.(type) {
+		case file.GoExpression:
+			sb.WriteString(itm.Expression)
+		case file.StringExpression:
+			sb.WriteString(string(itm.Quote) + "…" + string(itm.Quote))
+		default:
+			sb.WriteString("…")
+		}
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// doc returns the documentation of decl's mixin, i.e. the corgi comments
+// directly preceding it, excluding machine comments.
+func (decl mixinDecl) doc() string {
+	comments := decl.file.TopLevelComments
+
+	_ = fileutil.Walk(decl.file.Scope, func(_ []fileutil.WalkContext, ctx fileutil.WalkContext) (bool, error) {
+		m, ok := (*ctx.Item).(file.Mixin)
+		if !ok {
+			return true, nil
+		}
+
+		if m.Position != decl.mixin.Position {
+			return true, nil
+		}
+
+		if len(ctx.Comments) > 0 {
+			comments = ctx.Comments
+		}
+		return false, fileutil.StopWalk
+	})
+
+	// only use the comments directly above the mixin
+	var lines []string
+	line := decl.mixin.Line
+	for i := len(comments) - 1; i >= 0; i-- {
+		c := comments[i]
+		if mc := fileutil.ParseMachineComment(c); mc != nil && mc.Namespace != "" {
+			line = c.Line
+			continue
+		}
+
+		for j := len(c.Lines) - 1; j >= 0; j-- {
+			if c.Lines[j].Line != line-1 {
+				break
+			}
+
+			lines = append(lines, strings.TrimPrefix(c.Lines[j].Comment, " "))
+			line--
+		}
+
+		if line != c.Line {
+			break
+		}
+	}
+
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// markdown returns a markdown description of decl's mixin, used for hovers
+// and completion items.
+func (decl mixinDecl) markdown() string {
+	var sb strings.Builder
+
+	sb.WriteString("```corgi\n")
+	sb.WriteString(decl.signature())
+	sb.WriteString("\n```")
+
+	if doc := decl.doc(); doc != "" {
+		sb.WriteString("\n\n")
+		sb.WriteString(doc)
+	}
+
+	if blocks := mixinBlockSignatures(decl.mixin); len(blocks) > 0 {
+		sb.WriteString("\n\nBlocks: `")
+		sb.WriteString(strings.Join(blocks, "`, `"))
+		sb.WriteByte('`')
+	}
+
+	return sb.String()
+}
+
+// mixinBlockSignatures returns the names of the blocks of m, followed by
+// their params, if they have any.
+func mixinBlockSignatures(m *file.Mixin) []string {
+	if m.MixinInfo == nil {
+		return mixinBlocks(m)
+	}
+
+	sigs := make([]string, len(m.Blocks))
+	for i, b := range m.Blocks {
+		if len(b.Params) == 0 {
+			sigs[i] = b.Name
+			continue
+		}
+
+		params := make([]string, len(b.Params))
+		for j, param := range b.Params {
+			params[j] = param.Name.Ident
+			if param.Type != nil {
+				params[j] += " " + param.Type.Type
+			}
+		}
+
+		sigs[i] = b.Name + "(" + strings.Join(params, ", ") + ")"
+	}
+
+	return sigs
+}
+
+// mixinBlocks returns the names of the blocks of m.
+func mixinBlocks(m *file.Mixin) []string {
+	if m.MixinInfo != nil {
+		names := make([]string, len(m.Blocks))
+		for i, b := range m.Blocks {
+			names[i] = b.Name
+		}
+
+		return names
+	}
+
+	// not linked yet
+
+	var names []string
+	_ = fileutil.Walk(m.Body, func(_ []fileutil.WalkContext, ctx fileutil.WalkContext) (bool, error) {
+		switch itm := (*ctx.Item).(type) {
+		case file.Block:
+			for _, name := range names {
+				if name == itm.Name.Ident {
+					return false, nil
+				}
+			}
+
+			names = append(names, itm.Name.Ident)
+			return false, nil
+		case file.Mixin, file.MixinCall:
+			return false, nil
+		default:
+			return true, nil
+		}
+	})
+
+	return names
+}
+
+// ============================================================================
+// Mixin Calls
+// ======================================================================================
+
+// walkMixinCalls calls fn for all mixin calls in f, until fn returns false.
+func walkMixinCalls(f *file.File, fn func(mc *file.MixinCall) bool) {
+	_ = fileutil.Walk(f.Scope, func(_ []fileutil.WalkContext, ctx fileutil.WalkContext) (bool, error) {
+		for _, mc := range mixinCalls(ctx.Scope, ctx.Index) {
+			if !fn(mc) {
+				return false, fileutil.StopWalk
+			}
+		}
+
+		return true, nil
+	})
+}
+
+// mixinCallAt returns the mixin call, whose namespace or name is located at
+// the passed 1-based line and col.
+func mixinCallAt(f *file.File, line, col int) *file.MixinCall {
+	var found *file.MixinCall
+
+	walkMixinCalls(f, func(mc *file.MixinCall) bool {
+		start := mc.Name.Position
+		if mc.Namespace != nil {
+			start = mc.Namespace.Position
+		}
+
+		if start.Line == line && mc.Name.Line == line &&
+			col >= start.Col && col <= mc.Name.Col+utf8.RuneCountInString(mc.Name.Ident) {
+			found = mc
+			return false
+		}
+
+		return true
+	})
+
+	return found
+}
+
+// mixinArgAt returns the mixin argument, whose name is located at the
+// passed 1-based line and col, alongside the mixin call it belongs to.
+func mixinArgAt(f *file.File, line, col int) (*file.MixinCall, *file.MixinArg) {
+	var (
+		foundCall *file.MixinCall
+		foundArg  *file.MixinArg
+	)
+
+	walkMixinCalls(f, func(mc *file.MixinCall) bool {
+		for i, arg := range mc.Args {
+			if arg.Name.Line == line &&
+				col >= arg.Name.Col && col <= arg.Name.Col+utf8.RuneCountInString(arg.Name.Ident) {
+				foundCall, foundArg = mc, &mc.Args[i]
+				return false
+			}
+		}
+
+		return true
+	})
+
+	return foundCall, foundArg
+}
+
+// mixinAt returns the mixin declared in f, whose name is located at the
+// passed 1-based line and col.
+func mixinAt(f *file.File, line, col int) *file.Mixin {
+	var found *file.Mixin
+
+	_ = fileutil.Walk(f.Scope, func(_ []fileutil.WalkContext, ctx fileutil.WalkContext) (bool, error) {
+		m, ok := (*ctx.Item).(file.Mixin)
+		if !ok {
+			return true, nil
+		}
+
+		if m.Name.Line == line && col >= m.Name.Col && col <= m.Name.Col+utf8.RuneCountInString(m.Name.Ident) {
+			found = &m
+			return false, fileutil.StopWalk
+		}
+
+		return true, nil
+	})
+
+	return found
+}
+
+// mixinCalls returns pointers to all mixin calls made directly by the item
+// at s[i], i.e. the item itself, if it is a mixin call, or the mixin calls
+// in its attributes and text.
+func mixinCalls(s file.Scope, i int) []*file.MixinCall {
+	switch itm := s[i].(type) {
+	case file.MixinCall:
+		return []*file.MixinCall{&itm}
+	case file.InlineText:
+		return mixinCallsInText(itm.Text)
+	case file.ArrowBlock:
+		return mixinCallsInText(itm.Lines...)
+	case file.Element:
+		return mixinCallsInAttributeCollections(itm.Attributes)
+	case file.DivShorthand:
+		return mixinCallsInAttributeCollections(itm.Attributes)
+	case file.And:
+		return mixinCallsInAttributeCollections(itm.Attributes)
+	default:
+		return nil
+	}
+}
+
+func mixinCallsInText(lns ...file.TextLine) []*file.MixinCall {
+	var mcs []*file.MixinCall
+
+	for _, ln := range lns {
+		for _, txtItm := range ln {
+			switch txtItm := txtItm.(type) {
+			case file.MixinCallInterpolation:
+				mc := txtItm.MixinCall
+				mcs = append(mcs, &mc)
+			case file.ElementInterpolation:
+				mcs = append(mcs, mixinCallsInAttributeCollections(txtItm.Element.Attributes)...)
+			}
+		}
+	}
+
+	return mcs
+}
+
+func mixinCallsInAttributeCollections(acolls []file.AttributeCollection) []*file.MixinCall {
+	var mcs []*file.MixinCall
+
+	for _, acoll := range acolls {
+		attrList, ok := acoll.(file.AttributeList)
+		if !ok {
+			continue
+		}
+
+		for _, attr := range attrList.Attributes {
+			if mcAttr, ok := attr.(file.MixinCallAttribute); ok {
+				mc := mcAttr.MixinCall
+				mcs = append(mcs, &mc)
+			}
+		}
+	}
+
+	return mcs
+}
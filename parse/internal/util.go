@@ -230,3 +230,40 @@ func chainExprItmsCheck(itms []file.ChainExpressionItem) bool {
 
 	return false
 }
+
+// ============================================================================
+// mixin.peg
+// ======================================================================================
+
+// spreadMixinArg checks if the passed mixin arg value ends with a `...`, and
+// if so, removes it.
+//
+// Since `...` does not terminate Go expressions, it will always be at the end
+// of the value's last expression.
+func spreadMixinArg(val file.Expression) (file.Expression, bool) {
+	if len(val.Expressions) == 0 {
+		return val, false
+	}
+
+	last := len(val.Expressions) - 1
+
+	goExpr, ok := val.Expressions[last].(file.GoExpression)
+	if !ok {
+		return val, false
+	}
+
+	trimmed := strings.TrimRight(goExpr.Expression, " \t")
+	if !strings.HasSuffix(trimmed, "...") {
+		return val, false
+	}
+
+	goExpr.Expression = strings.TrimSuffix(trimmed, "...")
+
+	exprs := make([]file.ExpressionItem, last, len(val.Expressions))
+	copy(exprs, val.Expressions)
+	if goExpr.Expression != "" {
+		exprs = append(exprs, goExpr)
+	}
+
+	return file.Expression{Expressions: exprs}, true
+}
@@ -60,6 +60,7 @@ func Parse(input []byte) (*file.File, error) {
 		if !ok {
 			corgierrList[i] = &corgierr.Error{
 				Message: err.Error(),
+				Code:    corgierr.CodeSyntax,
 				ErrorAnnotation: corgierr.Annotation{
 					ContextStart: 1,
 					ContextEnd:   2,
@@ -76,6 +77,10 @@ func Parse(input []byte) (*file.File, error) {
 			cerr = parserErrorToCorgiError(lines, parserErr)
 		}
 
+		if cerr.Code == "" {
+			cerr.Code = corgierr.CodeSyntax
+		}
+
 		cerr.ErrorAnnotation.File = f
 		for j := range cerr.HintAnnotations {
 			cerr.HintAnnotations[j].File = f
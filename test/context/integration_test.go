@@ -0,0 +1,31 @@
+//go:build integration_test && !prepare_integration_test
+
+package context
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi/test/internal/outcheck"
+)
+
+func TestContext(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "context.expect")
+	err := Context(context.Background(), w, []string{"a", "b"})
+	require.NoError(t, err)
+}
+
+func TestContext_Canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := Context(ctx, io.Discard, []string{"a", "b"})
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,14 @@
+//go:build prepare_integration_test
+
+package context
+
+import (
+	"testing"
+
+	"github.com/mavolin/corgi/test/internal/compile"
+)
+
+func TestContext(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "context.corgi", compile.Options{})
+}
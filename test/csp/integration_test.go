@@ -0,0 +1,28 @@
+//go:build integration_test && !prepare_integration_test
+
+package csp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi/test/internal/outcheck"
+	"github.com/mavolin/corgi/woof"
+)
+
+func TestCSP(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "csp.expect")
+
+	err := Page(w, "abc", "hello")
+	require.NoError(t, err)
+
+	expect := woof.CSPHashes{
+		Scripts: []string{woof.CSPHash(`console.log("static");`)},
+		Styles:  []string{woof.CSPHash(`p{color:red}`)},
+	}
+	assert.Equal(t, expect, PageCSPHashes())
+}
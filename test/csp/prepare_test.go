@@ -0,0 +1,14 @@
+//go:build prepare_integration_test
+
+package csp
+
+import (
+	"testing"
+
+	"github.com/mavolin/corgi/test/internal/compile"
+)
+
+func TestCSP(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "csp.corgi", compile.Options{})
+}
@@ -0,0 +1,138 @@
+//go:build integration_test && !prepare_integration_test
+
+package errors
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi/test/internal/outcheck"
+	"github.com/mavolin/corgi/woof"
+)
+
+const (
+	fileName         = "github.com/mavolin/corgi/test/errors/errors.corgi"
+	positionFileName = "github.com/mavolin/corgi/test/errors/position.corgi"
+	recoverFileName  = "github.com/mavolin/corgi/test/errors/recover.corgi"
+)
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "errors.expect")
+	err := Errors(w, "ok", false, 2)
+	require.NoError(t, err)
+}
+
+func TestErrors_Unprintable(t *testing.T) {
+	t.Parallel()
+
+	err := Errors(io.Discard, struct{}{}, false, 2)
+
+	var rerr *woof.RenderError
+	require.ErrorAs(t, err, &rerr)
+	assert.Equal(t, fileName, rerr.File)
+	assert.Equal(t, 7, rerr.Line)
+	assert.Equal(t, 8, rerr.Col)
+	assert.Equal(t, []woof.MixinCall{{Name: "item", File: fileName, Line: 13, Col: 5}}, rerr.MixinStack)
+
+	var uerr *woof.UnprintableValueError
+	assert.ErrorAs(t, err, &uerr)
+}
+
+func TestErrors_Return(t *testing.T) {
+	t.Parallel()
+
+	err := Errors(io.Discard, "ok", true, 2)
+
+	var rerr *woof.RenderError
+	require.ErrorAs(t, err, &rerr)
+	assert.Equal(t, fileName, rerr.File)
+	assert.Equal(t, 11, rerr.Line)
+	assert.Equal(t, 5, rerr.Col)
+	assert.Equal(t, []woof.MixinCall{{Name: "check", File: fileName, Line: 14, Col: 1}}, rerr.MixinStack)
+	assert.Equal(t, "check failed", errors.Unwrap(err).Error())
+	assert.Equal(t, fileName+":11:5: check failed (in mixin check called at "+fileName+":14:1)", err.Error())
+}
+
+func TestErrors_Panic(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() {
+		_ = Errors(io.Discard, "ok", false, -1)
+	})
+}
+
+func TestErrors_PanicRecovery(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := Errors(woof.WithBuffering(woof.WithPanicRecovery(&buf)), "ok", false, -1)
+
+	var rerr *woof.RenderError
+	require.ErrorAs(t, err, &rerr)
+	assert.Equal(t, fileName, rerr.File)
+	assert.Equal(t, 15, rerr.Line)
+	assert.Empty(t, rerr.MixinStack)
+
+	var perr *woof.PanicError
+	require.ErrorAs(t, err, &perr)
+	assert.Contains(t, perr.Value, "negative Repeat count")
+	assert.Contains(t, string(perr.Stack), "strings.Repeat")
+
+	assert.Zero(t, buf.Len(), "partially rendered output was written")
+}
+
+func TestPosition_AfterMixinCall(t *testing.T) {
+	t.Parallel()
+
+	err := Position(woof.WithPanicRecovery(io.Discard), nil)
+
+	var rerr *woof.RenderError
+	require.ErrorAs(t, err, &rerr)
+	assert.Equal(t, positionFileName, rerr.File)
+	assert.Equal(t, 10, rerr.Line)
+	assert.Equal(t, 3, rerr.Col)
+	assert.Empty(t, rerr.MixinStack)
+
+	var perr *woof.PanicError
+	assert.ErrorAs(t, err, &perr)
+}
+
+func TestRecover_NilDeref(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		where     string
+		line, col int
+	}{
+		{where: "code", line: 6, col: 5},
+		{where: "else if", line: 8, col: 9},
+		{where: "if", line: 11, col: 6},
+	}
+
+	for _, c := range testCases {
+		c := c
+		t.Run(c.where, func(t *testing.T) {
+			t.Parallel()
+
+			err := Recover(woof.WithPanicRecovery(io.Discard), nil, c.where)
+
+			var rerr *woof.RenderError
+			require.ErrorAs(t, err, &rerr)
+			assert.Equal(t, recoverFileName, rerr.File)
+			assert.Equal(t, c.line, rerr.Line)
+			assert.Equal(t, c.col, rerr.Col)
+			assert.Empty(t, rerr.MixinStack)
+
+			var perr *woof.PanicError
+			require.ErrorAs(t, err, &perr)
+			assert.Contains(t, perr.Error(), "nil pointer dereference")
+		})
+	}
+}
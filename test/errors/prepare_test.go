@@ -0,0 +1,24 @@
+//go:build prepare_integration_test
+
+package errors
+
+import (
+	"testing"
+
+	"github.com/mavolin/corgi/test/internal/compile"
+)
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "errors.corgi", compile.Options{})
+}
+
+func TestPosition(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "position.corgi", compile.Options{})
+}
+
+func TestRecover(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "recover.corgi", compile.Options{})
+}
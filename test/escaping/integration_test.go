@@ -36,3 +36,12 @@ func TestCSS(t *testing.T) {
 	err := CSS(w)
 	require.NoError(t, err)
 }
+
+func TestScriptTypes(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "script_types.expect")
+
+	err := ScriptTypes(w, "</script><script>alert(1)</script>")
+	require.NoError(t, err)
+}
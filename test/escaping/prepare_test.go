@@ -22,3 +22,8 @@ func TestCSS(t *testing.T) {
 	t.Parallel()
 	compile.Compile(t, "css.corgi", compile.Options{})
 }
+
+func TestScriptTypes(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "script_types.corgi", compile.Options{})
+}
@@ -25,3 +25,11 @@ func TestAppend(t *testing.T) {
 	err := Append(w)
 	require.NoError(t, err)
 }
+
+func TestCode(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "code.expect")
+	err := Code(w, "Corgi")
+	require.NoError(t, err)
+}
@@ -17,3 +17,8 @@ func TestAppend(t *testing.T) {
 	t.Parallel()
 	compile.Compile(t, "append.corgi", compile.Options{})
 }
+
+func TestCode(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "code.corgi", compile.Options{})
+}
@@ -0,0 +1,51 @@
+//go:build integration_test && !prepare_integration_test
+
+package flush
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi/test/internal/outcheck"
+	"github.com/mavolin/corgi/woof"
+)
+
+// chunkWriter records the chunks written to it, and the number of bytes
+// written before each flush.
+type chunkWriter struct {
+	chunks  []string
+	flushes []int
+	n       int
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.chunks = append(w.chunks, string(p))
+	w.n += len(p)
+	return len(p), nil
+}
+
+func (w *chunkWriter) Flush() {
+	w.flushes = append(w.flushes, w.n)
+}
+
+func TestFlush(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "flush.expect")
+	err := Flush(w, []string{"a", "b"})
+	require.NoError(t, err)
+}
+
+func TestFlush_Buffered(t *testing.T) {
+	t.Parallel()
+
+	var w chunkWriter
+	err := Flush(woof.WithBuffering(&w), []string{"a", "b"})
+	require.NoError(t, err)
+
+	head := "<html><head><title>Flush</title></head>"
+	assert.Equal(t, []string{head, "<body><ul><li>a</li><li>b</li></ul></body></html>"}, w.chunks)
+	assert.Equal(t, []int{len(head)}, w.flushes)
+}
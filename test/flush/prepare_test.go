@@ -0,0 +1,14 @@
+//go:build prepare_integration_test
+
+package flush
+
+import (
+	"testing"
+
+	"github.com/mavolin/corgi/test/internal/compile"
+)
+
+func TestFlush(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "flush.corgi", compile.Options{})
+}
@@ -25,3 +25,39 @@ func TestWhile(t *testing.T) {
 	err := While(w)
 	require.NoError(t, err)
 }
+
+func TestElse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		ch := make(chan string)
+		close(ch)
+
+		w := outcheck.New(t, "else.empty.expect")
+		err := Else(w, nil, ch, 0)
+		require.NoError(t, err)
+	})
+
+	t.Run("non-empty", func(t *testing.T) {
+		t.Parallel()
+
+		ch := make(chan string, 2)
+		ch <- "foo"
+		ch <- "bar"
+		close(ch)
+
+		w := outcheck.New(t, "else.non-empty.expect")
+		err := Else(w, []string{"a", "b"}, ch, 2)
+		require.NoError(t, err)
+	})
+}
+
+func TestLoopControl(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "loop_control.expect")
+	err := LoopControl(w, []int{1, -2, 3, 7, 4}, [][]int{{1, 2}, {3, 0, 4}, {5, 9, 6}, {7}})
+	require.NoError(t, err)
+}
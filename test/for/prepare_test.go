@@ -17,3 +17,14 @@ func TestWhile(t *testing.T) {
 	t.Parallel()
 	compile.Compile(t, "while.corgi", compile.Options{Package: "_for"})
 }
+
+func TestElse(t *testing.T) {
+	t.Parallel()
+	// else.corgi ranges over an int
+	compile.Compile(t, "else.corgi", compile.Options{Package: "_for", GoVersion: "go1.22"})
+}
+
+func TestLoopControl(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "loop_control.corgi", compile.Options{Package: "_for"})
+}
@@ -0,0 +1,32 @@
+//go:build integration_test && !prepare_integration_test
+
+package formatters
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi/test/internal/outcheck"
+	"github.com/mavolin/corgi/woof"
+)
+
+func TestFormatters(t *testing.T) {
+	t.Parallel()
+
+	woof.RegisterFormatter(woof.DefaultFormatters, func(t time.Time) (string, error) {
+		return t.Format("2006-01-02"), nil
+	})
+
+	fs := new(woof.Formatters)
+	woof.RegisterFormatter(fs, func(p price) (string, error) {
+		return "<" + strconv.Itoa(int(p)) + " €>", nil
+	})
+
+	w := outcheck.New(t, "formatters.expect")
+
+	err := Formatters(woof.WithFormatters(w, fs), time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC), 42)
+	require.NoError(t, err)
+}
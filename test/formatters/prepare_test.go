@@ -0,0 +1,14 @@
+//go:build prepare_integration_test
+
+package formatters
+
+import (
+	"testing"
+
+	"github.com/mavolin/corgi/test/internal/compile"
+)
+
+func TestFormatters(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "formatters.corgi", compile.Options{})
+}
@@ -0,0 +1,3 @@
+package formatters
+
+type price int
@@ -0,0 +1,35 @@
+//go:build integration_test && !prepare_integration_test
+
+package fragments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi/test/internal/outcheck"
+)
+
+func TestPage(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "page.expect")
+	err := Page(w, "World")
+	require.NoError(t, err)
+}
+
+func TestPageBlockContent(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "page_content.expect")
+	err := PageBlockContent(w, "World")
+	require.NoError(t, err)
+}
+
+func TestPageBlockTitle(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "page_title.expect")
+	err := PageBlockTitle(w, "World")
+	require.NoError(t, err)
+}
@@ -0,0 +1,14 @@
+//go:build prepare_integration_test
+
+package fragments
+
+import (
+	"testing"
+
+	"github.com/mavolin/corgi/test/internal/compile"
+)
+
+func TestPage(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "page.corgi", compile.Options{})
+}
@@ -0,0 +1,136 @@
+//go:build integration_test && !prepare_integration_test
+
+package handler
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi/corgihttp"
+	"github.com/mavolin/corgi/test/internal/outcheck"
+)
+
+func greetingArgs(r *http.Request) (string, bool, error) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		return "", false, &corgihttp.Error{Status: http.StatusNotFound, Err: errors.New("no name")}
+	}
+
+	return name, true, nil
+}
+
+func TestGreeting(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "greeting.expect")
+
+	h := GreetingHandler(greetingArgs, corgihttp.Options{})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?name=World", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	_, err := io.Copy(w, rec.Body)
+	require.NoError(t, err)
+}
+
+func TestGreeting_Error(t *testing.T) {
+	t.Parallel()
+
+	h := GreetingHandler(greetingArgs, corgihttp.Options{})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "Not Found\n", rec.Body.String())
+}
+
+func TestGreeting_Unbuffered(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "greeting.expect")
+
+	h := GreetingHandler(greetingArgs, corgihttp.Options{Unbuffered: true})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?name=World", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, rec.Flushed, "response not flushed")
+	_, err := io.Copy(w, rec.Body)
+	require.NoError(t, err)
+}
+
+func TestGreeting_Nonce(t *testing.T) {
+	t.Parallel()
+
+	h := GreetingHandler(greetingArgs, corgihttp.Options{
+		Nonce:                 true,
+		ContentSecurityPolicy: "script-src 'nonce-{nonce}'",
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?name=World", nil))
+
+	csp := rec.Header().Get("Content-Security-Policy")
+	require.Regexp(t, `^script-src 'nonce-[A-Za-z0-9+/]+={0,2}'$`, csp)
+	nonce := csp[len("script-src 'nonce-") : len(csp)-1]
+
+	assert.Equal(t, `<p>Hello, World!</p><script nonce="`+nonce+`">console.log("hi");</script>`, rec.Body.String())
+}
+
+func TestGreeting_GzipETag(t *testing.T) {
+	t.Parallel()
+
+	h := GreetingHandler(greetingArgs, corgihttp.Options{Gzip: true, ETag: true})
+
+	req := httptest.NewRequest(http.MethodGet, "/?name=World", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	gzr, err := gzip.NewReader(rec.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(gzr)
+	require.NoError(t, err)
+	assert.Equal(t, `<p>Hello, World!</p><script>console.log("hi");</script>`, string(body))
+
+	req.Header.Set("If-None-Match", etag)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "list.expect")
+
+	h := ListHandler(func(r *http.Request) (string, []string, error) {
+		return "Fruits", r.URL.Query()["item"], nil
+	}, corgihttp.Options{})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?item=apple&item=pear", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	_, err := io.Copy(w, rec.Body)
+	require.NoError(t, err)
+}
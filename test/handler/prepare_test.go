@@ -0,0 +1,19 @@
+//go:build prepare_integration_test
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/mavolin/corgi/test/internal/compile"
+)
+
+func TestGreeting(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "greeting.corgi", compile.Options{})
+}
+
+func TestList(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "list.corgi", compile.Options{})
+}
@@ -26,6 +26,18 @@ type Options struct {
 	Package string
 
 	AllowedFilters []string
+
+	// Pretty enables the pretty mode of the writer.
+	Pretty bool
+	// LineDirectives enables the line directives of the writer.
+	LineDirectives bool
+
+	// GoVersion, if set, is the minimum Go version, e.g. "go1.22", the
+	// generated file is constrained to.
+	//
+	// Since a build constraint sets the language version of its file, this
+	// allows using newer language features than the module's go.mod permits.
+	GoVersion string
 }
 
 func init() {
@@ -58,7 +70,10 @@ func Compile(t *testing.T, name string, o Options) {
 	}
 
 	w := write.New(write.Options{
-		AllowedFilters: o.AllowedFilters,
+		AllowedFilters:    o.AllowedFilters,
+		Pretty:            o.Pretty,
+		LineDirectives:    o.LineDirectives,
+		GeneratedFileName: name + ".go",
 	})
 
 	file, err := os.Create(name + ".go")
@@ -80,6 +95,13 @@ func Compile(t *testing.T, name string, o Options) {
 		t.Fatalf("failed to start goimports: %s", err.Error())
 	}
 
+	if o.GoVersion != "" {
+		if _, err = genOut.Write([]byte("//go:build " + o.GoVersion + "\n\n")); err != nil {
+			t.Fatalf("could not write to output file: %s", err)
+			return
+		}
+	}
+
 	if err = w.GenerateFile(genOut, o.Package, f); err != nil {
 		t.Fatalf("could not write to output file: %s", err)
 		return
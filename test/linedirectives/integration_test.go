@@ -0,0 +1,20 @@
+//go:build integration_test && !prepare_integration_test
+
+package linedirectives
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi/test/internal/outcheck"
+)
+
+func TestLineDirectives(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "linedirectives.expect")
+
+	err := LineDirectives(w)
+	require.NoError(t, err)
+}
@@ -0,0 +1,14 @@
+//go:build prepare_integration_test
+
+package linedirectives
+
+import (
+	"testing"
+
+	"github.com/mavolin/corgi/test/internal/compile"
+)
+
+func TestLineDirectives(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "linedirectives.corgi", compile.Options{LineDirectives: true})
+}
@@ -49,3 +49,35 @@ func TestExternalAlias(t *testing.T) {
 	err := ExternalAlias(w)
 	require.NoError(t, err)
 }
+
+func TestGeneric(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "generic.expect")
+	err := Generic(w, []string{"foo", "bar"}, map[string]int{"foo": 1, "bar": 2})
+	require.NoError(t, err)
+}
+
+func TestVariadic(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "variadic.expect")
+	err := Variadic(w, []string{"foo", "bar"}, []int{3, 4})
+	require.NoError(t, err)
+}
+
+func TestScopedBlocks(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "scoped_blocks.expect")
+	err := ScopedBlocks(w, []string{"foo", "bar"}, map[string]int{"foo": 42, "bar": 69})
+	require.NoError(t, err)
+}
+
+func TestBlockParamValues(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "block_param_values.expect")
+	err := BlockParamValues(w, []string{"foo", "bar"}, map[string]int{"foo": 42, "bar": 69})
+	require.NoError(t, err)
+}
@@ -32,3 +32,23 @@ func TestExternalAlias(t *testing.T) {
 	t.Parallel()
 	compile.Compile(t, "external_alias.corgi", compile.Options{})
 }
+
+func TestGeneric(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "generic.corgi", compile.Options{})
+}
+
+func TestVariadic(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "variadic.corgi", compile.Options{})
+}
+
+func TestScopedBlocks(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "scoped_blocks.corgi", compile.Options{})
+}
+
+func TestBlockParamValues(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "block_param_values.corgi", compile.Options{})
+}
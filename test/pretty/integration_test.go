@@ -0,0 +1,20 @@
+//go:build integration_test && !prepare_integration_test
+
+package pretty
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi/test/internal/outcheck"
+)
+
+func TestPretty(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "pretty.expect")
+
+	err := Pretty(w, "</script>")
+	require.NoError(t, err)
+}
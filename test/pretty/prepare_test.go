@@ -0,0 +1,14 @@
+//go:build prepare_integration_test
+
+package pretty
+
+import (
+	"testing"
+
+	"github.com/mavolin/corgi/test/internal/compile"
+)
+
+func TestPretty(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "pretty.corgi", compile.Options{Pretty: true})
+}
@@ -0,0 +1,21 @@
+//go:build integration_test && !prepare_integration_test
+
+package sanitize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi/test/internal/outcheck"
+)
+
+func TestSanitize(t *testing.T) {
+	t.Parallel()
+
+	w := outcheck.New(t, "sanitize.expect")
+
+	err := Sanitize(w, `<p onclick="steal()">Nice <a href="javascript:steal()">post</a>!</div><script>steal()</script>`+
+		`<a href="https://example.com">link</a> <b>unclosed`)
+	require.NoError(t, err)
+}
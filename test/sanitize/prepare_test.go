@@ -0,0 +1,14 @@
+//go:build prepare_integration_test
+
+package sanitize
+
+import (
+	"testing"
+
+	"github.com/mavolin/corgi/test/internal/compile"
+)
+
+func TestSanitize(t *testing.T) {
+	t.Parallel()
+	compile.Compile(t, "sanitize.corgi", compile.Options{})
+}
@@ -0,0 +1,324 @@
+// Package typecheck provides type checking of the Go code in corgi files.
+package typecheck
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mavolin/corgi/corgierr"
+	"github.com/mavolin/corgi/file"
+	"github.com/mavolin/corgi/internal/anno"
+	"github.com/mavolin/corgi/internal/gocmd"
+	"github.com/mavolin/corgi/write"
+)
+
+// Options are the options used by [File].
+type Options struct {
+	// Dir is the directory of the package the file is generated for.
+	//
+	// All Go files in Dir belonging to the package, except for test files and
+	// the file named FileName, are type-checked alongside the generated file.
+	Dir string
+	// FileName is the name of the file the generated code will be written
+	// to.
+	FileName string
+	// Package is the name of the package of the generated file.
+	Package string
+
+	// GoExecPath is the path to the go binary used to compile the packages
+	// imported by the package.
+	//
+	// If not set, the go binary in the PATH is used.
+	GoExecPath string
+
+	// Write are the options used to generate the file.
+	//
+	// LineDirectives, LineDirectiveFileName, and GeneratedFileName are
+	// ignored.
+	Write write.Options
+
+	// Process, if set, is called with the generated code before it is
+	// type-checked.
+	//
+	// It can be used to add missing imports using goimports.
+	Process func(src []byte) ([]byte, error)
+}
+
+// File generates the passed main file and type-checks the result.
+//
+// Errors in the generated code are mapped back onto the corgi files the code
+// originates from, using line directives.
+// Errors in code that doesn't originate from a corgi file, e.g. in the other
+// Go files of the package, are ignored.
+//
+// If the generated code contains type or syntax errors, File returns a
+// [corgierr.List].
+func File(f *file.File, o Options) error {
+	dir, err := filepath.Abs(o.Dir)
+	if err != nil {
+		return err
+	}
+
+	files := make(map[string]*file.File)
+	names := make(map[*file.File]string)
+
+	wo := o.Write
+	if wo.IdentPrefix == "" {
+		wo.IdentPrefix = "__corgi_"
+	}
+	mixinVarRegexp := regexp.MustCompile(regexp.QuoteMeta(wo.IdentPrefix) + `mixin\d+`)
+
+	wo.LineDirectives = true
+	wo.GeneratedFileName = o.FileName
+	wo.LineDirectiveFileName = func(f *file.File) string {
+		if name, ok := names[f]; ok {
+			return name
+		}
+
+		// use names that can't collide with actual files, so that we can
+		// tell corgi code apart from Go code
+		name := filepath.Join(dir, ".corgi", strconv.Itoa(len(names)))
+		names[f] = name
+		files[name] = f
+		return name
+	}
+
+	var buf bytes.Buffer
+	if err := write.New(wo).GenerateFile(&buf, o.Package, f); err != nil {
+		return err
+	}
+
+	genName := filepath.Join(dir, o.FileName)
+	fset := token.NewFileSet()
+
+	// check for syntax errors first, as they can't be mapped after processing
+	src := buf.Bytes()
+	genFile, err := parser.ParseFile(fset, genName, src, 0)
+	if err != nil {
+		return syntaxErrors(files, err)
+	}
+
+	if o.Process != nil {
+		src, err = o.Process(src)
+		if err != nil {
+			return err
+		}
+
+		genFile, err = parser.ParseFile(fset, genName, src, 0)
+		if err != nil {
+			return syntaxErrors(files, err)
+		}
+	}
+
+	astFiles, err := packageFiles(fset, dir, o.FileName)
+	if err != nil {
+		return err
+	}
+	astFiles = append(astFiles, genFile)
+
+	imp, err := newImporter(fset, dir, o.GoExecPath, astFiles)
+	if err != nil {
+		return err
+	}
+
+	var errs corgierr.List
+	seen := make(map[string]struct{})
+
+	conf := types.Config{
+		Importer:    imp,
+		FakeImportC: true,
+		Error: func(err error) {
+			var terr types.Error
+			if !errors.As(err, &terr) {
+				return
+			}
+
+			pos := fset.Position(terr.Pos)
+			key := pos.String() + ": " + terr.Msg
+			if _, ok := seen[key]; ok {
+				return
+			}
+			seen[key] = struct{}{}
+
+			// generated mixins are called through variables, replace their
+			// names with something more meaningful
+			msg := mixinVarRegexp.ReplaceAllString(terr.Msg, "mixin")
+
+			if cerr := corgiError(files, pos, "type error", corgierr.CodeTypeError, msg); cerr != nil {
+				errs = append(errs, cerr)
+			}
+		},
+	}
+	_, _ = conf.Check(genFile.Name.Name, fset, astFiles, nil)
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+func syntaxErrors(files map[string]*file.File, err error) error {
+	var serrs scanner.ErrorList
+	if !errors.As(err, &serrs) {
+		return err
+	}
+
+	// errors following the first one are usually caused by it, so only
+	// report the first one
+	if len(serrs) == 0 {
+		return err
+	}
+
+	cerr := corgiError(files, serrs[0].Pos, "Go syntax error", corgierr.CodeGoSyntaxError, serrs[0].Msg)
+	if cerr == nil {
+		return err
+	}
+
+	return corgierr.List{cerr}
+}
+
+// corgiError creates a [corgierr.Error] with the passed message and code,
+// annotating the passed position in the corgi file it is located in with
+// annotation.
+//
+// If the position is not located in a corgi file, corgiError returns nil.
+func corgiError(
+	files map[string]*file.File, pos token.Position, msg string, code corgierr.Code, annotation string,
+) *corgierr.Error {
+	f := files[pos.Filename]
+	if f == nil || pos.Line <= 0 || pos.Line > len(f.Lines) {
+		return nil
+	}
+
+	ln := f.Lines[pos.Line-1]
+
+	a := anno.Annotation{
+		Start:      file.Position{Line: pos.Line, Col: pos.Column},
+		Annotation: annotation,
+	}
+	if pos.Column <= 0 || pos.Column > len(ln) {
+		// the line directive had no column, so annotate the entire line
+		a.Start.Col = len(ln) - len(bytes.TrimLeft([]byte(ln), " \t")) + 1
+		a.ToEOL = true
+	} else {
+		a.Len = tokenLen(ln[pos.Column-1:])
+	}
+
+	return &corgierr.Error{Message: msg, Code: code, ErrorAnnotation: anno.Anno(f, a)}
+}
+
+// tokenLen returns the length of the identifier at the start of s, or 1, if s
+// doesn't start with an identifier.
+func tokenLen(s string) int {
+	var n int
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+
+		n += size
+	}
+
+	if n == 0 {
+		return 1
+	}
+
+	return n
+}
+
+// packageFiles parses the Go files of the package in dir, excluding test
+// files and the file named exclude.
+func packageFiles(fset *token.FileSet, dir, exclude string) ([]*ast.File, error) {
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		var noGoErr *build.NoGoError
+		if errors.As(err, &noGoErr) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	names := make([]string, 0, len(pkg.GoFiles)+len(pkg.CgoFiles))
+	names = append(names, pkg.GoFiles...)
+	names = append(names, pkg.CgoFiles...)
+
+	files := make([]*ast.File, 0, len(names)+1)
+	for _, name := range names {
+		if name == exclude {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("typecheck: %w", err)
+		}
+
+		files = append(files, f)
+	}
+
+	return files, nil
+}
+
+// newImporter returns an importer that imports the export data of the
+// packages imported by files, compiled by the go command.
+func newImporter(fset *token.FileSet, dir, goExecPath string, files []*ast.File) (types.Importer, error) {
+	if goExecPath == "" {
+		var err error
+		goExecPath, err = exec.LookPath("go")
+		if err != nil {
+			return nil, fmt.Errorf("typecheck: %w", err)
+		}
+	}
+
+	pathSet := make(map[string]struct{})
+	for _, f := range files {
+		for _, imp := range f.Imports {
+			path, err := strconv.Unquote(imp.Path.Value)
+			if err == nil && path != "C" && path != "unsafe" {
+				pathSet[path] = struct{}{}
+			}
+		}
+	}
+
+	if len(pathSet) == 0 {
+		return importer.ForCompiler(fset, "gc", nil), nil
+	}
+
+	paths := make([]string, 0, len(pathSet))
+	for path := range pathSet {
+		paths = append(paths, path)
+	}
+
+	exports, err := gocmd.NewCmd(goExecPath).ListExports(dir, paths...)
+	if err != nil {
+		return nil, fmt.Errorf("typecheck: list imported packages: %w", err)
+	}
+
+	return importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+		export, ok := exports[path]
+		if !ok {
+			return nil, fmt.Errorf("no export data for %q", path)
+		}
+
+		return os.Open(export)
+	}), nil
+}
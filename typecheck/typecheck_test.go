@@ -0,0 +1,177 @@
+package typecheck_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi"
+	"github.com/mavolin/corgi/corgierr"
+	"github.com/mavolin/corgi/typecheck"
+)
+
+// newPackage creates a package in a module that uses this copy of corgi, and
+// returns its directory.
+func newPackage(t *testing.T) string {
+	t.Helper()
+
+	root, err := filepath.Abs("..")
+	require.NoError(t, err)
+
+	goSum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	goMod := "module example.com/test\n\n" +
+		"go 1.19\n\n" +
+		"require github.com/mavolin/corgi v0.0.0\n\n" +
+		"replace github.com/mavolin/corgi => " + root + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.sum"), goSum, 0o644))
+
+	return dir
+}
+
+// check type-checks the main file with the passed contents, and returns the
+// resulting errors.
+func check(t *testing.T, src string) []*corgierr.Error {
+	t.Helper()
+
+	l, err := corgi.NewFSLoader(corgi.FSLoadOptions{
+		Modules: map[string]fs.FS{
+			"example.com/test": fstest.MapFS{
+				"page.corgi": &fstest.MapFile{Data: []byte(src)},
+			},
+		},
+		NoPrecompile: true,
+	})
+	require.NoError(t, err)
+
+	f, err := l.LoadMain("example.com/test/page.corgi")
+	require.NoError(t, err)
+
+	err = typecheck.File(f, typecheck.Options{
+		Dir:      newPackage(t),
+		FileName: "page.corgi.go",
+		Package:  "test",
+	})
+	if err == nil {
+		return nil
+	}
+
+	lerr := corgierr.As(err)
+	require.NotNil(t, lerr, err.Error())
+	return lerr
+}
+
+func TestFile_Constraints(t *testing.T) {
+	t.Parallel()
+
+	t.Run("satisfied", func(t *testing.T) {
+		t.Parallel()
+
+		errs := check(t, "func Page()\n\n"+
+			"mixin num[T ~int | ~float64](v T)\n"+
+			"  p #{v}\n\n"+
+			"+num[int](v=1)\n"+
+			"+num(v=1.5)\n")
+		assert.Empty(t, errs)
+	})
+	t.Run("not satisfied", func(t *testing.T) {
+		t.Parallel()
+
+		errs := check(t, "func Page()\n\n"+
+			"mixin num[T ~int | ~float64](v T)\n"+
+			"  p #{v}\n\n"+
+			"+num[string](v=\"x\")\n")
+		require.Len(t, errs, 1)
+		assert.Equal(t, corgierr.CodeTypeError, errs[0].Code)
+		assert.Equal(t, 6, errs[0].ErrorAnnotation.Line)
+		assert.Equal(t, 6, errs[0].ErrorAnnotation.Start)
+		assert.Contains(t, errs[0].ErrorAnnotation.Annotation, "string does not satisfy ~int | ~float64")
+	})
+	t.Run("inferred", func(t *testing.T) {
+		t.Parallel()
+
+		errs := check(t, "func Page()\n\n"+
+			"mixin num[T ~int | ~float64](v T)\n"+
+			"  p #{v}\n\n"+
+			"+num(v=\"x\")\n")
+		require.Len(t, errs, 1)
+		assert.Equal(t, corgierr.CodeTypeError, errs[0].Code)
+		assert.Equal(t, 6, errs[0].ErrorAnnotation.Line)
+	})
+}
+
+func TestFile_Interpolation(t *testing.T) {
+	t.Parallel()
+
+	errs := check(t, "func Page(n int)\n\np #{n + \"x\"}\n")
+	require.Len(t, errs, 1)
+	assert.Equal(t, corgierr.CodeTypeError, errs[0].Code)
+	assert.Equal(t, 3, errs[0].ErrorAnnotation.Line)
+	assert.Equal(t, 5, errs[0].ErrorAnnotation.Start)
+	assert.Contains(t, errs[0].ErrorAnnotation.Annotation, "mismatched types int and untyped string")
+}
+
+func TestFile_SyntaxError(t *testing.T) {
+	t.Parallel()
+
+	errs := check(t, "func Page(a, b int)\n\n- c := a b\np #{c}\n")
+	require.Len(t, errs, 1)
+	assert.Equal(t, "Go syntax error", errs[0].Message)
+	assert.Equal(t, corgierr.CodeGoSyntaxError, errs[0].Code)
+	assert.Equal(t, 3, errs[0].ErrorAnnotation.Line)
+}
+
+func TestFile_MixinArg(t *testing.T) {
+	t.Parallel()
+
+	t.Run("required", func(t *testing.T) {
+		t.Parallel()
+
+		errs := check(t, "func Page(n int)\n\n"+
+			"mixin m(s string)\n"+
+			"  p #{s}\n\n"+
+			"+m(s=n)\n")
+		require.Len(t, errs, 1)
+		assert.Equal(t, corgierr.CodeTypeError, errs[0].Code)
+		assert.Equal(t, 6, errs[0].ErrorAnnotation.Line)
+		assert.Equal(t, 6, errs[0].ErrorAnnotation.Start)
+		assert.Contains(t, errs[0].ErrorAnnotation.Annotation, "cannot use")
+	})
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+
+		errs := check(t, "func Page(n int)\n\n"+
+			"mixin m(a string, b string = \"b\")\n"+
+			"  p #{a}#{b}\n\n"+
+			"+m(a=\"a\", b=n)\n")
+		require.Len(t, errs, 1)
+		assert.Equal(t, corgierr.CodeTypeError, errs[0].Code)
+		assert.Equal(t, 6, errs[0].ErrorAnnotation.Line)
+		assert.Equal(t, 13, errs[0].ErrorAnnotation.Start)
+		assert.Contains(t, errs[0].ErrorAnnotation.Annotation, "cannot use")
+	})
+}
+
+func TestFile_BlockParamValue(t *testing.T) {
+	t.Parallel()
+
+	errs := check(t, "func Page(n int)\n\n"+
+		"mixin m()\n"+
+		"  block _(s string = n)\n\n"+
+		"+m()\n"+
+		"  block _(s)\n"+
+		"    p #{s}\n")
+	require.Len(t, errs, 1)
+	assert.Equal(t, corgierr.CodeTypeError, errs[0].Code)
+	assert.Equal(t, 4, errs[0].ErrorAnnotation.Line)
+	assert.Equal(t, 22, errs[0].ErrorAnnotation.Start)
+	assert.Contains(t, errs[0].ErrorAnnotation.Annotation, "cannot use")
+}
@@ -30,6 +30,7 @@ func onlyTemplateFilesContainBlockPlaceholders(f *file.File) *errList {
 
 				errs.PushBack(&corgierr.Error{
 					Message: "use of template block without extending a template",
+					Code:    corgierr.CodeTemplateBlockWithoutExtend,
 					ErrorAnnotation: anno.Anno(f, anno.Annotation{
 						Start:      itm.Position,
 						Len:        (itm.Name.Col - itm.Col) + len(itm.Name.Ident),
@@ -64,6 +65,7 @@ func onlyTemplateFilesContainBlockPlaceholders(f *file.File) *errList {
 
 			errs.PushBack(&corgierr.Error{
 				Message: "use of template block outside of template file",
+				Code:    corgierr.CodeTemplateBlockOutsideTemplate,
 				ErrorAnnotation: anno.Anno(f, anno.Annotation{
 					Start:      itm.Position,
 					Len:        (itm.Name.Col - itm.Col) + len(itm.Name.Ident),
@@ -100,14 +102,11 @@ func duplicateTemplateBlocks(f *file.File) *errList {
 			continue
 		}
 
-		if cmpBlocks.Len() == 0 {
-			continue
-		}
-
 		for cmpBlockE := cmpBlocks.Front(); cmpBlockE != nil; cmpBlockE = cmpBlockE.Next() {
 			if block.Name.Ident == cmpBlockE.V().Name.Ident {
 				errs.PushBack(&corgierr.Error{
 					Message: "template block filled twice",
+					Code:    corgierr.CodeTemplateBlockFilledTwice,
 					ErrorAnnotation: anno.Anno(f, anno.Annotation{
 						ContextLen: 2,
 						Start:      block.Name.Position,
@@ -189,6 +188,7 @@ scope:
 
 		errs.PushBack(&corgierr.Error{
 			Message: "unknown template block",
+			Code:    corgierr.CodeUnknownTemplateBlock,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      block.Position,
 				End:        file.Position{Line: block.Line, Col: block.Name.Col + len(block.Name.Ident)},
@@ -199,3 +199,45 @@ scope:
 
 	return &errs
 }
+
+// template blocks have no params.
+func templateBlockParams(f *file.File) *errList {
+	var errs errList
+
+	fileutil.Walk(f.Scope, func(parents []fileutil.WalkContext, ctx fileutil.WalkContext) (dive bool, err error) {
+		switch itm := (*ctx.Item).(type) {
+		case file.Include:
+			return false, nil
+		case file.Mixin:
+			return false, nil
+		case file.Block:
+			if len(parents) > 0 {
+				if _, ok := (*parents[len(parents)-1].Item).(file.MixinCall); ok {
+					return true, nil
+				}
+			}
+
+			if itm.Params == nil {
+				return true, nil
+			}
+
+			errs.PushBack(&corgierr.Error{
+				Message: "template block with params",
+				Code:    corgierr.CodeTemplateBlockParams,
+				ErrorAnnotation: anno.Anno(f, anno.Annotation{
+					Start:      itm.Position,
+					Len:        (itm.Name.Col - itm.Col) + len(itm.Name.Ident),
+					Annotation: "template blocks cannot have params",
+				}),
+				Suggestions: []corgierr.Suggestion{
+					{Suggestion: "only the blocks of mixins and mixin calls can have params; remove the param list"},
+				},
+			})
+			return true, nil
+		default:
+			return true, nil
+		}
+	})
+
+	return &errs
+}
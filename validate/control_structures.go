@@ -0,0 +1,121 @@
+package validate
+
+import (
+	"fmt"
+
+	"github.com/mavolin/corgi/corgierr"
+	"github.com/mavolin/corgi/file"
+	"github.com/mavolin/corgi/file/fileutil"
+	"github.com/mavolin/corgi/internal/anno"
+)
+
+// forElses checks that only loops with a condition or range expression have
+// an else.
+func forElses(f *file.File) *errList {
+	var errs errList
+
+	fileutil.Walk(f.Scope, func(parents []fileutil.WalkContext, ctx fileutil.WalkContext) (dive bool, err error) {
+		switch itm := (*ctx.Item).(type) {
+		case file.Include:
+			return false, nil
+		case file.For:
+			if itm.Else != nil && itm.Expression == nil {
+				errs.PushBack(&corgierr.Error{
+					Message: "`else` on infinite `for`",
+					Code:    corgierr.CodeElseOnInfiniteFor,
+					ErrorAnnotation: anno.Anno(f, anno.Annotation{
+						Start:      itm.Else.Position,
+						Len:        len("else"),
+						Annotation: "this `else` would never be executed",
+					}),
+					HintAnnotations: []corgierr.Annotation{
+						anno.Anno(f, anno.Annotation{
+							Start:      itm.Position,
+							Len:        len("for"),
+							Annotation: "because this loop always executes its body at least once",
+						}),
+					},
+					Suggestions: []corgierr.Suggestion{
+						{Suggestion: "remove the `else`, or add a condition or range expression to the `for`"},
+					},
+				})
+			}
+		}
+
+		return true, nil
+	})
+
+	return &errs
+}
+
+// loopControls checks that every break and continue is placed inside the loop
+// it refers to, and that that loop is not outside the mixin or mixin call the
+// break or continue is placed in.
+func loopControls(f *file.File) *errList {
+	var errs errList
+
+	fileutil.Walk(f.Scope, func(parents []fileutil.WalkContext, ctx fileutil.WalkContext) (dive bool, err error) {
+		switch itm := (*ctx.Item).(type) {
+		case file.Include:
+			return false, nil
+		case file.Break:
+			errs.PushBackList(_loopControl(f, parents, "break", itm.Label, itm.Position))
+		case file.Continue:
+			errs.PushBackList(_loopControl(f, parents, "continue", itm.Label, itm.Position))
+		}
+
+		return true, nil
+	})
+
+	return &errs
+}
+
+func _loopControl(
+	f *file.File, parents []fileutil.WalkContext, keyword string, label *file.Ident, pos file.Position,
+) *errList {
+	var errs errList
+
+	loop, acrossMixin := fileutil.LoopOf(parents, label)
+	if loop == nil {
+		annotation := "this `" + keyword + "` is not placed inside a `for` loop"
+		if label != nil {
+			annotation = "this `" + keyword + "` is not placed inside a `for` loop labeled `" + label.Ident + "`"
+		}
+
+		errs.PushBack(&corgierr.Error{
+			Message: fmt.Sprintf("`%s` outside of loop", keyword),
+			Code:    corgierr.CodeLoopControlOutsideLoop,
+			ErrorAnnotation: anno.Anno(f, anno.Annotation{
+				Start:      pos,
+				ToEOL:      true,
+				Annotation: annotation,
+			}),
+		})
+		return &errs
+	}
+
+	if acrossMixin {
+		errs.PushBack(&corgierr.Error{
+			Message: fmt.Sprintf("`%s` across mixin boundary", keyword),
+			Code:    corgierr.CodeLoopControlAcrossMixin,
+			ErrorAnnotation: anno.Anno(f, anno.Annotation{
+				Start:      pos,
+				ToEOL:      true,
+				Annotation: "this `" + keyword + "` is placed inside a mixin or the body of a mixin call",
+			}),
+			HintAnnotations: []corgierr.Annotation{
+				anno.Anno(f, anno.Annotation{
+					Start:      loop.Position,
+					Len:        len("for"),
+					Annotation: "but refers to this loop outside of it",
+				}),
+			},
+			Suggestions: []corgierr.Suggestion{
+				{Suggestion: "mixins and the bodies of mixin calls are generated as separate functions,\n" +
+					"and therefore cannot `" + keyword + "` the loops they are placed in"},
+			},
+		})
+	}
+
+	return &errs
+}
@@ -27,6 +27,7 @@ func _topLevelAttributes(f *file.File, s file.Scope) *errList {
 		case file.And:
 			errs.PushBack(&corgierr.Error{
 				Message: "top-level attribute",
+				Code:    corgierr.CodeTopLevelAttribute,
 				ErrorAnnotation: anno.Anno(f, anno.Annotation{
 					Start:      itm.Position,
 					Annotation: "attributes cannot be placed outside of elements",
@@ -46,6 +47,7 @@ func _topLevelAttributes(f *file.File, s file.Scope) *errList {
 			if fileutil.IsAttrMixin(*itm.Mixin) {
 				errs.PushBack(&corgierr.Error{
 					Message: "top-level attribute",
+					Code:    corgierr.CodeTopLevelAttribute,
 					ErrorAnnotation: anno.Anno(f, anno.Annotation{
 						Start:      itm.Position,
 						Annotation: "attributes cannot be placed outside of elements",
@@ -66,6 +68,7 @@ func _topLevelAttributes(f *file.File, s file.Scope) *errList {
 			if itm.Mixin.Mixin.WritesTopLevelAttributes {
 				errs.PushBack(&corgierr.Error{
 					Message: "top-level `&`",
+					Code:    corgierr.CodeTopLevelAnd,
 					ErrorAnnotation: anno.Anno(f, anno.Annotation{
 						Start: itm.Position,
 						Len:   annoLen,
@@ -84,6 +87,7 @@ func _topLevelAttributes(f *file.File, s file.Scope) *errList {
 			if andPos != file.InvalidPosition && itm.Mixin.Mixin.TopLevelAndPlaceholder {
 				errs.PushBack(&corgierr.Error{
 					Message: "top-level `&`",
+					Code:    corgierr.CodeTopLevelAnd,
 					ErrorAnnotation: anno.Anno(f, anno.Annotation{
 						Start:      itm.Position,
 						Len:        annoLen,
@@ -156,6 +160,7 @@ func _topLevelAttributes(f *file.File, s file.Scope) *errList {
 				if andPos != file.InvalidPosition && ublock.DefaultTopLevelAndPlaceholder {
 					errs.PushBack(&corgierr.Error{
 						Message: "top-level `&`",
+						Code:    corgierr.CodeTopLevelAnd,
 						ErrorAnnotation: anno.Anno(f, anno.Annotation{
 							Start: itm.Position,
 							Len:   annoLen,
@@ -180,6 +185,7 @@ func _topLevelAttributes(f *file.File, s file.Scope) *errList {
 				} else if ublock.DefaultWritesTopLevelAttributes {
 					errs.PushBack(&corgierr.Error{
 						Message: "top-level attributes in top-level block default",
+						Code:    corgierr.CodeTopLevelAndInBlockDefault,
 						ErrorAnnotation: anno.Anno(f, anno.Annotation{
 							Start: itm.Position,
 							Len:   annoLen,
@@ -237,6 +243,7 @@ func _topLevelTemplateBlockAnds(f *file.File, s file.Scope) *errList {
 		case file.And:
 			errs.PushBack(&corgierr.Error{
 				Message: "top-level `&` in block",
+				Code:    corgierr.CodeTopLevelAndInBlock,
 				ErrorAnnotation: anno.Anno(f, anno.Annotation{
 					Start:      itm.Position,
 					Annotation: "attributes may not be placed at the top-level of a template block",
@@ -253,6 +260,7 @@ func _topLevelTemplateBlockAnds(f *file.File, s file.Scope) *errList {
 			if fileutil.IsAttrMixin(*itm.Mixin) {
 				errs.PushBack(&corgierr.Error{
 					Message: "top-level attribute",
+					Code:    corgierr.CodeTopLevelAttribute,
 					ErrorAnnotation: anno.Anno(f, anno.Annotation{
 						Start:      itm.Position,
 						Annotation: "attributes cannot be placed outside of elements",
@@ -273,6 +281,7 @@ func _topLevelTemplateBlockAnds(f *file.File, s file.Scope) *errList {
 			if itm.Mixin.Mixin.WritesTopLevelAttributes {
 				errs.PushBack(&corgierr.Error{
 					Message: "top-level `&`",
+					Code:    corgierr.CodeTopLevelAnd,
 					ErrorAnnotation: anno.Anno(f, anno.Annotation{
 						Start: itm.Position,
 						Len:   annoLen,
@@ -291,6 +300,7 @@ func _topLevelTemplateBlockAnds(f *file.File, s file.Scope) *errList {
 			if andPos != file.InvalidPosition && itm.Mixin.Mixin.TopLevelAndPlaceholder {
 				errs.PushBack(&corgierr.Error{
 					Message: "top-level `&`",
+					Code:    corgierr.CodeTopLevelAnd,
 					ErrorAnnotation: anno.Anno(f, anno.Annotation{
 						Start:      itm.Position,
 						Len:        annoLen,
@@ -363,6 +373,7 @@ func _topLevelTemplateBlockAnds(f *file.File, s file.Scope) *errList {
 				if andPos != file.InvalidPosition && ublock.DefaultTopLevelAndPlaceholder {
 					errs.PushBack(&corgierr.Error{
 						Message: "top-level `&`",
+						Code:    corgierr.CodeTopLevelAnd,
 						ErrorAnnotation: anno.Anno(f, anno.Annotation{
 							Start: itm.Position,
 							Len:   annoLen,
@@ -387,6 +398,7 @@ func _topLevelTemplateBlockAnds(f *file.File, s file.Scope) *errList {
 				} else if ublock.DefaultWritesTopLevelAttributes {
 					errs.PushBack(&corgierr.Error{
 						Message: "top-level `&` in top-level block default",
+						Code:    corgierr.CodeTopLevelAndInBlockDefault,
 						ErrorAnnotation: anno.Anno(f, anno.Annotation{
 							Start: itm.Position,
 							Len:   annoLen,
@@ -484,6 +496,7 @@ func _attributePlacement(
 
 			errs.PushBack(&corgierr.Error{
 				Message: "use of `&` after writing to element's body",
+				Code:    corgierr.CodeAttributeAfterBody,
 				ErrorAnnotation: anno.Anno(f, anno.Annotation{
 					Start:      itm.Position,
 					Annotation: "so you cannot place an `&` here",
@@ -573,6 +586,7 @@ func _attributePlacement(
 					if and, ok := nonCtrl.(file.And); ok {
 						errs.PushBack(&corgierr.Error{
 							Message: "use of `&` in for-loop that also writes to element's body",
+							Code:    corgierr.CodeAndInForLoopWritingBody,
 							ErrorAnnotation: anno.Anno(f, anno.Annotation{
 								Start:      and.Position,
 								Annotation: "you placed an `&` here",
@@ -588,6 +602,7 @@ func _attributePlacement(
 					} else if mixin, ok := nonCtrl.(file.MixinCall); ok {
 						errs.PushBack(&corgierr.Error{
 							Message: "use of `&` in for-loop that also writes to element's body",
+							Code:    corgierr.CodeAndInForLoopWritingBody,
 							ErrorAnnotation: anno.Anno(f, anno.Annotation{
 								Start:      mixin.Position,
 								Annotation: "you placed a mixin writing an attribute (and possibly also text) here",
@@ -599,6 +614,16 @@ func _attributePlacement(
 
 				firstText = firstTextAfterFor
 			}
+
+			// Unless the loop only writes attributes, the start tag is closed
+			// before the loop, hence we also pass the loop's text to else.
+			if itm.Else != nil {
+				firstElseText, elseErrs := _attributePlacement(f, elAnno, firstText, itm.Else.Then)
+				errs.PushBackList(&elseErrs)
+				if firstText == nil {
+					firstText = firstElseText
+				}
+			}
 		case file.Element:
 			if firstText == nil {
 				a := anno.Anno(f, anno.Annotation{
@@ -675,6 +700,7 @@ func _attributePlacement(
 
 				errs.PushBack(&corgierr.Error{
 					Message: "attribute placed after writing to element's body",
+					Code:    corgierr.CodeAttributeAfterBody,
 					ErrorAnnotation: anno.Anno(f, anno.Annotation{
 						Start:      itm.Position,
 						End:        end,
@@ -740,6 +766,7 @@ func _mixinCallAndPlacement(
 	if mc.Mixin.Mixin.WritesTopLevelAttributes {
 		errs.PushBack(&corgierr.Error{
 			Message: "use of `&` after writing to element's body",
+			Code:    corgierr.CodeAttributeAfterBody,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      mc.Position,
 				Len:        annoLen,
@@ -759,6 +786,7 @@ func _mixinCallAndPlacement(
 	if andPos != file.InvalidPosition && mc.Mixin.Mixin.TopLevelAndPlaceholder {
 		errs.PushBack(&corgierr.Error{
 			Message: "use of `&` after writing to element's body",
+			Code:    corgierr.CodeAttributeAfterBody,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      mc.Position,
 				Len:        annoLen,
@@ -834,6 +862,7 @@ handleUnfilledBlocks:
 		if andPos != file.InvalidPosition && ublock.DefaultTopLevelAndPlaceholder {
 			errs.PushBack(&corgierr.Error{
 				Message: "use of `&` after writing to element's body",
+				Code:    corgierr.CodeAttributeAfterBody,
 				ErrorAnnotation: anno.Anno(f, anno.Annotation{
 					Start: mc.Position,
 					Len:   annoLen,
@@ -860,6 +889,7 @@ handleUnfilledBlocks:
 		} else if ublock.DefaultWritesTopLevelAttributes {
 			errs.PushBack(&corgierr.Error{
 				Message: "top-level `&` in top-level block default",
+				Code:    corgierr.CodeTopLevelAndInBlockDefault,
 				ErrorAnnotation: anno.Anno(f, anno.Annotation{
 					Start: mc.Position,
 					Len:   annoLen,
@@ -21,11 +21,12 @@ func mainFile(f *file.File) *errList {
 		if len(f.Uses) > 0 {
 			expectPos.Line = f.Uses[len(f.Uses)-1].Uses[len(f.Uses[len(f.Uses)-1].Uses)-1].Line + 1
 		} else if len(f.Imports) > 0 {
-			expectPos.Line = f.Imports[len(f.Imports)-1].Imports[len(f.Imports[len(f.Uses)-1].Imports)-1].Line + 1
+			expectPos.Line = f.Imports[len(f.Imports)-1].Imports[len(f.Imports[len(f.Imports)-1].Imports)-1].Line + 1
 		}
 
 		errs.PushBack(&corgierr.Error{
 			Message: "missing func header",
+			Code:    corgierr.CodeMissingFuncHeader,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      expectPos,
 				Annotation: "expected the func header here",
@@ -49,6 +50,7 @@ func mainFile(f *file.File) *errList {
 
 			errs.PushBack(&corgierr.Error{
 				Message: "template block placeholder in main file",
+				Code:    corgierr.CodeTemplateBlockPlaceholderInMain,
 				ErrorAnnotation: anno.Anno(f, anno.Annotation{
 					Start:      itm.Position,
 					ToEOL:      true,
@@ -61,6 +63,7 @@ func mainFile(f *file.File) *errList {
 		case file.IfBlock:
 			errs.PushBack(&corgierr.Error{
 				Message: "`if block` in main file",
+				Code:    corgierr.CodeIfBlockInMain,
 				ErrorAnnotation: anno.Anno(f, anno.Annotation{
 					Start:      itm.Position,
 					ToEOL:      true,
@@ -88,6 +91,7 @@ func templateFile(f *file.File) *errList {
 	if f.Func != nil {
 		errs.PushBack(&corgierr.Error{
 			Message: "template file with `func` header",
+			Code:    corgierr.CodeTemplateFuncHeader,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      f.Func.Position,
 				Len:        len("func"),
@@ -115,6 +119,7 @@ func extendingFile(f *file.File) *errList {
 		default:
 			errs.PushBack(&corgierr.Error{
 				Message: fmt.Sprintf("unexpected top-level item %T", itm),
+				Code:    corgierr.CodeUnexpectedTopLevelItem,
 				ErrorAnnotation: anno.Anno(f, anno.Annotation{
 					Start: itm.Pos(),
 					ToEOL: true,
@@ -138,6 +143,7 @@ func libraryFile(f *file.File) *errList {
 	if f.Func != nil {
 		errs.PushBack(&corgierr.Error{
 			Message: "func header in use file",
+			Code:    corgierr.CodeUseFileFuncHeader,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      f.Func.Position,
 				Len:        len("func"),
@@ -154,6 +160,7 @@ func libraryFile(f *file.File) *errList {
 		default:
 			errs.PushBack(&corgierr.Error{
 				Message: fmt.Sprintf("unexpected top-level item %T", itm),
+				Code:    corgierr.CodeUnexpectedTopLevelItem,
 				ErrorAnnotation: anno.Anno(f, anno.Annotation{
 					Start:      itm.Pos(),
 					ToEOL:      true,
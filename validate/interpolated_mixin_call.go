@@ -28,6 +28,8 @@ func interpolatedMixinCallChecks(f *file.File) *errList {
 			for _, itm := range line {
 				if mci, ok := itm.(file.MixinCallInterpolation); ok {
 					errs.PushBackList(_topLevelAndInInterpolatedMixinCall(f, mci))
+					errs.PushBackList(_duplicateMixinCallArgs(f, mci.MixinCall))
+					errs.PushBackList(_spreadMixinCallArgs(f, mci.MixinCall))
 					errs.PushBackList(_requiredInterpolatedMixinCallAttributes(f, mci))
 					errs.PushBackList(_interpolatedMixinCallBlockExists(f, mci))
 				}
@@ -44,6 +46,7 @@ func _topLevelAndInInterpolatedMixinCall(f *file.File, mci file.MixinCallInterpo
 	if mci.MixinCall.Mixin.Mixin.WritesTopLevelAttributes {
 		return list.List1(&corgierr.Error{
 			Message: "interpolated mixin writes attributes",
+			Code:    corgierr.CodeInterpolatedMixinAttributes,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      mci.Position,
 				End:        interpolationEnd(mci.Value),
@@ -72,56 +75,35 @@ func _requiredInterpolatedMixinCallAttributes(f *file.File, mci file.MixinCallIn
 
 params:
 	for _, param := range mci.MixinCall.Mixin.Mixin.Params {
-		if param.Default != nil {
+		if param.Variadic {
+			// variadic params are never required, but their args still
+			// need a value
+			for _, arg := range mci.MixinCall.Args {
+				if arg.Name.Ident == param.Name.Ident {
+					if err := chainArgWithoutDefault(f, arg,
+						"args of variadic params can only be set by a chain expression with a default"); err != nil {
+						errs.PushBack(err)
+					}
+				}
+			}
+			continue
+		} else if param.Default != nil {
 			continue
 		}
 
 		for _, arg := range mci.MixinCall.Args {
 			if arg.Name.Ident == param.Name.Ident {
-				if len(arg.Value.Expressions) != 0 {
-					continue params
-				}
-
-				ce, ok := arg.Value.Expressions[0].(file.ChainExpression)
-				if !ok || ce.Default != nil {
-					continue params
+				if err := chainArgWithoutDefault(f, arg,
+					"this arg is required and can only be set by a chain expression with a default"); err != nil {
+					errs.PushBack(err)
 				}
-
-				var ceLen int
-				if len(ce.Chain) > 0 {
-					last := ce.Chain[len(ce.Chain)-1]
-
-					switch last := last.(type) {
-					case file.IndexExpression:
-						ceLen = last.RBracePos.Col - ce.Col
-					case file.DotIdentExpression:
-						ceLen = (last.Pos().Col - ce.Col) + len(".") + len(last.Ident.Ident)
-					case file.ParenExpression:
-						ceLen = last.RParenPos.Col - ce.Col
-					case file.TypeAssertionExpression:
-						ceLen = last.RParenPos.Col - ce.Col
-					}
-				} else {
-					ceLen = ce.DerefCount + len(ce.Root.Expression)
-					if ce.CheckRoot {
-						ceLen++
-					}
-				}
-
-				errs.PushBack(&corgierr.Error{
-					Message: "required mixin call arg set with chain expression without default",
-					ErrorAnnotation: anno.Anno(f, anno.Annotation{
-						Start:      ce.Position,
-						Len:        ceLen,
-						Annotation: "this arg is required and can only be set by a chain expression with a default",
-					}),
-				})
 				continue params
 			}
 		}
 
 		errs.PushBack(&corgierr.Error{
 			Message: "required mixin call arg not set",
+			Code:    corgierr.CodeMissingMixinCallArg,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      mci.MixinCall.Position,
 				Len:        annoLen,
@@ -148,6 +130,7 @@ func _interpolatedMixinCallBlockExists(f *file.File, mci file.MixinCallInterpola
 
 	return list.List1(&corgierr.Error{
 		Message: "interpolated mixin call has value, but called mixin has no `_` block",
+		Code:    corgierr.CodeMixinCallValueWithoutBlock,
 		ErrorAnnotation: anno.Anno(f, anno.Annotation{
 			Start:      start,
 			End:        end,
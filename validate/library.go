@@ -30,6 +30,7 @@ func libraryMixinNameConflicts(fs []*file.File) *errList {
 
 				errs.PushBack(&corgierr.Error{
 					Message: "duplicate mixin in package",
+					Code:    corgierr.CodeDuplicateLibraryMixin,
 					ErrorAnnotation: anno.Anno(f, anno.Annotation{
 						Start:      m.Name.Position,
 						Len:        len(m.Name.Ident),
@@ -43,13 +44,13 @@ func libraryMixinNameConflicts(fs []*file.File) *errList {
 						}),
 					},
 				})
-
-				foundMixins.PushBack(struct {
-					File  *file.File
-					Mixin file.Mixin
-				}{File: f, Mixin: m})
 				break
 			}
+
+			foundMixins.PushBack(struct {
+				File  *file.File
+				Mixin file.Mixin
+			}{File: f, Mixin: m})
 		}
 	}
 
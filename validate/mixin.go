@@ -24,6 +24,7 @@ func mixinChecks(f *file.File) *errList {
 
 		errs.PushBackList(_mixinParamsHaveType(f, m))
 		errs.PushBackList(_duplicateMixinParams(f, m))
+		errs.PushBackList(_mixinBlockParams(f, m))
 
 		return true, nil
 	})
@@ -53,6 +54,7 @@ func mixinsInMixins(f *file.File) *errList {
 
 			errs.PushBack(&corgierr.Error{
 				Message: "mixin declared inside other mixin",
+				Code:    corgierr.CodeNestedMixin,
 				ErrorAnnotation: anno.Anno(f, anno.Annotation{
 					Start:      inner.Position,
 					Len:        (inner.Name.Col - inner.Col) + len(inner.Name.Ident),
@@ -104,6 +106,7 @@ func duplicateMixinNames(f *file.File) *errList {
 			if otherE.V().Name.Ident == m.Name.Ident {
 				errs.PushBack(&corgierr.Error{
 					Message: "duplicate mixin name within same scope",
+					Code:    corgierr.CodeDuplicateMixin,
 					ErrorAnnotation: anno.Anno(f, anno.Annotation{
 						Start:      m.Name.Position,
 						Len:        len(m.Name.Ident),
@@ -141,6 +144,7 @@ func _mixinParamsHaveType(f *file.File, m file.Mixin) *errList {
 
 		errs.PushBack(&corgierr.Error{
 			Message: "unable to infer type of mixin param",
+			Code:    corgierr.CodeUninferrableParamType,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      param.Name.Position,
 				Len:        len(param.Name.Ident),
@@ -158,6 +162,101 @@ func _mixinParamsHaveType(f *file.File, m file.Mixin) *errList {
 	return &errs
 }
 
+// the params of mixin blocks have types, and all placeholders of a block
+// have the same params.
+func _mixinBlockParams(f *file.File, m file.Mixin) *errList {
+	var errs errList
+
+	firsts := make(map[string]file.Block)
+
+	fileutil.Walk(m.Body, func(parents []fileutil.WalkContext, ctx fileutil.WalkContext) (dive bool, err error) {
+		b, ok := (*ctx.Item).(file.Block)
+		if !ok {
+			return true, nil
+		}
+
+		if len(parents) > 0 {
+			if _, ok := (*parents[len(parents)-1].Item).(file.MixinCall); ok {
+				return true, nil
+			}
+		}
+
+		for _, param := range b.Params {
+			if param.Type != nil {
+				continue
+			}
+
+			errs.PushBack(&corgierr.Error{
+				Message: "untyped mixin block param",
+				Code:    corgierr.CodeUntypedMixinBlockParam,
+				ErrorAnnotation: anno.Anno(f, anno.Annotation{
+					Start:      param.Position,
+					Len:        len(param.Name.Ident),
+					Annotation: "the params of a mixin's blocks need a type",
+				}),
+				Suggestions: []corgierr.Suggestion{
+					{
+						Suggestion: "give this param the type of the variable `" + param.Name.Ident + "`",
+						Example:    "`" + param.Name.Ident + " string`",
+					},
+				},
+			})
+		}
+
+		first, ok := firsts[b.Name.Ident]
+		if !ok {
+			firsts[b.Name.Ident] = b
+			return true, nil
+		}
+
+		if sameBlockParamTypes(first.Params, b.Params) {
+			return true, nil
+		}
+
+		errs.PushBack(&corgierr.Error{
+			Message: "mismatched mixin block params",
+			Code:    corgierr.CodeMismatchedMixinBlockParams,
+			ErrorAnnotation: anno.Anno(f, anno.Annotation{
+				Start:      b.Position,
+				Len:        (b.Name.Col - b.Col) + len(b.Name.Ident),
+				Annotation: "the params of this placeholder differ from those of the first",
+			}),
+			HintAnnotations: []corgierr.Annotation{
+				anno.Anno(f, anno.Annotation{
+					Start:      first.Position,
+					Len:        (first.Name.Col - first.Col) + len(first.Name.Ident),
+					Annotation: "block `" + b.Name.Ident + "` first placed here",
+				}),
+			},
+			Suggestions: []corgierr.Suggestion{
+				{Suggestion: "all placeholders of a block must have the same number of params, of the same types"},
+			},
+		})
+		return true, nil
+	})
+
+	return &errs
+}
+
+func sameBlockParamTypes(a, b []file.BlockParam) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].Type == nil || b[i].Type == nil {
+			// already reported as untyped
+			continue
+		}
+
+		if a[i].Type.Type != b[i].Type.Type {
+			return false
+		}
+	}
+
+	return true
+}
+
 func _duplicateMixinParams(f *file.File, m file.Mixin) *errList {
 	if len(m.Params) <= 1 {
 		return &errList{}
@@ -198,6 +297,7 @@ func _duplicateMixinParams(f *file.File, m file.Mixin) *errList {
 
 		errs.PushBack(&corgierr.Error{
 			Message: "duplicate mixin parameter",
+			Code:    corgierr.CodeDuplicateMixinParam,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      a.Name.Position,
 				Len:        len(a.Name.Ident),
@@ -2,6 +2,7 @@ package validate
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/mavolin/corgi/corgierr"
 	"github.com/mavolin/corgi/file"
@@ -25,10 +26,12 @@ func mixinCallChecks(f *file.File) *errList {
 
 		errs.PushBackList(_mixinCallArgsExist(f, mc))
 		errs.PushBackList(_duplicateMixinCallArgs(f, mc))
+		errs.PushBackList(_spreadMixinCallArgs(f, mc))
 		errs.PushBackList(_requiredMixinCallAttributes(f, mc))
 		errs.PushBackList(_mixinCallBody(f, mc))
 		errs.PushBackList(_mixinCallBlocksExist(f, mc))
 		errs.PushBackList(_duplicateMixinCallBlocks(f, mc))
+		errs.PushBackList(_mixinCallBlockParams(f, mc))
 		errs.PushBackList(_mixinCallBlockAttrs(f, mc))
 
 		return true, nil
@@ -51,6 +54,7 @@ args:
 
 		errs.PushBack(&corgierr.Error{
 			Message: "non-existent mixin call arg",
+			Code:    corgierr.CodeUnknownMixinCallArg,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				ContextStart: mc.Position,
 				Start:        arg.Name.Position,
@@ -63,34 +67,88 @@ args:
 	return &errs
 }
 
-// mixin call doesn't specify any args twice.
+// mixin call doesn't specify any args twice, unless they are variadic.
 func _duplicateMixinCallArgs(f *file.File, mc file.MixinCall) *errList {
 	var errs errList
 
 	for i, arg := range mc.Args {
+		var variadic bool
+		for _, param := range mc.Mixin.Mixin.Params {
+			if param.Name.Ident == arg.Name.Ident {
+				variadic = param.Variadic
+				break
+			}
+		}
+
 		for _, cmp := range mc.Args[:i] {
-			if arg.Name.Ident == cmp.Name.Ident {
-				errs.PushBack(&corgierr.Error{
-					Message: "duplicate mixin call arg",
-					ErrorAnnotation: anno.Anno(f, anno.Annotation{
+			if arg.Name.Ident != cmp.Name.Ident {
+				continue
+			}
+
+			msg, suggestion := "duplicate mixin call arg", "you should only set the arg once"
+			if variadic {
+				if !arg.Spread && !cmp.Spread {
+					continue
+				}
+
+				msg = "spread mixin call arg combined with other args"
+				suggestion = "either spread a single slice, or set the arg once for every element"
+			}
+
+			errs.PushBack(&corgierr.Error{
+				Message: msg,
+				Code:    corgierr.CodeDuplicateMixinCallArg,
+				ErrorAnnotation: anno.Anno(f, anno.Annotation{
+					ContextStart: mc.Position,
+					Start:        arg.Name.Position,
+					Len:          len(arg.Name.Ident),
+					Annotation:   "and then here again",
+				}),
+				HintAnnotations: []corgierr.Annotation{
+					anno.Anno(f, anno.Annotation{
 						ContextStart: mc.Position,
-						Start:        arg.Name.Position,
-						Len:          len(arg.Name.Ident),
-						Annotation:   "and then here again",
+						Start:        cmp.Name.Position,
+						Len:          len(cmp.Name.Ident),
+						Annotation:   "you first set `" + cmp.Name.Ident + "` here",
 					}),
-					HintAnnotations: []corgierr.Annotation{
-						anno.Anno(f, anno.Annotation{
-							ContextStart: mc.Position,
-							Start:        cmp.Name.Position,
-							Len:          len(cmp.Name.Ident),
-							Annotation:   "you first set `" + cmp.Name.Ident + "` here",
-						}),
-					},
-					Suggestions: []corgierr.Suggestion{
-						{Suggestion: "you should only set the arg once"},
-					},
-				})
+				},
+				Suggestions: []corgierr.Suggestion{
+					{Suggestion: suggestion},
+				},
+			})
+		}
+	}
+
+	return &errs
+}
+
+// only variadic params are set using spread args.
+func _spreadMixinCallArgs(f *file.File, mc file.MixinCall) *errList {
+	var errs errList
+
+	for _, arg := range mc.Args {
+		if !arg.Spread {
+			continue
+		}
+
+		for _, param := range mc.Mixin.Mixin.Params {
+			if param.Name.Ident != arg.Name.Ident || param.Variadic {
+				continue
 			}
+
+			errs.PushBack(&corgierr.Error{
+				Message: "spread arg for non-variadic mixin param",
+				Code:    corgierr.CodeSpreadNonVariadicMixinArg,
+				ErrorAnnotation: anno.Anno(f, anno.Annotation{
+					ContextStart: mc.Position,
+					Start:        arg.Name.Position,
+					Len:          len(arg.Name.Ident),
+					Annotation:   "`" + arg.Name.Ident + "` is not variadic, so you can't spread a slice into it",
+				}),
+				Suggestions: []corgierr.Suggestion{
+					{Suggestion: "remove the `...`"},
+				},
+			})
 		}
 	}
 
@@ -103,56 +161,35 @@ func _requiredMixinCallAttributes(f *file.File, mc file.MixinCall) *errList {
 
 params:
 	for _, param := range mc.Mixin.Mixin.Params {
-		if param.Default != nil {
+		if param.Variadic {
+			// variadic params are never required, but their args still
+			// need a value
+			for _, arg := range mc.Args {
+				if arg.Name.Ident == param.Name.Ident {
+					if err := chainArgWithoutDefault(f, arg,
+						"args of variadic params can only be set by a chain expression with a default"); err != nil {
+						errs.PushBack(err)
+					}
+				}
+			}
+			continue
+		} else if param.Default != nil {
 			continue
 		}
 
 		for _, arg := range mc.Args {
 			if arg.Name.Ident == param.Name.Ident {
-				if len(arg.Value.Expressions) != 0 {
-					continue params
-				}
-
-				ce, ok := arg.Value.Expressions[0].(file.ChainExpression)
-				if !ok || ce.Default != nil {
-					continue params
+				if err := chainArgWithoutDefault(f, arg,
+					"this arg is required and can only be set by a chain expression with a default"); err != nil {
+					errs.PushBack(err)
 				}
-
-				var ceLen int
-				if len(ce.Chain) > 0 {
-					last := ce.Chain[len(ce.Chain)-1]
-
-					switch last := last.(type) {
-					case file.IndexExpression:
-						ceLen = last.RBracePos.Col - ce.Col
-					case file.DotIdentExpression:
-						ceLen = (last.Pos().Col - ce.Col) + len(".") + len(last.Ident.Ident)
-					case file.ParenExpression:
-						ceLen = last.RParenPos.Col - ce.Col
-					case file.TypeAssertionExpression:
-						ceLen = last.RParenPos.Col - ce.Col
-					}
-				} else {
-					ceLen = ce.DerefCount + len(ce.Root.Expression)
-					if ce.CheckRoot {
-						ceLen++
-					}
-				}
-
-				errs.PushBack(&corgierr.Error{
-					Message: "required mixin call arg set with chain expression without default",
-					ErrorAnnotation: anno.Anno(f, anno.Annotation{
-						Start:      ce.Position,
-						Len:        ceLen,
-						Annotation: "this arg is required and can only be set by a chain expression with a default",
-					}),
-				})
 				continue params
 			}
 		}
 
 		errs.PushBack(&corgierr.Error{
 			Message: "required mixin call arg not set",
+			Code:    corgierr.CodeMissingMixinCallArg,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      mc.Position,
 				Len:        len("+") + (mc.Name.Col - mc.Col) + len(mc.Name.Ident),
@@ -164,6 +201,51 @@ params:
 	return &errs
 }
 
+// chainArgWithoutDefault returns an error, if arg is set with a chain
+// expression without a default, which would leave the arg unset, if the
+// chain expression does not evaluate.
+func chainArgWithoutDefault(f *file.File, arg file.MixinArg, annotation string) *corgierr.Error {
+	if len(arg.Value.Expressions) != 1 {
+		return nil
+	}
+
+	ce, ok := arg.Value.Expressions[0].(file.ChainExpression)
+	if !ok || ce.Default != nil {
+		return nil
+	}
+
+	var ceLen int
+	if len(ce.Chain) > 0 {
+		last := ce.Chain[len(ce.Chain)-1]
+
+		switch last := last.(type) {
+		case file.IndexExpression:
+			ceLen = last.RBracePos.Col - ce.Col
+		case file.DotIdentExpression:
+			ceLen = (last.Pos().Col - ce.Col) + len(".") + len(last.Ident.Ident)
+		case file.ParenExpression:
+			ceLen = last.RParenPos.Col - ce.Col
+		case file.TypeAssertionExpression:
+			ceLen = last.RParenPos.Col - ce.Col
+		}
+	} else {
+		ceLen = ce.DerefCount + len(ce.Root.Expression)
+		if ce.CheckRoot {
+			ceLen++
+		}
+	}
+
+	return &corgierr.Error{
+		Message: "required mixin call arg set with chain expression without default",
+		Code:    corgierr.CodeRequiredArgChainWithoutDefault,
+		ErrorAnnotation: anno.Anno(f, anno.Annotation{
+			Start:      ce.Position,
+			Len:        ceLen,
+			Annotation: annotation,
+		}),
+	}
+}
+
 // mixin call body only contains for, if, if block, switch, &s, attribute mixin
 // calls, and top-level blocks.
 func _mixinCallBody(f *file.File, mc file.MixinCall) *errList {
@@ -194,6 +276,12 @@ func _mixinCallBody(f *file.File, mc file.MixinCall) *errList {
 			return true, nil
 		case file.For:
 			return true, nil
+		case file.Break:
+			// reported by loopControls
+			return false, nil
+		case file.Continue:
+			// reported by loopControls
+			return false, nil
 		case file.BlockExpansion:
 			return true, nil
 		case file.And:
@@ -209,6 +297,7 @@ func _mixinCallBody(f *file.File, mc file.MixinCall) *errList {
 
 			errs.PushBack(&corgierr.Error{
 				Message: "use of & in mixin call to mixin without &-placeholder",
+				Code:    corgierr.CodeAndWithoutPlaceholder,
 				ErrorAnnotation: anno.Anno(f, anno.Annotation{
 					Start:      itm.Position,
 					Annotation: "no element to place these attributes on",
@@ -238,6 +327,7 @@ func _mixinCallBody(f *file.File, mc file.MixinCall) *errList {
 
 				errs.PushBack(&corgierr.Error{
 					Message: "conditional mixin call block",
+					Code:    corgierr.CodeConditionalMixinCallBlock,
 					ErrorAnnotation: anno.Anno(f, anno.Annotation{
 						ContextStartDelta: -1,
 						Start:             itm.Position,
@@ -256,6 +346,7 @@ func _mixinCallBody(f *file.File, mc file.MixinCall) *errList {
 		default:
 			errs.PushBack(&corgierr.Error{
 				Message: fmt.Sprintf("unexpected item %T in mixin call", itm),
+				Code:    corgierr.CodeUnexpectedMixinCallItem,
 				ErrorAnnotation: anno.Anno(f, anno.Annotation{
 					Start:      itm.Pos(),
 					ToEOL:      true,
@@ -281,6 +372,7 @@ func _mixinCallBlocksExist(f *file.File, mc file.MixinCall) *errList {
 
 			return list.List1(&corgierr.Error{
 				Message: "unknown block in mixin call",
+				Code:    corgierr.CodeUnknownMixinCallBlock,
 				ErrorAnnotation: anno.Anno(f, anno.Annotation{
 					Start:      sh.Position,
 					Annotation: "this mixin has no `_` block, so you can't use a shorthand",
@@ -307,6 +399,7 @@ body:
 
 		errs.PushBack(&corgierr.Error{
 			Message: "unknown block in mixin call",
+			Code:    corgierr.CodeUnknownMixinCallBlock,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      block.Position,
 				Len:        (block.Name.Col - block.Col) + len(block.Name.Ident),
@@ -334,6 +427,7 @@ func _duplicateMixinCallBlocks(f *file.File, mc file.MixinCall) *errList {
 			if block.Name.Ident == otherE.V().Name.Ident {
 				errs.PushBack(&corgierr.Error{
 					Message: "mixin call block filled twice",
+					Code:    corgierr.CodeMixinCallBlockFilledTwice,
 					ErrorAnnotation: anno.Anno(f, anno.Annotation{
 						Start:      block.Position,
 						Len:        (block.Name.Col - block.Col) + len(block.Name.Ident),
@@ -357,6 +451,82 @@ func _duplicateMixinCallBlocks(f *file.File, mc file.MixinCall) *errList {
 	return &errs
 }
 
+// the params of mixin call blocks are untyped, have no values, and match those
+// of the mixin's block.
+func _mixinCallBlockParams(f *file.File, mc file.MixinCall) *errList {
+	var errs errList
+
+	for _, itm := range mc.Body {
+		block, ok := itm.(file.Block)
+		if !ok {
+			continue
+		}
+
+		for _, param := range block.Params {
+			if param.Value != nil {
+				errs.PushBack(&corgierr.Error{
+					Message: "mixin call block param with value",
+					Code:    corgierr.CodeMixinCallBlockParamValue,
+					ErrorAnnotation: anno.Anno(f, anno.Annotation{
+						Start:      *param.AssignPos,
+						Len:        1,
+						Annotation: "the params of mixin call blocks are bound to the values passed by the mixin",
+					}),
+					HintAnnotations: []corgierr.Annotation{inThisMixinCall(f, mc)},
+					Suggestions: []corgierr.Suggestion{
+						{Suggestion: "remove the value", Example: "`block " + block.Name.Ident + "(" + param.Name.Ident + ")`"},
+					},
+				})
+			}
+
+			if param.Type == nil {
+				continue
+			}
+
+			errs.PushBack(&corgierr.Error{
+				Message: "typed mixin call block param",
+				Code:    corgierr.CodeTypedMixinCallBlockParam,
+				ErrorAnnotation: anno.Anno(f, anno.Annotation{
+					Start:      param.Type.Position,
+					Len:        len(param.Type.Type),
+					Annotation: "the params of mixin call blocks take their types from the mixin",
+				}),
+				HintAnnotations: []corgierr.Annotation{inThisMixinCall(f, mc)},
+				Suggestions: []corgierr.Suggestion{
+					{Suggestion: "remove the type", Example: "`block " + block.Name.Ident + "(" + param.Name.Ident + ")`"},
+				},
+			})
+		}
+
+		if block.Params == nil {
+			continue
+		}
+
+		for _, mblock := range mc.Mixin.Mixin.Blocks {
+			if block.Name.Ident != mblock.Name || len(block.Params) == len(mblock.Params) {
+				continue
+			}
+
+			errs.PushBack(&corgierr.Error{
+				Message: "wrong number of mixin call block params",
+				Code:    corgierr.CodeMixinCallBlockParamCount,
+				ErrorAnnotation: anno.Anno(f, anno.Annotation{
+					Start:      block.Position,
+					Len:        (block.Name.Col - block.Col) + len(block.Name.Ident),
+					Annotation: fmt.Sprintf("block `%s` has %d params, but you bound %d",
+						block.Name.Ident, len(mblock.Params), len(block.Params)),
+				}),
+				HintAnnotations: []corgierr.Annotation{inThisMixinCall(f, mc)},
+				Suggestions: []corgierr.Suggestion{
+					{Suggestion: "bind a name for each param of the block, or omit the param list to ignore them"},
+				},
+			})
+		}
+	}
+
+	return &errs
+}
+
 // check that only mixin blocks that can contain attrs, actually contain attrs.
 func _mixinCallBlockAttrs(f *file.File, mc file.MixinCall) *errList {
 	if len(mc.Body) == 1 {
@@ -378,6 +548,7 @@ func _mixinCallBlockAttrs(f *file.File, mc file.MixinCall) *errList {
 				case file.And:
 					return list.List1(&corgierr.Error{
 						Message: "top-level attribute in mixin call block that doesn't allow top-level attributes",
+						Code:    corgierr.CodeTopLevelAttributeInMixinCallBlock,
 						ErrorAnnotation: anno.Anno(f, anno.Annotation{
 							Start: attr.Position,
 							Annotation: "this mixin places the `_` block after it has written to the body of an element\n" +
@@ -388,6 +559,7 @@ func _mixinCallBlockAttrs(f *file.File, mc file.MixinCall) *errList {
 				case file.MixinCall:
 					return list.List1(&corgierr.Error{
 						Message: "top-level attribute in mixin call block that doesn't allow top-level attributes",
+						Code:    corgierr.CodeTopLevelAttributeInMixinCallBlock,
 						ErrorAnnotation: anno.Anno(f, anno.Annotation{
 							Start: attr.Position,
 							Len:   len("+") + (mc.Name.Col - mc.Col) + len(mc.Name.Ident),
@@ -431,6 +603,7 @@ body:
 			case file.And:
 				errs.PushBack(&corgierr.Error{
 					Message: "top-level attribute in mixin call block that doesn't allow top-level attributes",
+					Code:    corgierr.CodeTopLevelAttributeInMixinCallBlock,
 					ErrorAnnotation: anno.Anno(f, anno.Annotation{
 						Start: attr.Position,
 						Annotation: "this mixin places the `" + mcBlock.Name.Ident + "` block after it has written\n" +
@@ -441,6 +614,7 @@ body:
 			case file.MixinCall:
 				errs.PushBack(&corgierr.Error{
 					Message: "top-level attribute in mixin call block that doesn't allow top-level attributes",
+					Code:    corgierr.CodeTopLevelAttributeInMixinCallBlock,
 					ErrorAnnotation: anno.Anno(f, anno.Annotation{
 						Start: attr.Position,
 						Len:   len("+") + (mc.Name.Col - mc.Col) + len(mc.Name.Ident),
@@ -489,6 +663,7 @@ func andPlaceholderPlacement(f *file.File) *errList {
 
 		errs.PushBack(&corgierr.Error{
 			Message: "&-placeholder used outside of mixin",
+			Code:    corgierr.CodeAndPlaceholderOutsideMixin,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      ap.Position,
 				Len:        2,
@@ -501,3 +676,129 @@ func andPlaceholderPlacement(f *file.File) *errList {
 
 	return &errs
 }
+
+// calls to generic mixins have as many type args as the mixin has type
+// params, either given explicitly or inferred.
+func mixinCallTypeArgs(f *file.File) *errList {
+	var errs errList
+
+	fileutil.Walk(f.Scope, func(parents []fileutil.WalkContext, ctx fileutil.WalkContext) (dive bool, err error) {
+		var mcs []file.MixinCall
+		switch itm := (*ctx.Item).(type) {
+		case file.Include:
+			return false, nil
+		case file.MixinCall:
+			mcs = []file.MixinCall{itm}
+		case file.InlineText:
+			mcs = _textMixinCalls(itm.Text)
+		case file.ArrowBlock:
+			mcs = _textMixinCalls(itm.Lines...)
+		case file.Element:
+			mcs = _attributeMixinCalls(itm.Attributes)
+		case file.DivShorthand:
+			mcs = _attributeMixinCalls(itm.Attributes)
+		case file.And:
+			mcs = _attributeMixinCalls(itm.Attributes)
+		}
+
+		for _, mc := range mcs {
+			errs.PushBackList(_mixinCallTypeArgs(f, mc))
+		}
+
+		return true, nil
+	})
+
+	return &errs
+}
+
+func _mixinCallTypeArgs(f *file.File, mc file.MixinCall) *errList {
+	m := mc.Mixin.Mixin
+
+	if len(mc.TypeArgs) > 0 && len(mc.TypeArgs) != len(m.TypeParams) {
+		first, last := mc.TypeArgs[0], mc.TypeArgs[len(mc.TypeArgs)-1]
+
+		var annotation string
+		switch len(m.TypeParams) {
+		case 0:
+			annotation = "`" + m.Name.Ident + "` is not generic"
+		case 1:
+			annotation = "`" + m.Name.Ident + "` has 1 type param"
+		default:
+			annotation = fmt.Sprintf("`%s` has %d type params", m.Name.Ident, len(m.TypeParams))
+		}
+
+		return list.List1(&corgierr.Error{
+			Message: "wrong number of mixin type args",
+			Code:    corgierr.CodeMixinTypeArgCount,
+			ErrorAnnotation: anno.Anno(f, anno.Annotation{
+				ContextStart: mc.Position,
+				Start:        first.Position,
+				Len:          (last.Col - first.Col) + len(last.Type),
+				Annotation:   annotation,
+			}),
+		})
+	}
+
+	if len(m.TypeParams) == 0 || len(mc.TypeArgs) > 0 || mc.InferredTypeArgs != nil {
+		return &errList{}
+	}
+
+	typeParams := make([]string, len(m.TypeParams))
+	exampleArgs := make([]string, len(m.TypeParams))
+	for i, tp := range m.TypeParams {
+		typeParams[i] = tp.Name.Ident
+		exampleArgs[i] = "string"
+	}
+
+	return list.List1(&corgierr.Error{
+		Message: "unable to infer mixin type args",
+		Code:    corgierr.CodeUninferrableMixinTypeArgs,
+		ErrorAnnotation: anno.Anno(f, anno.Annotation{
+			Start:      mc.Position,
+			Len:        len("+") + (mc.Name.Col - mc.Col) + len(mc.Name.Ident),
+			Annotation: "cannot infer " + strings.Join(typeParams, ", ") + " from the args of this call",
+		}),
+		Suggestions: []corgierr.Suggestion{
+			{
+				Suggestion: "specify the type args explicitly",
+				Example:    "`+" + m.Name.Ident + "[" + strings.Join(exampleArgs, ", ") + "](...)`",
+			},
+		},
+	})
+}
+
+func _textMixinCalls(lns ...file.TextLine) []file.MixinCall {
+	var mcs []file.MixinCall
+
+	for _, ln := range lns {
+		for _, itm := range ln {
+			switch itm := itm.(type) {
+			case file.MixinCallInterpolation:
+				mcs = append(mcs, itm.MixinCall)
+			case file.ElementInterpolation:
+				mcs = append(mcs, _attributeMixinCalls(itm.Element.Attributes)...)
+			}
+		}
+	}
+
+	return mcs
+}
+
+func _attributeMixinCalls(acolls []file.AttributeCollection) []file.MixinCall {
+	var mcs []file.MixinCall
+
+	for _, acoll := range acolls {
+		alist, ok := acoll.(file.AttributeList)
+		if !ok {
+			continue
+		}
+
+		for _, attr := range alist.Attributes {
+			if mcAttr, ok := attr.(file.MixinCallAttribute); ok {
+				mcs = append(mcs, mcAttr.MixinCall)
+			}
+		}
+	}
+
+	return mcs
+}
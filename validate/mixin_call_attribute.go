@@ -55,6 +55,7 @@ func _mixinCallAttributeIsPlain(f *file.File, mca file.MixinCallAttribute) *errL
 	if at != woof.ContentTypePlain {
 		return list.List1(&corgierr.Error{
 			Message: "mixin call attribute as " + at.String() + " attribute",
+			Code:    corgierr.CodeMixinCallAttributeType,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      mca.Position,
 				End:        mixinCallAttributeEnd(mca),
@@ -71,6 +72,7 @@ func _mixinCallAttributesOnlyWriteText(f *file.File, mca file.MixinCallAttribute
 	if lm.WritesTopLevelAttributes {
 		return list.List1(&corgierr.Error{
 			Message: "mixin call attribute: mixin writes other attributes",
+			Code:    corgierr.CodeMixinCallAttributeAttributes,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      mca.Position,
 				End:        mixinCallAttributeEnd(mca),
@@ -83,6 +85,7 @@ func _mixinCallAttributesOnlyWriteText(f *file.File, mca file.MixinCallAttribute
 	} else if lm.WritesElements {
 		return list.List1(&corgierr.Error{
 			Message: "mixin call attribute: mixin writes elements",
+			Code:    corgierr.CodeMixinCallAttributeElements,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      mca.Position,
 				End:        mixinCallAttributeEnd(mca),
@@ -101,6 +104,7 @@ func _topLevelAndInMixinCallAttribute(f *file.File, mca file.MixinCallAttribute)
 	if mca.MixinCall.Mixin.Mixin.WritesTopLevelAttributes {
 		return list.List1(&corgierr.Error{
 			Message: "interpolated mixin writes attributes",
+			Code:    corgierr.CodeInterpolatedMixinAttributes,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      mca.Position,
 				End:        mixinCallAttributeEnd(mca),
@@ -124,56 +128,35 @@ func _requiredMixinCallAttributeAttributes(f *file.File, mca file.MixinCallAttri
 
 params:
 	for _, param := range mca.MixinCall.Mixin.Mixin.Params {
-		if param.Default != nil {
+		if param.Variadic {
+			// variadic params are never required, but their args still
+			// need a value
+			for _, arg := range mca.MixinCall.Args {
+				if arg.Name.Ident == param.Name.Ident {
+					if err := chainArgWithoutDefault(f, arg,
+						"args of variadic params can only be set by a chain expression with a default"); err != nil {
+						errs.PushBack(err)
+					}
+				}
+			}
+			continue
+		} else if param.Default != nil {
 			continue
 		}
 
 		for _, arg := range mca.MixinCall.Args {
 			if arg.Name.Ident == param.Name.Ident {
-				if len(arg.Value.Expressions) != 0 {
-					continue params
-				}
-
-				ce, ok := arg.Value.Expressions[0].(file.ChainExpression)
-				if !ok || ce.Default != nil {
-					continue params
+				if err := chainArgWithoutDefault(f, arg,
+					"this arg is required and can only be set by a chain expression with a default"); err != nil {
+					errs.PushBack(err)
 				}
-
-				var ceLen int
-				if len(ce.Chain) > 0 {
-					last := ce.Chain[len(ce.Chain)-1]
-
-					switch last := last.(type) {
-					case file.IndexExpression:
-						ceLen = last.RBracePos.Col - ce.Col
-					case file.DotIdentExpression:
-						ceLen = (last.Pos().Col - ce.Col) + len(".") + len(last.Ident.Ident)
-					case file.ParenExpression:
-						ceLen = last.RParenPos.Col - ce.Col
-					case file.TypeAssertionExpression:
-						ceLen = last.RParenPos.Col - ce.Col
-					}
-				} else {
-					ceLen = ce.DerefCount + len(ce.Root.Expression)
-					if ce.CheckRoot {
-						ceLen++
-					}
-				}
-
-				errs.PushBack(&corgierr.Error{
-					Message: "required mixin call arg set with chain expression without default",
-					ErrorAnnotation: anno.Anno(f, anno.Annotation{
-						Start:      ce.Position,
-						Len:        ceLen,
-						Annotation: "this arg is required and can only be set by a chain expression with a default",
-					}),
-				})
 				continue params
 			}
 		}
 
 		errs.PushBack(&corgierr.Error{
 			Message: "required mixin call arg not set",
+			Code:    corgierr.CodeMissingMixinCallArg,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      mca.MixinCall.Position,
 				Len:        len("+") + (mca.MixinCall.Name.Col - mca.MixinCall.Col) + len(mca.MixinCall.Name.Ident),
@@ -200,6 +183,7 @@ func _mixinCallAttributeBlockExists(f *file.File, mca file.MixinCallAttribute) *
 
 	return list.List1(&corgierr.Error{
 		Message: "mixin call attribute has value, but called mixin has no `_` block",
+		Code:    corgierr.CodeMixinCallValueWithoutBlock,
 		ErrorAnnotation: anno.Anno(f, anno.Annotation{
 			Start:      start,
 			End:        end,
@@ -36,6 +36,7 @@ func useNamespaces(f *file.File) *errList {
 					case usePath == cmpUsePath:
 						errs.PushBack(&corgierr.Error{
 							Message: "duplicate use",
+							Code:    corgierr.CodeDuplicateUse,
 							ErrorAnnotation: anno.Anno(f, anno.Annotation{
 								Start:      spec.Path.Position,
 								ToEOL:      true,
@@ -53,6 +54,7 @@ func useNamespaces(f *file.File) *errList {
 					case spec.Alias != nil && cmpSpec.Alias != nil && spec.Alias.Ident == cmpSpec.Alias.Ident:
 						errs.PushBack(&corgierr.Error{
 							Message: "duplicate use alias",
+							Code:    corgierr.CodeDuplicateUseAlias,
 							ErrorAnnotation: anno.Anno(f, anno.Annotation{
 								Start:      spec.Alias.Position,
 								Len:        len(spec.Alias.Ident),
@@ -68,16 +70,27 @@ func useNamespaces(f *file.File) *errList {
 							Suggestions: []corgierr.Suggestion{{Suggestion: "use a different alias for one of these"}},
 						})
 					default:
+						start := spec.Path.Position
+						if spec.Alias != nil {
+							start = spec.Alias.Position
+						}
+
+						cmpStart := cmpSpec.Path.Position
+						if cmpSpec.Alias != nil {
+							cmpStart = cmpSpec.Alias.Position
+						}
+
 						errs.PushBack(&corgierr.Error{
 							Message: "use namespace collision",
+							Code:    corgierr.CodeUseNamespaceCollision,
 							ErrorAnnotation: anno.Anno(f, anno.Annotation{
-								Start:      spec.Alias.Position,
+								Start:      start,
 								ToEOL:      true,
 								Annotation: "duplicate",
 							}),
 							HintAnnotations: []corgierr.Annotation{
 								anno.Anno(f, anno.Annotation{
-									Start:      cmpSpec.Alias.Position,
+									Start:      cmpStart,
 									ToEOL:      true,
 									Annotation: "first use with this namespace",
 								}),
@@ -4,6 +4,7 @@ import (
 	"path"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/mavolin/corgi/corgierr"
 	"github.com/mavolin/corgi/file"
@@ -60,6 +61,7 @@ func duplicateImports(f *file.File) *errList {
 
 				errs.PushBack(&corgierr.Error{
 					Message: "duplicate import",
+					Code:    corgierr.CodeDuplicateImport,
 					ErrorAnnotation: corgierr.Annotation{
 						File:         f,
 						ContextStart: a.Path.Line,
@@ -183,6 +185,7 @@ func importNamespaces(cmps map[string]importNamespace, f *file.File) *errList {
 
 			errs.PushBack(&corgierr.Error{
 				Message: "duplicate import namespace",
+				Code:    corgierr.CodeDuplicateImportNamespace,
 				ErrorAnnotation: corgierr.Annotation{
 					File:         f,
 					ContextStart: a.Path.Line,
@@ -228,6 +231,7 @@ func usePathBaseIsValidIdent(f *file.File) *errList {
 			if !identRegexp.MatchString(base) {
 				errs.PushBack(&corgierr.Error{
 					Message: "use path with non-identifier as base",
+					Code:    corgierr.CodeUseNonIdentifierBase,
 					ErrorAnnotation: anno.Anno(f, anno.Annotation{
 						Start:       spec.Path.Position,
 						StartOffset: 1 + len(spec.Path.Contents) - len(base),
@@ -291,6 +295,7 @@ func unusedUses(f *file.File) *errList {
 	for _, spec := range unusedSpecs {
 		errs.PushBack(&corgierr.Error{
 			Message: "unused `use`",
+			Code:    corgierr.CodeUnusedUse,
 			ErrorAnnotation: anno.Anno(f, anno.Annotation{
 				Start:      spec.Position,
 				ToEOL:      true,
@@ -364,3 +369,136 @@ func _unusedUsesTextLines(unusedSpecs *[]file.UseSpec, lns ...file.TextLine) {
 		}
 	}
 }
+
+// contextParam checks that the name of the context.Context param added by a
+// `//corgi:context [name]` machine comment is not also the name of a param of
+// the main file's func.
+func contextParam(f *file.File) *errList {
+	if f.Type != file.TypeMain || f.Func == nil {
+		return &errList{}
+	}
+
+	var errs errList
+
+	for _, comm := range f.TopLevelComments {
+		mcom := fileutil.ParseMachineComment(comm)
+		if mcom == nil || mcom.Namespace != "corgi" || mcom.Directive != "context" {
+			continue
+		}
+
+		name := mcom.Args
+		if name == "" {
+			name = "ctx"
+		}
+
+		for _, param := range f.Func.Params {
+			for _, paramName := range param.Names {
+				if paramName.Ident != name {
+					continue
+				}
+
+				errs.PushBack(&corgierr.Error{
+					Message: "context param collides with func param",
+					Code:    corgierr.CodeContextParamCollision,
+					ErrorAnnotation: anno.Anno(f, anno.Annotation{
+						Start:      paramName.Position,
+						Len:        len(paramName.Ident),
+						Annotation: "this param has the same name as the context.Context param",
+					}),
+					HintAnnotations: []corgierr.Annotation{
+						anno.Anno(f, anno.Annotation{
+							Start:      comm.Position,
+							ToEOL:      true,
+							Annotation: "the context.Context param `" + name + "` is added here",
+						}),
+					},
+					Suggestions: []corgierr.Suggestion{
+						{Suggestion: "rename the param, or name the context.Context param using `//corgi:context <name>`"},
+					},
+				})
+			}
+		}
+	}
+
+	return &errs
+}
+
+// fragments checks that the blocks named in a `//corgi:fragments` machine
+// comment are template block placeholders of the templates the main file
+// extends.
+func fragments(f *file.File) *errList {
+	if f.Type != file.TypeMain {
+		return &errList{}
+	}
+
+	var errs errList
+
+	for _, comm := range f.TopLevelComments {
+		mcom := fileutil.ParseMachineComment(comm)
+		if mcom == nil || mcom.Namespace != "corgi" || mcom.Directive != "fragments" {
+			continue
+		}
+
+		ln := comm.Lines[0]
+		offset := strings.Index(ln.Comment, mcom.Args)
+		for _, name := range strings.Fields(mcom.Args) {
+			nameOffset := offset + strings.Index(ln.Comment[offset:], name)
+			offset = nameOffset + len(name)
+
+			if hasTemplateBlockPlaceholder(f, name) {
+				continue
+			}
+
+			errs.PushBack(&corgierr.Error{
+				Message: "unknown fragment block",
+				Code:    corgierr.CodeUnknownFragmentBlock,
+				ErrorAnnotation: anno.Anno(f, anno.Annotation{
+					Start:      file.Position{Line: ln.Line, Col: ln.Col + nameOffset},
+					Len:        len(name),
+					Annotation: "this block never appears in the template you are extending",
+				}),
+				Suggestions: []corgierr.Suggestion{
+					{Suggestion: "remove the name, or add a template block with this name to the template"},
+				},
+			})
+		}
+	}
+
+	return &errs
+}
+
+// hasTemplateBlockPlaceholder reports whether one of the templates f extends
+// has a template block placeholder with the passed name.
+func hasTemplateBlockPlaceholder(f *file.File, name string) bool {
+	for extend := f.Extend; extend != nil; extend = extend.File.Extend {
+		var found bool
+		fileutil.Walk(extend.File.Scope, func(parents []fileutil.WalkContext, ctx fileutil.WalkContext) (dive bool, err error) {
+			switch itm := (*ctx.Item).(type) {
+			case file.Include:
+				return false, nil
+			case file.Mixin:
+				return false, nil
+			case file.Block:
+				if itm.Name.Ident != name {
+					return true, nil
+				}
+
+				if len(parents) > 0 {
+					if _, ok := (*parents[len(parents)-1].Item).(file.MixinCall); ok {
+						return true, nil
+					}
+				}
+
+				found = true
+				return false, fileutil.StopWalk
+			default:
+				return true, nil
+			}
+		})
+		if found {
+			return true
+		}
+	}
+
+	return false
+}
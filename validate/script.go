@@ -0,0 +1,130 @@
+package validate
+
+import (
+	"github.com/mavolin/corgi/corgierr"
+	"github.com/mavolin/corgi/file"
+	"github.com/mavolin/corgi/file/fileutil"
+	"github.com/mavolin/corgi/internal/anno"
+)
+
+// scriptTypes makes sure that the type of every script element is known at
+// generation time, so that its body can be escaped accordingly.
+func scriptTypes(f *file.File) *errList {
+	var errs errList
+
+	fileutil.Walk(f.Scope, func(parents []fileutil.WalkContext, ctx fileutil.WalkContext) (dive bool, err error) {
+		var lines []file.TextLine
+		switch itm := (*ctx.Item).(type) {
+		case file.Include:
+			return false, nil
+		case file.Element:
+			if itm.Name == "script" {
+				errs.PushBackList(_staticScriptType(f, itm))
+			}
+			return true, nil
+		case file.ArrowBlock:
+			lines = itm.Lines
+		case file.InlineText:
+			lines = []file.TextLine{itm.Text}
+		default:
+			return true, nil
+		}
+
+		for _, line := range lines {
+			for _, itm := range line {
+				if ei, ok := itm.(file.ElementInterpolation); ok && ei.Element.Name == "script" {
+					errs.PushBackList(_staticScriptType(f, ei.Element))
+				}
+			}
+		}
+
+		return true, nil
+	})
+
+	return &errs
+}
+
+func _staticScriptType(f *file.File, el file.Element) *errList {
+	var errs errList
+
+	if _, static := fileutil.ScriptType(el); !static {
+		// ScriptType uses the first type attribute
+		pos := _typeAttributePositions(el.Attributes)[0]
+		errs.PushBack(_dynamicScriptTypeError(f, el, pos, "the type of a script must be a string literal"))
+	}
+
+	fileutil.Walk(el.Body, func(parents []fileutil.WalkContext, ctx fileutil.WalkContext) (dive bool, err error) {
+		switch itm := (*ctx.Item).(type) {
+		case file.And:
+			for _, pos := range _typeAttributePositions(itm.Attributes) {
+				errs.PushBack(_dynamicScriptTypeError(f, el, pos, "the type of a script cannot be set using `&`"))
+			}
+			return false, nil
+		case file.If:
+			return true, nil
+		case file.IfBlock:
+			return true, nil
+		case file.Switch:
+			return true, nil
+		case file.For:
+			return true, nil
+		default:
+			return false, nil
+		}
+	})
+
+	return &errs
+}
+
+// _typeAttributePositions returns the positions of all non-boolean type
+// attributes in acolls.
+func _typeAttributePositions(acolls []file.AttributeCollection) []file.Position {
+	var positions []file.Position
+
+	for _, acoll := range acolls {
+		alist, ok := acoll.(file.AttributeList)
+		if !ok {
+			continue
+		}
+
+		for _, attr := range alist.Attributes {
+			switch attr := attr.(type) {
+			case file.SimpleAttribute:
+				if attr.Name == "type" && attr.Value != nil {
+					positions = append(positions, attr.Position)
+				}
+			case file.MixinCallAttribute:
+				if attr.Name == "type" {
+					positions = append(positions, attr.Position)
+				}
+			}
+		}
+	}
+
+	return positions
+}
+
+func _dynamicScriptTypeError(f *file.File, el file.Element, attrPos file.Position, annotation string) *corgierr.Error {
+	return &corgierr.Error{
+		Message: "dynamic script type",
+		Code:    corgierr.CodeDynamicScriptType,
+		ErrorAnnotation: anno.Anno(f, anno.Annotation{
+			Start:      attrPos,
+			Len:        len("type"),
+			Annotation: annotation,
+		}),
+		HintAnnotations: []corgierr.Annotation{
+			anno.Anno(f, anno.Annotation{
+				Start:      el.Position,
+				Len:        len(el.Name),
+				Annotation: "for this script",
+			}),
+		},
+		Suggestions: []corgierr.Suggestion{
+			{
+				Suggestion: "set the type using a string literal, e.g. `type=\"application/ld+json\"`,\n" +
+					"so that corgi knows how to escape the body of the script",
+			},
+		},
+	}
+}
@@ -77,6 +77,8 @@ func _file(f *file.File, valedFiles map[string]struct{}, impNamespaces map[strin
 	errs.PushBackList(unusedUses(f))
 
 	errs.PushBackList(mainFile(f))
+	errs.PushBackList(contextParam(f))
+	errs.PushBackList(fragments(f))
 	errs.PushBackList(templateFile(f))
 	errs.PushBackList(extendingFile(f))
 	errs.PushBackList(libraryFile(f))
@@ -84,6 +86,7 @@ func _file(f *file.File, valedFiles map[string]struct{}, impNamespaces map[strin
 	errs.PushBackList(onlyTemplateFilesContainBlockPlaceholders(f))
 	errs.PushBackList(duplicateTemplateBlocks(f))
 	errs.PushBackList(nonExistentTemplateBlocks(f))
+	errs.PushBackList(templateBlockParams(f))
 
 	errs.PushBackList(mixinChecks(f))
 	errs.PushBackList(mixinsInMixins(f))
@@ -96,10 +99,17 @@ func _file(f *file.File, valedFiles map[string]struct{}, impNamespaces map[strin
 
 	errs.PushBackList(mixinCallAttributeChecks(f))
 
+	errs.PushBackList(mixinCallTypeArgs(f))
+
 	errs.PushBackList(attributePlacement(f))
 	errs.PushBackList(topLevelAttribute(f))
 	errs.PushBackList(topLevelTemplateBlockAnds(f))
 
+	errs.PushBackList(scriptTypes(f))
+
+	errs.PushBackList(forElses(f))
+	errs.PushBackList(loopControls(f))
+
 	if f.Extend != nil {
 		errs.PushBackList(_file(f.Extend.File, valedFiles, impNamespaces))
 	}
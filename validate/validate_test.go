@@ -0,0 +1,243 @@
+package validate_test
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi"
+	"github.com/mavolin/corgi/corgierr"
+)
+
+const testModule = "example.com/test"
+
+// loadMain loads the main file page.corgi from a module consisting of the
+// passed files, and returns the codes of the errors it has.
+func loadMain(t *testing.T, files map[string]string) []corgierr.Code {
+	t.Helper()
+
+	fsys := make(fstest.MapFS, len(files))
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+
+	l, err := corgi.NewFSLoader(corgi.FSLoadOptions{
+		Modules:      map[string]fs.FS{testModule: fsys},
+		NoPrecompile: true,
+	})
+	require.NoError(t, err)
+
+	_, err = l.LoadMain(testModule + "/page.corgi")
+	if err == nil {
+		return nil
+	}
+
+	lerr := corgierr.As(err)
+	require.NotEmpty(t, lerr, "err: %v", err)
+
+	codes := make([]corgierr.Code, len(lerr))
+	for i, err := range lerr {
+		codes[i] = err.Code
+	}
+	return codes
+}
+
+func TestLibraryMixinNameConflicts(t *testing.T) {
+	t.Parallel()
+
+	codes := loadMain(t, map[string]string{
+		"page.corgi":   "use \"" + testModule + "/lib\"\n\nfunc Page()\n\n+lib.foo()\n",
+		"lib/a.corgil": "mixin foo()\n  p a\n",
+		"lib/b.corgil": "mixin foo()\n  p b\n",
+	})
+	assert.Equal(t, []corgierr.Code{corgierr.CodeDuplicateLibraryMixin}, codes)
+}
+
+func TestRequiredMixinCallArgs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("chain expression without default", func(t *testing.T) {
+		t.Parallel()
+
+		codes := loadMain(t, map[string]string{
+			"page.corgi": `func Page(m map[string]string)
+
+mixin foo(x string)
+  p #{x}
+
++foo(x=m["k"?])
+`,
+		})
+		assert.Equal(t, []corgierr.Code{corgierr.CodeRequiredArgChainWithoutDefault}, codes)
+	})
+
+	t.Run("chain expression with default", func(t *testing.T) {
+		t.Parallel()
+
+		codes := loadMain(t, map[string]string{
+			"page.corgi": `func Page(m map[string]string)
+
+mixin foo(x string)
+  p #{x}
+
++foo(x=m["k"?] ~ "")
+`,
+		})
+		assert.Empty(t, codes)
+	})
+
+	t.Run("interpolated", func(t *testing.T) {
+		t.Parallel()
+
+		codes := loadMain(t, map[string]string{
+			"page.corgi": `func Page(m map[string]string)
+
+mixin foo(x string)
+  > #{x}
+
+p #+foo(x=m["k"?])
+`,
+		})
+		assert.Equal(t, []corgierr.Code{corgierr.CodeRequiredArgChainWithoutDefault}, codes)
+	})
+}
+
+func TestDuplicateTemplateBlocks(t *testing.T) {
+	t.Parallel()
+
+	codes := loadMain(t, map[string]string{
+		"page.corgi": `extend "` + testModule + `/base.corgi"
+
+func Page()
+
+block content
+  p a
+
+block content
+  p b
+`,
+		"base.corgi": `html
+  body
+    block content
+`,
+	})
+	assert.Equal(t, []corgierr.Code{corgierr.CodeTemplateBlockFilledTwice}, codes)
+}
+
+func TestMainFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("missing func header after imports", func(t *testing.T) {
+		t.Parallel()
+
+		codes := loadMain(t, map[string]string{
+			"page.corgi": "import \"strings\"\n\np #{strings.ToUpper(\"a\")}\n",
+		})
+		assert.Equal(t, []corgierr.Code{corgierr.CodeMissingFuncHeader}, codes)
+	})
+}
+
+func TestUseNamespaces(t *testing.T) {
+	t.Parallel()
+
+	codes := loadMain(t, map[string]string{
+		"page.corgi": `use "` + testModule + `/a/ui"
+use "` + testModule + `/b/ui"
+
+func Page()
+`,
+		"a/ui/a.corgil": "mixin a()\n  p a\n",
+		"b/ui/b.corgil": "mixin b()\n  p b\n",
+	})
+	assert.Equal(t, []corgierr.Code{corgierr.CodeUseNamespaceCollision}, codes)
+}
+
+func TestLoopControlInMixinCall(t *testing.T) {
+	t.Parallel()
+
+	for _, keyword := range []string{"break", "continue"} {
+		keyword := keyword
+		t.Run(keyword, func(t *testing.T) {
+			t.Parallel()
+
+			codes := loadMain(t, map[string]string{
+				"page.corgi": "func Page(items []string)\n\n" +
+					"mixin foo()\n  block _\n\n" +
+					"for _, it := range items\n  +foo()\n    " + keyword + "\n",
+			})
+			assert.Equal(t, []corgierr.Code{corgierr.CodeLoopControlAcrossMixin}, codes)
+		})
+	}
+}
+
+func TestContextParam(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		comment string
+		params  string
+		expect  []corgierr.Code
+	}{
+		{name: "default name", comment: "//corgi:context", params: "ctx string", expect: []corgierr.Code{corgierr.CodeContextParamCollision}},
+		{name: "custom name", comment: "//corgi:context c", params: "a, c string", expect: []corgierr.Code{corgierr.CodeContextParamCollision}},
+		{name: "renamed", comment: "//corgi:context goCtx", params: "ctx string"},
+	}
+
+	for _, c := range testCases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			codes := loadMain(t, map[string]string{
+				"page.corgi": c.comment + "\n\nfunc Page(" + c.params + ")\n\np foo\n",
+			})
+			assert.Equal(t, c.expect, codes)
+		})
+	}
+}
+
+func TestFragments(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		blocks string
+		expect []corgierr.Code
+	}{
+		{name: "known", blocks: "content title"},
+		{name: "unknown", blocks: "content sidebar", expect: []corgierr.Code{corgierr.CodeUnknownFragmentBlock}},
+		{name: "mixin block", blocks: "inner", expect: []corgierr.Code{corgierr.CodeUnknownFragmentBlock}},
+	}
+
+	for _, c := range testCases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			codes := loadMain(t, map[string]string{
+				"base.corgi": "mixin m()\n  block inner\n\n" +
+					"html\n  head: title: block title\n  body: block content\n",
+				"page.corgi": "//corgi:fragments " + c.blocks + "\n\n" +
+					"extend \"" + testModule + "/base.corgi\"\n\n" +
+					"func Page()\n\n" +
+					"block content\n  p Hello\n",
+			})
+			assert.Equal(t, c.expect, codes)
+		})
+	}
+}
+
+func TestMixinCallBlockParamValue(t *testing.T) {
+	t.Parallel()
+
+	codes := loadMain(t, map[string]string{
+		"page.corgi": "func Page(items []string)\n\n" +
+			"mixin list()\n  for i := range items\n    block _(item string = items[i])\n\n" +
+			"+list()\n  block _(item = \"x\")\n    p #{item}\n",
+	})
+	assert.Equal(t, []corgierr.Code{corgierr.CodeMixinCallBlockParamValue}, codes)
+}
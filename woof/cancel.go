@@ -0,0 +1,29 @@
+package woof
+
+import "context"
+
+// SetContext sets the context.Context that is checked by
+// [Context.CheckContext].
+//
+// It is called by generated functions of main files with a `//corgi:context`
+// machine comment.
+func (ctx *Context) SetContext(goCtx context.Context) {
+	ctx.goCtx = goCtx
+}
+
+// CheckContext aborts rendering with the context's error, if the
+// context.Context set using [Context.SetContext] is done.
+//
+// If no context.Context was set, CheckContext is a no-op.
+//
+// Generated functions call CheckContext at the start of every loop iteration
+// and before every mixin call.
+func (ctx *Context) CheckContext() {
+	if ctx.goCtx == nil {
+		return
+	}
+
+	if err := ctx.goCtx.Err(); err != nil {
+		ctx.Panic(err)
+	}
+}
@@ -2,65 +2,212 @@ package woof
 
 import (
 	"bytes"
+	"context"
 	"io"
+	"runtime/debug"
+	"strings"
 )
 
 type Context struct {
 	err      error
 	w        io.Writer
-	nonce    string
 	classBuf bytes.Buffer
 	closed   bool
 	inAttr   bool
+
+	// buf is the buffer used in buffered mode, see [WithBuffering].
+	buf *bytes.Buffer
+	// goCtx is the context.Context set using [Context.SetContext].
+	goCtx context.Context
+	// recoverPanics indicates whether all panics are converted to errors,
+	// see [WithPanicRecovery].
+	recoverPanics bool
+
+	// pos is the position of the item rendered last, as set by [Context.At].
+	pos renderPos
+	// mixinStack are the mixin calls the current item is nested in, with the
+	// outermost call first.
+	mixinStack []MixinCall
+
+	scriptNonce string
+	styleNonce  string
+
+	// formatters are the formatters set using [WithFormatters].
+	formatters *Formatters
+
+	// prettyElems contains an entry for each element opened using
+	// [Context.PrettyOpen] that has not yet been closed, indicating whether
+	// it contains items placed on their own line.
+	prettyElems []bool
+	// prettyStarted indicates whether anything was placed on its own line
+	// yet.
+	prettyStarted bool
 }
 
 func NewContext(w io.Writer) *Context {
+	if cw, ok := w.(*contextWriter); ok {
+		ctx := &Context{
+			w:           cw.Writer,
+			formatters:  cw.formatters,
+			scriptNonce: cw.scriptNonce,
+			styleNonce:  cw.styleNonce,
+			closed:      true,
+
+			recoverPanics: cw.recoverPanics,
+		}
+		if cw.buffered {
+			ctx.buf = bufferPool.Get().(*bytes.Buffer)
+		}
+
+		return ctx
+	}
+
 	return &Context{w: w, closed: true}
 }
 
+// contextWriter is a writer that carries the settings of the [Context]
+// created for it.
+type contextWriter struct {
+	io.Writer
+
+	formatters  *Formatters
+	scriptNonce string
+	styleNonce  string
+	buffered    bool
+
+	recoverPanics bool
+}
+
+// newContextWriter returns a new *contextWriter writing to w.
+//
+// If w is a *contextWriter itself, its settings are copied.
+func newContextWriter(w io.Writer) *contextWriter {
+	if cw, ok := w.(*contextWriter); ok {
+		cp := *cw
+		return &cp
+	}
+
+	return &contextWriter{Writer: w}
+}
+
+// WithNonce returns a writer that writes to w, and that causes the [Context]
+// created for it to inject nonce into all script and style elements.
+//
+// Only templates with a `//corgi:nonce` or `//corgi:stylenonce` machine
+// comment inject nonces into their script or style elements respectively.
+// If the machine comment has no args, the nonce passed to WithNonce is used.
+//
+// It is intended to be passed to a generated function:
+//
+//	err := RenderPage(woof.WithNonce(w, nonce), user)
+func WithNonce(w io.Writer, nonce string) io.Writer {
+	cw := newContextWriter(w)
+	cw.scriptNonce = htmlAttrValEscaper.Replace(nonce)
+	cw.styleNonce = cw.scriptNonce
+	return cw
+}
+
 func (ctx *Context) SetScriptNonce(nonce any) {
 	s, err := Stringify(nonce)
 	if err != nil {
 		ctx.Panic(err)
 	}
-	ctx.nonce = htmlAttrValEscaper.Replace(s)
+	ctx.scriptNonce = htmlAttrValEscaper.Replace(s)
 }
 
 func (ctx *Context) InjectNonce() {
-	if ctx.nonce == "" {
+	if ctx.scriptNonce == "" {
+		return
+	}
+	ctx.Write(` nonce="` + ctx.scriptNonce + `"`)
+}
+
+func (ctx *Context) SetStyleNonce(nonce any) {
+	s, err := Stringify(nonce)
+	if err != nil {
+		ctx.Panic(err)
+	}
+	ctx.styleNonce = htmlAttrValEscaper.Replace(s)
+}
+
+func (ctx *Context) InjectStyleNonce() {
+	if ctx.styleNonce == "" {
 		return
 	}
-	ctx.Write(` nonce="` + ctx.nonce + `"`)
+	ctx.Write(` nonce="` + ctx.styleNonce + `"`)
 }
 
+// Panic aborts rendering with err.
+//
+// If the position of the item that is being rendered is known, err is wrapped
+// in a *[RenderError].
 func (ctx *Context) Panic(err error) {
-	ctx.err = err
-	panic(err)
+	ctx.err = ctx.renderError(err)
+	panic(ctx.err)
+}
+
+// Recovers reports whether [Context.Recover] should be called with the
+// value recovered from a panic.
+//
+// This is the case, if rendering was aborted using [Context.Panic], or if
+// ctx recovers from all panics, see [WithPanicRecovery].
+// Otherwise, panics are left untouched, so that they keep their original
+// stack.
+func (ctx *Context) Recovers() bool {
+	return ctx.err != nil || ctx.recoverPanics
 }
 
-func (ctx *Context) Recover() error {
-	if ctx.err != nil {
-		_ = recover()
-		return ctx.err
+// Recover is called by generated functions with the value recovered from a
+// panic, since recover only stops a panic if called directly by the deferred
+// function.
+//
+// If the panic was caused by [Context.Panic], Recover returns the error
+// passed to it.
+// If ctx recovers from all panics, it returns a *[PanicError] wrapped in a
+// *[RenderError].
+// If r is nil, i.e. if there was no panic, Recover returns nil.
+//
+// In buffered mode, the output buffered since the last flush point is
+// discarded.
+func (ctx *Context) Recover(r any) error {
+	if ctx.err == nil {
+		if r == nil {
+			return nil
+		} else if !ctx.recoverPanics {
+			panic(r)
+		}
+
+		ctx.err = ctx.renderError(&PanicError{Value: r, Stack: debug.Stack()})
 	}
 
-	return nil
+	ctx.releaseBuffer()
+	return ctx.err
 }
 
 func (ctx *Context) Write(s string) {
+	if ctx.buf != nil {
+		ctx.buf.WriteString(s)
+		return
+	}
+
 	if _, err := io.WriteString(ctx.w, s); err != nil {
 		ctx.Panic(err)
 	}
 }
 
 func (ctx *Context) WriteBytes(data []byte) {
+	if ctx.buf != nil {
+		ctx.buf.Write(data)
+		return
+	}
+
 	if _, err := ctx.w.Write(data); err != nil {
 		ctx.Panic(err)
 	}
 }
 
 func (ctx *Context) BufferClass(class any) {
-	classStr, err := EscapeHTMLAttrVal(class)
+	classStr, err := EscapeHTMLAttrVal(ctx.format(class))
 	if err != nil {
 		ctx.Panic(err)
 	}
@@ -149,7 +296,51 @@ func (ctx *Context) CloseStartTag(extraClasses HTMLAttrVal, void bool) {
 	ctx.Write(">")
 }
 
+// PrettyBreak is used by files generated in pretty mode to place the next
+// item, e.g. a doctype or an HTML comment, on its own line, indented by the
+// number of elements it is nested in.
+func (ctx *Context) PrettyBreak() {
+	if len(ctx.prettyElems) > 0 {
+		ctx.prettyElems[len(ctx.prettyElems)-1] = true
+	}
+
+	if !ctx.prettyStarted {
+		ctx.prettyStarted = true
+		return
+	}
+
+	ctx.Write("\n" + strings.Repeat("  ", len(ctx.prettyElems)))
+}
+
+// PrettyOpen is used by files generated in pretty mode to place the element
+// that is about to be started on its own line, and to indent its children.
+//
+// Each call to PrettyOpen must be followed by a call to [Context.PrettyClose].
+func (ctx *Context) PrettyOpen() {
+	ctx.PrettyBreak()
+	ctx.prettyElems = append(ctx.prettyElems, false)
+}
+
+// PrettyClose ends an element started using [Context.PrettyOpen].
+//
+// If the element contains items placed on their own line, the end tag about
+// to be written is placed on its own line as well.
+func (ctx *Context) PrettyClose() {
+	if len(ctx.prettyElems) == 0 {
+		return
+	}
+
+	hasBlockChildren := ctx.prettyElems[len(ctx.prettyElems)-1]
+	ctx.prettyElems = ctx.prettyElems[:len(ctx.prettyElems)-1]
+
+	if hasBlockChildren {
+		ctx.Write("\n" + strings.Repeat("  ", len(ctx.prettyElems)))
+	}
+}
+
 func WriteAny[T ~string](ctx *Context, escaper func(val any) (T, error), val any) {
+	val = ctx.format(val)
+
 	if escaper != nil {
 		s, err := escaper(val)
 		if err != nil {
@@ -168,7 +359,7 @@ func WriteAny[T ~string](ctx *Context, escaper func(val any) (T, error), val any
 }
 
 func WriteAnys[T ~string](ctx *Context, escaper func(vals ...any) (T, error), vals ...any) {
-	s, err := escaper(vals...)
+	s, err := escaper(ctx.formatAll(vals)...)
 	if err != nil {
 		ctx.Panic(err)
 	}
@@ -194,6 +385,8 @@ func WriteAttr[T ~string](ctx *Context, name string, val any, escaper func(val a
 		return
 	}
 
+	val = ctx.format(val)
+
 	if escaper != nil {
 		s, err := escaper(val)
 		if err != nil {
@@ -213,7 +406,7 @@ func WriteAttr[T ~string](ctx *Context, name string, val any, escaper func(val a
 }
 
 func Must[T ~string](ctx *Context, f func(val any) (T, error), val any) T {
-	t, err := f(val)
+	t, err := f(ctx.format(val))
 	if err != nil {
 		ctx.Panic(err)
 	}
@@ -222,7 +415,7 @@ func Must[T ~string](ctx *Context, f func(val any) (T, error), val any) T {
 }
 
 func MustContext[T ~string](ctx *Context, f func(vals ...any) (T, error), vals ...any) T {
-	t, err := f(vals...)
+	t, err := f(ctx.formatAll(vals)...)
 	if err != nil {
 		ctx.Panic(err)
 	}
@@ -0,0 +1,35 @@
+package woof
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// CSPHashes are the hashes of the static inline scripts and styles of a
+// template.
+//
+// Each hash is formatted as a CSP hash source, e.g. 'sha256-...', and can be
+// used as-is in a Content-Security-Policy header.
+type CSPHashes struct {
+	Scripts []string
+	Styles  []string
+}
+
+// ScriptSrc returns the script hashes separated by spaces, for use in a
+// script-src directive.
+func (h CSPHashes) ScriptSrc() string {
+	return strings.Join(h.Scripts, " ")
+}
+
+// StyleSrc returns the style hashes separated by spaces, for use in a
+// style-src directive.
+func (h CSPHashes) StyleSrc() string {
+	return strings.Join(h.Styles, " ")
+}
+
+// CSPHash returns the SHA-256 hash of s, formatted as a CSP hash source.
+func CSPHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return "'sha256-" + base64.StdEncoding.EncodeToString(sum[:]) + "'"
+}
@@ -0,0 +1,174 @@
+package woof
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// RenderError is the error returned by generated functions, if rendering
+// fails at a known position in a corgi file.
+//
+// It wraps the error that caused rendering to fail.
+type RenderError struct {
+	// File is the corgi file, in which rendering failed.
+	//
+	// If the file belongs to a Go module, it is the module path followed by
+	// the path of the file in the module, e.g.
+	// github.com/mavolin/corgi/std/fmt/list.corgil.
+	File string
+	// Line and Col are the 1-indexed position of the item in File, that
+	// failed to render.
+	Line, Col int
+
+	// MixinStack are the calls to the mixins the item is located in, with the
+	// innermost call first.
+	MixinStack []MixinCall
+
+	Err error
+}
+
+// MixinCall is a call to a mixin.
+type MixinCall struct {
+	// Name is the name of the mixin, including its namespace, if it was
+	// called using one.
+	Name string
+	// File, Line, and Col are the position of the call.
+	File      string
+	Line, Col int
+}
+
+var _ error = (*RenderError)(nil)
+
+func (err *RenderError) Error() string {
+	var sb strings.Builder
+
+	sb.WriteString(err.File)
+	sb.WriteByte(':')
+	sb.WriteString(strconv.Itoa(err.Line))
+	sb.WriteByte(':')
+	sb.WriteString(strconv.Itoa(err.Col))
+	sb.WriteString(": ")
+	sb.WriteString(err.Err.Error())
+
+	for i, c := range err.MixinStack {
+		if i == 0 {
+			sb.WriteString(" (in ")
+		} else {
+			sb.WriteString(", in ")
+		}
+
+		sb.WriteString("mixin " + c.Name + " called at " + c.File + ":" + strconv.Itoa(c.Line) + ":" + strconv.Itoa(c.Col))
+	}
+	if len(err.MixinStack) > 0 {
+		sb.WriteByte(')')
+	}
+
+	return sb.String()
+}
+
+func (err *RenderError) Unwrap() error {
+	return err.Err
+}
+
+// PanicError is the error a panic during rendering is converted to, if the
+// [Context] recovers from all panics, see [WithPanicRecovery].
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the goroutine at the time of the panic, as
+	// returned by [runtime/debug.Stack].
+	Stack []byte
+}
+
+var _ error = (*PanicError)(nil)
+
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", err.Value)
+}
+
+// Unwrap returns Value, if it is an error, and nil otherwise.
+func (err *PanicError) Unwrap() error {
+	verr, _ := err.Value.(error)
+	return verr
+}
+
+// WithPanicRecovery returns a writer that writes to w, and that causes the
+// [Context] created for it to recover from all panics, not just those caused
+// by errors returned by the template, and to return them as a *[PanicError]
+// wrapped in a *[RenderError], if the position is known.
+//
+// Output that was already written to w can't be taken back.
+// Use [WithBuffering] to discard the output buffered since the last flush
+// point, if rendering fails.
+//
+// It is intended to be passed to a generated function:
+//
+//	err := RenderPage(woof.WithPanicRecovery(w), user)
+func WithPanicRecovery(w io.Writer) io.Writer {
+	cw := newContextWriter(w)
+	cw.recoverPanics = true
+	return cw
+}
+
+// renderPos is a position in a corgi file.
+type renderPos struct {
+	file      string
+	line, col int
+}
+
+// At sets the position of the item that is rendered next.
+//
+// It is called by generated functions before each statement that may fail,
+// so that [Context.Panic] can attribute the error to that position.
+func (ctx *Context) At(file string, line, col int) {
+	ctx.pos = renderPos{file: file, line: line, col: col}
+}
+
+// PushMixin records a call to the mixin with the passed name at the passed
+// position.
+//
+// Each call to PushMixin is followed by a call to [Context.PopMixin], once
+// the mixin returns.
+func (ctx *Context) PushMixin(name, file string, line, col int) {
+	ctx.At(file, line, col)
+	ctx.mixinStack = append(ctx.mixinStack, MixinCall{Name: name, File: file, Line: line, Col: col})
+}
+
+// PopMixin removes the mixin call recorded last using [Context.PushMixin],
+// and restores the position to that of the call, so that errors occurring
+// in the caller before the next call to [Context.At] are attributed to it,
+// instead of to the last item rendered by the mixin.
+func (ctx *Context) PopMixin() {
+	if len(ctx.mixinStack) > 0 {
+		call := ctx.mixinStack[len(ctx.mixinStack)-1]
+		ctx.mixinStack = ctx.mixinStack[:len(ctx.mixinStack)-1]
+		ctx.At(call.File, call.Line, call.Col)
+	}
+}
+
+// renderError wraps err in a *RenderError with the current position of ctx.
+//
+// If the position is unknown, err is returned as is.
+func (ctx *Context) renderError(err error) error {
+	if ctx.pos.file == "" {
+		return err
+	}
+
+	rerr := &RenderError{
+		File: ctx.pos.file,
+		Line: ctx.pos.line,
+		Col:  ctx.pos.col,
+		Err:  err,
+	}
+
+	if len(ctx.mixinStack) > 0 {
+		rerr.MixinStack = make([]MixinCall, len(ctx.mixinStack))
+		for i, c := range ctx.mixinStack {
+			rerr.MixinStack[len(ctx.mixinStack)-1-i] = c
+		}
+	}
+
+	return rerr
+}
@@ -151,6 +151,18 @@ type (
 	// pass the resultant object into the template, where it will be
 	// converted to sanitized JSON when presented in a JavaScript context.
 	JSAttrVal string
+
+	// JSON encapsulates a known safe JSON value, such as `{"foo": "bar"}`,
+	// that can be embedded in a script element of a JSON type, such as
+	// application/ld+json.
+	//
+	// It must not contain `</script`, which is why corgi's JSON escaping
+	// escapes all `<`, `>`, and `&`.
+	//
+	// Use of this type presents a security risk:
+	// the encapsulated content should come from a trusted source,
+	// as it will be included verbatim in the template output.
+	JSON string
 )
 
 const UnsafeReplacement = "ZcorgiZ"
@@ -0,0 +1,85 @@
+package woof
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// maxPooledBufferSize is the maximum capacity of a buffer that is returned to
+// the pool, so that a single large page doesn't permanently occupy memory.
+const maxPooledBufferSize = 1 << 20
+
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// WithBuffering returns a writer that writes to w, and that causes the
+// [Context] created for it to batch all writes in a pooled buffer, instead of
+// writing them to w directly.
+//
+// The buffer is written to w at every flush point, i.e. at every
+// `//corgi:flush` machine comment, and once rendering completes.
+//
+// It is intended to be passed to a generated function:
+//
+//	err := RenderPage(woof.WithBuffering(w), user)
+func WithBuffering(w io.Writer) io.Writer {
+	cw := newContextWriter(w)
+	cw.buffered = true
+	return cw
+}
+
+// Flush writes the output buffered by ctx, if any, to the underlying writer,
+// and then flushes the underlying writer, if it implements either
+// Flush() error, like a *bufio.Writer, or Flush(), like an http.Flusher.
+//
+// Flush is called at every `//corgi:flush` machine comment.
+func (ctx *Context) Flush() {
+	ctx.writeBuffer()
+
+	switch w := ctx.w.(type) {
+	case interface{ Flush() error }:
+		if err := w.Flush(); err != nil {
+			ctx.Panic(err)
+		}
+	case interface{ Flush() }:
+		w.Flush()
+	}
+}
+
+// Done writes the output buffered by ctx, if any, to the underlying writer,
+// and releases the buffer.
+//
+// It is called by generated functions once rendering completes.
+func (ctx *Context) Done() {
+	ctx.writeBuffer()
+	ctx.releaseBuffer()
+}
+
+func (ctx *Context) writeBuffer() {
+	if ctx.buf == nil || ctx.buf.Len() == 0 {
+		return
+	}
+
+	_, err := ctx.w.Write(ctx.buf.Bytes())
+	ctx.buf.Reset()
+	if err != nil {
+		ctx.Panic(err)
+	}
+}
+
+// releaseBuffer returns the buffer of ctx to the pool, discarding all
+// output that hasn't been written yet.
+func (ctx *Context) releaseBuffer() {
+	if ctx.buf == nil {
+		return
+	}
+
+	if ctx.buf.Cap() <= maxPooledBufferSize {
+		ctx.buf.Reset()
+		bufferPool.Put(ctx.buf)
+	}
+
+	ctx.buf = nil
+}
@@ -0,0 +1,169 @@
+package woof
+
+import (
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Formatters is a registry of functions used to convert values of specific
+// types to strings.
+//
+// Formatters are consulted by [Stringify], [JSify], and all Escape and Filter
+// functions, before they fall back to their default handling of a value.
+//
+// Formatters is safe for concurrent use.
+type Formatters struct {
+	mut    sync.RWMutex
+	types  map[reflect.Type]func(any) (string, error)
+	ifaces []ifaceFormatter
+
+	// n is the number of registered formatters, so that an empty registry
+	// can be detected without locking.
+	n atomic.Int32
+}
+
+type ifaceFormatter struct {
+	typ reflect.Type
+	f   func(any) (string, error)
+}
+
+// DefaultFormatters is the global formatter registry, consulted for all
+// values, regardless of the [Context] they are written by.
+var DefaultFormatters = new(Formatters)
+
+// RegisterFormatter registers f as the formatter for values of type T in fs.
+//
+// If T is an interface type, f is used for all values implementing T, that
+// don't have a formatter registered for their concrete type.
+// Interface formatters are consulted in the order they were registered.
+//
+// f is also used for pointers to T, unless a formatter for the pointer type
+// is registered.
+// Nil pointers are always formatted as "".
+//
+// Registering a formatter for a type that already has one replaces it.
+//
+// To register a global formatter, use [DefaultFormatters] as fs.
+func RegisterFormatter[T any](fs *Formatters, f func(T) (string, error)) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	anyF := func(val any) (string, error) {
+		return f(val.(T))
+	}
+
+	fs.mut.Lock()
+	defer fs.mut.Unlock()
+
+	if typ.Kind() == reflect.Interface {
+		for i, iface := range fs.ifaces {
+			if iface.typ == typ {
+				fs.ifaces[i].f = anyF
+				return
+			}
+		}
+
+		fs.ifaces = append(fs.ifaces, ifaceFormatter{typ: typ, f: anyF})
+		fs.n.Add(1)
+		return
+	}
+
+	if fs.types == nil {
+		fs.types = make(map[reflect.Type]func(any) (string, error))
+	}
+
+	if _, ok := fs.types[typ]; !ok {
+		fs.n.Add(1)
+	}
+	fs.types[typ] = anyF
+}
+
+// Format formats val using the formatter registered for its type.
+//
+// If there is no formatter for val, ok is false.
+func (fs *Formatters) Format(val any) (s string, ok bool, err error) {
+	if fs == nil || fs.n.Load() == 0 || val == nil {
+		return "", false, nil
+	}
+
+	fs.mut.RLock()
+	defer fs.mut.RUnlock()
+
+	rv := reflect.ValueOf(val)
+	for {
+		if f := fs.formatter(rv.Type()); f != nil {
+			s, err = f(rv.Interface())
+			return s, true, err
+		}
+
+		if rv.Kind() != reflect.Pointer {
+			return "", false, nil
+		}
+
+		if rv.IsNil() {
+			if fs.formatter(rv.Type().Elem()) != nil {
+				return "", true, nil
+			}
+
+			return "", false, nil
+		}
+
+		rv = rv.Elem()
+	}
+}
+
+func (fs *Formatters) formatter(typ reflect.Type) func(any) (string, error) {
+	if f := fs.types[typ]; f != nil {
+		return f
+	}
+
+	for _, iface := range fs.ifaces {
+		if typ.Implements(iface.typ) {
+			return iface.f
+		}
+	}
+
+	return nil
+}
+
+// WithFormatters returns a writer that writes to w, and that causes the
+// [Context] created for it to use fs to format values.
+//
+// The formatters in fs take precedence over [DefaultFormatters].
+//
+// It is intended to be passed to a generated function:
+//
+//	err := RenderPage(woof.WithFormatters(w, fs), user)
+func WithFormatters(w io.Writer, fs *Formatters) io.Writer {
+	cw := newContextWriter(w)
+	cw.formatters = fs
+	return cw
+}
+
+// format formats val using the formatters of ctx.
+//
+// If ctx has no formatter for val, format returns val as is, leaving it to
+// the escaper to consult [DefaultFormatters].
+func (ctx *Context) format(val any) any {
+	s, ok, err := ctx.formatters.Format(val)
+	if err != nil {
+		ctx.Panic(err)
+	} else if !ok {
+		return val
+	}
+
+	return s
+}
+
+func (ctx *Context) formatAll(vals []any) []any {
+	if ctx.formatters == nil {
+		return vals
+	}
+
+	formatted := make([]any, len(vals))
+	for i, val := range vals {
+		formatted[i] = ctx.format(val)
+	}
+
+	return formatted
+}
@@ -0,0 +1,525 @@
+package woof
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// SanitizePolicy is an allowlist-based policy used to sanitize untrusted
+// HTML, such as user comments or CMS content.
+//
+// The sanitized HTML only contains the allowed elements and attributes.
+// Text is re-escaped, comments are removed, and unclosed elements are closed.
+// Disallowed elements are removed, but their content is kept, unless they are
+// raw text elements, such as script, style, or textarea, which are always
+// removed entirely.
+type SanitizePolicy struct {
+	// Elements maps the lowercase names of the allowed elements to the names
+	// of the attributes allowed on them.
+	Elements map[string][]string
+	// GlobalAttributes are the names of the attributes allowed on all allowed
+	// elements.
+	GlobalAttributes []string
+
+	// URLSchemes are the allowed schemes of URLs in attributes such as href
+	// and src.
+	//
+	// If nil, the schemes allowed by [FilterURL] are used, i.e. http, https,
+	// mailto, and tel.
+	//
+	// Relative URLs are always allowed.
+	URLSchemes []string
+	// ClassPatterns are the patterns of the allowed classes.
+	//
+	// If the class attribute is allowed, all classes not matching any of the
+	// patterns are removed.
+	// If ClassPatterns is nil, all classes are allowed.
+	ClassPatterns []*regexp.Regexp
+	// StyleProperties are the names of the CSS properties allowed in style
+	// attributes.
+	//
+	// Values are filtered using [FilterCSSValue], and declarations with unsafe
+	// values are removed.
+	StyleProperties []string
+}
+
+// UGCPolicy is a [SanitizePolicy] suitable for user-generated content, such
+// as comments.
+//
+// It allows common text formatting, lists, tables, links, and images, but no
+// classes or styles.
+var UGCPolicy = &SanitizePolicy{
+	Elements: map[string][]string{
+		"a":          {"href", "title"},
+		"abbr":       {"title"},
+		"b":          nil,
+		"blockquote": {"cite"},
+		"br":         nil,
+		"code":       nil,
+		"dd":         nil,
+		"del":        nil,
+		"dl":         nil,
+		"dt":         nil,
+		"em":         nil,
+		"h1":         nil,
+		"h2":         nil,
+		"h3":         nil,
+		"h4":         nil,
+		"h5":         nil,
+		"h6":         nil,
+		"hr":         nil,
+		"i":          nil,
+		"img":        {"src", "srcset", "alt", "title", "width", "height"},
+		"ins":        nil,
+		"kbd":        nil,
+		"li":         nil,
+		"mark":       nil,
+		"ol":         {"start"},
+		"p":          nil,
+		"pre":        nil,
+		"q":          {"cite"},
+		"s":          nil,
+		"samp":       nil,
+		"small":      nil,
+		"span":       nil,
+		"strong":     nil,
+		"sub":        nil,
+		"sup":        nil,
+		"table":      nil,
+		"tbody":      nil,
+		"td":         {"colspan", "rowspan"},
+		"tfoot":      nil,
+		"th":         {"colspan", "rowspan", "scope"},
+		"thead":      nil,
+		"tr":         nil,
+		"u":          nil,
+		"ul":         nil,
+	},
+	GlobalAttributes: []string{"dir", "lang"},
+}
+
+// SanitizeHTML sanitizes the stringified val using [UGCPolicy].
+func SanitizeHTML(val any) (HTMLBody, error) {
+	return UGCPolicy.Sanitize(val)
+}
+
+// Sanitize sanitizes the stringified val according to the policy.
+func (p *SanitizePolicy) Sanitize(val any) (HTMLBody, error) {
+	s, err := Stringify(val)
+	if err != nil {
+		return "", err
+	}
+
+	san := sanitizer{p: p, in: s}
+	san.sanitize()
+	return HTMLBody(san.out.String()), nil
+}
+
+// rawTextElements are the elements whose content is not parsed as HTML.
+var rawTextElements = map[string]struct{}{
+	"iframe": {}, "noembed": {}, "noframes": {}, "noscript": {}, "plaintext": {},
+	"script": {}, "style": {}, "textarea": {}, "title": {}, "xmp": {},
+}
+
+var voidElements = map[string]struct{}{
+	"area": {}, "base": {}, "br": {}, "col": {}, "embed": {}, "hr": {}, "img": {},
+	"input": {}, "link": {}, "meta": {}, "source": {}, "track": {}, "wbr": {},
+}
+
+// urlAttributes are the attributes whose values are URLs.
+var urlAttributes = map[string]struct{}{
+	"action": {}, "background": {}, "cite": {}, "formaction": {}, "href": {},
+	"longdesc": {}, "poster": {}, "src": {}, "usemap": {}, "xlink:href": {},
+}
+
+type sanitizer struct {
+	p   *SanitizePolicy
+	in  string
+	pos int
+	out strings.Builder
+
+	// open are the names of the allowed elements that are still open.
+	open []string
+}
+
+type sanitizeAttr struct {
+	name string
+	val  string
+}
+
+func (san *sanitizer) sanitize() {
+	for san.pos < len(san.in) {
+		lt := strings.IndexByte(san.in[san.pos:], '<')
+		if lt < 0 {
+			san.text(san.in[san.pos:])
+			break
+		}
+
+		san.text(san.in[san.pos : san.pos+lt])
+		san.pos += lt
+		san.tag()
+	}
+
+	for i := len(san.open) - 1; i >= 0; i-- {
+		san.out.WriteString("</" + san.open[i] + ">")
+	}
+}
+
+func (san *sanitizer) text(s string) {
+	san.out.WriteString(htmlEscaper.Replace(html.UnescapeString(s)))
+}
+
+// tag handles the tag, comment, or text starting with the '<' at san.pos.
+func (san *sanitizer) tag() {
+	rest := san.in[san.pos+1:]
+
+	switch {
+	case strings.HasPrefix(rest, "!--"):
+		san.pos += 1 + 3
+		san.comment()
+	case strings.HasPrefix(rest, "/") && len(rest) > 1 && isASCIILetter(rest[1]):
+		san.pos += 2
+		name := strings.ToLower(san.name())
+		san.skipPast('>')
+		san.endTag(name)
+	case len(rest) > 0 && isASCIILetter(rest[0]):
+		san.pos++
+		name := strings.ToLower(san.name())
+		attrs, ok := san.attrs()
+		if !ok { // unterminated tag, which browsers drop
+			san.pos = len(san.in)
+			return
+		}
+		san.startTag(name, attrs)
+	case strings.HasPrefix(rest, "!"), strings.HasPrefix(rest, "?"), strings.HasPrefix(rest, "/"):
+		// bogus comment
+		san.skipPast('>')
+	default:
+		san.out.WriteString("&lt;")
+		san.pos++
+	}
+}
+
+// comment skips the comment whose content starts at san.pos, as well as the
+// comment's end.
+//
+// Like browsers, it also ends comments at "--!>", and treats "<!-->" and
+// "<!--->" as empty comments.
+func (san *sanitizer) comment() {
+	rest := san.in[san.pos:]
+	switch {
+	case strings.HasPrefix(rest, ">"):
+		san.pos++
+		return
+	case strings.HasPrefix(rest, "->"):
+		san.pos += 2
+		return
+	}
+
+	for i := 0; i < len(rest); i++ {
+		if rest[i] != '-' {
+			continue
+		}
+
+		if strings.HasPrefix(rest[i:], "-->") {
+			san.pos += i + len("-->")
+			return
+		} else if strings.HasPrefix(rest[i:], "--!>") {
+			san.pos += i + len("--!>")
+			return
+		}
+	}
+
+	san.pos = len(san.in)
+}
+
+func (san *sanitizer) skipPast(c byte) {
+	end := strings.IndexByte(san.in[san.pos:], c)
+	if end < 0 {
+		san.pos = len(san.in)
+		return
+	}
+
+	san.pos += end + 1
+}
+
+func (san *sanitizer) name() string {
+	start := san.pos
+	for san.pos < len(san.in) {
+		c := san.in[san.pos]
+		if isHTMLSpace(c) || c == '/' || c == '>' {
+			break
+		}
+		san.pos++
+	}
+
+	return san.in[start:san.pos]
+}
+
+// attrs parses the attributes of a start tag and consumes the closing '>'.
+//
+// If the tag is not terminated, ok is false.
+func (san *sanitizer) attrs() (attrs []sanitizeAttr, ok bool) {
+	for {
+		for san.pos < len(san.in) && (isHTMLSpace(san.in[san.pos]) || san.in[san.pos] == '/') {
+			san.pos++
+		}
+
+		if san.pos >= len(san.in) {
+			return nil, false
+		} else if san.in[san.pos] == '>' {
+			san.pos++
+			return attrs, true
+		}
+
+		start := san.pos
+		san.pos++ // a name may start with a '='
+		for san.pos < len(san.in) {
+			c := san.in[san.pos]
+			if isHTMLSpace(c) || c == '/' || c == '>' || c == '=' {
+				break
+			}
+			san.pos++
+		}
+		attr := sanitizeAttr{name: strings.ToLower(san.in[start:san.pos])}
+
+		for san.pos < len(san.in) && isHTMLSpace(san.in[san.pos]) {
+			san.pos++
+		}
+
+		if san.pos < len(san.in) && san.in[san.pos] == '=' {
+			san.pos++
+			for san.pos < len(san.in) && isHTMLSpace(san.in[san.pos]) {
+				san.pos++
+			}
+
+			if san.pos >= len(san.in) {
+				return nil, false
+			}
+
+			switch quote := san.in[san.pos]; quote {
+			case '"', '\'':
+				end := strings.IndexByte(san.in[san.pos+1:], quote)
+				if end < 0 {
+					return nil, false
+				}
+
+				attr.val = san.in[san.pos+1 : san.pos+1+end]
+				san.pos += 1 + end + 1
+			default:
+				start := san.pos
+				for san.pos < len(san.in) && !isHTMLSpace(san.in[san.pos]) && san.in[san.pos] != '>' {
+					san.pos++
+				}
+				attr.val = san.in[start:san.pos]
+			}
+
+			attr.val = html.UnescapeString(attr.val)
+		}
+
+		// the first occurrence of an attribute wins
+		for _, a := range attrs {
+			if a.name == attr.name {
+				goto next
+			}
+		}
+		attrs = append(attrs, attr)
+	next:
+	}
+}
+
+func (san *sanitizer) startTag(name string, attrs []sanitizeAttr) {
+	if _, ok := rawTextElements[name]; ok {
+		san.skipRawText(name)
+		return
+	}
+
+	allowedAttrs, ok := san.p.Elements[name]
+	if !ok {
+		return
+	}
+
+	san.out.WriteString("<" + name)
+
+	for _, attr := range attrs {
+		if !containsString(allowedAttrs, attr.name) && !containsString(san.p.GlobalAttributes, attr.name) {
+			continue
+		}
+
+		val, ok := san.attrVal(attr)
+		if ok {
+			san.out.WriteString(" " + attr.name + `="` + htmlEscaper.Replace(val) + `"`)
+		}
+	}
+
+	san.out.WriteByte('>')
+
+	if _, ok := voidElements[name]; !ok {
+		san.open = append(san.open, name)
+	}
+}
+
+// skipRawText skips the content of the raw text element with the passed
+// name, as well as its end tag.
+func (san *sanitizer) skipRawText(name string) {
+	for {
+		lt := strings.Index(san.in[san.pos:], "</")
+		if lt < 0 {
+			san.pos = len(san.in)
+			return
+		}
+		san.pos += lt + 2
+
+		if len(san.in)-san.pos >= len(name) && strings.EqualFold(san.in[san.pos:san.pos+len(name)], name) {
+			san.pos += len(name)
+			if san.pos >= len(san.in) || isHTMLSpace(san.in[san.pos]) ||
+				san.in[san.pos] == '/' || san.in[san.pos] == '>' {
+				san.skipPast('>')
+				return
+			}
+		}
+	}
+}
+
+func (san *sanitizer) endTag(name string) {
+	for i := len(san.open) - 1; i >= 0; i-- {
+		if san.open[i] != name {
+			continue
+		}
+
+		for j := len(san.open) - 1; j >= i; j-- {
+			san.out.WriteString("</" + san.open[j] + ">")
+		}
+		san.open = san.open[:i]
+		return
+	}
+}
+
+// attrVal returns the sanitized, unescaped value of attr.
+//
+// If the attribute must be removed, ok is false.
+func (san *sanitizer) attrVal(attr sanitizeAttr) (val string, ok bool) {
+	// event handlers are never allowed, even if the policy says so
+	if strings.HasPrefix(attr.name, "on") {
+		return "", false
+	}
+
+	switch attr.name {
+	case "class":
+		return san.class(attr.val)
+	case "style":
+		return san.style(attr.val)
+	case "srcset":
+		srcset, err := FilterSrcset(attr.val)
+		if err != nil || strings.Contains(string(srcset), UnsafeReplacement) {
+			return "", false
+		}
+
+		return html.UnescapeString(string(srcset)), true
+	}
+
+	if _, ok := urlAttributes[attr.name]; ok {
+		return san.url(attr.val)
+	}
+
+	return attr.val, true
+}
+
+func (san *sanitizer) url(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+
+	proto := urlProto(s)
+	if proto != "" {
+		if san.p.URLSchemes == nil {
+			if !isSafeURLProtocol(proto) {
+				return "", false
+			}
+		} else if !containsStringFold(san.p.URLSchemes, proto) {
+			return "", false
+		}
+	}
+
+	return string(NormalizeURL(URL(s))), true
+}
+
+func (san *sanitizer) class(s string) (string, bool) {
+	if san.p.ClassPatterns == nil {
+		return s, true
+	}
+
+	classes := strings.Fields(s)
+	allowed := classes[:0]
+
+classes:
+	for _, class := range classes {
+		for _, pattern := range san.p.ClassPatterns {
+			if pattern.MatchString(class) {
+				allowed = append(allowed, class)
+				continue classes
+			}
+		}
+	}
+
+	if len(allowed) == 0 {
+		return "", false
+	}
+
+	return strings.Join(allowed, " "), true
+}
+
+func (san *sanitizer) style(s string) (string, bool) {
+	var b strings.Builder
+
+	for _, decl := range strings.Split(s, ";") {
+		prop, val, ok := strings.Cut(decl, ":")
+		if !ok {
+			continue
+		}
+
+		prop = strings.ToLower(strings.TrimSpace(prop))
+		if !containsString(san.p.StyleProperties, prop) {
+			continue
+		}
+
+		css, err := FilterCSSValue(strings.TrimSpace(val))
+		if err != nil || css == UnsafeReplacement {
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(prop + ": " + string(css))
+	}
+
+	if b.Len() == 0 {
+		return "", false
+	}
+
+	return b.String(), true
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func containsString(ss []string, s string) bool {
+	for _, s2 := range ss {
+		if s2 == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsStringFold(ss []string, s string) bool {
+	for _, s2 := range ss {
+		if strings.EqualFold(s2, s) {
+			return true
+		}
+	}
+
+	return false
+}
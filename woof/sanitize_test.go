@@ -0,0 +1,148 @@
+package woof_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi/woof"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name   string
+		in     string
+		expect woof.HTMLBody
+	}{
+		// javascript: URLs
+		{name: "javascript url", in: `<a href="javascript:alert(1)">x</a>`, expect: "<a>x</a>"},
+		{name: "mixed case scheme", in: `<a href="JaVaScRiPt:alert(1)">x</a>`, expect: "<a>x</a>"},
+		{name: "leading space", in: `<a href="  javascript:alert(1)">x</a>`, expect: "<a>x</a>"},
+		{name: "leading control char", in: "<a href=\"\x01javascript:alert(1)\">x</a>", expect: "<a>x</a>"},
+		{name: "unquoted", in: `<a href=javascript:alert(1)>x</a>`, expect: "<a>x</a>"},
+		{name: "decimal entity", in: `<a href="&#106;avascript:alert(1)">x</a>`, expect: "<a>x</a>"},
+		{name: "hex entity colon", in: `<a href="&#x6A;avascript&colon;alert(1)">x</a>`, expect: "<a>x</a>"},
+		{name: "entity tab", in: `<a href="java&#x09;script:alert(1)">x</a>`, expect: "<a>x</a>"},
+		{name: "named entity tab", in: `<a href="java&Tab;script:alert(1)">x</a>`, expect: "<a>x</a>"},
+		{name: "duplicate attribute", in: `<a href=x href=javascript:alert(1)>x</a>`, expect: `<a href="x">x</a>`},
+		{name: "img src", in: `<img src="javascript:alert(1)">`, expect: "<img>"},
+		{name: "cite", in: `<q cite="javascript:alert(1)">x</q>`, expect: "<q>x</q>"},
+		{name: "data url", in: `<a href="data:text/html,<script>alert(1)</script>">x</a>`, expect: "<a>x</a>"},
+		{name: "vbscript url", in: `<a href="vbscript:msgbox(1)">x</a>`, expect: "<a>x</a>"},
+		{name: "mixed case https", in: `<a href="HTTPS://example.com">x</a>`, expect: `<a href="HTTPS://example.com">x</a>`},
+		{name: "relative url with colon", in: `<a href="/a:b">x</a>`, expect: `<a href="/a:b">x</a>`},
+
+		// event handlers
+		{name: "onerror", in: `<img src="x" onerror="alert(1)">`, expect: `<img src="x">`},
+		{name: "uppercase onerror", in: `<img src=x ONERROR=alert(1)>`, expect: `<img src="x">`},
+		{name: "onmouseover", in: `<b onmouseover=alert(1) lang=en>x</b>`, expect: `<b lang="en">x</b>`},
+		{
+			name:   "slash separated onerror",
+			in:     `<img src=x/onerror=alert(1)>`,
+			expect: `<img src="x/onerror=alert%281%29">`,
+		},
+		{name: "onerror after slash", in: `<img src="x"/onerror=alert(1)>`, expect: `<img src="x">`},
+
+		// svg and math
+		{name: "svg onload", in: `<svg onload=alert(1)><circle r=1></circle></svg>`, expect: ""},
+		{name: "svg script", in: `<svg><script>alert(1)</script></svg>`, expect: ""},
+		{name: "svg xlink:href", in: `<svg><a xlink:href="javascript:alert(1)">x</a></svg>`, expect: "<a>x</a>"},
+		{name: "math xlink:href", in: `<math><mi xlink:href="javascript:alert(1)">x</mi></math>`, expect: "x"},
+		{name: "math style", in: `<math><style><img src=x onerror=alert(1)></style></math>`, expect: ""},
+
+		// unclosed tags
+		{name: "unclosed element", in: `<b>x`, expect: "<b>x</b>"},
+		{name: "misnested elements", in: `<b><i>x</b>y`, expect: "<b><i>x</i></b>y"},
+		{name: "unopened end tag", in: `</b>x`, expect: "x"},
+		{name: "unterminated attribute", in: `<a href="x`, expect: ""},
+		{name: "unterminated tag", in: `<img src=x onerror=alert(1)//`, expect: ""},
+		{name: "stray lt", in: `a < b &amp; c > d`, expect: "a &lt; b &amp; c &gt; d"},
+
+		// comments
+		{name: "comment", in: `<!-- <img src=x onerror=alert(1)> -->x`, expect: "x"},
+		{name: "unterminated comment", in: `<!--<img src=x onerror=alert(1)>`, expect: ""},
+		{name: "abrupt empty comment", in: `<!-->x`, expect: "x"},
+		{name: "abrupt empty comment dash", in: `<!--->x`, expect: "x"},
+		{name: "comment ending with bang", in: `<!--a--!>x`, expect: "x"},
+		{name: "bogus comment", in: `<!x>y`, expect: "y"},
+		{name: "processing instruction", in: `<?xml x?>y`, expect: "y"},
+		{name: "conditional comment", in: `<!--[if IE]><img src=x onerror=alert(1)><![endif]-->x`, expect: "x"},
+
+		// raw text
+		{name: "script", in: `<script>alert(1)</script>x`, expect: "x"},
+		{name: "uppercase script", in: `<SCRIPT>alert(1)</SCRIPT >x`, expect: "x"},
+		{name: "script end tag prefix", in: `<script>"</scriptx>"</script>x`, expect: "x"},
+		{name: "unterminated script", in: `<script>alert(1)`, expect: ""},
+		{name: "style", in: `<style>body{}</style>x`, expect: "x"},
+		{name: "tags in style", in: `<style><img src=x onerror=alert(1)></style>x`, expect: "x"},
+		{name: "tags in textarea", in: `<textarea><img src=x onerror=alert(1)></textarea>x`, expect: "x"},
+		{name: "tags in title", in: `<title><img src=x onerror=alert(1)></title>x`, expect: "x"},
+		{
+			name:   "noscript",
+			in:     `<noscript><p title="</noscript><img src=x onerror=alert(1)>"></noscript>`,
+			expect: `<img src="x">&#34;&gt;`,
+		},
+
+		// escaping
+		{name: "escaped text", in: `&lt;script&gt;`, expect: "&lt;script&gt;"},
+		{name: "escaped attribute", in: `<abbr title="&quot;><script>">x</abbr>`, expect: `<abbr title="&#34;&gt;&lt;script&gt;">x</abbr>`},
+	}
+
+	for _, c := range testCases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := woof.SanitizeHTML(c.in)
+			require.NoError(t, err)
+			assert.Equal(t, c.expect, actual)
+		})
+	}
+}
+
+func TestSanitizePolicy_Sanitize(t *testing.T) {
+	t.Parallel()
+
+	p := &woof.SanitizePolicy{
+		Elements: map[string][]string{
+			"a":    {"href", "onclick"},
+			"span": {"class", "style"},
+		},
+		URLSchemes:      []string{"https", "javascript"},
+		ClassPatterns:   []*regexp.Regexp{regexp.MustCompile(`^text-\w+$`)},
+		StyleProperties: []string{"color"},
+	}
+
+	testCases := []struct {
+		name   string
+		in     string
+		expect woof.HTMLBody
+	}{
+		{name: "allowed event handler", in: `<a onclick="alert(1)">x</a>`, expect: "<a>x</a>"},
+		{name: "allowed scheme", in: `<a href="JAVASCRIPT:void(0)">x</a>`, expect: `<a href="JAVASCRIPT:void%280%29">x</a>`},
+		{name: "disallowed scheme", in: `<a href="http://example.com">x</a>`, expect: "<a>x</a>"},
+		{name: "class", in: `<span class="text-red evil">x</span>`, expect: `<span class="text-red">x</span>`},
+		{name: "no allowed class", in: `<span class="evil">x</span>`, expect: "<span>x</span>"},
+		{
+			name:   "style",
+			in:     `<span style="COLOR: red; background: url(javascript:alert(1))">x</span>`,
+			expect: `<span style="color: red">x</span>`,
+		},
+		{name: "unsafe style", in: `<span style="color: expression(alert(1))">x</span>`, expect: "<span>x</span>"},
+	}
+
+	for _, c := range testCases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := p.Sanitize(c.in)
+			require.NoError(t, err)
+			assert.Equal(t, c.expect, actual)
+		})
+	}
+}
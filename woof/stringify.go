@@ -51,6 +51,9 @@ var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
 // val may also be a pointer to any of the above types, or a type approximation,
 // i.e. satisfy interface{ ~string }, interface{ ~int }, etc.
 //
+// If a formatter for the type of val is registered in [DefaultFormatters], it
+// is used instead.
+//
 // If val is nil or dereferences to nil, Stringify returns "".
 //
 // If Stringify can't print a value, i.e. val is of an unsupported type,
@@ -60,6 +63,13 @@ func Stringify(val any) (string, error) {
 }
 
 func stringify(val any, escaper func(string) string) (string, error) {
+	if s, ok, err := DefaultFormatters.Format(val); ok || err != nil {
+		if err != nil || escaper == nil {
+			return s, err
+		}
+		return escaper(s), nil
+	}
+
 	// for the types in the switch, this is faster than using reflect directly:
 	// string: 2ns vs 13ns
 	// uint64: 12ns vs 15ns
@@ -194,7 +204,16 @@ func (err *UnprintableValueError) Error() string {
 // JSify converts the passed value to a JavaScript value.
 //
 // It is safe to embed into HTML without further escaping.
+//
+// If a formatter for the type of val is registered in [DefaultFormatters], the
+// formatted value is converted to a JavaScript string.
 func JSify(val any) (JS, error) {
+	if s, ok, err := DefaultFormatters.Format(val); err != nil {
+		return "", err
+	} else if ok {
+		val = s
+	}
+
 	switch t := val.(type) {
 	case JS:
 		return t, nil
@@ -252,3 +271,39 @@ func JSify(val any) (JS, error) {
 	}
 	return JS(jsonVal), nil
 }
+
+// JSONify converts the passed value to JSON.
+//
+// Unlike [JSify], it never produces JavaScript-only constructs: values of type
+// [JS] and [JSStr] are encoded as JSON strings, and only values of type [JSON]
+// are used as is.
+// The result is safe to embed into a script element of a JSON type without
+// further escaping.
+//
+// If a formatter for the type of val is registered in [DefaultFormatters], the
+// formatted value is converted to a JSON string.
+func JSONify(val any) (JSON, error) {
+	if s, ok, err := DefaultFormatters.Format(val); err != nil {
+		return "", err
+	} else if ok {
+		val = s
+	}
+
+	switch t := val.(type) {
+	case JSON:
+		return t, nil
+	case json.Marshaler:
+		// Do not treat as a Stringer.
+	case fmt.Stringer:
+		val = t.String()
+	}
+
+	// json.Marshal escapes <, >, and & by default, so we can't produce a
+	// closing script tag
+	jsonVal, err := json.Marshal(val)
+	if err != nil {
+		return "", err
+	}
+
+	return JSON(jsonVal), nil
+}
@@ -14,9 +14,12 @@ func block(ctx *ctx, b file.Block) {
 		ctx.flushClasses()
 		ctx.callUnclosedIfUnclosed()
 		ctx.writeln("if " + ctx.ident("mixinBlock_"+b.Name.Ident) + " != nil {")
-		ctx.writeln("  " + ctx.ident("mixinBlock_"+b.Name.Ident) + "()")
+		ctx.write("  " + ctx.ident("mixinBlock_"+b.Name.Ident) + "(")
+		writeBlockArgs(ctx, b)
+		ctx.writeln(")")
 		if len(b.Body) > 0 {
 			ctx.writeln("} else {")
+			writeBlockParamValues(ctx, b)
 			scope(ctx, b.Body, false)
 			ctx.flushGenerate()
 			ctx.flushClasses()
@@ -46,6 +49,52 @@ func block(ctx *ctx, b file.Block) {
 	}
 }
 
+// writeBlockArgs writes the values of the params of the mixin block
+// placeholder b, separated by commas.
+//
+// If line directives are enabled, every value bound using `=` is placed on
+// its own line, preceded by an inline line directive, see writeMixinArgs.
+func writeBlockArgs(ctx *ctx, b file.Block) {
+	oldInStatement := ctx.inStatement
+	ctx.inStatement = true
+	defer func() {
+		ctx.inStatement = oldInStatement
+		ctx.genLineDirective()
+	}()
+
+	for i, param := range b.Params {
+		if i > 0 {
+			ctx.write(", ")
+		}
+
+		if param.Value == nil {
+			ctx.write(param.Name.Ident)
+			continue
+		}
+
+		if directive := ctx.inlineLineDirective(*param.Value); directive != "" {
+			ctx.writeln("")
+			ctx.write(directive)
+		}
+
+		ctx.write(inlineExpression(ctx, *param.Value))
+	}
+}
+
+// writeBlockParamValues declares the params of the mixin block placeholder b
+// that are bound to a value, so that they can also be used by the default
+// content of b.
+func writeBlockParamValues(ctx *ctx, b file.Block) {
+	for _, param := range b.Params {
+		if param.Value == nil {
+			continue
+		}
+
+		ctx.writeln(param.Name.Ident + " := " + ctx.inlineLineDirective(*param.Value) + inlineExpression(ctx, *param.Value))
+		ctx.writeln("_ = " + param.Name.Ident)
+	}
+}
+
 func resolveTemplateBlock(ctx *ctx, call file.Block) (b file.Block, stackPos int) {
 	stack := ctx.stack()[1:]
 	for i := len(stack) - 1; i >= 0; i-- {
@@ -1,6 +1,9 @@
 package write
 
 import (
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"regexp"
 	"strings"
 
@@ -10,8 +13,20 @@ import (
 var funcHeaderRegexp = regexp.MustCompile(`^func *\w+\([^)]*\) *\{`)
 
 func code(ctx *ctx, c file.Code) {
+	stmtStarts := statementStarts(c)
+
+	ctx.inStatement = true
+	defer func() {
+		ctx.inStatement = false
+		ctx.genLineDirective()
+	}()
+
 	var ignoreControl bool
-	for _, line := range c.Lines {
+	for i, line := range c.Lines {
+		if stmtStarts[i] {
+			ctx.genLineDirective()
+		}
+
 		switch {
 		// If we're in the body of an inline function, we don't want to flush
 		case funcHeaderRegexp.MatchString(line.Code):
@@ -26,6 +41,182 @@ func code(ctx *ctx, c file.Code) {
 			}
 		}
 
-		ctx.writeln(line.Code)
+		if stmtStarts[i] {
+			ctx.writePosOf(line)
+		}
+		ctx.writeln(ctx.codeLineDirective(line, stmtStarts[i]) + line.Code)
+	}
+
+	if endsWithJump(c) {
+		ctx.scope().terminated = true
+	}
+}
+
+// endsWithJump reports whether the last statement in the outermost scope of c
+// is a return, break, continue, or goto, so that the code following c in the
+// same block is unreachable.
+//
+// If c is not valid Go code, endsWithJump returns false.
+func endsWithJump(c file.Code) bool {
+	_, stmts, err := parseStmts(c)
+	if err != nil || len(stmts) == 0 {
+		return false
+	}
+
+	switch stmt := stmts[len(stmts)-1].(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.BranchStmt:
+		return stmt.Tok != token.FALLTHROUGH
+	default:
+		return false
+	}
+}
+
+// parseStmts parses c as the body of a func, and returns the statements in
+// its outermost scope.
+//
+// The first line of c is line 3 in the returned file set.
+func parseStmts(c file.Code) (*token.FileSet, []ast.Stmt, error) {
+	var sb strings.Builder
+	sb.WriteString("package p\nfunc _() {\n")
+	for _, line := range c.Lines {
+		sb.WriteString(line.Code)
+		sb.WriteByte('\n')
+	}
+	sb.WriteString("}")
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", sb.String(), 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fset, f.Decls[0].(*ast.FuncDecl).Body.List, nil
+}
+
+// statementStarts reports for each line of c, whether it is the first line of
+// a statement in the outermost scope of c, i.e. whether a statement may be
+// placed before it.
+//
+// If c is not valid Go code by itself, e.g. because it opens a block that
+// is closed by another code item, only its first line is reported, unless it
+// closes a block.
+func statementStarts(c file.Code) []bool {
+	starts := make([]bool, len(c.Lines))
+
+	fset, stmts, err := parseStmts(c)
+	if err != nil {
+		if len(c.Lines) > 0 && !strings.HasPrefix(strings.TrimSpace(c.Lines[0].Code), "}") {
+			starts[0] = true
+		}
+		return starts
+	}
+
+	for _, stmt := range stmts {
+		// the first two lines are the package clause and the func header
+		if ln := fset.Position(stmt.Pos()).Line - 3; ln >= 0 && ln < len(starts) {
+			starts[ln] = true
+		}
 	}
+
+	return starts
+}
+
+// declStarts is the equivalent of statementStarts for global code, which
+// consists of declarations instead of statements.
+func declStarts(c file.Code) []bool {
+	starts := make([]bool, len(c.Lines))
+
+	var sb strings.Builder
+	sb.WriteString("package p\n")
+	for _, line := range c.Lines {
+		sb.WriteString(line.Code)
+		sb.WriteByte('\n')
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", sb.String(), 0)
+	if err != nil {
+		if len(c.Lines) > 0 && !strings.HasPrefix(strings.TrimSpace(c.Lines[0].Code), "}") {
+			starts[0] = true
+		}
+		return starts
+	}
+
+	for _, decl := range f.Decls {
+		// the first line is the package clause
+		if ln := fset.Position(decl.Pos()).Line - 2; ln >= 0 && ln < len(starts) {
+			starts[ln] = true
+		}
+	}
+
+	return starts
+}
+
+// writeCodeLines writes the lines of c.
+//
+// Line directives are only added in front of the lines in starts, as the
+// other lines may continue a statement, e.g. a multi-line raw string, whose
+// value a directive would change.
+func writeCodeLines(ctx *ctx, c file.Code, starts []bool) {
+	ctx.inStatement = true
+	defer func() {
+		ctx.inStatement = false
+		ctx.genLineDirective()
+	}()
+
+	for i, ln := range c.Lines {
+		if starts[i] {
+			ctx.genLineDirective()
+		}
+
+		ctx.writeln(ctx.codeLineDirective(ln, starts[i]) + ln.Code)
+	}
+}
+
+// declaredVars returns the names of the variables declared in the outermost
+// scope of c.
+//
+// If c is not valid Go code, declaredVars returns nil.
+func declaredVars(c file.Code) []string {
+	_, stmts, err := parseStmts(c)
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	addName := func(ident *ast.Ident) {
+		if ident.Name != "_" {
+			names = append(names, ident.Name)
+		}
+	}
+
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *ast.AssignStmt:
+			if stmt.Tok != token.DEFINE {
+				continue
+			}
+
+			for _, expr := range stmt.Lhs {
+				if ident, ok := expr.(*ast.Ident); ok {
+					addName(ident)
+				}
+			}
+		case *ast.DeclStmt:
+			gen, ok := stmt.Decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+
+			for _, spec := range gen.Specs {
+				for _, ident := range spec.(*ast.ValueSpec).Names {
+					addName(ident)
+				}
+			}
+		}
+	}
+
+	return names
 }
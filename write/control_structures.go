@@ -17,6 +17,7 @@ func _if(ctx *ctx, _if file.If) {
 	allClosed := true
 
 	ctx.startScope(false)
+	ctx.writePosOf(_if.Condition)
 	ctx.write("if ")
 	ctx.write(inlineCondition(ctx, _if.Condition))
 	ctx.writeln(" {")
@@ -33,6 +34,11 @@ func _if(ctx *ctx, _if file.If) {
 		ctx.startScope(false)
 
 		ctx.write("} else if ")
+		// there is no room for a statement before an else if, so we set its
+		// position in the init statement
+		if call := ctx.posCall(ctx.srcFile(), elseIf.Condition); call != "" {
+			ctx.write(call + "; ")
+		}
 		ctx.write(inlineCondition(ctx, elseIf.Condition))
 		ctx.writeln(" {")
 		scope(ctx, elseIf.Then, false)
@@ -179,6 +185,11 @@ func _switch(ctx *ctx, sw file.Switch) {
 
 	allClosed := true
 
+	if sw.Comparator != nil {
+		ctx.writePosOf(*sw.Comparator)
+	} else {
+		ctx.writePosOf(sw)
+	}
 	ctx.write("switch ")
 	if sw.Comparator != nil {
 		ctx.write(inlineExpression(ctx, *sw.Comparator))
@@ -233,6 +244,10 @@ func _switch(ctx *ctx, sw file.Switch) {
 
 func _for(ctx *ctx, f file.For) {
 	_, attrLoop := fileutil.IsFirstNonControlAttr(f.Body)
+	if !attrLoop && f.Else != nil {
+		_, attrLoop = fileutil.IsFirstNonControlAttr(f.Else.Then)
+	}
+
 	if !attrLoop {
 		ctx.closeStartTag()
 
@@ -251,8 +266,37 @@ func _for(ctx *ctx, f file.For) {
 	ctx.flushGenerate()
 	ctx.flushClasses()
 
-	if f.Expression == nil && len(f.Expression.Expressions) == 0 {
+	if f.Else == nil {
+		forLoop(ctx, f)
+		return
+	}
+
+	if attrLoop {
+		ctx.callUnclosedIfUnclosed()
+	}
+
+	ctx.writeln("{")
+	ctx.writeln("var " + ctx.ident("looped") + " bool")
+	forLoop(ctx, f)
+	ctx.writeln("if !" + ctx.ident("looped") + " {")
+	forElse(ctx, *f.Else, attrLoop)
+	ctx.writeln("}")
+	ctx.writeln("}")
+}
+
+func forLoop(ctx *ctx, f file.For) {
+	loop := loopNesting{targeted: loopTargeted(f)}
+	if f.Label != nil {
+		loop.label = f.Label.Ident
+	}
+
+	ctx.loops = append(ctx.loops, loop)
+	defer func() { ctx.loops = ctx.loops[:len(ctx.loops)-1] }()
+
+	if f.Expression == nil || len(f.Expression.Expressions) == 0 {
+		writeLoopLabel(ctx)
 		ctx.writeln("for {")
+		startLoopBody(ctx, f)
 		scope(ctx, f.Body, false)
 		ctx.flushGenerate()
 		ctx.flushClasses()
@@ -260,6 +304,8 @@ func _for(ctx *ctx, f file.For) {
 		return
 	}
 
+	ctx.writePosOf(*f.Expression)
+
 	if len(f.Expression.Expressions) == 1 {
 		rangeExpr, ok := f.Expression.Expressions[0].(file.RangeExpression)
 		if ok {
@@ -268,13 +314,107 @@ func _for(ctx *ctx, f file.For) {
 		}
 	}
 
-	ctx.writeln("for " + inlineExpression(ctx, *f.Expression) + " {")
+	cond := inlineExpression(ctx, *f.Expression)
+	writeLoopLabel(ctx)
+	ctx.writeln("for " + cond + " {")
+	startLoopBody(ctx, f)
 	scope(ctx, f.Body, false)
 	ctx.flushGenerate()
 	ctx.flushClasses()
 	ctx.writeln("}")
 }
 
+// loopTargeted reports whether a break or continue in the body of f refers to
+// f.
+func loopTargeted(f file.For) bool {
+	var targeted bool
+	_ = fileutil.Walk(f.Body, func(parents []fileutil.WalkContext, wctx fileutil.WalkContext) (dive bool, err error) {
+		var label *file.Ident
+		switch itm := (*wctx.Item).(type) {
+		case file.Include:
+			return false, nil
+		case file.Break:
+			label = itm.Label
+		case file.Continue:
+			label = itm.Label
+		default:
+			return true, nil
+		}
+
+		if label != nil && (f.Label == nil || label.Ident != f.Label.Ident) {
+			return false, nil
+		}
+
+		if loop, acrossMixin := fileutil.LoopOf(parents, label); loop == nil && !acrossMixin {
+			targeted = true
+			return false, fileutil.StopWalk
+		}
+		return false, nil
+	})
+
+	return targeted
+}
+
+// writeLoopLabel writes a new Go label for the loop about to be written, if
+// it is targeted by a break or continue.
+//
+// The label is generated anew for each call, as the same loop may be written
+// multiple times, e.g. when ranging over a chain expression with a default.
+func writeLoopLabel(ctx *ctx) {
+	loop := &ctx.loops[len(ctx.loops)-1]
+	if !loop.targeted {
+		return
+	}
+
+	loop.goLabel = ctx.nextLoopIdent()
+	ctx.writeln(loop.goLabel + ":")
+}
+
+// startLoopBody writes the statements at the start of the body of f.
+//
+// That is a check of the context.Context, unless the first item of the body
+// is a mixin call, which is checked anyway, and, if f has an else, the
+// assignment marking that the body was executed.
+func startLoopBody(ctx *ctx, f file.For) {
+	ctx.loops[len(ctx.loops)-1].depth = ctx.scopes.Len()
+
+	if f.Else != nil {
+		ctx.writeln(ctx.ident("looped") + " = true")
+	}
+
+	if len(f.Body) > 0 {
+		if _, ok := f.Body[0].(file.MixinCall); ok {
+			return
+		}
+	}
+
+	ctx.checkContext()
+}
+
+// forElse writes the else of a for loop.
+//
+// If the loop only writes attributes, the start tag isn't closed before the
+// loop, so the else is treated like the else of an if.
+func forElse(ctx *ctx, e file.Else, attrLoop bool) {
+	if !attrLoop {
+		scope(ctx, e.Then, false)
+		ctx.flushGenerate()
+		ctx.flushClasses()
+		return
+	}
+
+	ctx.startScope(false)
+	scope(ctx, e.Then, false)
+	ctx.flushGenerate()
+	ctx.flushClasses()
+	ctx.callClosedIfClosed()
+	ctx.endScope()
+
+	if ctx.scope().startClosed != closed {
+		ctx.scope().startClosed = maybeClosed
+	}
+}
+
 func forRange(ctx *ctx, f file.For, rangeExpr file.RangeExpression) {
 	ctx.debugItem(rangeExpr, "(see below)")
 
@@ -290,6 +430,8 @@ func forRange(ctx *ctx, f file.For, rangeExpr file.RangeExpression) {
 }
 
 func _forRange(ctx *ctx, f file.For, rangeExpr file.RangeExpression, rangerExpr string) {
+	writeLoopLabel(ctx)
+
 	if rangeExpr.Ordered {
 		ctx.writeln("for _, " + ctx.ident("orderVal") + " := range " + ctx.woofFunc("OrderedMap", rangerExpr) + " {")
 		if rangeExpr.Var1 != nil {
@@ -323,8 +465,40 @@ func _forRange(ctx *ctx, f file.For, rangeExpr file.RangeExpression, rangerExpr
 		ctx.writeln("range " + rangerExpr + " {")
 	}
 
+	startLoopBody(ctx, f)
 	scope(ctx, f.Body, false)
 	ctx.flushGenerate()
 	ctx.flushClasses()
 	ctx.writeln("}")
 }
+
+// ============================================================================
+// Break and Continue
+// ======================================================================================
+
+// loopControl writes a break or continue, as indicated by keyword, of the
+// loop with the passed label, or the innermost loop, if label is nil.
+//
+// Before that, it closes all elements started inside the loop's body.
+func loopControl(ctx *ctx, itm file.Poser, keyword string, label *file.Ident) {
+	ctx.debugItem(itm, keyword)
+	ctx.flushGenerate()
+	ctx.flushClasses()
+
+	loop := ctx.loops[len(ctx.loops)-1]
+	if label != nil {
+		for i := len(ctx.loops) - 1; i >= 0; i-- {
+			if ctx.loops[i].label == label.Ident {
+				loop = ctx.loops[i]
+				break
+			}
+		}
+	}
+
+	ctx.closeElemsAbove(loop.depth)
+	ctx.flushGenerate()
+	ctx.callClosedIfClosed()
+
+	ctx.writeln(keyword + " " + loop.goLabel)
+	ctx.scope().terminated = true
+}
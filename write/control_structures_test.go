@@ -0,0 +1,118 @@
+package write_test
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi"
+	"github.com/mavolin/corgi/write"
+)
+
+const jumpsCorgi = `func Page(nums []int)
+
+mixin m(n int)
+  switch n
+    case 0
+      return
+    case 1
+      p #{n}
+      return
+  span
+
+for _, n := range nums
+  +m(n=n)
+  if n < 0
+    div
+      p
+        continue
+  if n > 9
+    strong
+      - break
+  em #{n}
+`
+
+// generate generates the main file with the passed contents using o.
+func generate(t *testing.T, src string, o write.Options) string {
+	t.Helper()
+
+	l, err := corgi.NewFSLoader(corgi.FSLoadOptions{
+		Modules: map[string]fs.FS{
+			"example.com/test": fstest.MapFS{
+				"page.corgi": &fstest.MapFile{Data: []byte(src)},
+			},
+		},
+		NoPrecompile: true,
+	})
+	require.NoError(t, err)
+
+	f, err := l.LoadMain("example.com/test/page.corgi")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = write.New(o).GenerateFile(&buf, "test", f)
+	require.NoError(t, err)
+
+	return buf.String()
+}
+
+func TestFor_Infinite(t *testing.T) {
+	out := generate(t, "func Page()\n\n- i := 0\nfor\n  - i++\n  if i > 3\n    break\n  p #{i}\n", write.Options{})
+	assert.Contains(t, out, "for {")
+}
+
+// TestJumps tests that nothing is written after a break, continue, or
+// return, as it would be unreachable.
+func TestJumps(t *testing.T) {
+	src := generate(t, jumpsCorgi, write.Options{})
+
+	fset := token.NewFileSet()
+	gof, err := parser.ParseFile(fset, "page.corgi.go", src, 0)
+	require.NoError(t, err, src)
+
+	var jumps int
+	checkStmts := func(stmts []ast.Stmt) {
+		for i, stmt := range stmts {
+			if !isJump(stmt) {
+				continue
+			}
+
+			jumps++
+			if i < len(stmts)-1 {
+				assert.Failf(t, "unreachable code", "%s is followed by code", fset.Position(stmt.Pos()))
+			}
+		}
+	}
+
+	ast.Inspect(gof, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.BlockStmt:
+			checkStmts(n.List)
+		case *ast.CaseClause:
+			checkStmts(n.Body)
+		}
+		return true
+	})
+
+	// 2 returns, break, and continue in the body, plus the return of the
+	// main func
+	assert.Equal(t, 5, jumps)
+}
+
+func isJump(stmt ast.Stmt) bool {
+	switch stmt := stmt.(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.BranchStmt:
+		return stmt.Tok != token.FALLTHROUGH
+	default:
+		return false
+	}
+}
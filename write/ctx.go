@@ -30,8 +30,18 @@ type ctx struct {
 	identPrefix  string
 	gotoCounter  int
 	mixinCounter int
+	loopCounter  int
 
-	hasNonce bool
+	hasNonce      bool
+	hasStyleNonce bool
+	// hasContext indicates whether the woof.Context has a context.Context,
+	// that needs to be checked in loops and before mixin calls.
+	hasContext bool
+
+	// scriptHashes and styleHashes are the CSP hashes of the static inline
+	// scripts and styles written so far, in the order they were written.
+	scriptHashes []string
+	styleHashes  []string
 
 	// _stack are the files we are currently in.
 	//
@@ -48,11 +58,31 @@ type ctx struct {
 
 	// mixin is the mixin we are currently generating.
 	mixin *file.Mixin
+	// typeArgs maps the type params of the instantiation of the generic
+	// mixin we are currently generating to their type args.
+	typeArgs map[string]string
+	// genericInstances are the type args of the instantiations of each
+	// generic mixin, by genericMixinKey.
+	genericInstances map[string][][]string
+	// genericMixins are the generic mixins in genericInstances, by
+	// genericMixinKey.
+	genericMixins map[string]*file.Mixin
+	// genericInstancePositions are the positions of the first calls of the
+	// instantiations in genericInstances, by genericInstanceID.
+	genericInstancePositions map[string]instancePos
+	// instantiations, if not nil, collects the instantiations of generic
+	// library mixins that are called.
+	//
+	// It is used when precompiling libraries.
+	instantiations *[]file.MixinInstantiation
 
 	scopes         *stack.Stack[*nesting]
 	classBuf       strings.Builder
 	calledUnclosed bool
 
+	// loops are the loops we are currently in, the innermost last.
+	loops []loopNesting
+
 	mixinFuncNames mixinFuncMap
 
 	allowedFilters  []string
@@ -62,6 +92,49 @@ type ctx struct {
 	corgierrPretty corgierr.PrettyOptions
 
 	debugEnabled bool
+	pretty       bool
+
+	lineDirectives bool
+	lineFileName   func(*file.File) string
+	genFileName    string
+	// outLine is the number of lines written so far.
+	outLine int
+	// atLineStart indicates whether the next write starts a new line.
+	atLineStart bool
+	// mappedFile and mappedLine are the name of the file and the line that
+	// the current line is attributed to by line directives, or "" and 0, if
+	// it is attributed to the generated file.
+	mappedFile string
+	mappedLine int
+	// hasNextLineDirective indicates that the following lines should be
+	// attributed to nextFile and nextLine, or to the generated file, if
+	// nextFile is "".
+	//
+	// The directive is only written right before the next line, so that
+	// directives that are superseded before anything is written are
+	// omitted.
+	hasNextLineDirective bool
+	nextFile             string
+	nextLine             int
+	// inlineDirectiveLine indicates that the current line contains an inline
+	// line directive, and that the following lines should be attributed back
+	// to the generated file.
+	inlineDirectiveLine bool
+	// inStatement indicates that the lines being written may belong to the
+	// same Go statement, e.g. the lines of a code item, so inline line
+	// directives must not be followed by a //line directive.
+	inStatement bool
+	// lineFile, if set, overrides the file used in line directives.
+	//
+	// It is used for items that don't originate from the current file, i.e.
+	// library mixins and the func code of templates.
+	lineFile *file.File
+
+	// posItem is the item last passed to debugItem, and posFile the file it
+	// originates from.
+	// They are used to attribute runtime errors to their position.
+	posItem file.Poser
+	posFile *file.File
 
 	generateBuf bytes.Buffer
 }
@@ -74,11 +147,42 @@ type nesting struct {
 	startClosed    closeState
 	haveBufClasses bool
 	shallow        bool
+
+	// pretty indicates that the element was placed on its own line using
+	// PrettyOpen, and must therefore be ended using PrettyClose.
+	pretty bool
+	// preformatted indicates that we are inside a pre or textarea element,
+	// whose whitespace must not be touched in pretty mode.
+	preformatted bool
+	// elem indicates that the nesting was started by an element, and not by
+	// a scope.
+	elem bool
+	// terminated indicates that the Go block the nesting is part of was left
+	// using a break, continue, or return, so that anything written to it
+	// afterwards, e.g. the end tags of its elements, would be unreachable.
+	terminated bool
+}
+
+type loopNesting struct {
+	// label is the corgi label of the loop, or "" if it has none.
+	label string
+	// targeted indicates whether a break or continue in the loop's body
+	// refers to the loop, and the loop therefore needs a Go label.
+	targeted bool
+	// goLabel is the Go label of the loop, if it is targeted.
+	goLabel string
+	// depth is the number of scopes at the start of the loop's body.
+	depth int
 }
 
 type mixinFuncMap struct {
 	m     map[string]map[string]string
 	scope map[*file.File]*list.List[map[string]string]
+	// generic are the names of the scope mixins that are generic.
+	//
+	// Since only their instantiations are declared, there are no variables
+	// with these names.
+	generic map[string]struct{}
 }
 
 func (mfm *mixinFuncMap) mixin(ctx *ctx, mc file.MixinCall) string {
@@ -121,6 +225,14 @@ func (mfm *mixinFuncMap) addScope(ctx *ctx, m *file.Mixin) string {
 
 	varName := ctx.nextMixinIdent()
 	currScope.V()[m.Name.Ident] = varName
+
+	if len(m.TypeParams) > 0 {
+		if mfm.generic == nil {
+			mfm.generic = make(map[string]struct{})
+		}
+		mfm.generic[varName] = struct{}{}
+	}
+
 	return varName
 }
 
@@ -153,11 +265,7 @@ func (s closeState) String() string {
 func newCtx(o Options) *ctx {
 	return &ctx{
 		identPrefix: o.IdentPrefix,
-		scopes: stack.New1(&nesting{
-			exprEscaper: plainBodyExprEscaper,
-			txtEscaper:  plainBodyTextEscaper,
-			startClosed: closed,
-		}),
+		scopes:      stack.New1(rootNesting()),
 		mixinFuncNames: mixinFuncMap{
 			m:     make(map[string]map[string]string),
 			scope: make(map[*file.File]*list.List[map[string]string]),
@@ -167,6 +275,20 @@ func newCtx(o Options) *ctx {
 		cli:             o.CLI,
 		corgierrPretty:  o.CorgierrPretty,
 		debugEnabled:    o.Debug,
+		pretty:          o.Pretty,
+		lineDirectives:  o.LineDirectives,
+		lineFileName:    o.LineDirectiveFileName,
+		genFileName:     o.GeneratedFileName,
+		atLineStart:     true,
+	}
+}
+
+// rootNesting returns the nesting of the root scope of a generated func.
+func rootNesting() *nesting {
+	return &nesting{
+		exprEscaper: plainBodyExprEscaper,
+		txtEscaper:  plainBodyTextEscaper,
+		startClosed: closed,
 	}
 }
 
@@ -189,6 +311,7 @@ func (ctx *ctx) startScope(shallow bool) *nesting {
 		startClosed:    old.startClosed,
 		haveBufClasses: old.haveBufClasses,
 		shallow:        shallow,
+		preformatted:   old.preformatted,
 	}
 	ctx.scopes.Push(newScope)
 	return newScope
@@ -204,6 +327,7 @@ func (ctx *ctx) endScope() *nesting {
 	if old.shallow {
 		ctx.scope().startClosed = old.startClosed
 		ctx.scope().haveBufClasses = old.haveBufClasses
+		ctx.scope().terminated = ctx.scope().terminated || old.terminated
 	}
 	return old
 }
@@ -268,6 +392,14 @@ func (ctx *ctx) ioQual(ident string) string {
 	return ctx.identPrefix + "io" + "." + ident
 }
 
+func (ctx *ctx) httpQual(ident string) string {
+	return ctx.identPrefix + "http" + "." + ident
+}
+
+func (ctx *ctx) corgihttpQual(ident string) string {
+	return ctx.identPrefix + "corgihttp" + "." + ident
+}
+
 func (ctx *ctx) fmtFunc(name string, args ...string) string {
 	var sb strings.Builder
 
@@ -298,6 +430,11 @@ func (ctx *ctx) nextMixinIdent() string {
 	return fmt.Sprint(ctx.identPrefix, "mixin", ctx.mixinCounter-1)
 }
 
+func (ctx *ctx) nextLoopIdent() string {
+	ctx.loopCounter++
+	return fmt.Sprint(ctx.identPrefix, "loop", ctx.loopCounter-1)
+}
+
 func (ctx *ctx) stack() []*file.File {
 	return ctx._stack[ctx.stackStart:]
 }
@@ -317,11 +454,15 @@ func (ctx *ctx) mainFile() *file.File {
 func (ctx *ctx) startElem(name string, void bool) {
 	ctx.closeStartTag()
 
+	preformatted := ctx.scope().preformatted || name == "pre" || name == "textarea"
+
 	ctx.generate("<"+name, nil)
 	ctx.scopes.Push(&nesting{
 		elemName:       name,
 		customVoidElem: void,
 		startClosed:    unclosed,
+		preformatted:   preformatted,
+		elem:           true,
 	})
 	ctx.calledUnclosed = false
 
@@ -337,6 +478,11 @@ func (ctx *ctx) startElem(name string, void bool) {
 	case "style":
 		ctx.scope().exprEscaper = styleBodyExprEscaper
 		ctx.scope().txtEscaper = styleBodyTextEscaper
+
+		if ctx.hasStyleNonce {
+			ctx.flushGenerate()
+			ctx.writeln(ctx.contextFunc("InjectStyleNonce"))
+		}
 	default:
 		ctx.scope().exprEscaper = plainBodyExprEscaper
 		ctx.scope().txtEscaper = plainBodyTextEscaper
@@ -344,8 +490,24 @@ func (ctx *ctx) startElem(name string, void bool) {
 }
 
 func (ctx *ctx) closeElem() {
+	if ctx.scope().terminated {
+		// the end tag would be unreachable, loopControl writes it before
+		// jumping instead
+		ctx.scopes.Pop()
+		ctx.scope().terminated = true
+		return
+	}
+
 	ctx.closeStartTag()
-	nest := ctx.scopes.Pop()
+	ctx.endTag(ctx.scopes.Pop())
+}
+
+// endTag writes the end tag of the element that started nest.
+func (ctx *ctx) endTag(nest *nesting) {
+	if nest.pretty {
+		ctx.flushGenerate()
+		ctx.writeln(ctx.contextFunc("PrettyClose"))
+	}
 
 	if nest.customVoidElem || voidelem.Is(nest.elemName) {
 		return
@@ -354,6 +516,64 @@ func (ctx *ctx) closeElem() {
 	ctx.generate("</"+nest.elemName+">", nil)
 }
 
+// closeElemsAbove writes the end tags of the elements started in the scopes
+// above depth, without ending them.
+//
+// It is used before jumping out of those scopes, e.g. when breaking out of a
+// loop, so that no element is left unclosed.
+func (ctx *ctx) closeElemsAbove(depth int) {
+	var elems []*nesting
+
+	n := ctx.scopes.Len() - depth
+	ctx.scopes.Each(func(nest *nesting) bool {
+		if n <= 0 {
+			return false
+		}
+		n--
+
+		if nest.elem {
+			elems = append(elems, nest)
+		}
+		return true
+	})
+
+	if len(elems) == 0 {
+		return
+	}
+
+	ctx.closeStartTag()
+	for _, nest := range elems {
+		ctx.endTag(nest)
+	}
+}
+
+// prettyBreak places the item about to be generated on its own line, if
+// pretty mode is enabled and we are not inside a preformatted element.
+func (ctx *ctx) prettyBreak() {
+	if !ctx.pretty || ctx.scope().preformatted {
+		return
+	}
+
+	ctx.flushGenerate()
+	ctx.writeln(ctx.contextFunc("PrettyBreak"))
+}
+
+// startBlockElem is the same as startElem, but in pretty mode places the
+// element on its own line and indents its children.
+func (ctx *ctx) startBlockElem(name string, void bool) {
+	if !ctx.pretty || ctx.scope().preformatted {
+		ctx.startElem(name, void)
+		return
+	}
+
+	ctx.closeStartTag()
+	ctx.flushGenerate()
+	ctx.writeln(ctx.contextFunc("PrettyOpen"))
+
+	ctx.startElem(name, void)
+	ctx.scope().pretty = true
+}
+
 func (ctx *ctx) debug(typ, s string) {
 	if !ctx.debugEnabled {
 		return
@@ -370,7 +590,15 @@ func (ctx *ctx) debugInline(typ, s string) {
 	ctx.write(fmt.Sprintf(" /* [%s] %s */ ", typ, s))
 }
 
+// debugItem is called before generating an item.
+//
+// It writes a line directive for the item, and, if debugging is enabled,
+// information about it.
 func (ctx *ctx) debugItem(itm file.Poser, s string) {
+	ctx.lineDirective(itm)
+	ctx.posItem = itm
+	ctx.posFile = ctx.srcFile()
+
 	if !ctx.debugEnabled {
 		return
 	}
@@ -378,6 +606,48 @@ func (ctx *ctx) debugItem(itm file.Poser, s string) {
 	ctx.debug("item", fmt.Sprintf("%T (%d:%d): %s", itm, itm.Pos().Line, itm.Pos().Col, s))
 }
 
+// writePos writes a call to woof.Context.At with the position of the item
+// last passed to debugItem, so that errors caused by the statement written
+// next are attributed to it.
+func (ctx *ctx) writePos() {
+	if ctx.posItem == nil {
+		return
+	}
+
+	if call := ctx.posCall(ctx.posFile, ctx.posItem); call != "" {
+		ctx.writeln(call)
+	}
+}
+
+// writePosOf is the same as writePos, but uses the position of itm in the
+// current source file.
+func (ctx *ctx) writePosOf(itm file.Poser) {
+	if call := ctx.posCall(ctx.srcFile(), itm); call != "" {
+		ctx.writeln(call)
+	}
+}
+
+// posCall returns a call to woof.Context.At with the position of itm in f.
+//
+// If the position of itm is unknown, posCall returns "".
+func (ctx *ctx) posCall(f *file.File, itm file.Poser) string {
+	pos := itm.Pos()
+	if pos.Line <= 0 {
+		return ""
+	}
+
+	return ctx.contextFunc("At", strconv.Quote(renderFileName(f)), strconv.Itoa(pos.Line), strconv.Itoa(pos.Col))
+}
+
+// renderFileName returns the name of f used in woof.RenderErrors.
+func renderFileName(f *file.File) string {
+	if f.Module != "" && f.PathInModule != "" {
+		return f.Module + "/" + f.PathInModule
+	}
+
+	return f.Name
+}
+
 func (ctx *ctx) debugItemInline(itm file.Poser, s string) {
 	if !ctx.debugEnabled {
 		return
@@ -387,17 +657,33 @@ func (ctx *ctx) debugItemInline(itm file.Poser, s string) {
 }
 
 func (ctx *ctx) write(s string) {
+	if s == "" {
+		return
+	}
+
+	ctx.writeNextLineDirective()
+
 	_, err := io.WriteString(ctx.out, s)
 	if err != nil {
 		panic(err)
 	}
+
+	ctx.wrote(s)
 }
 
 func (ctx *ctx) writeBytes(p []byte) {
+	if len(p) == 0 {
+		return
+	}
+
+	ctx.writeNextLineDirective()
+
 	_, err := ctx.out.Write(p)
 	if err != nil {
 		panic(err)
 	}
+
+	ctx.wrote(string(p))
 }
 
 func (ctx *ctx) writeln(s string) {
@@ -474,6 +760,7 @@ func (ctx *ctx) generateExpr(expr string, esc *expressionEscaper) {
 	ctx.flushGenerate()
 
 	escName := ctx.woofQual(esc.funcName)
+	ctx.writePos()
 	ctx.writeln(ctx.woofFunc("WriteAny", ctx.ident(ctxVar), escName, expr))
 }
 
@@ -549,7 +836,7 @@ func (ctx *ctx) callUnclosedIfUnclosed() {
 func (ctx *ctx) callClosedIfClosed() {
 	ctx.debug("call closed if closed", "close state: "+ctx.scope().startClosed.String())
 
-	if ctx.scope().startClosed == closed {
+	if ctx.scope().startClosed == closed && !ctx.scope().terminated {
 		ctx.writeln(ctx.contextFunc("Closed"))
 	}
 }
@@ -562,3 +849,11 @@ func (ctx *ctx) stringify(val any) string {
 
 	return s
 }
+
+// checkContext writes a check of the context.Context of the woof.Context, if
+// it has one.
+func (ctx *ctx) checkContext() {
+	if ctx.hasContext {
+		ctx.writeln(ctx.contextFunc("CheckContext"))
+	}
+}
@@ -2,10 +2,12 @@ package write
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/mavolin/corgi/file"
+	"github.com/mavolin/corgi/file/fileutil"
 	"github.com/mavolin/corgi/woof"
 )
 
@@ -15,6 +17,7 @@ import (
 
 func doctype(ctx *ctx, _ file.Doctype) {
 	ctx.closeStartTag()
+	ctx.prettyBreak()
 
 	ctx.generate("<!doctype html>", nil)
 }
@@ -23,10 +26,28 @@ func doctype(ctx *ctx, _ file.Doctype) {
 // Comment
 // ======================================================================================
 
+// corgiComment writes a flush point, if c is a `//corgi:flush` machine
+// comment.
+//
+// All other corgi comments are ignored.
+func corgiComment(ctx *ctx, c file.CorgiComment) {
+	mcom := fileutil.ParseMachineComment(c)
+	if mcom == nil || mcom.Namespace != "corgi" || mcom.Directive != "flush" {
+		return
+	}
+
+	ctx.debugItem(c, "flush point")
+	ctx.closeStartTag()
+	ctx.flushGenerate()
+	ctx.flushClasses()
+	ctx.writeln(ctx.contextFunc("Flush"))
+}
+
 var htmlCommentEscaper = strings.NewReplacer("-->", "-- >")
 
 func htmlComment(ctx *ctx, c file.HTMLComment) {
 	ctx.closeStartTag()
+	ctx.prettyBreak()
 
 	ctx.generate("<!--", nil)
 
@@ -47,7 +68,10 @@ func htmlComment(ctx *ctx, c file.HTMLComment) {
 
 func element(ctx *ctx, el file.Element) {
 	ctx.debugItem(el, el.Name)
-	ctx.startElem(el.Name, el.Void)
+	ctx.startBlockElem(el.Name, el.Void)
+	if el.Name == "script" {
+		useScriptTypeEscapers(ctx, el)
+	}
 
 	for _, acoll := range el.Attributes {
 		attributeCollection(ctx, acoll)
@@ -108,13 +132,14 @@ func minifyStyleElement(ctx *ctx, el file.Element) bool {
 	}
 
 	s := styleBodyTextEscaper.f(sb.String())
-	css, err := mini.String("text/css", s)
+	css, err := ctx.minify("text/css", s)
 	if err != nil {
 		panic(fmt.Errorf("%s:%d:%d: style contains invalid CSS: %w", ctx.currentFile().Name, el.Line, el.Col, err))
 	}
 
 	ctx.closeStartTag()
 	ctx.generate(css, nil)
+	ctx.styleHashes = appendCSPHash(ctx.styleHashes, css)
 	return true
 }
 
@@ -150,7 +175,64 @@ func onlyTextInTextLines(lns ...file.TextLine) int {
 
 const jsExprPlaceholder = "__corgi_expr"
 
+var jsExprPlaceholderRegexp = regexp.MustCompile(jsExprPlaceholder + `\d+`)
+
+type scriptKind uint8
+
+const (
+	jsScript scriptKind = iota
+	jsonScript
+	// otherScript is a script of a type not interpreted by browsers, such as
+	// text/template.
+	otherScript
+)
+
+// scriptKindOf returns the kind of script a script element with the passed
+// type attribute value is.
+func scriptKindOf(typ string) scriptKind {
+	essence, _, _ := strings.Cut(typ, ";")
+	essence = strings.ToLower(strings.TrimSpace(essence))
+
+	switch essence {
+	// https://html.spec.whatwg.org/multipage/scripting.html#prepare-the-script-element
+	case "", "module",
+		"application/ecmascript", "application/javascript", "application/x-ecmascript",
+		"application/x-javascript", "text/ecmascript", "text/javascript", "text/javascript1.0",
+		"text/javascript1.1", "text/javascript1.2", "text/javascript1.3", "text/javascript1.4",
+		"text/javascript1.5", "text/jscript", "text/livescript", "text/x-ecmascript", "text/x-javascript":
+		return jsScript
+	case "importmap", "speculationrules", "application/json", "text/json":
+		return jsonScript
+	}
+
+	if strings.HasSuffix(essence, "+json") {
+		return jsonScript
+	}
+
+	return otherScript
+}
+
+// useScriptTypeEscapers replaces the escapers of the script element that was
+// just started, if it is not a JavaScript script.
+func useScriptTypeEscapers(ctx *ctx, el file.Element) {
+	typ, _ := fileutil.ScriptType(el)
+
+	switch scriptKindOf(typ) {
+	case jsonScript:
+		ctx.scope().exprEscaper = jsonBodyExprEscaper
+	case otherScript:
+		ctx.scope().exprEscaper = plainBodyExprEscaper
+	}
+}
+
 func minifyScriptElement(ctx *ctx, el file.Element) bool {
+	typ, _ := fileutil.ScriptType(el)
+
+	kind := scriptKindOf(typ)
+	if kind == otherScript {
+		return false
+	}
+
 	var n int
 
 	for _, itm := range el.Body {
@@ -189,7 +271,19 @@ func minifyScriptElement(ctx *ctx, el file.Element) bool {
 	}
 
 	s := scriptBodyTextEscaper.f(sb.String())
-	js, err := mini.String("application/javascript", s)
+
+	mediatype := "application/javascript"
+	exprEscaper := scriptBodyExprEscaper
+	phQuote := ""
+	if kind == jsonScript {
+		mediatype = "application/json"
+		exprEscaper = jsonBodyExprEscaper
+		// JSON has no identifiers, so use strings as placeholders
+		phQuote = `"`
+		s = jsExprPlaceholderRegexp.ReplaceAllString(s, `"$0"`)
+	}
+
+	code, err := ctx.minify(mediatype, s)
 	if err != nil {
 		return false
 	}
@@ -197,22 +291,43 @@ func minifyScriptElement(ctx *ctx, el file.Element) bool {
 	ctx.closeStartTag()
 
 	for i, expr := range placeholderExprs {
-		ph := jsExprPlaceholder + strconv.Itoa(i)
-		placeholderIndex := strings.Index(js, ph)
+		ph := phQuote + jsExprPlaceholder + strconv.Itoa(i) + phQuote
+		placeholderIndex := strings.Index(code, ph)
 		if placeholderIndex < 0 {
 			return false
 		}
 
-		ctx.generate(js[:placeholderIndex], nil)
-		generateExpression(ctx, expr, nil, &scriptBodyExprEscaper, nil)
-		js = js[placeholderIndex+len(ph):]
+		ctx.generate(code[:placeholderIndex], nil)
+		generateExpression(ctx, expr, nil, &exprEscaper, nil)
+		code = code[placeholderIndex+len(ph):]
 	}
 
-	ctx.generate(js, nil)
+	ctx.generate(code, nil)
+
+	if len(placeholderExprs) == 0 {
+		ctx.scriptHashes = appendCSPHash(ctx.scriptHashes, code)
+	}
 
 	return true
 }
 
+// appendCSPHash appends the CSP hash of the passed static script or style
+// body to hashes, if it's not already in it.
+func appendCSPHash(hashes []string, body string) []string {
+	return appendHash(hashes, woof.CSPHash(body))
+}
+
+// appendHash appends hash to hashes, if it's not already in it.
+func appendHash(hashes []string, hash string) []string {
+	for _, h := range hashes {
+		if h == hash {
+			return hashes
+		}
+	}
+
+	return append(hashes, hash)
+}
+
 func onlyExprsInTextLines(lns ...file.TextLine) int {
 	var n int
 
@@ -280,7 +395,7 @@ func writeTextLines(sb *strings.Builder, placeholderExprs *[]file.Expression, ln
 func divShorthand(ctx *ctx, dsh file.DivShorthand) {
 	ctx.debugItem(dsh, "")
 
-	ctx.startElem("div", false)
+	ctx.startBlockElem("div", false)
 
 	for _, acoll := range dsh.Attributes {
 		attributeCollection(ctx, acoll)
@@ -410,6 +525,7 @@ func simpleAttribute(ctx *ctx, sattr file.SimpleAttribute) {
 			if ok {
 				valueChainExpression(ctx, cexpr, func(expr string) {
 					ctx.flushGenerate()
+					ctx.writePos()
 					ctx.writeln(ctx.woofFunc("WriteAttr", ctx.ident(ctxVar), strconv.Quote(sattr.Name), expr,
 						ctx.woofQual("EscapeHTMLAttrVal")))
 				})
@@ -422,6 +538,7 @@ func simpleAttribute(ctx *ctx, sattr file.SimpleAttribute) {
 	case woof.ContentTypePlain:
 		expr := inlineExpression(ctx, *sattr.Value)
 		ctx.flushGenerate()
+		ctx.writePos()
 		ctx.writeln(ctx.woofFunc("WriteAttr", ctx.ident(ctxVar), strconv.Quote(sattr.Name), expr,
 			ctx.woofQual("EscapeHTMLAttrVal")))
 	case woof.ContentTypeCSS:
@@ -483,6 +600,7 @@ func classAttribute(ctx *ctx, attr file.SimpleAttribute) {
 		case file.ChainExpression:
 			ctx.flushClasses()
 			valueChainExpression(ctx, exprItm, func(expr string) {
+				ctx.writePos()
 				ctx.writeln(ctx.contextFunc("BufferClass", expr))
 			})
 			return
@@ -490,7 +608,9 @@ func classAttribute(ctx *ctx, attr file.SimpleAttribute) {
 	}
 
 	ctx.flushClasses()
-	ctx.writeln(ctx.contextFunc("BufferClass", inlineExpression(ctx, *attr.Value)))
+	expr := inlineExpression(ctx, *attr.Value)
+	ctx.writePos()
+	ctx.writeln(ctx.contextFunc("BufferClass", expr))
 }
 
 // =================================== AndPlaceholder ===================================
@@ -0,0 +1,75 @@
+package write_test
+
+import (
+	"bytes"
+	"io/fs"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi"
+	"github.com/mavolin/corgi/woof"
+	"github.com/mavolin/corgi/write"
+)
+
+const cspLibCorgi = `mixin Scripts()
+  script
+    > console.log("a");
+  script
+    > console.log("b");
+
+mixin Style()
+  style
+    > p { color: red; }
+`
+
+// TestCSPHashes_PrecompiledLibrary tests that the CSP hashes of the scripts
+// and styles of precompiled library mixins are part of the CSP hashes of the
+// main file.
+func TestCSPHashes_PrecompiledLibrary(t *testing.T) {
+	libFiles := fstest.MapFS{
+		"lib/lib.corgil": &fstest.MapFile{Data: []byte(cspLibCorgi)},
+	}
+
+	l, err := corgi.NewFSLoader(corgi.FSLoadOptions{
+		Modules:      map[string]fs.FS{"example.com/test": libFiles},
+		NoPrecompile: true,
+	})
+	require.NoError(t, err)
+
+	lib, err := l.LoadLibrary("example.com/test/lib")
+	require.NoError(t, err)
+
+	var precomp bytes.Buffer
+	err = write.New(write.Options{}).PrecompileLibrary(&precomp, lib)
+	require.NoError(t, err)
+
+	l, err = corgi.NewFSLoader(corgi.FSLoadOptions{
+		Modules: map[string]fs.FS{
+			"example.com/test": fstest.MapFS{
+				"lib/lib.precorgi": &fstest.MapFile{Data: precomp.Bytes()},
+				"page.corgi": &fstest.MapFile{Data: []byte("//corgi:csphashes\n\n" +
+					"use \"example.com/test/lib\"\n\n" +
+					"func Page()\n\n" +
+					"+lib.Scripts()\n+lib.Style()\n")},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	f, err := l.LoadMain("example.com/test/page.corgi")
+	require.NoError(t, err)
+	require.True(t, f.Uses[0].Uses[0].Library.Precompiled, "library not loaded from precompiled file")
+
+	var buf bytes.Buffer
+	err = write.New(write.Options{}).GenerateFile(&buf, "test", f)
+	require.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, "Scripts: []string{"+strconv.Quote(woof.CSPHash(`console.log("a");`))+", "+
+		strconv.Quote(woof.CSPHash(`console.log("b");`))+"}")
+	assert.Contains(t, out, "Styles: []string{"+strconv.Quote(woof.CSPHash("p{color:red}"))+"}")
+}
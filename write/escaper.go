@@ -70,6 +70,7 @@ var (
 
 	plainBodyExprEscaper  = expressionEscaper{funcName: "EscapeHTMLBody"}
 	scriptBodyExprEscaper = expressionEscaper{funcName: "JSify"}
+	jsonBodyExprEscaper   = expressionEscaper{funcName: "JSONify"}
 	styleBodyExprEscaper  = expressionEscaper{funcName: "FilterCSSValue"}
 
 	plainAttrExprEscaper = expressionEscaper{funcName: "EscapeHTMLAttrVal"}
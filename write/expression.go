@@ -317,7 +317,7 @@ func generateTernaryExpression(
 
 func generateGoExpression(ctx *ctx, gexpr file.GoExpression, esc *expressionEscaper) {
 	ctx.debugItem(gexpr, gexpr.Expression)
-	ctx.generateExpr(gexpr.Expression, esc)
+	ctx.generateExpr(ctx.withInlineLineDirective(gexpr, gexpr.Expression), esc)
 }
 
 func generateStringExpression(ctx *ctx, sexpr file.StringExpression, txtEsc *textEscaper, exprEsc *expressionEscaper) {
@@ -413,7 +413,7 @@ func inlineExpression(ctx *ctx, expr file.Expression) string {
 		}
 	}
 
-	return sb.String()
+	return ctx.withInlineLineDirective(expr, sb.String())
 }
 
 func escapedInlineExpression(ctx *ctx, expr file.Expression, esc expressionEscaper) string {
@@ -433,20 +433,7 @@ func escapedInlineExpression(ctx *ctx, expr file.Expression, esc expressionEscap
 	sb.WriteString(", ")
 	sb.WriteString(ctx.woofQual(esc.funcName))
 	sb.WriteString(", ")
-
-	for _, exprItm := range expr.Expressions {
-		switch exprItm := exprItm.(type) {
-		case file.TernaryExpression:
-			inlineTernaryExpression(ctx, &sb, exprItm)
-		case file.GoExpression:
-			inlineGoExpression(ctx, &sb, exprItm)
-		case file.StringExpression:
-			inlineStringExpression(ctx, &sb, exprItm, nil, "")
-		default:
-			ctx.youShouldntSeeThisError(fmt.Errorf("unknown expression item %T", exprItm))
-		}
-	}
-
+	sb.WriteString(inlineExpression(ctx, expr))
 	sb.WriteByte(')')
 
 	return sb.String()
@@ -482,7 +469,7 @@ func typedInlineExpression(ctx *ctx, expr file.Expression, typeHint string) stri
 		switch exprItm := expr.Expressions[0].(type) {
 		case file.StringExpression:
 			inlineStringExpression(ctx, &sb, exprItm, nil, typeHint)
-			return sb.String()
+			return ctx.withInlineLineDirective(expr, sb.String())
 		}
 	}
 
@@ -515,6 +502,9 @@ func mixinArgExpression(ctx *ctx, param file.MixinParam, arg file.MixinArg) stri
 		sb.WriteByte('[')
 		sb.WriteString(typ)
 		sb.WriteString("](")
+		// attribute type errors of the value to the value, instead of to
+		// the call of Ptr
+		sb.WriteString(ctx.inlineLineDirective(arg.Value))
 	}
 
 	switch woofType(ctx, typ) {
@@ -547,6 +537,38 @@ func mixinArgExpression(ctx *ctx, param file.MixinParam, arg file.MixinArg) stri
 	return sb.String()
 }
 
+// variadicMixinArgExpression returns the slice passed for the variadic param,
+// consisting of the values of all args that set it.
+func variadicMixinArgExpression(ctx *ctx, param file.MixinParam, args []file.MixinArg) string {
+	var sb strings.Builder
+
+	var n int
+	for _, arg := range args {
+		if arg.Name.Ident != param.Name.Ident {
+			continue
+		}
+
+		if arg.Spread {
+			return inlineExpression(ctx, arg.Value)
+		}
+
+		if n == 0 {
+			sb.WriteString("[]" + param.Type.Type + "{")
+		} else {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(mixinArgExpression(ctx, param, arg))
+		n++
+	}
+
+	if n == 0 {
+		return "nil"
+	}
+
+	sb.WriteByte('}')
+	return sb.String()
+}
+
 const woofPath = "github.com/mavolin/corgi/woof"
 
 func woofType(ctx *ctx, typ string) string {
@@ -878,7 +900,7 @@ func inlineCondition(ctx *ctx, condition file.Expression) string {
 		}
 	}
 
-	return sb.String()
+	return ctx.withInlineLineDirective(condition, sb.String())
 }
 
 func inlineConditionChainExpression(ctx *ctx, sb *strings.Builder, cexpr file.ChainExpression) {
@@ -962,6 +984,7 @@ func generateContextExpression(ctx *ctx, expr file.Expression, ctxEsc contextEsc
 			valueChainExpression(ctx, exprItm, func(expr string) {
 				writer(func() {
 					ctx.flushGenerate()
+					ctx.writePos()
 					ctx.writeln(ctx.woofFunc("WriteAnys", ctx.ident(ctxVar), ctx.woofQual(ctxEsc.funcName), expr))
 				})
 			})
@@ -983,8 +1006,9 @@ func generateContextExpression(ctx *ctx, expr file.Expression, ctxEsc contextEsc
 
 	writer(func() {
 		ctx.flushGenerate()
-		ctx.writeln(ctx.woofFunc("WriteAnys", ctx.ident(ctxVar), ctx.woofQual(ctxEsc.funcName),
-			inlineExpression(ctx, expr)))
+		inlineExpr := inlineExpression(ctx, expr)
+		ctx.writePos()
+		ctx.writeln(ctx.woofFunc("WriteAnys", ctx.ident(ctxVar), ctx.woofQual(ctxEsc.funcName), inlineExpr))
 	})
 }
 
@@ -1046,6 +1070,7 @@ func generateContextStringExpression(ctx *ctx, sexpr file.StringExpression, ctxE
 		}
 	}
 
+	ctx.writePos()
 	ctx.writeln(ctx.woofFunc("WriteAnys", ctx.ident(ctxVar), ctx.woofQual(ctxEsc.funcName), b.String()))
 }
 
@@ -23,6 +23,7 @@ func commandFilter(ctx *ctx, filter file.CommandFilter) {
 		if ctx.cli {
 			fmt.Println((&corgierr.Error{
 				Message: "disallowed filter",
+				Code:    corgierr.CodeDisallowedFilter,
 				ErrorAnnotation: anno.Anno(ctx.currentFile(), anno.Annotation{
 					Start:       filter.Position,
 					StartOffset: 1,
@@ -31,8 +32,8 @@ func commandFilter(ctx *ctx, filter file.CommandFilter) {
 				}),
 				Suggestions: []corgierr.Suggestion{
 					{
-						Suggestion: "allow this filter using the `-allow-filter` flag\n" +
-							"or edit the list of allowed filters using `-edit-allowed-filters`",
+						Suggestion: "trust this filter using the `-trust-filter` flag,\n" +
+							"or add it to your list of trusted filters",
 					},
 				},
 			}).Pretty(ctx.corgierrPretty))
@@ -86,6 +87,7 @@ func commandFilter(ctx *ctx, filter file.CommandFilter) {
 		if ctx.cli {
 			fmt.Println((&corgierr.Error{
 				Message: "failed to run filter",
+				Code:    corgierr.CodeFilterFailed,
 				ErrorAnnotation: anno.Anno(ctx.currentFile(), anno.Annotation{
 					Start:      filter.Position,
 					ToEOL:      true,
@@ -129,7 +131,7 @@ func rawFilter(ctx *ctx, filter file.RawFilter) {
 	ctx.closeStartTag()
 	switch filter.Type {
 	case file.RawHTML:
-		minified, err := mini.String("text/html", sb.String())
+		minified, err := ctx.minify("text/html", sb.String())
 		if err != nil {
 			panic(fmt.Errorf("%s:%d:%d: failed to minify in HTML raw filter: %w", ctx.currentFile().Name, filter.Line,
 				filter.Col, err))
@@ -137,7 +139,7 @@ func rawFilter(ctx *ctx, filter file.RawFilter) {
 
 		ctx.generate(minified, nil)
 	case file.RawSVG:
-		minified, err := mini.String("image/svg+xml", sb.String())
+		minified, err := ctx.minify("image/svg+xml", sb.String())
 		if err != nil {
 			panic(fmt.Errorf("%s:%d:%d: failed to minify in SVG raw filter: %w", ctx.currentFile().Name, filter.Line,
 				filter.Col, err))
@@ -145,7 +147,7 @@ func rawFilter(ctx *ctx, filter file.RawFilter) {
 
 		ctx.generate(minified, nil)
 	case file.RawJS:
-		minified, err := mini.String("application/javascript", sb.String())
+		minified, err := ctx.minify("application/javascript", sb.String())
 		if err != nil {
 			panic(fmt.Errorf("%s:%d:%d: failed to minify in JS raw filter: %w", ctx.currentFile().Name, filter.Line,
 				filter.Col, err))
@@ -153,7 +155,7 @@ func rawFilter(ctx *ctx, filter file.RawFilter) {
 
 		ctx.generate(minified, nil)
 	case file.RawCSS:
-		minified, err := mini.String("text/css", sb.String())
+		minified, err := ctx.minify("text/css", sb.String())
 		if err != nil {
 			panic(fmt.Errorf("%s:%d:%d: failed to minify in CSS raw filter: %w", ctx.currentFile().Name, filter.Line,
 				filter.Col, err))
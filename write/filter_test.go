@@ -0,0 +1,48 @@
+package write_test
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi"
+	"github.com/mavolin/corgi/write"
+)
+
+func TestCommandFilter_Disallowed(t *testing.T) {
+	l, err := corgi.NewFSLoader(corgi.FSLoadOptions{
+		Modules: map[string]fs.FS{
+			"example.com/test": fstest.MapFS{
+				"page.corgi": &fstest.MapFile{Data: []byte("func Page()\n\np\n  :rev\n    abc\n")},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	f, err := l.LoadMain("example.com/test/page.corgi")
+	require.NoError(t, err)
+
+	// the CLI error is printed to stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	stdout := os.Stdout
+	os.Stdout = w
+	t.Cleanup(func() { os.Stdout = stdout })
+
+	err = write.New(write.Options{CLI: true}).GenerateFile(io.Discard, "test", f)
+	require.NoError(t, w.Close())
+	os.Stdout = stdout
+
+	assert.ErrorContains(t, err, "filter `rev` not allowed by settings")
+
+	printed, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Contains(t, string(printed), "`-trust-filter`")
+	assert.NotContains(t, string(printed), "`-allow-filter`")
+}
@@ -0,0 +1,641 @@
+package write
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/types"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mavolin/corgi/file"
+	"github.com/mavolin/corgi/file/fileutil"
+)
+
+// Go doesn't allow func literals to have type params, so generic mixins are
+// instantiated once for every combination of type args they are called with.
+//
+// Before generating the func, collectGenericInstances determines those
+// combinations, so that all instantiations can be declared where the mixin
+// is declared.
+// Each instantiation is stored in its own variable, whose name is the name
+// the mixin would have if it were not generic, suffixed with a hash of the
+// type args.
+// Inside the instantiation, the type params are declared as aliases of their
+// type args.
+//
+// Since aliases don't have constraints, the type args are checked against
+// the constraints of the type params separately, by writeConstraintChecks.
+
+// ============================================================================
+// Names
+// ======================================================================================
+
+// genericMixinName returns the name of the variable holding the
+// instantiation of the generic mixin stored in base with the passed type
+// args.
+//
+// If typeArgs is empty, genericMixinName returns base.
+func genericMixinName(base string, typeArgs []string) string {
+	if len(typeArgs) == 0 {
+		return base
+	}
+
+	h := fnv.New32a()
+	for _, typeArg := range typeArgs {
+		h.Write([]byte(typeArg))
+		h.Write([]byte{0})
+	}
+
+	return base + "_" + strconv.FormatUint(uint64(h.Sum32()), 36)
+}
+
+func libraryMixinKey(module, pathInModule, name string) string {
+	return module + "/" + pathInModule + "." + name
+}
+
+// genericMixinKey returns the key under which the instantiations of the
+// linked mixin are stored in ctx.genericInstances.
+func genericMixinKey(lm file.LinkedMixin) string {
+	if lib := lm.File.Library; lib != nil {
+		return libraryMixinKey(lib.Module, lib.PathInModule, lm.Mixin.Name.Ident)
+	}
+
+	return scopeMixinKey(lm.Mixin)
+}
+
+// scopeMixinKey returns the key of a mixin that is not part of a library.
+//
+// Mixins are copied around by value, but all copies share the same body.
+func scopeMixinKey(m *file.Mixin) string {
+	return fmt.Sprintf("%s@%d:%d/%p", m.Name.Ident, m.Line, m.Col, m.Body)
+}
+
+// mixinInstances returns the type args of the instantiations of the mixin m
+// with the passed key.
+//
+// If m is not generic, it returns a single nil instantiation.
+func mixinInstances(ctx *ctx, key string, m *file.Mixin) [][]string {
+	if len(m.TypeParams) == 0 {
+		return [][]string{nil}
+	}
+
+	return ctx.genericInstances[key]
+}
+
+// ============================================================================
+// Types
+// ======================================================================================
+
+// substituteType replaces the type params in typ with the type args they map
+// to in typeArgs.
+//
+// Additionally, it normalizes the formatting of typ, so that equal types
+// yield equal strings.
+func substituteType(typ string, typeArgs map[string]string) string {
+	expr, err := parser.ParseExpr(typ)
+	if err != nil {
+		return typ
+	}
+
+	if len(typeArgs) > 0 {
+		substituteTypeParams(expr, typeArgs)
+	}
+
+	return types.ExprString(expr)
+}
+
+func substituteTypeParams(node ast.Node, typeArgs map[string]string) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			// qualified identifiers never refer to type params
+			return false
+		case *ast.Field:
+			// skip the names of params, results, and struct fields
+			if n.Type != nil {
+				substituteTypeParams(n.Type, typeArgs)
+			}
+			return false
+		case *ast.Ident:
+			if typeArg, ok := typeArgs[n.Name]; ok {
+				n.Name = typeArg
+			}
+		}
+
+		return true
+	})
+}
+
+// typeArgMap maps the type params of m to the passed type args.
+func typeArgMap(m *file.Mixin, typeArgs []string) map[string]string {
+	tam := make(map[string]string, len(m.TypeParams))
+	for i, tp := range m.TypeParams {
+		if i < len(typeArgs) {
+			tam[tp.Name.Ident] = typeArgs[i]
+		}
+	}
+
+	return tam
+}
+
+// mixinCallTypeArgs returns the type args of mc, either explicit or
+// inferred, with the type params of the calling mixin replaced using
+// typeArgs.
+func mixinCallTypeArgs(mc file.MixinCall, typeArgs map[string]string) []string {
+	if len(mc.TypeArgs) > 0 {
+		callTypeArgs := make([]string, len(mc.TypeArgs))
+		for i, typeArg := range mc.TypeArgs {
+			callTypeArgs[i] = substituteType(typeArg.Type, typeArgs)
+		}
+
+		return callTypeArgs
+	}
+
+	callTypeArgs := make([]string, len(mc.InferredTypeArgs))
+	for i, typeArg := range mc.InferredTypeArgs {
+		callTypeArgs[i] = substituteType(typeArg, typeArgs)
+	}
+
+	return callTypeArgs
+}
+
+// instantiateParams returns the params of m with the type params in their
+// types replaced using typeArgs.
+func instantiateParams(m *file.Mixin, typeArgs map[string]string) []file.MixinParam {
+	if len(typeArgs) == 0 {
+		return m.Params
+	}
+
+	params := make([]file.MixinParam, len(m.Params))
+	copy(params, m.Params)
+
+	for i, param := range params {
+		if param.Type != nil {
+			typ := *param.Type
+			typ.Type = substituteType(typ.Type, typeArgs)
+			params[i].Type = &typ
+		}
+	}
+
+	return params
+}
+
+// ============================================================================
+// Writing
+// ======================================================================================
+
+// mixinFuncName returns the name of the func that needs to be called for mc.
+func mixinFuncName(ctx *ctx, mc file.MixinCall) string {
+	funcName := ctx.mixinFuncNames.mixin(ctx, mc)
+	if len(mc.Mixin.Mixin.TypeParams) == 0 {
+		return funcName
+	}
+
+	typeArgs := mixinCallTypeArgs(mc, ctx.typeArgs)
+	if lib := mc.Mixin.File.Library; lib != nil && ctx.instantiations != nil {
+		addInstantiation(ctx.instantiations, file.MixinInstantiation{
+			Module:       lib.Module,
+			PathInModule: lib.PathInModule,
+			Name:         mc.Name.Ident,
+			TypeArgs:     typeArgs,
+		})
+	}
+
+	return genericMixinName(funcName, typeArgs)
+}
+
+func addInstantiation(insts *[]file.MixinInstantiation, inst file.MixinInstantiation) {
+	for _, cmp := range *insts {
+		if cmp.Module == inst.Module && cmp.PathInModule == inst.PathInModule && cmp.Name == inst.Name &&
+			strings.Join(cmp.TypeArgs, "\x00") == strings.Join(inst.TypeArgs, "\x00") {
+			return
+		}
+	}
+
+	*insts = append(*insts, inst)
+}
+
+// writeMixinInstance writes the func literal of the instantiation of the
+// mixin m with the passed type args.
+func writeMixinInstance(ctx *ctx, m *file.Mixin, typeArgs []string) {
+	oldTypeArgs := ctx.typeArgs
+	ctx.typeArgs = typeArgMap(m, typeArgs)
+	writeMixinFunc(ctx, m)
+	ctx.typeArgs = oldTypeArgs
+}
+
+// writeTypeAliases declares the type params of m as aliases of the type args
+// of the instantiation currently being generated.
+func writeTypeAliases(ctx *ctx, m *file.Mixin) {
+	for _, tp := range m.TypeParams {
+		ctx.writeln("type " + tp.Name.Ident + " = " + ctx.typeArgs[tp.Name.Ident])
+	}
+}
+
+// writeConstraintChecks writes a generic func for every generic mixin that
+// is instantiated, using the type params of the mixin, and instantiates it
+// with the type args of each instantiation of the mixin.
+//
+// Since the instantiations declare the type params of the mixin as aliases,
+// this is what makes the compiler check the type args against the
+// constraints.
+// Go doesn't allow generic funcs inside funcs, so they are declared at
+// package level, and named after the main func, to not collide with the
+// funcs of other generated files.
+func writeConstraintChecks(ctx *ctx) {
+	if len(ctx.genericInstances) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(ctx.genericInstances))
+	for key := range ctx.genericInstances {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		m := ctx.genericMixins[key]
+		names[i] = ctx.ident(ctx.mainFile().Func.Name.Ident + "_mixinConstraints" + strconv.Itoa(i))
+
+		ctx.writeln("")
+		ctx.write("func " + names[i] + "[")
+		for j, tp := range m.TypeParams {
+			if j > 0 {
+				ctx.write(", ")
+			}
+			ctx.write(tp.Name.Ident + " " + tp.Constraint.Type)
+		}
+		ctx.writeln("]() {}")
+	}
+
+	// Use a single var decl, and put every type arg on its own line, so that
+	// formatting neither adds nor removes lines, and the inline line
+	// directives directly precede the type args they belong to.
+	// The directives attributing the following lines back to the generated
+	// file are placed in front of the specs, as gofmt would separate a
+	// directive in front of the closing parenthesis by a blank line.
+	ctx.writeln("")
+	ctx.writeln("var (")
+	ctx.inStatement = true
+	for i, key := range keys {
+		for _, typeArgs := range ctx.genericInstances[key] {
+			ctx.genLineDirective()
+
+			pos := ctx.genericInstancePositions[genericInstanceID(key, typeArgs)]
+			ctx.lineFile = pos.file
+
+			ctx.writeln("_ = " + names[i] + "[")
+			for j, typeArg := range typeArgs {
+				if pos.file != nil && j < len(pos.typeArgs) {
+					ctx.write(ctx.inlineLineDirective(pos.typeArgs[j]))
+				}
+				if j < len(typeArgs)-1 {
+					ctx.writeln(typeArg + ",")
+				} else {
+					ctx.writeln(typeArg + "]")
+				}
+			}
+		}
+	}
+	ctx.lineFile = nil
+	ctx.writeln(")")
+	ctx.writeln("")
+	ctx.inStatement = false
+	ctx.genLineDirective()
+}
+
+// writePrecompiledMixinInstance writes the instantiation of the precompiled
+// generic mixin pm of lib with the passed type args.
+//
+// Since the func literal of pm uses the type params of pm, it is wrapped in
+// a func that declares them.
+func writePrecompiledMixinInstance(ctx *ctx, lib *file.Library, pm file.PrecompiledMixin, typeArgs []string) {
+	tam := typeArgMap(&pm.Mixin, typeArgs)
+
+	ctx.write("func(")
+	for i, param := range instantiateParams(&pm.Mixin, tam) {
+		ctx.write(fmt.Sprint("p", i, " "))
+		if param.Default != nil {
+			ctx.write("*")
+		}
+
+		ctx.write(mixinParamType(param) + ", ")
+	}
+	for i, b := range pm.Mixin.Blocks {
+		ctx.write(fmt.Sprint("b", i, " ", blockFuncType(b, tam), ", "))
+	}
+	if pm.Mixin.HasAndPlaceholders {
+		ctx.write("ab func()")
+	}
+	ctx.writeln(") {")
+
+	oldTypeArgs := ctx.typeArgs
+	ctx.typeArgs = tam
+	writeTypeAliases(ctx, &pm.Mixin)
+	ctx.typeArgs = oldTypeArgs
+
+	writeInstantiationBindings(ctx, lib, pm, tam, make(map[string]struct{}))
+
+	ctx.write("(")
+	ctx.writeBytes(bytes.TrimRight(pm.Mixin.Precompiled, "\n"))
+	ctx.write(")(")
+	for i := range pm.Mixin.Params {
+		ctx.write(fmt.Sprint("p", i, ", "))
+	}
+	for i := range pm.Mixin.Blocks {
+		ctx.write(fmt.Sprint("b", i, ", "))
+	}
+	if pm.Mixin.HasAndPlaceholders {
+		ctx.write("ab")
+	}
+	ctx.writeln(")")
+	ctx.writeln("}")
+}
+
+// writeInstantiationBindings declares the variables that the precompiled
+// mixin pm of lib uses to call instantiations of generic library mixins, by
+// assigning them forwarders to the instantiations they refer to.
+//
+// typeArgs are the type args of pm, if it is generic.
+// Variables in declared are not declared again, and newly declared variables
+// are added to it.
+func writeInstantiationBindings(
+	ctx *ctx, lib *file.Library, pm file.PrecompiledMixin, typeArgs map[string]string, declared map[string]struct{},
+) {
+	for _, inst := range pm.Instantiations {
+		var precompVar, realVar string
+		var m *file.Mixin
+		if inst.Module == lib.Module && inst.PathInModule == lib.PathInModule {
+			for i, b := range lib.Mixins {
+				if b.Mixin.Name.Ident == inst.Name {
+					precompVar, realVar = b.Var, b.Var
+					m = &lib.Mixins[i].Mixin
+					break
+				}
+			}
+		} else {
+			for _, libDep := range lib.Dependencies {
+				if libDep.Module != inst.Module || libDep.PathInModule != inst.PathInModule {
+					continue
+				}
+
+				for _, mDep := range libDep.Mixins {
+					if mDep.Name == inst.Name {
+						precompVar, m = mDep.Var, mDep.Mixin
+						break
+					}
+				}
+			}
+
+			realVar = ctx.mixinFuncNames.mixinByName(ctx, inst.Module, inst.PathInModule, inst.Name)
+		}
+
+		instTypeArgs := make([]string, len(inst.TypeArgs))
+		for i, typeArg := range inst.TypeArgs {
+			instTypeArgs[i] = substituteType(typeArg, typeArgs)
+		}
+
+		from := genericMixinName(precompVar, inst.TypeArgs)
+		to := genericMixinName(realVar, instTypeArgs)
+		if from == to {
+			continue
+		} else if _, ok := declared[from]; ok {
+			continue
+		}
+
+		declared[from] = struct{}{}
+		ctx.write(from + " := ")
+		writeMixinForwarder(ctx, m, typeArgMap(m, instTypeArgs), to)
+	}
+}
+
+// ============================================================================
+// Collecting Instantiations
+// ======================================================================================
+
+type genericInstanceCollector struct {
+	instances map[string][][]string
+	mixins    map[string]*file.Mixin
+	positions map[string]instancePos
+	seen      map[string]struct{}
+
+	// file is the file the items currently being walked originate from.
+	file *file.File
+}
+
+// instancePos is the position of the first call of an instantiation of a
+// generic mixin.
+type instancePos struct {
+	file *file.File
+	// typeArgs are the positions of the type args of the call, or, if they
+	// were inferred, the position of the call for each type arg.
+	typeArgs []file.Poser
+}
+
+// genericInstanceID returns the id of the instantiation of the generic mixin
+// with the passed key and type args.
+func genericInstanceID(key string, typeArgs []string) string {
+	return key + "\x00" + strings.Join(typeArgs, "\x00")
+}
+
+// collectGenericInstances collects the type args of all instantiations of
+// generic mixins used by the main file, and stores them in
+// ctx.genericInstances.
+func collectGenericInstances(ctx *ctx) {
+	c := genericInstanceCollector{
+		instances: make(map[string][][]string),
+		mixins:    make(map[string]*file.Mixin),
+		positions: make(map[string]instancePos),
+		seen:      make(map[string]struct{}),
+	}
+
+	for _, f := range ctx._stack {
+		c.file = f
+		c.scope(f.Scope, nil)
+	}
+
+	// generic library mixins are only instantiated through calls
+	for _, ulib := range fileutil.ListUsedMixins(ctx.mainFile()).External {
+		for _, um := range ulib.Mixins {
+			if len(um.Mixin.TypeParams) > 0 {
+				continue
+			}
+
+			if !ulib.Library.Precompiled {
+				c.file = libraryMixinFile(ulib.Library, um.Mixin)
+				c.scope(um.Mixin.Body, nil)
+				continue
+			}
+
+			for _, pm := range ulib.Library.Mixins {
+				if pm.Mixin.Name.Ident == um.Mixin.Name.Ident {
+					c.precompiled(ulib.Library, pm, nil)
+					break
+				}
+			}
+		}
+	}
+
+	ctx.genericInstances = c.instances
+	ctx.genericMixins = c.mixins
+	ctx.genericInstancePositions = c.positions
+}
+
+func (c *genericInstanceCollector) scope(s file.Scope, typeArgs map[string]string) {
+	fileutil.Walk(s, func(parents []fileutil.WalkContext, wctx fileutil.WalkContext) (dive bool, err error) { //nolint:errcheck
+		switch itm := (*wctx.Item).(type) {
+		case file.Mixin:
+			// generic mixins are collected once for every instantiation
+			return len(itm.TypeParams) == 0, nil
+		case file.Include:
+			if cincl, ok := itm.Include.(file.CorgiInclude); ok {
+				oldFile := c.file
+				c.file = cincl.File
+				c.scope(cincl.File.Scope, typeArgs)
+				c.file = oldFile
+			}
+			return false, nil
+		case file.MixinCall:
+			c.call(itm, typeArgs)
+		case file.InlineText:
+			c.textLines(typeArgs, itm.Text)
+		case file.ArrowBlock:
+			c.textLines(typeArgs, itm.Lines...)
+		case file.Element:
+			c.attributeCollections(itm.Attributes, typeArgs)
+		case file.DivShorthand:
+			c.attributeCollections(itm.Attributes, typeArgs)
+		case file.And:
+			c.attributeCollections(itm.Attributes, typeArgs)
+		}
+
+		return true, nil
+	})
+}
+
+func (c *genericInstanceCollector) textLines(typeArgs map[string]string, lns ...file.TextLine) {
+	for _, ln := range lns {
+		for _, itm := range ln {
+			switch itm := itm.(type) {
+			case file.MixinCallInterpolation:
+				c.call(itm.MixinCall, typeArgs)
+			case file.ElementInterpolation:
+				c.attributeCollections(itm.Element.Attributes, typeArgs)
+			}
+		}
+	}
+}
+
+func (c *genericInstanceCollector) attributeCollections(acolls []file.AttributeCollection, typeArgs map[string]string) {
+	for _, acoll := range acolls {
+		alist, ok := acoll.(file.AttributeList)
+		if !ok {
+			continue
+		}
+
+		for _, attr := range alist.Attributes {
+			if mcAttr, ok := attr.(file.MixinCallAttribute); ok {
+				c.call(mcAttr.MixinCall, typeArgs)
+			}
+		}
+	}
+}
+
+func (c *genericInstanceCollector) call(mc file.MixinCall, typeArgs map[string]string) {
+	if mc.Mixin == nil || len(mc.Mixin.Mixin.TypeParams) == 0 {
+		return
+	}
+
+	pos := instancePos{file: c.file, typeArgs: make([]file.Poser, len(mc.Mixin.Mixin.TypeParams))}
+	for i := range pos.typeArgs {
+		if i < len(mc.TypeArgs) {
+			pos.typeArgs[i] = mc.TypeArgs[i]
+		} else {
+			pos.typeArgs[i] = mc.Name
+		}
+	}
+
+	c.add(mc.Mixin.File.Library, mc.Mixin.Mixin, genericMixinKey(*mc.Mixin),
+		mixinCallTypeArgs(mc, typeArgs), &pos)
+}
+
+// add adds the instantiation of the generic mixin m with the passed key and
+// type args, and collects the instantiations it in turn requires.
+//
+// lib is the library m belongs to, if any.
+// pos is the position of the call, or nil, if it is unknown.
+func (c *genericInstanceCollector) add(
+	lib *file.Library, m *file.Mixin, key string, typeArgs []string, pos *instancePos,
+) {
+	id := genericInstanceID(key, typeArgs)
+	if _, ok := c.seen[id]; ok {
+		return
+	}
+	c.seen[id] = struct{}{}
+
+	c.instances[key] = append(c.instances[key], typeArgs)
+	c.mixins[key] = m
+	if pos != nil {
+		c.positions[id] = *pos
+	}
+
+	if lib == nil || !lib.Precompiled {
+		oldFile := c.file
+		if lib != nil {
+			c.file = libraryMixinFile(lib, m)
+		}
+
+		c.scope(m.Body, typeArgMap(m, typeArgs))
+		c.file = oldFile
+		return
+	}
+
+	for _, pm := range lib.Mixins {
+		if pm.Mixin.Name.Ident == m.Name.Ident {
+			c.precompiled(lib, pm, typeArgMap(m, typeArgs))
+			return
+		}
+	}
+}
+
+// precompiled collects the instantiations required by the precompiled mixin
+// pm of lib.
+func (c *genericInstanceCollector) precompiled(lib *file.Library, pm file.PrecompiledMixin, typeArgs map[string]string) {
+	for _, inst := range pm.Instantiations {
+		instTypeArgs := make([]string, len(inst.TypeArgs))
+		for i, typeArg := range inst.TypeArgs {
+			instTypeArgs[i] = substituteType(typeArg, typeArgs)
+		}
+
+		key := libraryMixinKey(inst.Module, inst.PathInModule, inst.Name)
+
+		if inst.Module == lib.Module && inst.PathInModule == lib.PathInModule {
+			for i := range lib.Mixins {
+				if lib.Mixins[i].Mixin.Name.Ident == inst.Name {
+					c.add(lib, &lib.Mixins[i].Mixin, key, instTypeArgs, nil)
+					break
+				}
+			}
+
+			continue
+		}
+
+		for _, libDep := range lib.Dependencies {
+			if libDep.Module != inst.Module || libDep.PathInModule != inst.PathInModule {
+				continue
+			}
+
+			for _, mDep := range libDep.Mixins {
+				if mDep.Name == inst.Name {
+					c.add(libDep.Library, mDep.Mixin, key, instTypeArgs, nil)
+					break
+				}
+			}
+		}
+	}
+}
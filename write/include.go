@@ -19,19 +19,19 @@ func include(ctx *ctx, incl file.Include) {
 		switch path.Ext(fileutil.Unquote(incl.Path)) {
 		case ".js":
 			var err error
-			contents, err = mini.String("application/javascript", inclF.Contents)
+			contents, err = ctx.minify("application/javascript", inclF.Contents)
 			if err != nil {
 				contents = inclF.Contents
 			}
 		case ".css":
 			var err error
-			contents, err = mini.String("text/css", inclF.Contents)
+			contents, err = ctx.minify("text/css", inclF.Contents)
 			if err != nil {
 				contents = inclF.Contents
 			}
 		case ".html":
 			var err error
-			contents, err = mini.String("text/html", inclF.Contents)
+			contents, err = ctx.minify("text/html", inclF.Contents)
 			if err != nil {
 				contents = inclF.Contents
 			}
@@ -0,0 +1,182 @@
+package write
+
+import (
+	"go/parser"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mavolin/corgi/file"
+)
+
+// srcFile returns the file the items currently being generated originate
+// from.
+func (ctx *ctx) srcFile() *file.File {
+	if ctx.lineFile != nil {
+		return ctx.lineFile
+	}
+
+	return ctx.currentFile()
+}
+
+func (ctx *ctx) lineDirectiveFileName() string {
+	f := ctx.srcFile()
+	if ctx.lineFileName != nil {
+		return ctx.lineFileName(f)
+	}
+
+	if f.AbsolutePath != "" {
+		return f.AbsolutePath
+	}
+
+	return f.Name
+}
+
+// lineDirective attributes the following lines to the line of itm in the
+// current source file, using a //line directive.
+//
+// The column is omitted, as the indentation of the following line will
+// change, if the generated file is formatted.
+//
+// The directive is written right before the next line, and only if that
+// line isn't already attributed to the line of itm.
+func (ctx *ctx) lineDirective(itm file.Poser) {
+	if !ctx.lineDirectives || itm.Pos().Line <= 0 {
+		return
+	}
+
+	ctx.hasNextLineDirective = true
+	ctx.nextFile, ctx.nextLine = ctx.lineDirectiveFileName(), itm.Pos().Line
+}
+
+// genLineDirective attributes the following lines back to the generated
+// file, so that the code corgi generates after an item, e.g. to close an
+// element, isn't attributed to the lines following the item in its corgi
+// file.
+//
+// Like lineDirective, the directive is written right before the next line,
+// and only if that line is attributed to a corgi file.
+//
+// If no generated file name was set, genLineDirective is a no-op.
+func (ctx *ctx) genLineDirective() {
+	if !ctx.lineDirectives || ctx.genFileName == "" {
+		return
+	}
+
+	ctx.hasNextLineDirective = true
+	ctx.nextFile, ctx.nextLine = "", 0
+}
+
+// writeNextLineDirective writes the directive requested last using
+// lineDirective or genLineDirective, if the next write starts a new line.
+func (ctx *ctx) writeNextLineDirective() {
+	if !ctx.hasNextLineDirective || !ctx.atLineStart {
+		return
+	}
+	ctx.hasNextLineDirective = false
+
+	if ctx.mappedFile == ctx.nextFile && ctx.mappedLine == ctx.nextLine {
+		return
+	}
+
+	var directive string
+	if ctx.nextFile == "" {
+		// +1 for the directive itself, and +1 since lines are 1-based
+		directive = "//line " + ctx.genFileName + ":" + strconv.Itoa(ctx.outLine+2) + "\n"
+	} else {
+		directive = "//line " + ctx.nextFile + ":" + strconv.Itoa(ctx.nextLine) + "\n"
+	}
+
+	if _, err := io.WriteString(ctx.out, directive); err != nil {
+		panic(err)
+	}
+
+	ctx.outLine++
+	ctx.mappedFile, ctx.mappedLine = ctx.nextFile, ctx.nextLine
+}
+
+// unknownMappedFile is the mappedFile used after an inline line directive,
+// whose exact position isn't tracked.
+const unknownMappedFile = "\x00"
+
+// wrote updates the line information of ctx after s was written.
+func (ctx *ctx) wrote(s string) {
+	n := strings.Count(s, "\n")
+	ctx.outLine += n
+	if ctx.mappedFile != "" {
+		ctx.mappedLine += n
+	}
+
+	if ctx.lineDirectives && strings.Contains(s, "/*line ") {
+		ctx.mappedFile, ctx.mappedLine = unknownMappedFile, 0
+		// the lines of code items may belong to the same statement, e.g. to
+		// a multi-line raw string, so code calls genLineDirective itself,
+		// once it is safe to do so
+		ctx.inlineDirectiveLine = !ctx.inStatement
+	}
+
+	ctx.atLineStart = s[len(s)-1] == '\n'
+	if ctx.atLineStart && ctx.inlineDirectiveLine {
+		ctx.inlineDirectiveLine = false
+		ctx.genLineDirective()
+	}
+}
+
+// inlineLineDirective returns a /*line*/ directive followed by a space, that
+// attributes the code placed directly after it to the position of itm in the
+// current source file.
+//
+// The space is added by gofmt anyway, so we add it ourselves and account for
+// it in the column, to keep the column correct regardless of whether the
+// generated file is formatted.
+//
+// Once written, the lines following the line the directive is placed on are
+// attributed back to the generated file.
+//
+// If line directives are disabled, inlineLineDirective returns "".
+func (ctx *ctx) inlineLineDirective(itm file.Poser) string {
+	pos := itm.Pos()
+	if !ctx.lineDirectives || pos.Line <= 0 {
+		return ""
+	}
+
+	s := "/*line " + ctx.lineDirectiveFileName() + ":" + strconv.Itoa(pos.Line)
+	if pos.Col > 1 {
+		s += ":" + strconv.Itoa(pos.Col-1)
+	}
+
+	return s + "*/ "
+}
+
+// codeLineDirective returns the inline line directive for the passed line of
+// Go code, if it starts a statement.
+//
+// Lines that don't start a statement may continue a multi-line raw string,
+// and lines starting with a closing brace don't get a directive either, as
+// gofmt would move the brace to its own line.
+func (ctx *ctx) codeLineDirective(ln file.CodeLine, stmtStart bool) string {
+	if !stmtStart || strings.HasPrefix(strings.TrimSpace(ln.Code), "}") {
+		return ""
+	}
+
+	return ctx.inlineLineDirective(ln)
+}
+
+// withInlineLineDirective prefixes the passed Go code, which originates from
+// itm, with an inline line directive.
+//
+// If code is an expression, it is additionally wrapped in parentheses, since
+// gofmt moves comments directly following a comma in front of the comma,
+// which would shift the column.
+func (ctx *ctx) withInlineLineDirective(itm file.Poser, code string) string {
+	directive := ctx.inlineLineDirective(itm)
+	if directive == "" {
+		return code
+	}
+
+	if _, err := parser.ParseExpr(code); err != nil {
+		return directive + code
+	}
+
+	return "(" + directive + code + ")"
+}
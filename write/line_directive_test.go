@@ -0,0 +1,141 @@
+package write_test
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi"
+	"github.com/mavolin/corgi/file"
+	"github.com/mavolin/corgi/write"
+)
+
+const lineDirectivesCorgi = `func Page(items []string)
+
+mixin item(v string)
+  li #{v}
+
+ul
+  for _, it := range items
+    +item(v=it)
+    if it != ""
+      - x := it
+      p #{x}
+`
+
+func TestLineDirectives(t *testing.T) {
+	l, err := corgi.NewFSLoader(corgi.FSLoadOptions{
+		Modules: map[string]fs.FS{
+			"example.com/test": fstest.MapFS{
+				"page.corgi": &fstest.MapFile{Data: []byte(lineDirectivesCorgi)},
+			},
+		},
+		NoPrecompile: true,
+	})
+	require.NoError(t, err)
+
+	f, err := l.LoadMain("example.com/test/page.corgi")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = write.New(write.Options{
+		LineDirectives:        true,
+		LineDirectiveFileName: func(f *file.File) string { return f.Name },
+		GeneratedFileName:     "page.corgi.go",
+	}).GenerateFile(&buf, "test", f)
+	require.NoError(t, err)
+
+	src := buf.String()
+
+	t.Run("no consecutive directives", func(t *testing.T) {
+		lines := strings.Split(src, "\n")
+		for i := 1; i < len(lines); i++ {
+			if strings.HasPrefix(lines[i], "//line ") && strings.HasPrefix(lines[i-1], "//line ") {
+				t.Errorf("consecutive line directives in lines %d and %d:\n%s\n%s", i, i+1, lines[i-1], lines[i])
+			}
+		}
+	})
+
+	fset := token.NewFileSet()
+	gof, err := parser.ParseFile(fset, "page.corgi.go", src, parser.ParseComments)
+	require.NoError(t, err, src)
+
+	t.Run("scaffolding", func(t *testing.T) {
+		var n int
+		ast.Inspect(gof, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || (sel.Sel.Name != "Closed" && sel.Sel.Name != "Done" && sel.Sel.Name != "PopMixin") {
+				return true
+			}
+
+			n++
+			pos := fset.Position(call.Pos())
+			physical := fset.PositionFor(call.Pos(), false)
+			assert.Equal(t, "page.corgi.go", pos.Filename, "%s in line %d", sel.Sel.Name, physical.Line)
+			assert.Equal(t, physical.Line, pos.Line, "%s in line %d", sel.Sel.Name, physical.Line)
+			return true
+		})
+		assert.NotZero(t, n, "no scaffolding found")
+	})
+
+	t.Run("items", func(t *testing.T) {
+		var found bool
+		ast.Inspect(gof, func(node ast.Node) bool {
+			stmt, ok := node.(*ast.RangeStmt)
+			if !ok {
+				return true
+			}
+
+			found = true
+			pos := fset.Position(stmt.Pos())
+			assert.Equal(t, "page.corgi", pos.Filename)
+			assert.Equal(t, 7, pos.Line)
+			return true
+		})
+		assert.True(t, found, "no for loop found")
+	})
+}
+
+const rawStringCorgi = "- var global = `foo\n  bar`\n\n" +
+	"func Page()\n\n" +
+	"- x := `foo\n  bar`\np #{x}#{global}\n"
+
+// TestLineDirectives_RawString tests that no line directives are placed inside
+// multi-line raw strings, as that would change their value.
+func TestLineDirectives_RawString(t *testing.T) {
+	src := generate(t, rawStringCorgi, write.Options{
+		LineDirectives:        true,
+		LineDirectiveFileName: func(f *file.File) string { return f.Name },
+		GeneratedFileName:     "page.corgi.go",
+	})
+
+	fset := token.NewFileSet()
+	gof, err := parser.ParseFile(fset, "page.corgi.go", src, parser.ParseComments)
+	require.NoError(t, err, src)
+
+	var n int
+	ast.Inspect(gof, func(node ast.Node) bool {
+		lit, ok := node.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING || !strings.HasPrefix(lit.Value, "`") {
+			return true
+		}
+
+		n++
+		assert.Equal(t, "`foo\nbar`", lit.Value)
+		return true
+	})
+	assert.Equal(t, 2, n, src)
+}
@@ -1,6 +1,9 @@
 package write
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
 	"regexp"
 
 	"github.com/tdewolff/minify"
@@ -17,4 +20,35 @@ func init() {
 	mini.AddFunc("text/html", html.Minify)
 	mini.AddFunc("image/svg+xml", svg.Minify)
 	mini.AddFuncRegexp(regexp.MustCompile("^(application|text)/(x-)?(java|ecma)script$"), js.Minify)
+	mini.AddFunc("application/json", minifyJSON)
+}
+
+// minifyJSON minifies JSON using encoding/json instead of minify's json
+// package, as the latter doesn't validate its input, but silently drops
+// everything after the first invalid token.
+func minifyJSON(_ *minify.M, w io.Writer, r io.Reader, _ map[string]string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return err
+	}
+
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// minify minifies s using the minifier registered for the passed mediatype.
+//
+// In pretty mode, s is still validated by minifying it, but returned as is.
+func (ctx *ctx) minify(mediatype, s string) (string, error) {
+	minified, err := mini.String(mediatype, s)
+	if err != nil || !ctx.pretty {
+		return minified, err
+	}
+
+	return s, nil
 }
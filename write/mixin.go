@@ -3,6 +3,7 @@ package write
 import (
 	"fmt"
 	"path"
+	"strconv"
 	"strings"
 
 	"github.com/mavolin/corgi/file"
@@ -33,13 +34,16 @@ func writeMixinVars(ctx *ctx, ums fileutil.UsedMixins) {
 			varName := ctx.nextMixinIdent()
 			moduleMixins[um.Mixin.Name.Ident] = varName
 
-			ctx.write("var " + varName + " ")
-			writeMixinSignature(ctx, um.Mixin)
-			if ctx.debugEnabled {
-				ctx.writeln(" // " + path.Join(ulib.Library.Module,
-					ulib.Library.PathInModule) + "." + um.Mixin.Name.Ident)
-			} else {
-				ctx.writeln("")
+			key := libraryMixinKey(ulib.Library.Module, ulib.Library.PathInModule, um.Mixin.Name.Ident)
+			for _, typeArgs := range mixinInstances(ctx, key, um.Mixin) {
+				ctx.write("var " + genericMixinName(varName, typeArgs) + " ")
+				writeMixinSignature(ctx, um.Mixin, typeArgMap(um.Mixin, typeArgs))
+				if ctx.debugEnabled {
+					ctx.writeln(" // " + path.Join(ulib.Library.Module,
+						ulib.Library.PathInModule) + "." + um.Mixin.Name.Ident)
+				} else {
+					ctx.writeln("")
+				}
 			}
 		}
 
@@ -83,7 +87,10 @@ func writeLibrary(ctx *ctx, ulib fileutil.UsedLibrary) {
 			ctx.writeln(ln)
 		}
 	}
+	defer func() { ctx.lineFile = nil }()
+
 	for _, f := range ulib.Library.Files {
+		ctx.lineFile = f
 		fileutil.Walk(f.Scope,
 			func(parents []fileutil.WalkContext, wctx fileutil.WalkContext) (dive bool, err error) {
 				c, ok := (*wctx.Item).(file.Code)
@@ -118,9 +125,7 @@ func writeLibrary(ctx *ctx, ulib fileutil.UsedLibrary) {
 				}
 
 				ctx.debugItem(c, "(see below)")
-				for _, ln := range c.Lines {
-					ctx.writeln(ln.Code)
-				}
+				writeCodeLines(ctx, c, statementStarts(c))
 
 				return false, nil
 			})
@@ -128,8 +133,18 @@ func writeLibrary(ctx *ctx, ulib fileutil.UsedLibrary) {
 
 	if !ulib.Library.Precompiled {
 		for _, m := range ulib.Mixins {
-			ctx.write(ctx.nextMixinIdent() + " = ")
-			writeMixinFunc(ctx, m.Mixin)
+			ctx.lineFile = libraryMixinFile(ulib.Library, m.Mixin)
+			varName := ctx.nextMixinIdent()
+
+			key := libraryMixinKey(ulib.Library.Module, ulib.Library.PathInModule, m.Mixin.Name.Ident)
+			for _, typeArgs := range mixinInstances(ctx, key, m.Mixin) {
+				instName := genericMixinName(varName, typeArgs)
+				ctx.write(instName + " = ")
+				writeMixinInstance(ctx, m.Mixin, typeArgs)
+				if len(typeArgs) > 0 {
+					ctx.writeln("_ = " + instName)
+				}
+			}
 		}
 
 		return
@@ -138,13 +153,16 @@ func writeLibrary(ctx *ctx, ulib fileutil.UsedLibrary) {
 	for _, a := range ulib.Mixins {
 		for _, b := range ulib.Library.Mixins {
 			if a.Mixin.Name.Ident == b.Mixin.Name.Ident {
-				ctx.write("var " + b.Var + " ")
-				writeMixinSignature(ctx, a.Mixin)
-				if ctx.debugEnabled {
-					ctx.writeln(" // " + path.Join(ulib.Library.Module, ulib.Library.PathInModule) +
-						"." + a.Mixin.Name.Ident)
-				} else {
-					ctx.writeln("")
+				key := libraryMixinKey(ulib.Library.Module, ulib.Library.PathInModule, a.Mixin.Name.Ident)
+				for _, typeArgs := range mixinInstances(ctx, key, a.Mixin) {
+					ctx.write("var " + genericMixinName(b.Var, typeArgs) + " ")
+					writeMixinSignature(ctx, a.Mixin, typeArgMap(a.Mixin, typeArgs))
+					if ctx.debugEnabled {
+						ctx.writeln(" // " + path.Join(ulib.Library.Module, ulib.Library.PathInModule) +
+							"." + a.Mixin.Name.Ident)
+					} else {
+						ctx.writeln("")
+					}
 				}
 
 				break
@@ -155,42 +173,17 @@ func writeLibrary(ctx *ctx, ulib fileutil.UsedLibrary) {
 	for _, libDep := range ulib.Library.Dependencies {
 	mixins:
 		for _, mDep := range libDep.Mixins {
+			// instantiations of generic mixins are bound below
+			if len(mDep.Mixin.TypeParams) > 0 {
+				continue
+			}
+
 			for _, requiredBy := range mDep.RequiredBy {
 				for _, um := range ulib.Mixins {
 					if um.Mixin.Name.Ident == requiredBy {
-						ctx.write(mDep.Var + " := func(")
-						for i, param := range mDep.Mixin.Params {
-							ctx.write(fmt.Sprint("p", i, " "))
-							if param.Default != nil {
-								ctx.write("*")
-							}
-
-							if param.Type != nil {
-								ctx.write(param.Type.Type)
-							} else {
-								ctx.write(param.InferredType)
-							}
-							ctx.write(", ")
-						}
-						for i := range mDep.Mixin.Blocks {
-							ctx.write(fmt.Sprint("b", i, " func(), "))
-						}
-						if mDep.Mixin.HasAndPlaceholders {
-							ctx.write("ab func()")
-						}
-						ctx.write(") { ")
-						ctx.write(ctx.mixinFuncNames.mixinByName(ctx, libDep.Module, libDep.PathInModule, mDep.Name))
-						ctx.write("(")
-						for i := range mDep.Mixin.Params {
-							ctx.write(fmt.Sprint("p", i, ", "))
-						}
-						for i := range mDep.Mixin.Blocks {
-							ctx.write(fmt.Sprint("b", i, ", "))
-						}
-						if mDep.Mixin.HasAndPlaceholders {
-							ctx.write("ab")
-						}
-						ctx.writeln(") }")
+						ctx.write(mDep.Var + " := ")
+						writeMixinForwarder(ctx, mDep.Mixin, nil,
+							ctx.mixinFuncNames.mixinByName(ctx, libDep.Module, libDep.PathInModule, mDep.Name))
 						continue mixins
 					}
 				}
@@ -198,14 +191,42 @@ func writeLibrary(ctx *ctx, ulib fileutil.UsedLibrary) {
 		}
 	}
 
+	declared := make(map[string]struct{})
 	for _, a := range ulib.Mixins {
 		for _, b := range ulib.Library.Mixins {
-			if a.Mixin.Name.Ident == b.Mixin.Name.Ident {
+			if a.Mixin.Name.Ident == b.Mixin.Name.Ident && len(a.Mixin.TypeParams) == 0 {
+				writeInstantiationBindings(ctx, ulib.Library, b, nil, declared)
+				break
+			}
+		}
+	}
+
+	for _, a := range ulib.Mixins {
+		for _, b := range ulib.Library.Mixins {
+			if a.Mixin.Name.Ident != b.Mixin.Name.Ident {
+				continue
+			}
+
+			for _, hash := range b.ScriptHashes {
+				ctx.scriptHashes = appendHash(ctx.scriptHashes, hash)
+			}
+			for _, hash := range b.StyleHashes {
+				ctx.styleHashes = appendHash(ctx.styleHashes, hash)
+			}
+
+			if len(a.Mixin.TypeParams) == 0 {
 				ctx.write(b.Var + " = ")
 				ctx.writeBytes(a.Mixin.Precompiled)
 				ctx.writeln("")
 				break
 			}
+
+			key := libraryMixinKey(ulib.Library.Module, ulib.Library.PathInModule, a.Mixin.Name.Ident)
+			for _, typeArgs := range mixinInstances(ctx, key, a.Mixin) {
+				ctx.write(genericMixinName(b.Var, typeArgs) + " = ")
+				writePrecompiledMixinInstance(ctx, ulib.Library, b, typeArgs)
+			}
+			break
 		}
 	}
 
@@ -213,30 +234,152 @@ func writeLibrary(ctx *ctx, ulib fileutil.UsedLibrary) {
 		for _, b := range ulib.Library.Mixins {
 			if a.Mixin.Name.Ident == b.Mixin.Name.Ident {
 				varName := ctx.nextMixinIdent()
-				ctx.writeln(varName + " = " + b.Var)
-				ctx.writeln("_ = " + varName + " // in case this is only a dependency of another mixin in this lib")
+
+				key := libraryMixinKey(ulib.Library.Module, ulib.Library.PathInModule, a.Mixin.Name.Ident)
+				for _, typeArgs := range mixinInstances(ctx, key, a.Mixin) {
+					instName := genericMixinName(varName, typeArgs)
+					ctx.writeln(instName + " = " + genericMixinName(b.Var, typeArgs))
+					ctx.writeln("_ = " + instName + " // in case this is only a dependency of another mixin in this lib")
+				}
 				break
 			}
 		}
 	}
 }
 
-func writeMixinSignature(ctx *ctx, m *file.Mixin) {
+// writeMixinForwarder writes a func literal with the signature of m, with
+// its type params replaced using typeArgs, that calls the func stored in the
+// variable target.
+//
+// Since target is only resolved when the literal is called, it may be
+// assigned after the literal is written.
+func writeMixinForwarder(ctx *ctx, m *file.Mixin, typeArgs map[string]string, target string) {
 	ctx.write("func(")
-	for _, param := range m.Params {
+	for i, param := range instantiateParams(m, typeArgs) {
+		ctx.write(fmt.Sprint("p", i, " "))
 		if param.Default != nil {
 			ctx.write("*")
 		}
 
-		if param.Type != nil {
-			ctx.write(param.Type.Type)
+		ctx.write(mixinParamType(param) + ", ")
+	}
+	for i, b := range m.Blocks {
+		ctx.write(fmt.Sprint("b", i, " ", blockFuncType(b, typeArgs), ", "))
+	}
+	if m.HasAndPlaceholders {
+		ctx.write("ab func()")
+	}
+	ctx.write(") { ")
+	ctx.write(target)
+	ctx.write("(")
+	for i := range m.Params {
+		ctx.write(fmt.Sprint("p", i, ", "))
+	}
+	for i := range m.Blocks {
+		ctx.write(fmt.Sprint("b", i, ", "))
+	}
+	if m.HasAndPlaceholders {
+		ctx.write("ab")
+	}
+	ctx.writeln(") }")
+}
+
+// mixinParamType returns the type of the variable that holds the value of
+// param inside its mixin.
+//
+// For params with a default, this is the type of the dereferenced pointer.
+func mixinParamType(param file.MixinParam) string {
+	if param.Variadic {
+		return "[]" + param.Type.Type
+	} else if param.Type != nil {
+		return param.Type.Type
+	}
+
+	return param.InferredType
+}
+
+// blockFuncType returns the type of the func that fills the block b, with
+// the type params of its mixin replaced using typeArgs.
+func blockFuncType(b file.MixinBlockInfo, typeArgs map[string]string) string {
+	var sb strings.Builder
+	sb.WriteString("func(")
+	for i, param := range b.Params {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+
+		sb.WriteString(blockParamType(param, typeArgs))
+	}
+	sb.WriteString(")")
+
+	return sb.String()
+}
+
+// blockFuncLiteral returns the start of the func literal that fills the
+// block placeholder, binding the values passed by the mixin to the params of
+// the filling block b.
+//
+// If b is nil, or has no params, the passed values are discarded.
+func blockFuncLiteral(placeholder file.MixinBlockInfo, b *file.Block, typeArgs map[string]string) string {
+	var sb strings.Builder
+	sb.WriteString("func(")
+	for i, param := range placeholder.Params {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+
+		if b != nil && i < len(b.Params) {
+			sb.WriteString(b.Params[i].Name.Ident)
 		} else {
-			ctx.write(param.InferredType)
+			sb.WriteString("_")
 		}
-		ctx.write(", ")
+
+		sb.WriteString(" " + blockParamType(param, typeArgs))
 	}
-	for range m.Blocks {
-		ctx.write("func(), ")
+	sb.WriteString(") {")
+
+	return sb.String()
+}
+
+// blockParamType returns the type of the block param, with the type params of
+// its mixin replaced using typeArgs.
+func blockParamType(param file.BlockParam, typeArgs map[string]string) string {
+	if param.Type == nil {
+		// validate will catch this
+		return ""
+	} else if len(typeArgs) > 0 {
+		return substituteType(param.Type.Type, typeArgs)
+	}
+
+	return param.Type.Type
+}
+
+// libraryMixinFile returns the file of lib that m is defined in.
+func libraryMixinFile(lib *file.Library, m *file.Mixin) *file.File {
+	for _, f := range lib.Files {
+		for _, itm := range f.Scope {
+			if fm, ok := itm.(file.Mixin); ok && fm.Name.Ident == m.Name.Ident {
+				return f
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeMixinSignature writes the type of the func of m, with its type params
+// replaced using typeArgs.
+func writeMixinSignature(ctx *ctx, m *file.Mixin, typeArgs map[string]string) {
+	ctx.write("func(")
+	for _, param := range instantiateParams(m, typeArgs) {
+		if param.Default != nil {
+			ctx.write("*")
+		}
+
+		ctx.write(mixinParamType(param) + ", ")
+	}
+	for _, b := range m.Blocks {
+		ctx.write(blockFuncType(b, typeArgs) + ", ")
 	}
 	if m.HasAndPlaceholders {
 		ctx.write("func()")
@@ -249,8 +392,18 @@ func scopeMixin(ctx *ctx, m file.Mixin) {
 	ctx.flushClasses()
 
 	name := ctx.mixinFuncNames.addScope(ctx, &m)
-	ctx.write(name + " := ")
-	writeMixinFunc(ctx, &m)
+	if len(m.TypeParams) == 0 {
+		ctx.write(name + " := ")
+		writeMixinFunc(ctx, &m)
+		return
+	}
+
+	for _, typeArgs := range ctx.genericInstances[scopeMixinKey(&m)] {
+		instName := genericMixinName(name, typeArgs)
+		ctx.write(instName + " := ")
+		writeMixinInstance(ctx, &m, typeArgs)
+		ctx.writeln("_ = " + instName)
+	}
 }
 
 func writeMixinFunc(ctx *ctx, m *file.Mixin) {
@@ -281,8 +434,10 @@ func writeMixinFunc(ctx *ctx, m *file.Mixin) {
 		return true, nil
 	})
 
+	params := instantiateParams(m, ctx.typeArgs)
+
 	ctx.write("func(")
-	for _, param := range m.Params {
+	for _, param := range params {
 		if param.Default != nil {
 			ctx.write(ctx.ident("mixinParam_" + param.Name.Ident))
 		} else {
@@ -296,21 +451,23 @@ func writeMixinFunc(ctx *ctx, m *file.Mixin) {
 		}
 
 		if param.Type != nil {
-			ctx.write(param.Type.Type)
-		} else {
-			ctx.write(param.InferredType)
+			ctx.write(ctx.inlineLineDirective(param.Type))
 		}
-		ctx.write(", ")
+		ctx.write(mixinParamType(param) + ", ")
 	}
 	for _, b := range m.Blocks {
-		ctx.write(ctx.ident("mixinBlock_"+b.Name) + " func(), ")
+		ctx.write(ctx.ident("mixinBlock_"+b.Name) + " " + blockFuncType(b, ctx.typeArgs) + ", ")
 	}
 	if m.HasAndPlaceholders {
 		ctx.write(ctx.ident(andPlaceholderFunc) + " func()")
 	}
 	ctx.writeln(") {")
 
-	for _, param := range m.Params {
+	if len(m.TypeParams) > 0 && ctx.typeArgs != nil {
+		writeTypeAliases(ctx, m)
+	}
+
+	for _, param := range params {
 		if param.Default == nil {
 			continue
 		}
@@ -320,12 +477,7 @@ func writeMixinFunc(ctx *ctx, m *file.Mixin) {
 
 		var defaultVal string
 
-		var typ string
-		if param.Type != nil {
-			typ = param.Type.Type
-		} else {
-			typ = param.InferredType
-		}
+		typ := mixinParamType(param)
 		switch woofType(ctx, typ) {
 		case "URL":
 			defaultVal = escapedInlineContextExpression(ctx, *param.Default, urlAttrExprEscaper)
@@ -367,28 +519,23 @@ func mixinCall(ctx *ctx, mc file.MixinCall) {
 		ctx.debugItem(mc, mc.Name.Ident)
 	}
 
-	funcName := ctx.mixinFuncNames.mixin(ctx, mc)
+	funcName := mixinFuncName(ctx, mc)
 
 	ctx.flushGenerate()
 	ctx.flushClasses()
 	ctx.callUnclosedIfUnclosed()
+	ctx.checkContext()
+	pushMixin(ctx, mc)
 
+	ctx.lineDirective(mc)
 	ctx.write(funcName + "(")
 
 	ctx.startScope(false)
 
-params:
-	for _, param := range mc.Mixin.Mixin.Params {
-		for _, arg := range mc.Args {
-			if arg.Name.Ident == param.Name.Ident {
-				ctx.write(mixinArgExpression(ctx, param, arg))
-				ctx.write(", ")
-				continue params
-			}
-		}
+	typeArgs := typeArgMap(mc.Mixin.Mixin, mixinCallTypeArgs(mc, ctx.typeArgs))
+	params := instantiateParams(mc.Mixin.Mixin, typeArgs)
 
-		ctx.write("nil, ")
-	}
+	writeMixinArgs(ctx, mc, params)
 
 blocks:
 	for _, placeholder := range mc.Mixin.Mixin.Blocks {
@@ -396,7 +543,7 @@ blocks:
 			if len(mc.Body) == 1 {
 				switch itm := mc.Body[0].(type) {
 				case file.InlineText:
-					ctx.writeln("func() {")
+					ctx.writeln(blockFuncLiteral(placeholder, nil, typeArgs))
 
 					ctx.startScope(false).startClosed = maybeClosed
 					inlineText(ctx, itm)
@@ -407,7 +554,7 @@ blocks:
 					ctx.write("}, ")
 					continue
 				case file.BlockExpansion:
-					ctx.writeln("func() {")
+					ctx.writeln(blockFuncLiteral(placeholder, nil, typeArgs))
 
 					ctx.startScope(false).startClosed = maybeClosed
 					blockExpansion(ctx, itm)
@@ -418,7 +565,7 @@ blocks:
 					ctx.endScope()
 					continue
 				case file.MixinMainBlockShorthand:
-					ctx.writeln("func() {")
+					ctx.writeln(blockFuncLiteral(placeholder, nil, typeArgs))
 
 					ctx.startScope(false).startClosed = maybeClosed
 					scope(ctx, itm.Body, false)
@@ -439,7 +586,7 @@ blocks:
 			}
 
 			if b.Name.Ident == placeholder.Name {
-				ctx.writeln("func() {")
+				ctx.writeln(blockFuncLiteral(placeholder, &b, typeArgs))
 
 				ctx.startScope(false).startClosed = maybeClosed
 				scope(ctx, b.Body, false)
@@ -483,11 +630,51 @@ blocks:
 	}
 
 	ctx.writeln(")")
+	ctx.writeln(ctx.contextFunc("PopMixin"))
 
 	ctx.endScope()
 	ctx.scope().startClosed = maybeClosed
 }
 
+// writeMixinArgs writes the args of mc for the passed params, each followed by
+// a comma.
+//
+// If line directives are enabled, every arg is placed on its own line,
+// preceded by an inline line directive, so that errors in it, e.g. a
+// mismatched type, are attributed to the arg and not just to its line.
+// They can't be placed on the same line, as gofmt moves comments directly
+// following a comma in front of it.
+func writeMixinArgs(ctx *ctx, mc file.MixinCall, params []file.MixinParam) {
+	oldInStatement := ctx.inStatement
+	ctx.inStatement = true
+	defer func() {
+		ctx.inStatement = oldInStatement
+		ctx.genLineDirective()
+	}()
+
+params:
+	for _, param := range params {
+		if param.Variadic {
+			ctx.write(variadicMixinArgExpression(ctx, param, mc.Args) + ", ")
+			continue
+		}
+
+		for _, arg := range mc.Args {
+			if arg.Name.Ident == param.Name.Ident {
+				if directive := ctx.inlineLineDirective(arg.Value); directive != "" {
+					ctx.writeln("")
+					ctx.write(directive)
+				}
+
+				ctx.write(mixinArgExpression(ctx, param, arg) + ", ")
+				continue params
+			}
+		}
+
+		ctx.write("nil, ")
+	}
+}
+
 func interpolationValueMixinCall(ctx *ctx, mc file.MixinCall, val file.InterpolationValue) {
 	if mc.Namespace != nil {
 		ctx.debugItem(mc, mc.Namespace.Ident+"."+mc.Name.Ident)
@@ -495,33 +682,29 @@ func interpolationValueMixinCall(ctx *ctx, mc file.MixinCall, val file.Interpola
 		ctx.debugItem(mc, mc.Name.Ident)
 	}
 
-	funcName := ctx.mixinFuncNames.mixin(ctx, mc)
+	funcName := mixinFuncName(ctx, mc)
 
 	ctx.flushGenerate()
 	ctx.flushClasses()
 	ctx.callUnclosedIfUnclosed()
+	ctx.checkContext()
+	pushMixin(ctx, mc)
 
+	ctx.lineDirective(mc)
 	ctx.write(funcName + "(")
 
-params:
-	for _, param := range mc.Mixin.Mixin.Params {
-		for _, arg := range mc.Args {
-			if arg.Name.Ident == param.Name.Ident {
-				ctx.write(mixinArgExpression(ctx, param, arg))
-				ctx.write(", ")
-				continue params
-			}
-		}
+	typeArgs := typeArgMap(mc.Mixin.Mixin, mixinCallTypeArgs(mc, ctx.typeArgs))
+	params := instantiateParams(mc.Mixin.Mixin, typeArgs)
 
-		ctx.write("nil, ")
-	}
+	writeMixinArgs(ctx, mc, params)
 
 	for _, placeholder := range mc.Mixin.Mixin.Blocks {
 		if placeholder.Name != "_" || val == nil {
 			ctx.write("nil, ")
+			continue
 		}
 
-		ctx.writeln("func() {")
+		ctx.writeln(blockFuncLiteral(placeholder, nil, typeArgs))
 
 		ctx.startScope(false).startClosed = maybeClosed
 		ctx.closeStartTag()
@@ -558,33 +741,45 @@ params:
 	}
 
 	ctx.writeln(")")
+	ctx.writeln(ctx.contextFunc("PopMixin"))
 
 	ctx.scope().startClosed = maybeClosed
 }
 
+// pushMixin writes a call to woof.Context.PushMixin for mc.
+func pushMixin(ctx *ctx, mc file.MixinCall) {
+	name := mc.Name.Ident
+	if mc.Namespace != nil {
+		name = mc.Namespace.Ident + "." + name
+	}
+
+	ctx.writeln(ctx.contextFunc("PushMixin", strconv.Quote(name),
+		strconv.Quote(renderFileName(ctx.srcFile())), strconv.Itoa(mc.Line), strconv.Itoa(mc.Col)))
+}
+
 // ============================================================================
 // Return
 // ======================================================================================
 
 func _return(ctx *ctx, ret file.Return) {
+	ctx.debugItem(ret, "return")
 	ctx.flushGenerate()
 	ctx.flushClasses()
 	ctx.callClosedIfClosed()
+	ctx.scope().terminated = true
 
-	if ctx.mixin != nil {
-		if ret.Err != nil {
-			ctx.writeln(ctx.contextFunc("Panic", inlineExpression(ctx, *ret.Err)))
-			return
-		}
-
-		ctx.writeln("return")
+	if ret.Err != nil {
+		errExpr := inlineExpression(ctx, *ret.Err)
+		ctx.writePos()
+		ctx.writeln(ctx.contextFunc("Panic", errExpr))
 		return
 	}
 
-	if ret.Err != nil {
-		ctx.writeln("return " + inlineExpression(ctx, *ret.Err))
+	if ctx.mixin != nil {
+		ctx.writeln("return")
 		return
 	}
 
+	ctx.writeln(ctx.contextFunc("Done"))
 	ctx.writeln("return nil")
 }
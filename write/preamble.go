@@ -1,11 +1,19 @@
 package write
 
 import (
+	"fmt"
 	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/mavolin/corgi/file"
 	"github.com/mavolin/corgi/file/fileutil"
+	"github.com/mavolin/corgi/internal/list"
 	"github.com/mavolin/corgi/internal/meta"
+	"github.com/mavolin/corgi/internal/stack"
 )
 
 func writePackage(ctx *ctx) {
@@ -72,6 +80,47 @@ func writeBaseImports(ctx *ctx) {
 	ctx.writeln(ctx.ident("fmt") + ` "fmt"`)
 	ctx.writeln(ctx.ident("io") + ` "io"`)
 	ctx.writeln(ctx.ident("woof") + ` "github.com/mavolin/corgi/woof"`)
+
+	if hasMachineComment(ctx.mainFile(), "context") {
+		ctx.writeln(ctx.ident("context") + ` "context"`)
+	}
+
+	if hasMachineComment(ctx.mainFile(), "handler") {
+		ctx.writeln(ctx.ident("http") + ` "net/http"`)
+		ctx.writeln(ctx.ident("corgihttp") + ` "github.com/mavolin/corgi/corgihttp"`)
+	}
+}
+
+// hasMachineComment reports whether f has a top-level `//corgi:directive`
+// machine comment.
+func hasMachineComment(f *file.File, directive string) bool {
+	for _, comm := range f.TopLevelComments {
+		mcom := fileutil.ParseMachineComment(comm)
+		if mcom != nil && mcom.Namespace == "corgi" && mcom.Directive == directive {
+			return true
+		}
+	}
+
+	return false
+}
+
+// contextParam returns the name of the context.Context param of the funcs
+// generated for f, as set by a `//corgi:context [name]` machine comment.
+//
+// If f has no such machine comment, contextParam returns "".
+func contextParam(f *file.File) string {
+	for _, comm := range f.TopLevelComments {
+		mcom := fileutil.ParseMachineComment(comm)
+		if mcom != nil && mcom.Namespace == "corgi" && mcom.Directive == "context" {
+			if mcom.Args == "" {
+				return "ctx"
+			}
+
+			return mcom.Args
+		}
+	}
+
+	return ""
 }
 
 func writeCodegenComment(ctx *ctx) {
@@ -81,29 +130,68 @@ func writeCodegenComment(ctx *ctx) {
 }
 
 func writeGlobalCode(ctx *ctx) {
+	ctx.lineFile = ctx.mainFile()
+	defer func() { ctx.lineFile = nil }()
+
 	for _, code := range ctx.mainFile().GlobalCode {
-		for _, ln := range code.Lines {
-			ctx.writeln(ln.Code)
-		}
+		writeCodeLines(ctx, code, declStarts(code))
 	}
 }
 
+// writeFuncCode writes the top-level code and mixins of the files extending
+// the base template.
+//
+// The mixin scopes of those files are left open, so that the blocks they fill
+// can call their mixins.
 func writeFuncCode(ctx *ctx) {
-	for _, f := range ctx._stack[:len(ctx._stack)-1] {
+	oldStart := ctx.stackStart
+	defer func() {
+		ctx.stackStart = oldStart
+		ctx.lineFile = nil
+	}()
+
+	for i, f := range ctx._stack[1:] {
+		ctx.stackStart = i + 1
+		ctx.lineFile = f
+		ctx.mixinFuncNames.startScope(ctx)
+
 		ctx.debug("func code", f.Name)
 		for _, itm := range f.Scope {
-			c, ok := itm.(file.Code)
-			if !ok {
-				continue
+			switch itm := itm.(type) {
+			case file.Code:
+				code(ctx, itm)
+			case file.Mixin:
+				scopeMixin(ctx, itm)
 			}
-
-			code(ctx, c)
 		}
 	}
 }
 
 func writeFunc(ctx *ctx) {
-	ctx.write("func " + ctx.mainFile().Func.Name.Ident + "(" + ctx.ident("w") + " " + ctx.ioQual("Writer"))
+	writeRenderFunc(ctx, ctx.mainFile().Func.Name.Ident, func() {
+		scope(ctx, ctx.baseFile().Scope, false)
+	})
+}
+
+// writeRenderFunc writes a func with the params of the main func, that calls
+// writeBody to write the items it renders.
+func writeRenderFunc(ctx *ctx, funcName string, writeBody func()) {
+	// reset the state left behind by previously written funcs
+	ctx.scopes = stack.New1(rootNesting())
+	ctx.mixinFuncNames.scope = make(map[*file.File]*list.List[map[string]string])
+	ctx.mixinFuncNames.generic = nil
+	ctx.hasNonce = false
+	ctx.hasStyleNonce = false
+	ctx.hasContext = false
+
+	goCtx := contextParam(ctx.mainFile())
+
+	ctx.lineFile = ctx.mainFile()
+	ctx.write("func " + funcName + "(")
+	if goCtx != "" {
+		ctx.write(goCtx + " " + ctx.ident("context") + ".Context, ")
+	}
+	ctx.write(ctx.ident("w") + " " + ctx.ioQual("Writer"))
 
 	for _, param := range ctx.mainFile().Func.Params {
 		ctx.write(", ")
@@ -115,30 +203,336 @@ func writeFunc(ctx *ctx) {
 			ctx.write(name.Ident)
 		}
 
-		ctx.write(" " + param.Type.Type)
+		ctx.write(" " + ctx.inlineLineDirective(param.Type))
+		if param.Variadic {
+			ctx.write("...")
+		}
+		ctx.write(param.Type.Type)
 	}
 
-	ctx.writeln(") error {")
+	// the result is named, so that the deferred recover can set it
+	ctx.writeln(") (" + ctx.ident("err") + " error) {")
+	ctx.lineFile = nil
 	defer ctx.writeln("}")
 
 	ctx.writeln(ctx.ident(ctxVar) + " := " + ctx.woofFunc("NewContext", ctx.ident("w")))
-	ctx.writeln("var " + ctx.ident("err") + " error")
-	ctx.writeln("defer func() { " + ctx.ident("err") + " = " + ctx.contextFunc("Recover") + "}()")
+	ctx.writeln("defer func() {")
+	ctx.writeln("if " + ctx.contextFunc("Recovers") + " {")
+	ctx.writeln(ctx.ident("err") + " = " + ctx.contextFunc("Recover", "recover()"))
+	ctx.writeln("}")
+	ctx.writeln("}()")
+
+	if goCtx != "" {
+		ctx.writeln(ctx.contextFunc("SetContext", goCtx))
+		ctx.hasContext = true
+	}
 
 	for _, comm := range ctx.mainFile().TopLevelComments {
 		mcom := fileutil.ParseMachineComment(comm)
-		if mcom.Namespace == "corgi" && mcom.Directive == "nonce" {
+		if mcom == nil || mcom.Namespace != "corgi" {
+			continue
+		}
+
+		switch mcom.Directive {
+		case "nonce":
+			if ctx.hasNonce {
+				continue
+			}
+
 			ctx.debugItem(comm, comm.Lines[0].Comment+" (used to inject script nonce attr)")
-			ctx.writeln(ctx.contextFunc("SetScriptNonce", mcom.Args))
+			// without args, the nonce set through woof.WithNonce is used
+			if mcom.Args != "" {
+				ctx.writeln(ctx.contextFunc("SetScriptNonce", mcom.Args))
+			}
 			ctx.hasNonce = true
-			break
+		case "stylenonce":
+			if ctx.hasStyleNonce {
+				continue
+			}
+
+			ctx.debugItem(comm, comm.Lines[0].Comment+" (used to inject style nonce attr)")
+			if mcom.Args != "" {
+				ctx.writeln(ctx.contextFunc("SetStyleNonce", mcom.Args))
+			}
+			ctx.hasStyleNonce = true
 		}
 	}
 
 	writeLibMixins(ctx)
 	writeFuncCode(ctx)
 
-	scope(ctx, ctx.baseFile().Scope, false)
+	writeBody()
 	ctx.flushGenerate()
+	ctx.writeln(ctx.contextFunc("Done"))
 	ctx.writeln("return " + ctx.ident("err"))
 }
+
+// writeCSPHashesFunc writes a func returning the CSP hashes of the static
+// inline scripts and styles written by writeFunc, if the main file requests
+// it using a `//corgi:csphashes` machine comment.
+//
+// The func is named after the main func, suffixed with CSPHashes.
+func writeCSPHashesFunc(ctx *ctx) {
+	if !hasMachineComment(ctx.mainFile(), "csphashes") {
+		return
+	}
+
+	name := ctx.mainFile().Func.Name.Ident
+
+	ctx.writeln("")
+	ctx.writeln("// " + name + "CSPHashes returns the CSP hashes of the static inline scripts")
+	ctx.writeln("// and styles rendered by " + name + ".")
+	ctx.writeln("func " + name + "CSPHashes() " + ctx.woofQual("CSPHashes") + " {")
+	ctx.writeln("return " + ctx.woofQual("CSPHashes") + "{")
+	ctx.writeln("Scripts: " + stringSliceLit(ctx.scriptHashes) + ",")
+	ctx.writeln("Styles: " + stringSliceLit(ctx.styleHashes) + ",")
+	ctx.writeln("}")
+	ctx.writeln("}")
+}
+
+func stringSliceLit(ss []string) string {
+	if len(ss) == 0 {
+		return "nil"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("[]string{")
+	for i, s := range ss {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(strconv.Quote(s))
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// writeHandlerFunc writes a func returning an http.Handler that renders the
+// main func, if the main file requests it using a `//corgi:handler` machine
+// comment.
+//
+// The func is named after the main func, suffixed with Handler.
+// If the main func has params, the handler func expects a func that extracts
+// their values from the request.
+func writeHandlerFunc(ctx *ctx) {
+	if !hasMachineComment(ctx.mainFile(), "handler") {
+		return
+	}
+
+	fn := ctx.mainFile().Func
+	name := fn.Name.Ident
+
+	ctx.lineFile = ctx.mainFile()
+	defer func() { ctx.lineFile = nil }()
+
+	ctx.writeln("")
+	ctx.lineDirective(fn)
+	defer ctx.genLineDirective()
+	ctx.writeln("// " + name + "Handler returns an http.Handler that renders " + name + ".")
+
+	var argTypes, argVars []string
+	for _, param := range fn.Params {
+		n := len(param.Names)
+		if n == 0 {
+			n = 1
+		}
+
+		for i := 0; i < n; i++ {
+			// args returns the values of a variadic param as a slice
+			if param.Variadic {
+				argTypes = append(argTypes, "[]"+param.Type.Type)
+			} else {
+				argTypes = append(argTypes, param.Type.Type)
+			}
+			argVars = append(argVars, ctx.ident("arg"+strconv.Itoa(len(argVars))))
+		}
+	}
+
+	if len(argTypes) == 0 {
+		ctx.writeln("func " + name + "Handler(o " + ctx.corgihttpQual("Options") + ") " +
+			ctx.httpQual("Handler") + " {")
+	} else {
+		ctx.writeln("//")
+		ctx.writeln("// args is called for every request to obtain the args " + name + " is called with.")
+		ctx.writeln("// If it returns an error, the error handler of the passed options is called.")
+		ctx.writeln("func " + name + "Handler(" +
+			"args func(*" + ctx.httpQual("Request") + ") (" + strings.Join(argTypes, ", ") + ", error), " +
+			"o " + ctx.corgihttpQual("Options") + ") " + ctx.httpQual("Handler") + " {")
+	}
+
+	ctx.writeln("return " + ctx.corgihttpQual("Handler") + "(func(" +
+		ctx.ident("w") + " " + ctx.ioQual("Writer") + ", " + ctx.ident("r") + " *" + ctx.httpQual("Request") + ") error {")
+
+	if len(argTypes) > 0 {
+		ctx.writeln(strings.Join(argVars, ", ") + ", " + ctx.ident("err") + " := args(" + ctx.ident("r") + ")")
+		ctx.writeln("if " + ctx.ident("err") + " != nil {")
+		ctx.writeln("return " + ctx.ident("err"))
+		ctx.writeln("}")
+		ctx.writeln("")
+	}
+
+	callArgs := append([]string{ctx.ident("w")}, argVars...)
+	if len(fn.Params) > 0 && fn.Params[len(fn.Params)-1].Variadic {
+		callArgs[len(callArgs)-1] += "..."
+	}
+	if contextParam(ctx.mainFile()) != "" {
+		callArgs = append([]string{ctx.ident("r") + ".Context()"}, callArgs...)
+	}
+
+	ctx.writeln("return " + name + "(" + strings.Join(callArgs, ", ") + ")")
+	ctx.writeln("}, o)")
+	ctx.writeln("}")
+}
+
+// writeFragmentFuncs writes a func for each template block placeholder, that
+// renders only that block, if the main file requests it using a
+// `//corgi:fragments` machine comment.
+//
+// The args of the machine comment are the names of the blocks to write funcs
+// for.
+// If there are no args, funcs are written for all blocks.
+//
+// The funcs are named after the main func, suffixed with Block and the
+// capitalized name of the block.
+func writeFragmentFuncs(ctx *ctx) {
+	var names []string
+	var found bool
+	for _, comm := range ctx.mainFile().TopLevelComments {
+		mcom := fileutil.ParseMachineComment(comm)
+		if mcom != nil && mcom.Namespace == "corgi" && mcom.Directive == "fragments" {
+			names = append(names, strings.Fields(mcom.Args)...)
+			found = true
+		}
+	}
+	if !found {
+		return
+	}
+
+	placeholders := templateBlockPlaceholders(ctx)
+	if len(names) == 0 {
+		for _, p := range placeholders {
+			names = append(names, p.block.Name.Ident)
+		}
+	}
+
+	for _, name := range names {
+		var p *blockPlaceholder
+		for i := range placeholders {
+			if placeholders[i].block.Name.Ident == name {
+				p = &placeholders[i]
+				break
+			}
+		}
+		if p == nil {
+			panic(fmt.Errorf("%s: //corgi:fragments: unknown block `%s`", ctx.mainFile().Name, name))
+		}
+
+		first, n := utf8.DecodeRuneInString(name)
+		funcName := ctx.mainFile().Func.Name.Ident + "Block" + string(unicode.ToUpper(first)) + name[n:]
+
+		ctx.writeln("")
+		ctx.writeln("// " + funcName + " renders only the " + name + " block of " + ctx.mainFile().Func.Name.Ident + ".")
+		writeRenderFunc(ctx, funcName, func() {
+			writeFragmentCode(ctx)
+
+			oldStart := ctx.stackStart
+			ctx.stackStart = p.stackPos
+			scope(ctx, file.Scope{p.block}, false)
+			ctx.stackStart = oldStart
+		})
+	}
+}
+
+// writeFragmentCode writes the top-level code and mixins of the base file,
+// which are written as part of the base file's scope, if writing the main func.
+//
+// Since the rendered block may not use all top-level variables and mixins,
+// it also marks them as used.
+func writeFragmentCode(ctx *ctx) {
+	oldStart := ctx.stackStart
+	defer func() {
+		ctx.stackStart = oldStart
+		ctx.lineFile = nil
+	}()
+
+	ctx.stackStart = 0
+	ctx.lineFile = ctx.baseFile()
+	ctx.mixinFuncNames.startScope(ctx)
+
+	for _, itm := range ctx.baseFile().Scope {
+		switch itm := itm.(type) {
+		case file.Code:
+			code(ctx, itm)
+		case file.Mixin:
+			scopeMixin(ctx, itm)
+		}
+	}
+
+	ctx.lineFile = nil
+	for _, f := range ctx._stack {
+		for _, itm := range f.Scope {
+			if c, ok := itm.(file.Code); ok {
+				for _, name := range declaredVars(c) {
+					ctx.writeln("_ = " + name)
+				}
+			}
+		}
+
+		if scopeMixins := ctx.mixinFuncNames.scope[f]; scopeMixins != nil {
+			for e := scopeMixins.Front(); e != nil; e = e.Next() {
+				varNames := make([]string, 0, len(e.V()))
+				for _, varName := range e.V() {
+					if _, ok := ctx.mixinFuncNames.generic[varName]; !ok {
+						varNames = append(varNames, varName)
+					}
+				}
+				sort.Strings(varNames)
+
+				for _, varName := range varNames {
+					ctx.writeln("_ = " + varName)
+				}
+			}
+		}
+	}
+}
+
+type blockPlaceholder struct {
+	block file.Block
+	// stackPos is the position of the file of the placeholder in the stack.
+	stackPos int
+}
+
+// templateBlockPlaceholders returns the block placeholders of the templates
+// extended by the main file, in order of appearance.
+//
+// If a block is placed multiple times, only its first placeholder is
+// returned.
+func templateBlockPlaceholders(ctx *ctx) []blockPlaceholder {
+	var ps []blockPlaceholder
+	seen := make(map[string]struct{})
+
+	for i, f := range ctx._stack[:len(ctx._stack)-1] {
+		i, f := i, f
+		_ = fileutil.Walk(f.Scope, func(parents []fileutil.WalkContext, wctx fileutil.WalkContext) (dive bool, err error) {
+			switch itm := (*wctx.Item).(type) {
+			case file.Mixin:
+				// blocks in mixins are mixin blocks
+				return false, nil
+			case file.Block:
+				// the top-level blocks of extending templates fill blocks
+				if f.Extend != nil && len(parents) == 0 {
+					return true, nil
+				}
+
+				if _, ok := seen[itm.Name.Ident]; !ok {
+					seen[itm.Name.Ident] = struct{}{}
+					ps = append(ps, blockPlaceholder{block: itm, stackPos: i})
+				}
+			}
+
+			return true, nil
+		})
+	}
+
+	return ps
+}
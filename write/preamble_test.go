@@ -0,0 +1,53 @@
+package write_test
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mavolin/corgi"
+	"github.com/mavolin/corgi/write"
+)
+
+func TestFragments(t *testing.T) {
+	t.Parallel()
+
+	l, err := corgi.NewFSLoader(corgi.FSLoadOptions{
+		Modules: map[string]fs.FS{
+			"example.com/test": fstest.MapFS{
+				"base.corgi": &fstest.MapFile{
+					Data: []byte("html\n  head: title: block title\n  body: block énigme\n"),
+				},
+				"page.corgi": &fstest.MapFile{
+					Data: []byte("//corgi:fragments title énigme\n\n" +
+						"extend \"example.com/test/base.corgi\"\n\n" +
+						"func Page()\n\n" +
+						"block title Hello\n\n" +
+						"block énigme\n  p Hello\n"),
+				},
+			},
+		},
+		NoPrecompile: true,
+	})
+	require.NoError(t, err)
+
+	f, err := l.LoadMain("example.com/test/page.corgi")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = write.New(write.Options{}).GenerateFile(&buf, "test", f)
+	require.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, "func PageBlockTitle(")
+	assert.Contains(t, out, "func PageBlockÉnigme(")
+
+	_, err = parser.ParseFile(token.NewFileSet(), "page.corgi.go", out, 0)
+	assert.NoError(t, err, out)
+}
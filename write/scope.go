@@ -27,9 +27,11 @@ func scope(ctx *ctx, s file.Scope, needsCodeScoping bool) {
 }
 
 func scopeItem(ctx *ctx, itm file.ScopeItem) {
+	defer ctx.genLineDirective()
+
 	switch itm := itm.(type) {
 	case file.CorgiComment:
-	// ignore
+		corgiComment(ctx, itm)
 
 	case file.Block:
 		block(ctx, itm)
@@ -45,6 +47,10 @@ func scopeItem(ctx *ctx, itm file.ScopeItem) {
 		_switch(ctx, itm)
 	case file.For:
 		_for(ctx, itm)
+	case file.Break:
+		loopControl(ctx, itm, "break", itm.Label)
+	case file.Continue:
+		loopControl(ctx, itm, "continue", itm.Label)
 	case file.Doctype:
 		doctype(ctx, itm)
 	case file.HTMLComment:
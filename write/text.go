@@ -95,6 +95,9 @@ func simpleInterpolation(ctx *ctx, interp file.SimpleInterpolation) {
 func elementInterpolation(ctx *ctx, interp file.ElementInterpolation) {
 	ctx.debugItem(interp, "(see below)")
 	ctx.startElem(interp.Element.Name, interp.Element.Void)
+	if interp.Element.Name == "script" {
+		useScriptTypeEscapers(ctx, interp.Element)
+	}
 
 	for _, acoll := range interp.Element.Attributes {
 		attributeCollection(ctx, acoll)
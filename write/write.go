@@ -36,6 +36,47 @@ type Options struct {
 	// Debug, if set to true, attaches file and position information of scope
 	// items to the generated file.
 	Debug bool
+
+	// Pretty, if set to true, generates indented HTML, placing each element,
+	// doctype, and HTML comment on its own line, except for interpolated
+	// elements and the contents of pre and textarea elements.
+	//
+	// Additionally, the contents of script and style elements, raw filters,
+	// and includes are not minified.
+	// Escaping is the same as in non-pretty mode.
+	//
+	// Since pretty mode adds whitespace between elements, the rendered page
+	// may look slightly different.
+	// It is intended for debugging and snapshot tests.
+	Pretty bool
+
+	// LineDirectives, if set to true, adds line directives to the generated
+	// file, so that compiler errors, vet diagnostics, and panics caused by Go
+	// code from a corgi file point to the corgi file instead of the generated
+	// file.
+	//
+	// Line directives are not added to the mixins of precompiled libraries.
+	LineDirectives bool
+	// LineDirectiveFileName returns the name of the passed file as used in
+	// line directives.
+	//
+	// Relative names are interpreted relative to the directory of the
+	// generated file.
+	//
+	// If LineDirectiveFileName is nil, the file's absolute path is used, or if
+	// that is not set, its name.
+	LineDirectiveFileName func(*file.File) string
+	// GeneratedFileName is the name of the generated file, as used in line
+	// directives.
+	//
+	// If set, the code corgi generates after an item, e.g. to close an
+	// element, is attributed back to the generated file.
+	// Otherwise, it is attributed to the lines following the item in its
+	// corgi file, which may not exist.
+	//
+	// Relative names are interpreted relative to the directory of the
+	// generated file.
+	GeneratedFileName string
 }
 
 type Writer struct {
@@ -89,7 +130,12 @@ func (w *Writer) GenerateFile(out io.Writer, destPackage string, f *file.File) (
 	writeImports(ctx)
 	writeCodegenComment(ctx)
 	writeGlobalCode(ctx)
+	collectGenericInstances(ctx)
+	writeConstraintChecks(ctx)
 	writeFunc(ctx)
+	writeFragmentFuncs(ctx)
+	writeCSPHashesFunc(ctx)
+	writeHandlerFunc(ctx)
 
 	return err
 }
@@ -211,14 +257,27 @@ func (w *Writer) PrecompileLibrary(out io.Writer, lib *file.Library) (err error)
 
 		ctx := newCtx(w.o)
 		ctx.out = &buf
+		// the absolute paths of the library's files won't be valid on the
+		// machines the library is used on
+		ctx.lineDirectives = false
 		ctx._stack = []*file.File{pm.File}
 		ctx.mixin = &pm.Mixin
 		ctx.mixinFuncNames = mixinFuncNames
 		ctx.hasNonce = true
+		ctx.hasStyleNonce = true
+		// the mixin may be called by a func with a context.Context
+		ctx.hasContext = true
+		// the libraries using this library need to instantiate the generic
+		// mixins this mixin calls
+		var insts []file.MixinInstantiation
+		ctx.instantiations = &insts
 
 		writeMixinFunc(ctx, &pm.Mixin)
 
 		lib.Mixins[i].Mixin.Precompiled = buf.Bytes()
+		lib.Mixins[i].Instantiations = insts
+		lib.Mixins[i].ScriptHashes = ctx.scriptHashes
+		lib.Mixins[i].StyleHashes = ctx.styleHashes
 	}
 
 	if err := precomp.Encode(out, lib); err != nil {